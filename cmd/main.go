@@ -8,18 +8,29 @@ import (
 	"time"
 
 	"st-test/cmd/util"
+	"st-test/internal/cluster"
 	"st-test/internal/http"
+	"st-test/internal/largeobject"
 	"st-test/internal/logger"
+	"st-test/internal/models"
+	"st-test/internal/replica"
 	"st-test/internal/repo"
+	"st-test/internal/scrubber"
+	"st-test/internal/search"
 	"st-test/internal/settings"
 	"st-test/internal/signals"
 	"st-test/internal/storage"
+	"st-test/internal/tracing"
+	"st-test/internal/webhook"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.uber.org/zap"
 )
 
 const (
 	serviceShutdownTimeout = 1 * time.Second
+	reencryptBatch         = 100
 )
 
 func main() {
@@ -51,14 +62,170 @@ func main() {
 		cancel()
 	})
 
-	ls, err := repo.NewRepo(sets.Storage)
+	reg := newRegistry()
+
+	// без включённой трассировки спаны не записываются.
+	tp := tracing.Noop()
+
+	var provider *tracing.Provider
+
+	if sets.Tracing.Enabled {
+		provider, err = tracing.New(ctx, sets.Tracing)
+		if err != nil {
+			stdlog.Fatal(err)
+		}
+
+		tp = provider
+	}
+
+	ls, err := repo.NewRepo(sets.Storage, repo.WithMetrics(reg), repo.WithTracer(tp))
 	if err != nil {
 		stdlog.Fatal(err)
 	}
 
-	store := storage.NewStore(log, ls)
+	storeOpts := []storage.Option{
+		storage.WithMetrics(reg),
+		storage.WithTracer(tp),
+		storage.WithQuotas(models.Quota{MaxObjects: sets.Quotas.MaxObjects, MaxBytes: sets.Quotas.MaxBytes},
+			clientQuotas(sets.Quotas.Clients)),
+	}
+
+	if sets.Search.Enabled {
+		storeOpts = append(storeOpts, storage.WithSearchIndex(search.NewIndex()))
+	}
+
+	if sets.Trash.Enabled {
+		storeOpts = append(storeOpts, storage.WithTrash(sets.Trash.Retention))
+	}
+
+	if sets.Expired.Enabled {
+		storeOpts = append(storeOpts, storage.WithExpiredArea(sets.Expired.Retention, sets.Expired.MaxObjects))
+	}
+
+	// ведомый экземпляр получает объекты только от ведущего.
+	if sets.Replica.Leader != "" {
+		storeOpts = append(storeOpts, storage.WithReplica())
+	}
+
+	var node *cluster.Node
+
+	// узел кластера применяет записи только из журнала Raft.
+	if sets.Cluster.Enabled {
+		if sets.Replica.Leader != "" {
+			stdlog.Fatal("cluster mode cannot be combined with replication from a leader")
+		}
+
+		node, err = cluster.NewNode(log, sets.Cluster)
+		if err != nil {
+			stdlog.Fatal(err)
+		}
+
+		storeOpts = append(storeOpts, storage.WithProposer(node))
+	}
+
+	store := storage.NewStore(log, ls, storeOpts...)
+
+	if node != nil {
+		if err := node.Start(store); err != nil {
+			stdlog.Fatal(err)
+		}
+	}
+
+	var reencryptWg sync.WaitGroup
+
+	if sets.Storage.Encryption.Keyfile != "" {
+		reencryptWg.Add(1)
+
+		go func() {
+			defer reencryptWg.Done()
+
+			n, err := ls.Reencrypt(ctx, reencryptBatch)
+			if err != nil {
+				nlog.Error("cannot reencrypt local repo", zap.Int("reencrypted", n), zap.Error(err))
+
+				return
+			}
+
+			nlog.Info("local repo reencrypted", zap.Int("reencrypted", n))
+		}()
+	}
+
+	var (
+		dispatcher  *webhook.Dispatcher
+		serviceOpts = []http.Option{http.WithMetrics(reg), http.WithTracing(tp)}
+		follower    *replica.Follower
+	)
+
+	if sets.Replica.Leader != "" {
+		follower, err = replica.NewFollower(log, store, sets.Replica, replica.WithTracer(tp))
+		if err != nil {
+			stdlog.Fatal(err)
+		}
+
+		follower.Run()
+
+		serviceOpts = append(serviceOpts, http.WithReplica(follower, sets.Replica.ProxyWrites))
+	}
+
+	if node != nil {
+		serviceOpts = append(serviceOpts, http.WithCluster(node))
+	}
+
+	// изменения ведомого повторяют изменения ведущего, поэтому события о них доставляет только ведущий.
+	if sets.Webhooks.Enabled && follower != nil {
+		nlog.Warn("webhooks are disabled on a replica, they are delivered by the leader")
+	}
+
+	// каждый узел кластера применяет все записи, поэтому события о них доставлялись бы каждым узлом.
+	if sets.Webhooks.Enabled && node != nil {
+		nlog.Warn("webhooks are not supported in cluster mode")
+	}
+
+	if sets.Webhooks.Enabled && follower == nil && node == nil {
+		dispatcher, err = webhook.NewDispatcher(log, ls, store, sets.Webhooks, webhook.WithTracer(tp))
+		if err != nil {
+			stdlog.Fatal(err)
+		}
+
+		dispatcher.Run()
+
+		serviceOpts = append(serviceOpts, http.WithWebhooks(dispatcher))
+	}
+
+	var scrub *scrubber.Scrubber
+
+	if sets.Scrub.Enabled {
+		scrub = scrubber.NewScrubber(log, ls, sets.Scrub)
+		scrub.Run()
+
+		serviceOpts = append(serviceOpts, http.WithScrubber(scrub))
+	}
+
+	var large *largeobject.Store
+
+	// части крупных объектов хранятся в репозитории узла и не реплицируются журналом.
+	if sets.Large.Enabled && node != nil {
+		nlog.Warn("large objects are not supported in cluster mode")
+	}
+
+	// по той же причине ведомый не получил бы тел крупных объектов ведущего.
+	if sets.Large.Enabled && follower != nil {
+		nlog.Warn("large objects are not supported on a replica")
+	}
+
+	if sets.Large.Enabled && node == nil && follower == nil {
+		large = largeobject.NewStore(log, ls, store, sets.Large, sets.API.Limits)
+
+		if err = large.Load(ctx); err != nil {
+			stdlog.Fatal(err)
+		}
+
+		large.Run()
+
+		serviceOpts = append(serviceOpts, http.WithLargeObjects(large))
+	}
 
-	httpService := http.NewService(log, &sets.API, store)
+	httpService := http.NewService(log, &sets.API, store, serviceOpts...)
 
 	serviceErrCh := make(chan error, 1)
 
@@ -90,11 +257,63 @@ func main() {
 			nlog.Error("cannot stop server", zap.Error(err))
 		}
 
+		if dispatcher != nil {
+			dispatcher.Stop()
+		}
+
+		if scrub != nil {
+			scrub.Stop()
+		}
+
+		if large != nil {
+			large.Stop()
+		}
+
+		if follower != nil {
+			follower.Stop()
+		}
+
+		// узел останавливается раньше хранилища, чтобы в него больше не применялись записи журнала.
+		if node != nil {
+			node.Stop()
+		}
+
+		reencryptWg.Wait()
 		store.Stop()
 		ls.Close()
 
 		ctxCancelShutdown()
+
+		// спаны отправляются последними, чтобы в них попала запись объектов при остановке хранилища.
+		if provider != nil {
+			ctxTracing, ctxCancelTracing := context.WithTimeout(context.Background(), serviceShutdownTimeout)
+
+			if err := provider.Shutdown(ctxTracing); err != nil {
+				nlog.Error("cannot stop tracing", zap.Error(err))
+			}
+
+			ctxCancelTracing()
+		}
 	}
 
 	wg.Wait()
 }
+
+// newRegistry создаёт отдельный реестр метрик сервиса с метриками среды выполнения Go и процесса.
+func newRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	return reg
+}
+
+// clientQuotas переводит квоты клиентов из настроек в квоты хранилища.
+func clientQuotas(clients map[string]settings.ClientQuota) map[string]models.Quota {
+	quotas := make(map[string]models.Quota, len(clients))
+
+	for client, q := range clients {
+		quotas[client] = models.Quota{MaxObjects: q.MaxObjects, MaxBytes: q.MaxBytes}
+	}
+
+	return quotas
+}
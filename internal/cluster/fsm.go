@@ -0,0 +1,176 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"st-test/internal/models"
+	"st-test/internal/storage"
+
+	"github.com/hashicorp/raft"
+)
+
+// entry запись журнала Raft: запись в хранилище, добавление узла, удаление узла RemoveMember
+// или заполнение пустого кластера объектами из репозитория ведущего Seed.
+type entry struct {
+	Command      *storage.Command `json:"command,omitempty"`
+	Member       *Member          `json:"member,omitempty"`
+	RemoveMember string           `json:"remove_member,omitempty"`
+	Seed         *models.Snapshot `json:"seed,omitempty"`
+}
+
+// snapshot снимок автомата: объекты и корзина хранилища, известные узлы кластера и признак того,
+// что кластер уже заполнен.
+type snapshot struct {
+	Store   models.Snapshot `json:"store"`
+	Members []Member        `json:"members"`
+	Seeded  bool            `json:"seeded"`
+}
+
+// fsm автомат Raft: применяет записи журнала к хранилищу и ведёт список узлов с адресами их API.
+// seeded выставляется после первой записи в хранилище: с этого момента записи Seed пропускаются,
+// чтобы не затереть объекты, уже записанные через журнал.
+type fsm struct {
+	store Store
+
+	m       sync.Mutex
+	members map[string]Member
+	seeded  bool
+}
+
+func newFSM(store Store, peers []Member) *fsm {
+	f := &fsm{store: store, members: make(map[string]Member, len(peers))}
+
+	for _, p := range peers {
+		f.members[p.ID] = p
+	}
+
+	return f
+}
+
+// Apply применяет запись журнала. Для записи в хранилище возвращает storage.Result, иначе ошибку или nil.
+func (f *fsm) Apply(l *raft.Log) any {
+	var e entry
+	if err := json.Unmarshal(l.Data, &e); err != nil {
+		return fmt.Errorf("decode log entry %d: %w", l.Index, err)
+	}
+
+	switch {
+	case e.Command != nil:
+		f.setSeeded()
+
+		return f.store.Apply(context.Background(), *e.Command)
+	case e.Seed != nil:
+		if f.isSeeded() {
+			return nil
+		}
+
+		f.setSeeded()
+
+		if err := f.store.ApplySnapshot(context.Background(), *e.Seed); err != nil {
+			return fmt.Errorf("apply seed: %w", err)
+		}
+	case e.Member != nil:
+		f.m.Lock()
+		f.members[e.Member.ID] = *e.Member
+		f.m.Unlock()
+	case e.RemoveMember != "":
+		f.m.Lock()
+		delete(f.members, e.RemoveMember)
+		f.m.Unlock()
+	}
+
+	return nil
+}
+
+// Snapshot снимает состояние автомата. Raft не применяет записи, пока снимок снимается,
+// поэтому объекты и узлы согласованы с индексом снимка.
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	snap, err := f.store.Snapshot(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("read store snapshot: %w", err)
+	}
+
+	return &fsmSnapshot{snapshot{Store: snap, Members: f.memberList(), Seeded: f.isSeeded()}}, nil
+}
+
+// Restore заменяет состояние автомата снимком, например полученным от ведущего при отставании узла.
+func (f *fsm) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	var snap snapshot
+	if err := json.NewDecoder(rc).Decode(&snap); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	if err := f.store.ApplySnapshot(context.Background(), snap.Store); err != nil {
+		return fmt.Errorf("apply store snapshot: %w", err)
+	}
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.members = make(map[string]Member, len(snap.Members))
+	for _, m := range snap.Members {
+		f.members[m.ID] = m
+	}
+
+	f.seeded = snap.Seeded
+
+	return nil
+}
+
+func (f *fsm) isSeeded() bool {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	return f.seeded
+}
+
+func (f *fsm) setSeeded() {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.seeded = true
+}
+
+func (f *fsm) member(id string) (Member, bool) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	m, ok := f.members[id]
+
+	return m, ok
+}
+
+func (f *fsm) memberList() []Member {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	members := make([]Member, 0, len(f.members))
+	for _, m := range f.members {
+		members = append(members, m)
+	}
+
+	return members
+}
+
+// fsmSnapshot снятый снимок автомата, который Raft записывает в хранилище снимков.
+type fsmSnapshot struct {
+	snap snapshot
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(s.snap); err != nil {
+		_ = sink.Cancel()
+
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	return sink.Close() //nolint:wrapcheck
+}
+
+func (s *fsmSnapshot) Release() {}
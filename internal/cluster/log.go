@@ -0,0 +1,54 @@
+package cluster
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/go-hclog"
+	"go.uber.org/zap"
+)
+
+// newRaftLogger создаёт логгер Raft, который передаёт записи в log с теми же уровнями.
+func newRaftLogger(log *zap.Logger) hclog.Logger {
+	logger := hclog.NewInterceptLogger(&hclog.LoggerOptions{
+		Name:   "raft",
+		Level:  hclog.Debug,
+		Output: io.Discard,
+	})
+	logger.RegisterSink(zapSink{log: log.Named("raft")})
+
+	return logger
+}
+
+// zapSink передаёт записи hclog в логгер zap; пары аргументов записи становятся полями.
+type zapSink struct {
+	log *zap.Logger
+}
+
+func (s zapSink) Accept(_ string, level hclog.Level, msg string, args ...any) {
+	fields := make([]zap.Field, 0, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		key, value := fmt.Sprint(args[i]), args[i+1]
+
+		// значения, которые Raft передаёт вместе с форматом, форматируются так же, как в hclog.
+		if f, ok := value.(hclog.Format); ok && len(f) > 0 {
+			if format, ok := f[0].(string); ok {
+				value = fmt.Sprintf(format, f[1:]...)
+			}
+		}
+
+		fields = append(fields, zap.Any(key, value))
+	}
+
+	switch level {
+	case hclog.Error:
+		s.log.Error(msg, fields...)
+	case hclog.Warn:
+		s.log.Warn(msg, fields...)
+	case hclog.Info:
+		s.log.Info(msg, fields...)
+	case hclog.Debug:
+		s.log.Debug(msg, fields...)
+	case hclog.NoLevel, hclog.Trace, hclog.Off:
+	}
+}
@@ -0,0 +1,643 @@
+// Package cluster описывает узел кластера: записи в хранилище реплицируются между узлами журналом Raft,
+// ведущий узел выбирается голосованием, а журнал сжимается снимками хранилища.
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultHeartbeatTimeout  = time.Second
+	defaultElectionTimeout   = time.Second
+	defaultSnapshotInterval  = 2 * time.Minute
+	defaultSnapshotThreshold = 8192
+	defaultApplyTimeout      = 5 * time.Second
+	// expireInterval период, с которым ведущий проверяет объекты с истёкшим сроком жизни или хранения.
+	expireInterval = time.Second
+	// transportTimeout время на ввод-вывод по соединению с другим узлом; maxPool — количество соединений с узлом.
+	transportTimeout = 10 * time.Second
+	maxPool          = 3
+	// retainSnapshots количество хранимых на диске снимков; logCacheSize — количество последних записей журнала в памяти.
+	retainSnapshots = 2
+	logCacheSize    = 512
+	// dirPerm права на каталог журнала и снимков.
+	dirPerm = 0o750
+)
+
+var (
+	// ErrInvalidSettings возвращается когда настройки кластера не заданы или заданы неверно.
+	ErrInvalidSettings = errors.New("invalid cluster settings")
+	// ErrInvalidMember возвращается когда у узла не задан идентификатор или адреса заданы неверно.
+	ErrInvalidMember = errors.New("invalid cluster member")
+	// ErrUnknownMember возвращается при удалении узла, которого нет в кластере.
+	ErrUnknownMember = errors.New("unknown cluster member")
+	// ErrNotLeader возвращается когда запись или чтение обращены не к ведущему узлу.
+	ErrNotLeader = errors.New("node is not the cluster leader")
+	// ErrNoLeader возвращается когда ведущий узел ещё не выбран или его адрес неизвестен.
+	ErrNoLeader = errors.New("cluster has no leader")
+)
+
+// Store описывает методы хранилища, которое реплицирует узел.
+type Store interface {
+	Apply(ctx context.Context, c storage.Command) storage.Result
+	Snapshot(ctx context.Context) (models.Snapshot, error)
+	ApplySnapshot(ctx context.Context, snap models.Snapshot) error
+	Expiring(now time.Time) []int
+	Purgeable(now time.Time) bool
+	RepoSnapshot(ctx context.Context) (models.Snapshot, error)
+}
+
+// Member узел кластера: идентификатор, адрес журнала Raft (host:port) и базовый url API.
+type Member struct {
+	ID          string `json:"id"`
+	RaftAddress string `json:"raft_address"`
+	HTTPAddress string `json:"http_address"`
+}
+
+// Validate проверяет, что у узла задан идентификатор и адреса заданы верно.
+func (m Member) Validate() error {
+	if m.ID == "" {
+		return fmt.Errorf("%w: id is empty", ErrInvalidMember)
+	}
+
+	if _, _, err := net.SplitHostPort(m.RaftAddress); err != nil {
+		return fmt.Errorf("%w: raft address %q: %w", ErrInvalidMember, m.RaftAddress, err)
+	}
+
+	if _, err := m.url(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m Member) url() (*url.URL, error) {
+	u, err := url.Parse(m.HTTPAddress)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: http address must be an absolute http or https url: %q", ErrInvalidMember, m.HTTPAddress)
+	}
+
+	return u, nil
+}
+
+// Leader ведущий узел кластера. Local выставляется, если ведущий — этот узел.
+type Leader struct {
+	ID    string
+	URL   *url.URL
+	Local bool
+}
+
+// MemberStatus узел кластера в текущей конфигурации Raft. Voter выставляется у узлов, участвующих в голосовании.
+type MemberStatus struct {
+	Member
+	Voter  bool
+	Leader bool
+}
+
+// Status состояние узла: роль (Leader, Follower, Candidate), ведущий узел, текущий срок и индексы журнала,
+// время последней связи с ведущим и узлы кластера.
+type Status struct {
+	ID           string
+	State        string
+	Leader       string
+	Term         uint64
+	LastIndex    uint64
+	CommitIndex  uint64
+	AppliedIndex uint64
+	LastContact  time.Time
+	Members      []MemberStatus
+}
+
+// Node узел кластера. Реализует storage.Proposer: запись в хранилище ведущего узла добавляется в журнал
+// и применяется к хранилищам всех узлов через Store.Apply после того, как её сохранило большинство узлов.
+type Node struct {
+	log *zap.Logger
+	set settings.ClusterSettings
+
+	raft      *raft.Raft
+	fsm       *fsm
+	store     Store
+	transport *raft.NetworkTransport
+	closers   []io.Closer
+
+	// seedMu не даёт ведущему заполнять кластер из репозитория несколько раз одновременно.
+	seedMu sync.Mutex
+
+	// barrierTerm срок, в котором ведущий дождался применения всех записей предыдущих сроков.
+	barrierTerm atomic.Uint64
+
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewNode конструктор для Node. Проверяет настройки, но к кластеру не подключается: это делает Start.
+func NewNode(log *zap.Logger, set settings.ClusterSettings) (*Node, error) {
+	self := Member{ID: set.NodeID, RaftAddress: set.RaftAddress, HTTPAddress: set.HTTPAddress}
+	if err := self.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSettings, err)
+	}
+
+	// хранилище узла восстанавливается только из журнала, поэтому журнал в памяти терялся бы при перезапуске.
+	if set.Dir == "" {
+		return nil, fmt.Errorf("%w: dir is required", ErrInvalidSettings)
+	}
+
+	for _, p := range set.Peers {
+		if err := peer(p).Validate(); err != nil {
+			return nil, fmt.Errorf("%w: peer: %w", ErrInvalidSettings, err)
+		}
+	}
+
+	if set.HeartbeatTimeout <= 0 {
+		set.HeartbeatTimeout = defaultHeartbeatTimeout
+	}
+
+	if set.ElectionTimeout <= 0 {
+		set.ElectionTimeout = defaultElectionTimeout
+	}
+
+	if set.SnapshotInterval <= 0 {
+		set.SnapshotInterval = defaultSnapshotInterval
+	}
+
+	if set.SnapshotThreshold == 0 {
+		set.SnapshotThreshold = defaultSnapshotThreshold
+	}
+
+	if set.ApplyTimeout <= 0 {
+		set.ApplyTimeout = defaultApplyTimeout
+	}
+
+	return &Node{
+		log:  log.Named("cluster").With(zap.String("node", set.NodeID)),
+		set:  set,
+		done: make(chan struct{}),
+	}, nil
+}
+
+func peer(p settings.PeerSettings) Member {
+	return Member{ID: p.ID, RaftAddress: p.RaftAddress, HTTPAddress: p.HTTPAddress}
+}
+
+// Start подключает узел к кластеру и восстанавливает хранилище store из снимка и журнала. При первом запуске
+// узла, который есть в Peers, кластер создаётся из Peers; узел не из Peers ждёт, пока его добавят через AddMember.
+// Новый кластер заполняет объектами из своего репозитория первый выбранный ведущий узел, объекты в репозиториях
+// остальных узлов заменяются ими.
+func (n *Node) Start(store Store) error {
+	n.store = store
+
+	logger := newRaftLogger(n.log)
+
+	transport, err := raft.NewTCPTransportWithLogger(n.set.RaftAddress, nil, maxPool, transportTimeout, logger)
+	if err != nil {
+		return fmt.Errorf("listen raft address %s: %w", n.set.RaftAddress, err)
+	}
+
+	n.transport = transport
+
+	logs, stable, snaps, err := n.stores(logger)
+	if err != nil {
+		n.close()
+
+		return err
+	}
+
+	conf := raft.DefaultConfig()
+	conf.LocalID = raft.ServerID(n.set.NodeID)
+	conf.HeartbeatTimeout = n.set.HeartbeatTimeout
+	conf.ElectionTimeout = n.set.ElectionTimeout
+	conf.LeaderLeaseTimeout = min(conf.LeaderLeaseTimeout, n.set.HeartbeatTimeout)
+	conf.SnapshotInterval = n.set.SnapshotInterval
+	conf.SnapshotThreshold = n.set.SnapshotThreshold
+	conf.Logger = logger
+
+	peers := make([]Member, 0, len(n.set.Peers))
+	for _, p := range n.set.Peers {
+		peers = append(peers, peer(p))
+	}
+
+	n.fsm = newFSM(store, peers)
+
+	exists, err := raft.HasExistingState(logs, stable, snaps)
+	if err != nil {
+		n.close()
+
+		return fmt.Errorf("read raft state: %w", err)
+	}
+
+	r, err := raft.NewRaft(conf, n.fsm, logs, stable, snaps, transport)
+	if err != nil {
+		n.close()
+
+		return fmt.Errorf("start raft: %w", err)
+	}
+
+	n.raft = r
+
+	if !exists {
+		n.bootstrap(peers)
+	}
+
+	n.wg.Add(1)
+
+	go func() {
+		defer n.wg.Done()
+
+		n.expireLoop(store)
+	}()
+
+	return nil
+}
+
+// stores создаёт журнал, хранилище состояния и хранилище снимков Raft в каталоге Dir.
+func (n *Node) stores(logger hclog.Logger) (raft.LogStore, raft.StableStore, raft.SnapshotStore, error) {
+	if err := os.MkdirAll(n.set.Dir, dirPerm); err != nil {
+		return nil, nil, nil, fmt.Errorf("create raft dir: %w", err)
+	}
+
+	bolt, err := raftboltdb.NewBoltStore(filepath.Join(n.set.Dir, "raft.db"))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("open raft log: %w", err)
+	}
+
+	n.closers = append(n.closers, bolt)
+
+	snaps, err := raft.NewFileSnapshotStoreWithLogger(n.set.Dir, retainSnapshots, logger)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("open raft snapshots: %w", err)
+	}
+
+	logs, err := raft.NewLogCache(logCacheSize, bolt)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("create raft log cache: %w", err)
+	}
+
+	return logs, bolt, snaps, nil
+}
+
+// bootstrap создаёт кластер из peers, если этот узел в них есть.
+func (n *Node) bootstrap(peers []Member) {
+	var servers []raft.Server
+
+	self := false
+
+	for _, p := range peers {
+		servers = append(servers, raft.Server{ID: raft.ServerID(p.ID), Address: raft.ServerAddress(p.RaftAddress)})
+		self = self || p.ID == n.set.NodeID
+	}
+
+	if !self {
+		n.log.Info("node is not among the peers, waiting to be added to the cluster")
+
+		return
+	}
+
+	if err := n.raft.BootstrapCluster(raft.Configuration{Servers: servers}).Error(); err != nil {
+		n.log.Warn("cannot bootstrap cluster", zap.Error(err))
+
+		return
+	}
+
+	n.log.Info("cluster bootstrapped", zap.Int("peers", len(servers)))
+}
+
+// Stop останавливает узел. Узел остаётся в конфигурации кластера; чтобы вывести его из кластера,
+// его нужно удалить через RemoveMember.
+func (n *Node) Stop() {
+	n.stopOnce.Do(func() {
+		close(n.done)
+	})
+
+	n.wg.Wait()
+
+	if n.raft != nil {
+		if err := n.raft.Shutdown().Error(); err != nil {
+			n.log.Warn("cannot shutdown raft", zap.Error(err))
+		}
+	}
+
+	n.close()
+}
+
+func (n *Node) close() {
+	if n.transport != nil {
+		_ = n.transport.Close()
+	}
+
+	for _, c := range n.closers {
+		if err := c.Close(); err != nil {
+			n.log.Warn("cannot close raft store", zap.Error(err))
+		}
+	}
+
+	n.closers = nil
+}
+
+// Propose реплицирует запись в хранилище и возвращает результат её применения к хранилищу этого узла.
+// Если узел не ведущий, возвращается ErrNotLeader.
+func (n *Node) Propose(ctx context.Context, c storage.Command) storage.Result {
+	if err := n.seed(ctx); err != nil {
+		return storage.Result{Err: err}
+	}
+
+	res, err := n.apply(ctx, entry{Command: &c})
+	if err != nil {
+		return storage.Result{Err: err}
+	}
+
+	r, ok := res.(storage.Result)
+	if !ok {
+		return storage.Result{Err: fmt.Errorf("unexpected apply response %T", res)}
+	}
+
+	return r
+}
+
+// apply добавляет запись в журнал и дожидается её применения к автомату этого узла.
+func (n *Node) apply(ctx context.Context, e entry) (any, error) {
+	if n.raft.State() != raft.Leader {
+		return nil, ErrNotLeader
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("encode log entry: %w", err)
+	}
+
+	f := n.raft.Apply(data, n.timeout(ctx))
+	if err := f.Error(); err != nil {
+		return nil, raftError("replicate log entry", err)
+	}
+
+	if err, ok := f.Response().(error); ok {
+		return nil, err
+	}
+
+	return f.Response(), nil
+}
+
+// seed заполняет ещё не заполненный кластер объектами и корзиной из репозитория ведущего. Кластер заполняется
+// раньше первой записи, поэтому объекты репозитория не затирают объекты, записанные через журнал.
+func (n *Node) seed(ctx context.Context) error {
+	if n.fsm.isSeeded() {
+		return nil
+	}
+
+	n.seedMu.Lock()
+	defer n.seedMu.Unlock()
+
+	if n.fsm.isSeeded() {
+		return nil
+	}
+
+	if n.raft.State() != raft.Leader {
+		return ErrNotLeader
+	}
+
+	snap, err := n.store.RepoSnapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("seed cluster from local repo: %w", err)
+	}
+
+	if _, err := n.apply(ctx, entry{Seed: &snap}); err != nil {
+		return err
+	}
+
+	n.log.Info("cluster seeded from local repo", zap.Int("items", len(snap.Items)), zap.Int("trash", len(snap.Trash)))
+
+	return nil
+}
+
+// timeout возвращает время на операцию Raft: ApplyTimeout, но не дольше срока ctx.
+func (n *Node) timeout(ctx context.Context) time.Duration {
+	timeout := n.set.ApplyTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline))
+	}
+
+	return max(timeout, time.Millisecond)
+}
+
+// raftError оборачивает ошибку Raft; потеря лидерства возвращается как ErrNotLeader.
+func raftError(op string, err error) error {
+	if errors.Is(err, raft.ErrNotLeader) || errors.Is(err, raft.ErrLeadershipLost) ||
+		errors.Is(err, raft.ErrLeadershipTransferInProgress) {
+		return fmt.Errorf("%s: %w: %w", op, ErrNotLeader, err)
+	}
+
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// Leader возвращает ведущий узел. Если ведущий ещё не выбран или его адрес неизвестен, возвращается ErrNoLeader.
+func (n *Node) Leader() (Leader, error) {
+	_, id := n.raft.LeaderWithID()
+	if id == "" {
+		return Leader{}, ErrNoLeader
+	}
+
+	m, ok := n.fsm.member(string(id))
+	if !ok {
+		return Leader{}, fmt.Errorf("%w: address of leader %s is unknown", ErrNoLeader, id)
+	}
+
+	u, err := m.url()
+	if err != nil {
+		return Leader{}, err
+	}
+
+	return Leader{ID: m.ID, URL: u, Local: m.ID == n.set.NodeID}, nil
+}
+
+// VerifyLeader проверяет, что узел по-прежнему ведущий и его хранилище содержит все подтверждённые записи,
+// поэтому чтение из него после проверки линеаризуемо. Если узел не ведущий, возвращается ErrNotLeader.
+func (n *Node) VerifyLeader(ctx context.Context) error {
+	if n.raft.State() != raft.Leader {
+		return ErrNotLeader
+	}
+
+	// новый ведущий мог ещё не применить записи, подтверждённые при прежнем ведущем.
+	term := n.raft.CurrentTerm()
+	if n.barrierTerm.Load() != term {
+		if err := n.raft.Barrier(n.timeout(ctx)).Error(); err != nil {
+			return raftError("wait for log to be applied", err)
+		}
+
+		n.barrierTerm.Store(term)
+	}
+
+	if err := n.seed(ctx); err != nil {
+		return err
+	}
+
+	if err := n.raft.VerifyLeader().Error(); err != nil {
+		return raftError("verify leadership", err)
+	}
+
+	return nil
+}
+
+// Ready сообщает, может ли узел обслуживать запросы: ведущий выбран и его адрес известен.
+func (n *Node) Ready() error {
+	_, err := n.Leader()
+
+	return err
+}
+
+// Status возвращает состояние узла и узлы кластера.
+func (n *Node) Status() (Status, error) {
+	f := n.raft.GetConfiguration()
+	if err := f.Error(); err != nil {
+		return Status{}, fmt.Errorf("read cluster configuration: %w", err)
+	}
+
+	_, leader := n.raft.LeaderWithID()
+
+	st := Status{
+		ID:           n.set.NodeID,
+		State:        n.raft.State().String(),
+		Leader:       string(leader),
+		Term:         n.raft.CurrentTerm(),
+		LastIndex:    n.raft.LastIndex(),
+		CommitIndex:  n.raft.CommitIndex(),
+		AppliedIndex: n.raft.AppliedIndex(),
+		LastContact:  n.raft.LastContact(),
+	}
+
+	for _, s := range f.Configuration().Servers {
+		m, ok := n.fsm.member(string(s.ID))
+		if !ok {
+			m = Member{ID: string(s.ID)}
+		}
+
+		m.RaftAddress = string(s.Address)
+
+		st.Members = append(st.Members, MemberStatus{
+			Member: m,
+			Voter:  s.Suffrage == raft.Voter,
+			Leader: s.ID == leader,
+		})
+	}
+
+	return st, nil
+}
+
+// AddMember добавляет узел в кластер голосующим. Адрес API узла реплицируется раньше, чем узел получает журнал,
+// чтобы все узлы могли перенаправлять ему запросы, когда он станет ведущим. Если узел уже есть в кластере,
+// его адреса обновляются. Вызывается только на ведущем, иначе возвращается ErrNotLeader.
+func (n *Node) AddMember(ctx context.Context, m Member) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := n.apply(ctx, entry{Member: &m}); err != nil {
+		return err
+	}
+
+	err := n.raft.AddVoter(raft.ServerID(m.ID), raft.ServerAddress(m.RaftAddress), 0, n.timeout(ctx)).Error()
+	if err != nil {
+		return raftError("add voter", err)
+	}
+
+	n.log.Info("member added", zap.String("member", m.ID), zap.String("raft_address", m.RaftAddress))
+
+	return nil
+}
+
+// RemoveMember удаляет узел id из кластера. Если узла нет, возвращается ErrUnknownMember. Ведущий может удалить
+// и себя: после этого кластер выбирает нового ведущего. Вызывается только на ведущем, иначе возвращается ErrNotLeader.
+func (n *Node) RemoveMember(ctx context.Context, id string) error {
+	if n.raft.State() != raft.Leader {
+		return ErrNotLeader
+	}
+
+	f := n.raft.GetConfiguration()
+	if err := f.Error(); err != nil {
+		return fmt.Errorf("read cluster configuration: %w", err)
+	}
+
+	found := false
+
+	for _, s := range f.Configuration().Servers {
+		found = found || string(s.ID) == id
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %s", ErrUnknownMember, id)
+	}
+
+	if _, err := n.apply(ctx, entry{RemoveMember: id}); err != nil {
+		return err
+	}
+
+	if err := n.raft.RemoveServer(raft.ServerID(id), 0, n.timeout(ctx)).Error(); err != nil {
+		return raftError("remove server", err)
+	}
+
+	n.log.Info("member removed", zap.String("member", id))
+
+	return nil
+}
+
+// expireLoop на ведущем заполняет новый кластер из репозитория и до остановки узла периодически реплицирует
+// удаление объектов с истёкшим сроком жизни, очистку корзины и области истёкших объектов. Момент удаления
+// передаётся в записи, поэтому все узлы удаляют одни и те же объекты.
+func (n *Node) expireLoop(store Store) {
+	ticker := time.NewTicker(expireInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.done:
+			return
+		case now := <-ticker.C:
+			if n.raft.State() != raft.Leader {
+				continue
+			}
+
+			if err := n.seed(context.Background()); err != nil {
+				n.log.Warn("cannot seed cluster", zap.Error(err))
+
+				continue
+			}
+
+			if ids := store.Expiring(now); len(ids) > 0 {
+				res := n.Propose(context.Background(), storage.Command{Type: storage.CommandExpire, IDs: ids, Time: now})
+				if res.Err != nil {
+					n.log.Warn("cannot expire items", zap.Int("count", len(ids)), zap.Error(res.Err))
+				}
+			}
+
+			if store.Purgeable(now) {
+				res := n.Propose(context.Background(), storage.Command{Type: storage.CommandPurge, Time: now})
+				if res.Err != nil {
+					n.log.Warn("cannot purge items", zap.Error(res.Err))
+				}
+			}
+		}
+	}
+}
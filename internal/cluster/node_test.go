@@ -0,0 +1,401 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
+	"st-test/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const waitTimeout = 10 * time.Second
+
+// freeAddr возвращает свободный адрес на loopback.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	return addr
+}
+
+// testPeers возвращает узлы кластера из size узлов на свободных портах loopback.
+func testPeers(t *testing.T, size int) []settings.PeerSettings {
+	t.Helper()
+
+	peers := make([]settings.PeerSettings, 0, size)
+	for i := range size {
+		peers = append(peers, testPeer(t, i+1))
+	}
+
+	return peers
+}
+
+func testPeer(t *testing.T, i int) settings.PeerSettings {
+	t.Helper()
+
+	return settings.PeerSettings{
+		ID:          fmt.Sprintf("node-%d", i),
+		RaftAddress: freeAddr(t),
+		HTTPAddress: fmt.Sprintf("http://node-%d:8080", i),
+	}
+}
+
+// testNode узел кластера вместе с его хранилищем.
+type testNode struct {
+	*Node
+	store *storage.Store
+}
+
+func testSettings(t *testing.T, self settings.PeerSettings, peers []settings.PeerSettings) settings.ClusterSettings {
+	t.Helper()
+
+	return settings.ClusterSettings{
+		Enabled:          true,
+		NodeID:           self.ID,
+		RaftAddress:      self.RaftAddress,
+		HTTPAddress:      self.HTTPAddress,
+		Dir:              t.TempDir(),
+		Peers:            peers,
+		HeartbeatTimeout: 100 * time.Millisecond,
+		ElectionTimeout:  100 * time.Millisecond,
+		ApplyTimeout:     2 * time.Second,
+	}
+}
+
+// testRepo возвращает репозиторий с объектами items, из которого ведущий может заполнить кластер.
+func testRepo(t *testing.T, items ...models.Item) *mocks.Repo {
+	t.Helper()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Maybe().Return(items, nil)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+	repo.EXPECT().ReadTrash(mock.Anything).Maybe().Return(nil, nil)
+
+	return repo
+}
+
+func startNode(t *testing.T, set settings.ClusterSettings) testNode {
+	t.Helper()
+
+	return startNodeRepo(t, set, testRepo(t))
+}
+
+func startNodeRepo(t *testing.T, set settings.ClusterSettings, repo *mocks.Repo) testNode {
+	t.Helper()
+
+	node, err := NewNode(zap.NewNop(), set)
+	require.NoError(t, err)
+
+	store := storage.NewStore(zap.NewNop(), repo, storage.WithProposer(node), storage.WithTrash(time.Hour))
+
+	require.NoError(t, node.Start(store))
+	t.Cleanup(node.Stop)
+
+	return testNode{Node: node, store: store}
+}
+
+func startCluster(t *testing.T, size int) []testNode {
+	t.Helper()
+
+	peers := testPeers(t, size)
+	nodes := make([]testNode, 0, size)
+
+	for _, p := range peers {
+		nodes = append(nodes, startNode(t, testSettings(t, p, peers)))
+	}
+
+	return nodes
+}
+
+// waitLeader дожидается, пока все узлы признают одного ведущего, и возвращает его.
+func waitLeader(t *testing.T, nodes []testNode) testNode {
+	t.Helper()
+
+	var leader testNode
+
+	require.Eventually(t, func() bool {
+		var id string
+
+		for _, n := range nodes {
+			l, err := n.Leader()
+			if err != nil || (id != "" && l.ID != id) {
+				return false
+			}
+
+			id = l.ID
+
+			if l.Local {
+				leader = n
+			}
+		}
+
+		return leader.Node != nil && leader.set.NodeID == id
+	}, waitTimeout, 10*time.Millisecond)
+
+	return leader
+}
+
+// waitObject дожидается, пока объект id с телом body появится в хранилище узла.
+func waitObject(t *testing.T, n testNode, id int, body string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		item, err := n.store.GetObject(context.Background(), id)
+
+		return err == nil && string(item.Body) == body
+	}, waitTimeout, 10*time.Millisecond, n.set.NodeID)
+}
+
+func TestNewNode(t *testing.T) {
+	t.Parallel()
+
+	self := settings.PeerSettings{ID: "node-1", RaftAddress: "127.0.0.1:7001", HTTPAddress: "http://127.0.0.1:8080"}
+
+	cases := map[string]settings.ClusterSettings{
+		"no node id":         testSettings(t, settings.PeerSettings{RaftAddress: self.RaftAddress, HTTPAddress: self.HTTPAddress}, nil),
+		"invalid raft addr":  testSettings(t, settings.PeerSettings{ID: self.ID, RaftAddress: "node-1", HTTPAddress: self.HTTPAddress}, nil),
+		"invalid http addr":  testSettings(t, settings.PeerSettings{ID: self.ID, RaftAddress: self.RaftAddress, HTTPAddress: "node-1"}, nil),
+		"invalid peer":       testSettings(t, self, []settings.PeerSettings{self, {ID: "node-2"}}),
+		"http addr not http": testSettings(t, settings.PeerSettings{ID: self.ID, RaftAddress: self.RaftAddress, HTTPAddress: "ftp://x"}, nil),
+		"no dir":             {NodeID: self.ID, RaftAddress: self.RaftAddress, HTTPAddress: self.HTTPAddress},
+	}
+
+	for name, set := range cases {
+		_, err := NewNode(zap.NewNop(), set)
+		require.ErrorIs(t, err, ErrInvalidSettings, name)
+	}
+
+	n, err := NewNode(zap.NewNop(), testSettings(t, self, []settings.PeerSettings{self}))
+	require.NoError(t, err)
+	assert.Equal(t, defaultSnapshotThreshold, int(n.set.SnapshotThreshold))
+}
+
+func TestCluster_Replication(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	nodes := startCluster(t, 3)
+	leader := waitLeader(t, nodes)
+
+	require.NoError(t, leader.VerifyLeader(ctx))
+
+	id, err := leader.store.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"a":1}`)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	// повторная запись того же id реплицируется как обновление.
+	id, err = leader.store.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"a":2}`)})
+	require.NoError(t, err)
+	assert.Zero(t, id)
+
+	for _, n := range nodes {
+		waitObject(t, n, 1, `{"a":2}`)
+
+		if n.set.NodeID == leader.set.NodeID {
+			continue
+		}
+
+		// запись и линеаризуемое чтение обслуживает только ведущий.
+		_, err := n.store.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`)})
+		require.ErrorIs(t, err, ErrNotLeader)
+		require.ErrorIs(t, n.VerifyLeader(ctx), ErrNotLeader)
+		require.ErrorIs(t, n.AddMember(ctx, Member{ID: "x", RaftAddress: "127.0.0.1:1", HTTPAddress: "http://x"}), ErrNotLeader)
+
+		l, err := n.Leader()
+		require.NoError(t, err)
+		assert.False(t, l.Local)
+		assert.Equal(t, leader.set.HTTPAddress, l.URL.String())
+	}
+
+	// все узлы применили записи в одном порядке и получили одинаковые версии.
+	want, err := leader.store.Snapshot(ctx)
+	require.NoError(t, err)
+
+	for _, n := range nodes {
+		got, err := n.store.Snapshot(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, n.set.NodeID)
+	}
+
+	st, err := leader.Status()
+	require.NoError(t, err)
+	assert.Equal(t, "Leader", st.State)
+	assert.Equal(t, leader.set.NodeID, st.Leader)
+	assert.Len(t, st.Members, 3)
+}
+
+func TestCluster_Failover(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	nodes := startCluster(t, 3)
+	leader := waitLeader(t, nodes)
+
+	_, err := leader.store.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"a":1}`)})
+	require.NoError(t, err)
+
+	var rest []testNode
+
+	for _, n := range nodes {
+		if n.set.NodeID != leader.set.NodeID {
+			rest = append(rest, n)
+		}
+	}
+
+	leader.Stop()
+
+	// оставшееся большинство выбирает нового ведущего, который продолжает принимать запись.
+	next := waitLeader(t, rest)
+	assert.NotEqual(t, leader.set.NodeID, next.set.NodeID)
+
+	require.NoError(t, next.VerifyLeader(ctx))
+
+	item, err := next.store.GetObject(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(item.Body))
+
+	require.NoError(t, next.store.DeleteObject(ctx, 1))
+
+	for _, n := range rest {
+		require.Eventually(t, func() bool {
+			_, err := n.store.StatObject(ctx, 1)
+
+			return err != nil
+		}, waitTimeout, 10*time.Millisecond)
+	}
+}
+
+func TestCluster_Membership(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	nodes := startCluster(t, 3)
+	leader := waitLeader(t, nodes)
+
+	for i := range 10 {
+		_, err := leader.store.SaveObject(ctx, models.Item{ID: i, Body: []byte(fmt.Sprintf(`{"i":%d}`, i))})
+		require.NoError(t, err)
+	}
+
+	// журнал сжимается снимком, поэтому новый узел получает объекты из снимка ведущего.
+	require.NoError(t, leader.raft.Snapshot().Error())
+
+	_, err := leader.store.SaveObject(ctx, models.Item{ID: 10, Body: []byte(`{"i":10}`)})
+	require.NoError(t, err)
+
+	peers := nodes[0].set.Peers
+	added := testPeer(t, 4)
+	joined := startNode(t, testSettings(t, added, peers))
+
+	member := Member{ID: added.ID, RaftAddress: added.RaftAddress, HTTPAddress: added.HTTPAddress}
+	require.ErrorIs(t, leader.AddMember(ctx, Member{ID: added.ID}), ErrInvalidMember)
+	require.NoError(t, leader.AddMember(ctx, member))
+
+	waitObject(t, joined, 0, `{"i":0}`)
+	waitObject(t, joined, 10, `{"i":10}`)
+
+	// новый узел знает адрес ведущего и сам известен остальным узлам.
+	l, err := joined.Leader()
+	require.NoError(t, err)
+	assert.Equal(t, leader.set.NodeID, l.ID)
+
+	st, err := nodes[1].Status()
+	require.NoError(t, err)
+	require.Len(t, st.Members, 4)
+	assert.Contains(t, st.Members, MemberStatus{Member: member, Voter: true})
+
+	require.ErrorIs(t, leader.RemoveMember(ctx, "node-9"), ErrUnknownMember)
+	require.NoError(t, leader.RemoveMember(ctx, added.ID))
+
+	st, err = leader.Status()
+	require.NoError(t, err)
+	assert.Len(t, st.Members, 3)
+}
+
+func TestCluster_Expire(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	nodes := startCluster(t, 3)
+	leader := waitLeader(t, nodes)
+
+	_, err := leader.store.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Expires: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	// объект удаляет ведущий записью журнала, поэтому он исчезает из хранилищ всех узлов.
+	for _, n := range nodes {
+		require.Eventually(t, func() bool {
+			snap, err := n.store.Snapshot(ctx)
+
+			return err == nil && len(snap.Items) == 0
+		}, waitTimeout, 10*time.Millisecond)
+	}
+}
+
+func TestCluster_Restart(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	self := testPeer(t, 1)
+	set := testSettings(t, self, []settings.PeerSettings{self})
+
+	n := startNode(t, set)
+	waitLeader(t, []testNode{n})
+
+	_, err := n.store.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"a":1}`)})
+	require.NoError(t, err)
+
+	n.Stop()
+
+	// хранилище восстанавливается из журнала на диске, кластер заново не создаётся и из репозитория не заполняется.
+	restarted := startNodeRepo(t, set, testRepo(t, models.Item{ID: 2, Body: []byte(`{}`)}))
+	waitLeader(t, []testNode{restarted})
+	waitObject(t, restarted, 1, `{"a":1}`)
+
+	_, err = restarted.store.StatObject(ctx, 2)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestCluster_Seed(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	peers := testPeers(t, 3)
+	nodes := make([]testNode, 0, len(peers))
+
+	// объекты в репозитории остались от запуска без кластера; ведущий заполняет ими кластер.
+	for _, p := range peers {
+		repo := testRepo(t, models.Item{ID: 1, Body: []byte(`{"a":1}`), Version: 7})
+		nodes = append(nodes, startNodeRepo(t, testSettings(t, p, peers), repo))
+	}
+
+	leader := waitLeader(t, nodes)
+
+	_, err := leader.store.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{"b":2}`)})
+	require.NoError(t, err)
+
+	for _, n := range nodes {
+		waitObject(t, n, 1, `{"a":1}`)
+		waitObject(t, n, 2, `{"b":2}`)
+
+		// нумерация изменений продолжается после версий объектов репозитория.
+		item, err := n.store.GetObject(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(8), item.Version)
+	}
+}
@@ -0,0 +1,217 @@
+// Package codec сжимает тела объектов перед записью на диск и распаковывает их при чтении.
+//
+// Каждая запись хранит имя кодека, которым она сжата, поэтому записи, сжатые разными кодеками
+// или не сжатые вовсе, могут храниться вместе, а кодек можно сменить без миграции данных.
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Codec имя кодека, которым сжата запись.
+type Codec string
+
+// Поддерживаемые кодеки.
+const (
+	None Codec = ""
+	Gzip Codec = "gzip"
+	Zstd Codec = "zstd"
+)
+
+// defaultThreshold минимальный размер тела в байтах, начиная с которого оно сжимается.
+const defaultThreshold = 1024
+
+var (
+	// ErrUnknownCodec возвращается когда кодек не поддерживается.
+	ErrUnknownCodec = errors.New("unknown codec")
+	// ErrCorrupted возвращается когда сжатые данные не удалось распаковать.
+	ErrCorrupted = errors.New("corrupted compressed data")
+)
+
+// Compressor сжимает тела не меньше порогового размера выбранным кодеком и распаковывает тела, сжатые любым кодеком.
+// Безопасен для конкурентного использования.
+type Compressor struct {
+	codec     Codec
+	threshold int
+
+	zstdOnce sync.Once
+	zstdEnc  *zstd.Encoder
+	zstdDec  *zstd.Decoder
+	zstdErr  error
+
+	gzipWriters sync.Pool
+
+	rawBytes    *prometheus.CounterVec
+	storedBytes *prometheus.CounterVec
+}
+
+// New конструктор для Compressor. Пустой codec отключает сжатие, но распаковка сжатых ранее записей продолжает работать.
+// Если threshold <= 0, используется порог по умолчанию.
+func New(codec Codec, threshold int) (*Compressor, error) {
+	switch codec {
+	case None, Gzip, Zstd:
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownCodec, codec)
+	}
+
+	if threshold <= 0 {
+		threshold = defaultThreshold
+	}
+
+	return &Compressor{
+		codec:     codec,
+		threshold: threshold,
+		rawBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "store",
+			Name:      "compression_raw_bytes_total",
+			Help:      "Size of bodies before compression by codec. The ratio to store_compression_stored_bytes_total is the compression ratio.",
+		}, []string{"codec"}),
+		storedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "store",
+			Name:      "compression_stored_bytes_total",
+			Help:      "Size of bodies written to disk by codec.",
+		}, []string{"codec"}),
+	}, nil
+}
+
+// Collectors возвращает метрики сжатия.
+func (c *Compressor) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{c.rawBytes, c.storedBytes}
+}
+
+// Encode сжимает тело, если оно не меньше порога и сжатие уменьшает его размер.
+// Возвращает данные для записи и кодек, которым они сжаты.
+func (c *Compressor) Encode(body []byte) ([]byte, Codec, error) {
+	if c.codec == None || len(body) < c.threshold {
+		c.observe(None, len(body), len(body))
+
+		return body, None, nil
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+
+	switch c.codec {
+	case Gzip:
+		data, err = c.gzipEncode(body)
+	case Zstd:
+		data, err = c.zstdEncode(body)
+	}
+
+	if err != nil {
+		return nil, None, fmt.Errorf("compress with %s: %w", c.codec, err)
+	}
+
+	// несжимаемые данные храним как есть.
+	if len(data) >= len(body) {
+		c.observe(None, len(body), len(body))
+
+		return body, None, nil
+	}
+
+	c.observe(c.codec, len(body), len(data))
+
+	return data, c.codec, nil
+}
+
+// Decode распаковывает данные, сжатые кодеком codec.
+func (c *Compressor) Decode(data []byte, codec Codec) ([]byte, error) {
+	switch codec {
+	case None:
+		return data, nil
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorrupted, err)
+		}
+
+		body, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorrupted, err)
+		}
+
+		return body, nil
+	case Zstd:
+		if err := c.initZstd(); err != nil {
+			return nil, err
+		}
+
+		body, err := c.zstdDec.DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorrupted, err)
+		}
+
+		return body, nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownCodec, codec)
+	}
+}
+
+func (c *Compressor) observe(codec Codec, raw, stored int) {
+	name := string(codec)
+	if codec == None {
+		name = "none"
+	}
+
+	c.rawBytes.WithLabelValues(name).Add(float64(raw))
+	c.storedBytes.WithLabelValues(name).Add(float64(stored))
+}
+
+func (c *Compressor) gzipEncode(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, ok := c.gzipWriters.Get().(*gzip.Writer)
+	if ok {
+		w.Reset(&buf)
+	} else {
+		w = gzip.NewWriter(&buf)
+	}
+
+	defer c.gzipWriters.Put(w)
+
+	if _, err := w.Write(body); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *Compressor) zstdEncode(body []byte) ([]byte, error) {
+	if err := c.initZstd(); err != nil {
+		return nil, err
+	}
+
+	return c.zstdEnc.EncodeAll(body, nil), nil
+}
+
+// initZstd создаёт кодировщик и декодировщик zstd при первом использовании.
+func (c *Compressor) initZstd() error {
+	c.zstdOnce.Do(func() {
+		c.zstdEnc, c.zstdErr = zstd.NewWriter(nil)
+		if c.zstdErr != nil {
+			return
+		}
+
+		c.zstdDec, c.zstdErr = zstd.NewReader(nil)
+	})
+
+	if c.zstdErr != nil {
+		return fmt.Errorf("init zstd: %w", c.zstdErr)
+	}
+
+	return nil
+}
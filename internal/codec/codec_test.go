@@ -0,0 +1,79 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressor(t *testing.T) {
+	t.Parallel()
+
+	large := bytes.Repeat([]byte(`{"key":"value"},`), 200)
+	small := []byte(`{"key":"value"}`)
+
+	for _, codec := range []Codec{None, Gzip, Zstd} {
+		codec := codec
+
+		t.Run("codec "+string(codec), func(t *testing.T) {
+			t.Parallel()
+
+			c, err := New(codec, 64)
+			require.NoError(t, err)
+
+			data, got, err := c.Encode(large)
+			require.NoError(t, err)
+			assert.Equal(t, codec, got)
+
+			if codec != None {
+				assert.Less(t, len(data), len(large))
+			}
+
+			body, err := c.Decode(data, got)
+			require.NoError(t, err)
+			assert.Equal(t, large, body)
+
+			// тела меньше порога не сжимаются.
+			data, got, err = c.Encode(small)
+			require.NoError(t, err)
+			assert.Equal(t, None, got)
+			assert.Equal(t, small, data)
+		})
+	}
+}
+
+func TestCompressor_DecodeAnyCodec(t *testing.T) {
+	t.Parallel()
+
+	body := bytes.Repeat([]byte("a"), 4096)
+
+	gz, err := New(Gzip, 0)
+	require.NoError(t, err)
+
+	data, codec, err := gz.Encode(body)
+	require.NoError(t, err)
+	require.Equal(t, Gzip, codec)
+
+	// сжатие отключено, но записи, сжатые раньше, читаются.
+	none, err := New(None, 0)
+	require.NoError(t, err)
+
+	got, err := none.Decode(data, codec)
+	require.NoError(t, err)
+	assert.Equal(t, body, got)
+
+	_, err = none.Decode([]byte("garbage"), Zstd)
+	require.ErrorIs(t, err, ErrCorrupted)
+
+	_, err = none.Decode(data, "lz4")
+	require.ErrorIs(t, err, ErrUnknownCodec)
+
+	_, err = New("lz4", 0)
+	require.ErrorIs(t, err, ErrUnknownCodec)
+
+	assert.InDelta(t, 4096, testutil.ToFloat64(gz.rawBytes.WithLabelValues("gzip")), 0)
+	assert.Less(t, testutil.ToFloat64(gz.storedBytes.WithLabelValues("gzip")), 4096.0)
+}
@@ -0,0 +1,173 @@
+// Package encryption шифрует тела объектов на диске AES-256-GCM.
+//
+// Ключи загружаются из локального файла вида
+//
+//	{"primary": "2024-02", "keys": {"2024-01": "<base64>", "2024-02": "<base64>"}}
+//
+// где каждый ключ — 32 байта в base64. Новые данные шифруются основным ключом, а его id сохраняется рядом
+// с данными, поэтому данные, зашифрованные прежними ключами, читаются, пока эти ключи остаются в файле.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// keySize размер ключа AES-256.
+const keySize = 32
+
+// macLabel метка, с которой из ключа шифрования выводится ключ MAC, чтобы один и тот же ключ
+// не использовался и для шифрования, и для контрольных сумм.
+const macLabel = "st-test body checksum"
+
+var (
+	// ErrInvalidKeyfile возвращается когда файл ключей не удалось разобрать.
+	ErrInvalidKeyfile = errors.New("invalid keyfile")
+	// ErrUnknownKey возвращается когда данные зашифрованы ключом, которого нет в файле ключей.
+	ErrUnknownKey = errors.New("unknown encryption key")
+	// ErrDecrypt возвращается когда данные не удалось расшифровать: они повреждены или зашифрованы другим ключом.
+	ErrDecrypt = errors.New("decryption failed")
+)
+
+// keyfile формат файла ключей.
+type keyfile struct {
+	Primary string            `json:"primary"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// Keyring набор ключей шифрования с выделенным основным ключом. Безопасен для конкурентного использования.
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+	// macs ключи MAC, выведенные из ключей шифрования.
+	macs map[string][]byte
+}
+
+// Load загружает ключи из файла.
+func Load(path string) (*Keyring, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keyfile: %w", err)
+	}
+
+	var kf keyfile
+
+	if err := json.Unmarshal(raw, &kf); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKeyfile, err)
+	}
+
+	keys := make(map[string][]byte, len(kf.Keys))
+
+	for id, encoded := range kf.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %w", ErrInvalidKeyfile, id, err)
+		}
+
+		keys[id] = key
+	}
+
+	return New(kf.Primary, keys)
+}
+
+// New создаёт набор из ключей keys с основным ключом primary.
+func New(primary string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("%w: primary key %q is not defined", ErrInvalidKeyfile, primary)
+	}
+
+	k := &Keyring{
+		primary: primary,
+		aeads:   make(map[string]cipher.AEAD, len(keys)),
+		macs:    make(map[string][]byte, len(keys)),
+	}
+
+	for id, key := range keys {
+		if id == "" {
+			return nil, fmt.Errorf("%w: empty key id", ErrInvalidKeyfile)
+		}
+
+		if len(key) != keySize {
+			return nil, fmt.Errorf("%w: key %q must be %d bytes", ErrInvalidKeyfile, id, keySize)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %w", ErrInvalidKeyfile, id, err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %w", ErrInvalidKeyfile, id, err)
+		}
+
+		k.aeads[id] = aead
+
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(macLabel))
+		k.macs[id] = mac.Sum(nil)
+	}
+
+	return k, nil
+}
+
+// Primary возвращает id основного ключа.
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// Encrypt шифрует данные основным ключом и возвращает шифротекст с nonce в начале и id ключа.
+// additional связывает шифротекст с контекстом (например, id объекта): расшифровать его можно только с тем же значением.
+func (k *Keyring) Encrypt(plain, additional []byte) ([]byte, string, error) {
+	aead := k.aeads[k.primary]
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, "", fmt.Errorf("generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plain, additional), k.primary, nil
+}
+
+// Decrypt расшифровывает данные, зашифрованные ключом keyID.
+func (k *Keyring) Decrypt(data []byte, keyID string, additional []byte) ([]byte, error) {
+	aead, ok := k.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: ciphertext is too short", ErrDecrypt)
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+
+	plain, err := aead.Open(nil, nonce, ciphertext, additional)
+	if err != nil {
+		return nil, fmt.Errorf("%w with key %q: %w", ErrDecrypt, keyID, err)
+	}
+
+	return plain, nil
+}
+
+// MAC возвращает HMAC-SHA256 данных на ключе, выведенном из ключа keyID. В отличие от простого хеша
+// по MAC нельзя проверить, хранится ли известное содержимое, не зная ключа.
+func (k *Keyring) MAC(keyID string, data []byte) ([]byte, error) {
+	key, ok := k.macs[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,111 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	t.Parallel()
+
+	k1, k2 := bytes.Repeat([]byte{1}, keySize), bytes.Repeat([]byte{2}, keySize)
+
+	old, err := New("k1", map[string][]byte{"k1": k1})
+	require.NoError(t, err)
+
+	data, keyID, err := old.Encrypt([]byte(`{"secret":true}`), []byte("storage:1"))
+	require.NoError(t, err)
+	assert.Equal(t, "k1", keyID)
+	assert.NotContains(t, string(data), "secret")
+
+	rotated, err := New("k2", map[string][]byte{"k1": k1, "k2": k2})
+	require.NoError(t, err)
+
+	plain, err := rotated.Decrypt(data, keyID, []byte("storage:1"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"secret":true}`, string(plain))
+
+	_, err = rotated.Decrypt(data, keyID, []byte("storage:2"))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = rotated.Decrypt(data, "k2", []byte("storage:1"))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = rotated.Decrypt(data[:3], keyID, nil)
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = rotated.Decrypt(data, "k3", []byte("storage:1"))
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	_, keyID, err = rotated.Encrypt([]byte(`{}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", keyID)
+}
+
+func TestKeyring_MAC(t *testing.T) {
+	t.Parallel()
+
+	k, err := New("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, keySize), "k2": bytes.Repeat([]byte{2}, keySize)})
+	require.NoError(t, err)
+
+	sum, err := k.MAC("k1", []byte(`{}`))
+	require.NoError(t, err)
+
+	again, err := k.MAC("k1", []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, sum, again)
+
+	other, err := k.MAC("k2", []byte(`{}`))
+	require.NoError(t, err)
+	assert.NotEqual(t, sum, other)
+
+	plain := sha256.Sum256([]byte(`{}`))
+	assert.NotEqual(t, plain[:], sum)
+
+	_, err = k.MAC("k3", nil)
+	require.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, keySize))
+
+	cases := []struct {
+		name    string
+		give    string
+		wantErr bool
+	}{
+		{name: "valid", give: `{"primary":"k1","keys":{"k1":"` + key + `"}}`},
+		{name: "invalid json", give: `{`, wantErr: true},
+		{name: "missing primary", give: `{"primary":"k2","keys":{"k1":"` + key + `"}}`, wantErr: true},
+		{name: "short key", give: `{"primary":"k1","keys":{"k1":"AAAA"}}`, wantErr: true},
+		{name: "invalid base64", give: `{"primary":"k1","keys":{"k1":"!"}}`, wantErr: true},
+	}
+
+	for i, tc := range cases {
+		path := filepath.Join(dir, tc.name)
+		require.NoError(t, os.WriteFile(path, []byte(tc.give), 0o600), i)
+
+		k, err := Load(path)
+		if tc.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidKeyfile, tc.name)
+
+			continue
+		}
+
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, "k1", k.Primary())
+	}
+
+	_, err := Load(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
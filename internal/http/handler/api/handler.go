@@ -2,8 +2,8 @@
 package api
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
@@ -12,7 +12,11 @@ import (
 
 	httpErr "st-test/internal/http/handler/handlererrors"
 	"st-test/internal/http/handler/responder"
+	"st-test/internal/jsoncheck"
+	"st-test/internal/mediatype"
 	"st-test/internal/models"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -21,6 +25,16 @@ import (
 const (
 	// expiresHeader заголовок для времени жизни объекта.
 	expiresHeader = "X-EXPIRES"
+	// expiresAtHeader заголовок с моментом истечения срока жизни возвращаемого объекта в формате RFC 3339.
+	expiresAtHeader = "X-EXPIRES-AT"
+	// versionHeader заголовок с версией возвращаемого объекта.
+	versionHeader = "X-VERSION"
+	// clientHeader заголовок с идентификатором клиента, в квоте которого учитывается объект.
+	clientHeader = "X-CLIENT-ID"
+	// defaultWaitTimeout время ожидания новой версии объекта по умолчанию.
+	defaultWaitTimeout = 30 * time.Second
+	// MaxWaitTimeout максимальное время ожидания новой версии объекта.
+	MaxWaitTimeout = time.Minute
 )
 
 // Storage описывает методы хранилища для сохранения и получения объектов.
@@ -29,23 +43,60 @@ const (
 type Storage interface {
 	SaveObject(ctx context.Context, item models.Item) (int, error)
 	GetObject(ctx context.Context, id int) (models.Item, error)
+	StatObject(ctx context.Context, id int) (models.Stat, error)
+	SetTTL(ctx context.Context, id int, ttl models.TTL) (models.Item, error)
+	Touch(ctx context.Context, id int) (models.Item, error)
+	WaitObject(ctx context.Context, id int, version uint64) (models.Item, error)
+	DeleteObject(ctx context.Context, id int) error
+	UpdateMetadata(ctx context.Context, id int, patch models.MetadataPatch) (models.Item, error)
+}
+
+// LargeStorage описывает методы хранилища крупных объектов.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=LargeStorage --with-expecter=true
+type LargeStorage interface {
+	Threshold() int64
+	Put(ctx context.Context, id int, owner, contentType string, body io.Reader) (models.LargeObject, bool, error)
+	Open(ctx context.Context, id int) (models.LargeObject, io.ReadSeeker, error)
+	Delete(ctx context.Context, id int) error
 }
 
 // Handler http-обработчик запросов.
 type Handler struct {
-	log   *zap.Logger
-	store Storage
+	log    *zap.Logger
+	store  Storage
+	large  LargeStorage
+	limits settings.LimitsSettings
+	// types правила приёма тел по типу содержимого, собранные из limits.
+	types mediatype.Policy
 }
 
 // NewHandler конструктор для Handler.
-func NewHandler(log *zap.Logger, store Storage) *Handler {
-	return &Handler{
-		log:   log.Named("object handler"),
-		store: store,
+func NewHandler(log *zap.Logger, store Storage, opts ...Option) *Handler {
+	h := &Handler{
+		log:    log.Named("object handler"),
+		store:  store,
+		limits: defaultLimits(),
+	}
+
+	for _, opt := range opts {
+		opt(h)
 	}
+
+	h.types = mediatype.NewPolicy(h.limits.ContentTypes, h.limits.JSONContentTypes)
+
+	return h
 }
 
-// AddObject метод обработки PUT запросов.
+// AddObject метод обработки PUT запросов. Тип содержимого из заголовка Content-Type сохраняется вместе с объектом;
+// тип не из списка допустимых отклоняется с 415, а как json проверяются только тела с типами json.
+// Метаданные объекта задаются заголовками X-Meta-*, теги — заголовком X-Tags; невалидные метаданные отклоняются с 400.
+// Срок жизни задаётся заголовками X-EXPIRES или X-EXPIRES-AT и X-EXPIRES-SLIDING, а X-TTL-MODE: keep сохраняет срок
+// жизни перезаписываемого объекта; неразборчивый срок жизни отклоняется с 400. Крупные объекты не имеют метаданных
+// и срока жизни.
+// Тело больше допустимого размера отклоняется с 413, слишком глубокий json или json со слишком большим
+// количеством ключей — с 422. Объект учитывается в квоте клиента из заголовка X-CLIENT-ID; при превышении квоты
+// возвращается 507. Если включены крупные объекты, тело больше порога записывается потоково без загрузки в память.
 func (h *Handler) AddObject(w http.ResponseWriter, r *http.Request) {
 	// получаем ID объекта из пути запроса
 	objectID, err := strconv.Atoi(chi.URLParam(r, "objectID"))
@@ -57,54 +108,79 @@ func (h *Handler) AddObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// вычитываем тело запроса
-	body, err := io.ReadAll(r.Body)
+	req, err := h.parsePut(objectID, r.Header)
 	if err != nil {
-		h.log.Error("failed read body", zap.Error(err))
-
-		responder.JSON(w, httpErr.NewInvalidInput("failed read body", err.Error()))
+		respondError(w, err)
 
 		return
 	}
 
-	defer r.Body.Close()
+	body := r.Body
 
-	// проверяем на валидность json
-	err = validate(body)
-	if err != nil {
-		h.log.Error("failed check body on json", zap.Error(err))
+	if h.large != nil {
+		threshold := h.large.Threshold()
+
+		if r.ContentLength > threshold {
+			h.addLargeObject(w, r, objectID, req.item.ContentType, r.Body)
+
+			return
+		}
 
-		responder.JSON(w, httpErr.NewInvalidInput("failed check body on json", err.Error()))
+		// размер тела неизвестен заранее: если оно оказалось больше порога, прочитанное начало
+		// дописывается к крупному объекту вместе с остатком.
+		if r.ContentLength < 0 {
+			head, err := io.ReadAll(io.LimitReader(r.Body, threshold+1))
+			if err != nil {
+				h.log.Error("failed read body", zap.Error(err))
+
+				responder.JSON(w, httpErr.NewInvalidInput("failed read body", err.Error()))
+
+				return
+			}
+
+			if int64(len(head)) > threshold {
+				h.addLargeObject(w, r, objectID, req.item.ContentType, io.MultiReader(bytes.NewReader(head), r.Body))
+
+				return
+			}
+
+			body = io.NopCloser(bytes.NewReader(head))
+		}
+	}
+
+	if r.ContentLength > h.limits.MaxBodySize {
+		responder.JSON(w, tooLargeError(h.limits.MaxBodySize))
 
 		return
 	}
 
-	var duration time.Duration
+	// вычитываем тело запроса
+	raw, err := io.ReadAll(http.MaxBytesReader(w, body, h.limits.MaxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			responder.JSON(w, tooLargeError(tooLarge.Limit))
 
-	// проверяем заголовок с описанием времени жизни объекта
-	if expires := r.Header.Get(expiresHeader); expires != "" {
-		duration, err = time.ParseDuration(expires)
-		if err != nil {
-			h.log.Error("failed parse expires duration", zap.Error(err))
+			return
 		}
+
+		h.log.Error("failed read body", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed read body", err.Error()))
+
+		return
 	}
 
-	// сохраняем объект в хранилище
-	resObjectID, err := h.store.SaveObject(r.Context(), models.Item{
-		ID:      objectID,
-		Body:    body,
-		Expires: duration,
-	})
-	if err != nil {
-		h.log.Error("failed save object", zap.Error(err))
+	defer r.Body.Close()
 
-		responder.JSON(w, httpErr.NewInternalError("failed save object", err.Error()))
+	created, err := h.savePut(r.Context(), req, raw)
+	if err != nil {
+		respondError(w, err)
 
 		return
 	}
 
-	// если из хранилища вернулся id = 0, значит объект уже был в хранилище и только обновили информацию
-	if resObjectID == 0 {
+	if !created {
 		h.log.Info("update object successful")
 
 		w.WriteHeader(http.StatusNoContent)
@@ -117,7 +193,10 @@ func (h *Handler) AddObject(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-// Object возвращает объект из хранилища.
+// Object возвращает объект из хранилища с сохранённым типом содержимого.
+// С параметром waitForVersion запрос ожидает, пока версия объекта не станет больше переданной,
+// но не дольше timeout (по умолчанию 30s). Если за это время объект не изменился, возвращается 304.
+// Крупный объект отдаётся потоком и может быть запрошен по частям заголовком Range.
 func (h *Handler) Object(w http.ResponseWriter, r *http.Request) {
 	// получаем ID объекта из пути запроса
 	objectID, err := strconv.Atoi(chi.URLParam(r, "objectID"))
@@ -129,9 +208,21 @@ func (h *Handler) Object(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Has("waitForVersion") {
+		h.waitObject(w, r, objectID)
+
+		return
+	}
+
 	// получаем объект из хранилища.
 	item, err := h.store.GetObject(r.Context(), objectID)
 	if err != nil {
+		if errors.Is(err, models.ErrNotFound) && h.large != nil {
+			h.largeObject(w, r, objectID)
+
+			return
+		}
+
 		if errors.Is(err, models.ErrNotFound) {
 			responder.JSON(w, httpErr.NewNotFoundError("failed get object"))
 
@@ -143,10 +234,191 @@ func (h *Handler) Object(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	writeObject(w, item)
+}
+
+// waitObject ожидает новую версию объекта.
+func (h *Handler) waitObject(w http.ResponseWriter, r *http.Request, objectID int) {
+	values := r.URL.Query()
+
+	version, err := strconv.ParseUint(values.Get("waitForVersion"), 10, 64)
+	if err != nil {
+		responder.JSON(w, httpErr.NewInvalidInput("failed parse waitForVersion", err.Error()))
+
+		return
+	}
+
+	timeout := defaultWaitTimeout
+
+	if v := values.Get("timeout"); v != "" {
+		timeout, err = time.ParseDuration(v)
+		if err != nil || timeout <= 0 || timeout > MaxWaitTimeout {
+			responder.JSON(w, httpErr.NewInvalidInput("failed parse timeout",
+				"timeout must be a positive duration not greater than "+MaxWaitTimeout.String()))
+
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	item, err := h.store.WaitObject(ctx, objectID, version)
+	if err != nil {
+		switch {
+		case r.Context().Err() != nil:
+			// клиент ушёл, отвечать некому.
+		case errors.Is(err, context.DeadlineExceeded):
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			h.log.Error("failed wait object", zap.Error(err))
+
+			responder.JSON(w, httpErr.NewInternalError("failed wait object", err.Error()))
+		}
+
+		return
+	}
+
+	writeObject(w, item)
+}
+
+// writeObject отдаёт тело объекта с его версией, типом содержимого, метаданными, размером, временем изменения
+// и истечения срока жизни. Объекты без типа содержимого отдаются как json. Заголовки нужны и в ответе на HEAD,
+// поэтому размер выставляется явно.
+func writeObject(w http.ResponseWriter, item models.Item) {
+	w.Header().Set(versionHeader, strconv.FormatUint(item.Version, 10))
+	w.Header().Set("Content-Length", strconv.Itoa(len(item.Body)))
+
+	if !item.UpdatedAt.IsZero() {
+		w.Header().Set("Last-Modified", item.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if !item.ExpiresAt.IsZero() {
+		w.Header().Set(expiresAtHeader, item.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+
+	setContentType(w, item.ContentType)
+	setMetadataHeader(w, item)
 	responder.JSON(w, item)
 }
 
-func validate(raw []byte) error {
-	var js json.RawMessage
-	return json.Unmarshal(raw, &js) //nolint:wrapcheck,nlreturn
+// setContentType выставляет тип содержимого ответа с телом объекта. Тип задан клиентом, поэтому браузерам
+// запрещено угадывать его по содержимому.
+func setContentType(w http.ResponseWriter, contentType string) {
+	if contentType == "" {
+		contentType = "application/json; charset=utf-8"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+}
+
+// contentTypeError возвращает ответ на недопустимый тип содержимого.
+func contentTypeError(err error) httpErr.HandlerError {
+	if errors.Is(err, mediatype.ErrUnsupported) {
+		return httpErr.NewUnsupportedMediaTypeError("failed check content type", err.Error())
+	}
+
+	return httpErr.NewInvalidInput("unknown Content-Type", err.Error())
+}
+
+// DeleteObject метод обработки DELETE запросов.
+func (h *Handler) DeleteObject(w http.ResponseWriter, r *http.Request) {
+	// получаем ID объекта из пути запроса
+	objectID, err := strconv.Atoi(chi.URLParam(r, "objectID"))
+	if err != nil {
+		h.log.Error("failed get object id", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed get object id", err.Error()))
+
+		return
+	}
+
+	// удаляем объект из хранилища.
+	err = h.store.DeleteObject(r.Context(), objectID)
+	if errors.Is(err, models.ErrNotFound) && h.large != nil {
+		err = h.large.Delete(r.Context(), objectID)
+	}
+
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			responder.JSON(w, httpErr.NewNotFoundError("failed delete object"))
+
+			return
+		}
+
+		h.log.Error("failed delete object", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed delete object", err.Error()))
+
+		return
+	}
+
+	h.log.Info("delete object successful")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addLargeObject записывает тело body как крупный объект, проверяя json по мере чтения.
+func (h *Handler) addLargeObject(w http.ResponseWriter, r *http.Request, objectID int, contentType string, body io.Reader) {
+	_, created, err := h.large.Put(r.Context(), objectID, r.Header.Get(clientHeader), contentType, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, mediatype.ErrInvalid), errors.Is(err, mediatype.ErrUnsupported):
+			responder.JSON(w, contentTypeError(err))
+		case errors.Is(err, jsoncheck.ErrInvalid):
+			h.log.Error("failed check body on json", zap.Error(err))
+
+			responder.JSON(w, httpErr.NewInvalidInput("failed check body on json", err.Error()))
+		case errors.Is(err, jsoncheck.ErrTooDeep), errors.Is(err, jsoncheck.ErrTooManyKeys):
+			h.log.Info("body exceeds json limits", zap.Error(err))
+
+			responder.JSON(w, httpErr.NewUnprocessableError("failed check body on json", err.Error()))
+		case errors.Is(err, storage.ErrQuotaExceeded):
+			h.log.Info("save large object exceeds quota", zap.Error(err))
+
+			responder.JSON(w, httpErr.NewQuotaExceededError("failed save object", err.Error()))
+		case errors.Is(err, models.ErrTooLarge):
+			responder.JSON(w, httpErr.NewTooLargeError("failed read body", err.Error()))
+		default:
+			h.log.Error("failed save large object", zap.Error(err))
+
+			responder.JSON(w, httpErr.NewInternalError("failed save object", err.Error()))
+		}
+
+		return
+	}
+
+	if !created {
+		h.log.Info("update large object successful")
+
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	h.log.Info("save large object successful")
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// largeObject отдаёт тело крупного объекта потоком. Поддерживаются запросы диапазонов (Range) и условные запросы
+// по ETag, которым служит контрольная сумма тела.
+func (h *Handler) largeObject(w http.ResponseWriter, r *http.Request, objectID int) {
+	obj, body, err := h.large.Open(r.Context(), objectID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			responder.JSON(w, httpErr.NewNotFoundError("failed get object"))
+
+			return
+		}
+
+		responder.JSON(w, httpErr.NewInternalError("failed get object", err.Error()))
+
+		return
+	}
+
+	setContentType(w, obj.ContentType)
+	w.Header().Set("ETag", `"`+obj.Checksum+`"`)
+	http.ServeContent(w, r, "", obj.UpdatedAt, body)
 }
@@ -3,17 +3,25 @@ package api
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"st-test/internal/http/handler/api/mocks"
+	"st-test/internal/jsoncheck"
 	"st-test/internal/models"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -72,6 +80,7 @@ func TestHandler_AddObject(t *testing.T) {
 				body := []byte(`some body`)
 				req, _ := http.NewRequest(http.MethodPut, "foo/bar", bytes.NewBuffer(body))
 				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+				req.Header.Set("Content-Type", "application/json")
 
 				return req
 			},
@@ -89,6 +98,7 @@ func TestHandler_AddObject(t *testing.T) {
 				body := []byte(`{"some":"body"}`)
 				req, _ := http.NewRequest(http.MethodPut, "foo/bar", bytes.NewBuffer(body))
 				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+				req.Header.Set("Content-Type", "application/json")
 
 				return req
 			},
@@ -102,6 +112,29 @@ func TestHandler_AddObject(t *testing.T) {
 				assert.Contains(t, rr.Body.String(), "failed save object")
 			},
 		},
+		{
+			name: "rejected by hook",
+			giveRequest: func() *http.Request {
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("objectID", "1")
+
+				body := []byte(`{"some":"body"}`)
+				req, _ := http.NewRequest(http.MethodPut, "foo/bar", bytes.NewBuffer(body))
+				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+				req.Header.Set("Content-Type", "application/json")
+
+				return req
+			},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().SaveObject(mock.Anything, mock.AnythingOfType("models.Item")).
+					Once().
+					Return(0, fmt.Errorf("%w: body is forbidden", storage.ErrRejected))
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+				assert.Contains(t, rr.Body.String(), "body is forbidden")
+			},
+		},
 		{
 			name: "successful update",
 			giveRequest: func() *http.Request {
@@ -111,6 +144,7 @@ func TestHandler_AddObject(t *testing.T) {
 				body := []byte(`{"some":"body"}`)
 				req, _ := http.NewRequest(http.MethodPut, "foo/bar", bytes.NewBuffer(body))
 				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+				req.Header.Set("Content-Type", "application/json")
 
 				return req
 			},
@@ -132,6 +166,7 @@ func TestHandler_AddObject(t *testing.T) {
 				body := []byte(`{"some":"body"}`)
 				req, _ := http.NewRequest(http.MethodPut, "foo/bar", bytes.NewBuffer(body))
 				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+				req.Header.Set("Content-Type", "application/json")
 
 				return req
 			},
@@ -144,6 +179,215 @@ func TestHandler_AddObject(t *testing.T) {
 				assert.Equal(t, http.StatusOK, rr.Code)
 			},
 		},
+		{
+			name: "body too large",
+			giveRequest: func() *http.Request {
+				return putRequest(`{"some":"`+strings.Repeat("x", 64)+`"}`, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+				assert.Contains(t, rr.Body.String(), "64 bytes")
+			},
+		},
+		{
+			name: "body too large without content length",
+			giveRequest: func() *http.Request {
+				req := putRequest(`{"some":"`+strings.Repeat("x", 64)+`"}`, nil)
+				req.ContentLength = -1
+
+				return req
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+			},
+		},
+		{
+			name: "json too deep",
+			giveRequest: func() *http.Request {
+				return putRequest(`{"a":[{"b":1}]}`, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+				assert.Contains(t, rr.Body.String(), "nested too deep")
+			},
+		},
+		{
+			name: "json with too many keys",
+			giveRequest: func() *http.Request {
+				return putRequest(`{"a":1,"b":{"c":2,"d":3},"e":4}`, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+				assert.Contains(t, rr.Body.String(), "too many keys")
+			},
+		},
+		{
+			name: "quota exceeded",
+			giveRequest: func() *http.Request {
+				return putRequest(`{"a":[1,2],"b":{"c":"}"}}`, http.Header{clientHeader: {"client-a"}})
+			},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().SaveObject(mock.Anything, models.Item{
+					ID:          1,
+					Body:        []byte(`{"a":[1,2],"b":{"c":"}"}}`),
+					Owner:       "client-a",
+					ContentType: "application/json",
+				}).Return(0, fmt.Errorf("%w: client %q may store at most 1 objects",
+					storage.ErrQuotaExceeded, "client-a")).Once()
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInsufficientStorage, rr.Code)
+				assert.Contains(t, rr.Body.String(), "QUOTA_EXCEEDED")
+			},
+		},
+		{
+			name: "without content type",
+			giveRequest: func() *http.Request {
+				req := putRequest(`{}`, nil)
+				req.Header.Del("Content-Type")
+
+				return req
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "unknown Content-Type")
+			},
+		},
+		{
+			name: "unsupported content type",
+			giveRequest: func() *http.Request {
+				return putRequest("\x89PNG", http.Header{"Content-Type": {"image/png"}})
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+				assert.Contains(t, rr.Body.String(), "UNSUPPORTED_MEDIA_TYPE")
+			},
+		},
+		{
+			name: "text body is not checked on json",
+			giveRequest: func() *http.Request {
+				return putRequest(`not json`, http.Header{"Content-Type": {"Text/Plain; Charset=utf-8"}})
+			},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().SaveObject(mock.Anything, models.Item{
+					ID:          1,
+					Body:        []byte(`not json`),
+					ContentType: "text/plain; charset=utf-8",
+				}).Return(1, nil).Once()
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+			},
+		},
+		{
+			name: "metadata and tags",
+			giveRequest: func() *http.Request {
+				req := putRequest(`{}`, nil)
+				req.Header.Set("X-Meta-Author", "Bob")
+				req.Header.Set("X-Tags", "draft, blog")
+				req.Header.Add("X-Tags", "draft")
+
+				return req
+			},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().SaveObject(mock.Anything, models.Item{
+					ID:          1,
+					Body:        []byte(`{}`),
+					ContentType: "application/json",
+					Metadata:    map[string]string{"author": "Bob"},
+					Tags:        []string{"blog", "draft"},
+				}).Return(1, nil).Once()
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+			},
+		},
+		{
+			name: "ttl headers",
+			giveRequest: func() *http.Request {
+				return putRequest(`{}`, http.Header{"X-Expires": {"1m"}, "X-Expires-Sliding": {"true"}, "X-Ttl-Mode": {"keep"}})
+			},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().SaveObject(mock.Anything, models.Item{
+					ID:          1,
+					Body:        []byte(`{}`),
+					ContentType: "application/json",
+					Expires:     time.Minute,
+					Sliding:     true,
+					KeepTTL:     true,
+				}).Return(0, nil).Once()
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, rr.Code)
+			},
+		},
+		{
+			name: "expires at header",
+			giveRequest: func() *http.Request {
+				return putRequest(`{}`, http.Header{"X-Expires-At": {"2100-01-01T00:00:00Z"}})
+			},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().SaveObject(mock.Anything, models.Item{
+					ID:          1,
+					Body:        []byte(`{}`),
+					ContentType: "application/json",
+					ExpiresAt:   time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+				}).Return(1, nil).Once()
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+			},
+		},
+		{
+			name: "invalid expires",
+			giveRequest: func() *http.Request {
+				return putRequest(`{}`, http.Header{"X-Expires": {"soon"}})
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed parse ttl")
+			},
+		},
+		{
+			name: "expires at in the past",
+			giveRequest: func() *http.Request {
+				return putRequest(`{}`, http.Header{"X-Expires-At": {"2000-01-01T00:00:00Z"}})
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "expiry time must be in the future")
+			},
+		},
+		{
+			name: "unknown ttl mode",
+			giveRequest: func() *http.Request {
+				return putRequest(`{}`, http.Header{"X-Ttl-Mode": {"forever"}})
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed parse ttl")
+			},
+		},
+		{
+			name: "invalid metadata",
+			giveRequest: func() *http.Request {
+				return putRequest(`{}`, http.Header{"X-Meta-Bad Key": {"value"}})
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed check metadata")
+			},
+		},
+		{
+			name: "json suffix body is checked on json",
+			giveRequest: func() *http.Request {
+				return putRequest(`not json`, http.Header{"Content-Type": {"application/ld+json"}})
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed check body on json")
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -156,10 +400,12 @@ func TestHandler_AddObject(t *testing.T) {
 				tc.prepareStore(store)
 			}
 
-			h := &Handler{
-				log:   log,
-				store: store,
-			}
+			h := NewHandler(log, store, WithLimits(settings.LimitsSettings{
+				MaxBodySize:  64,
+				MaxJSONDepth: 2,
+				MaxJSONKeys:  4,
+				ContentTypes: []string{"application/json", "+json", "text/plain"},
+			}))
 
 			var (
 				req = tc.giveRequest()
@@ -263,6 +509,144 @@ func TestHandler_Object(t *testing.T) {
 			},
 			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+				assert.Contains(t, rr.Body.String(), "{\"some\":\"body\"}")
+			},
+		},
+		{
+			name: "metadata headers",
+			giveRequest: func() *http.Request {
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("objectID", "1")
+
+				req, _ := http.NewRequest(http.MethodHead, "foo/bar", http.NoBody)
+				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+				return req
+			},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().GetObject(mock.Anything, 1).
+					Once().
+					Return(models.Item{
+						Body:      []byte(`{}`),
+						Version:   2,
+						Metadata:  map[string]string{"author": "Bob"},
+						Tags:      []string{"blog", "draft"},
+						UpdatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+						ExpiresAt: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC),
+					}, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "2", rr.Header().Get("X-Version"))
+				assert.Equal(t, "2", rr.Header().Get("Content-Length"))
+				assert.Equal(t, "Wed, 01 May 2024 10:00:00 GMT", rr.Header().Get("Last-Modified"))
+				assert.Equal(t, "2024-05-02T10:00:00Z", rr.Header().Get("X-Expires-At"))
+				assert.Equal(t, "Bob", rr.Header().Get("X-Meta-Author"))
+				assert.Equal(t, "blog,draft", rr.Header().Get("X-Tags"))
+			},
+		},
+		{
+			name: "binary object",
+			giveRequest: func() *http.Request {
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("objectID", "1")
+
+				req, _ := http.NewRequest(http.MethodGet, "foo/bar", http.NoBody)
+				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+				return req
+			},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().GetObject(mock.Anything, 1).
+					Once().
+					Return(models.Item{
+						Body:        []byte{0x89, 'P', 'N', 'G'},
+						ContentType: "image/png",
+					}, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+				assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+				assert.Equal(t, []byte{0x89, 'P', 'N', 'G'}, rr.Body.Bytes())
+			},
+		},
+		{
+			name: "invalid wait version",
+			giveRequest: func() *http.Request {
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("objectID", "1")
+
+				req, _ := http.NewRequest(http.MethodGet, "foo/bar?waitForVersion=abc", http.NoBody)
+				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+				return req
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed parse waitForVersion")
+			},
+		},
+		{
+			name: "invalid wait timeout",
+			giveRequest: func() *http.Request {
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("objectID", "1")
+
+				req, _ := http.NewRequest(http.MethodGet, "foo/bar?waitForVersion=1&timeout=2m", http.NoBody)
+				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+				return req
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed parse timeout")
+			},
+		},
+		{
+			name: "wait timeout",
+			giveRequest: func() *http.Request {
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("objectID", "1")
+
+				req, _ := http.NewRequest(http.MethodGet, "foo/bar?waitForVersion=3&timeout=10ms", http.NoBody)
+				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+				return req
+			},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().WaitObject(mock.Anything, 1, uint64(3)).
+					RunAndReturn(func(ctx context.Context, _ int, _ uint64) (models.Item, error) {
+						<-ctx.Done()
+
+						return models.Item{}, ctx.Err()
+					}).Once()
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotModified, rr.Code)
+				assert.Empty(t, rr.Body.String())
+			},
+		},
+		{
+			name: "wait success",
+			giveRequest: func() *http.Request {
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("objectID", "1")
+
+				req, _ := http.NewRequest(http.MethodGet, "foo/bar?waitForVersion=3", http.NoBody)
+				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+				return req
+			},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().WaitObject(mock.Anything, 1, uint64(3)).
+					Once().
+					Return(models.Item{ID: 1, Body: []byte(`{"some":"body"}`), Version: 4}, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "4", rr.Header().Get(versionHeader))
 				assert.Contains(t, rr.Body.String(), "{\"some\":\"body\"}")
 			},
 		},
@@ -293,3 +677,695 @@ func TestHandler_Object(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_DeleteObject(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	cases := []struct {
+		name         string
+		giveID       string
+		prepareStore func(store *mocks.Storage)
+		checkResult  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "invalid object id format",
+			giveID: "invalid",
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed get object id")
+			},
+		},
+		{
+			name:   "not found",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().DeleteObject(mock.Anything, 1).
+					Once().
+					Return(models.ErrNotFound)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed delete object")
+			},
+		},
+		{
+			name:   "store error",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().DeleteObject(mock.Anything, 1).
+					Once().
+					Return(errors.New("some error"))
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed delete object")
+			},
+		},
+		{
+			name:   "success",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().DeleteObject(mock.Anything, 1).
+					Once().
+					Return(nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, rr.Code)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			require.NotNil(t, store)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			h := &Handler{
+				log:   log,
+				store: store,
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("objectID", tc.giveID)
+
+			req, _ := http.NewRequest(http.MethodDelete, "foo/bar", http.NoBody)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			h.DeleteObject(rr, req)
+			tc.checkResult(t, rr)
+		})
+	}
+}
+
+func TestHandler_UpdateMetadata(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	author := "Bob"
+
+	cases := []struct {
+		name         string
+		giveID       string
+		giveBody     string
+		prepareStore func(store *mocks.Storage)
+		checkResult  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "invalid object id format",
+			giveID: "invalid",
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed get object id")
+			},
+		},
+		{
+			name:     "invalid request",
+			giveID:   "1",
+			giveBody: `not json`,
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed decode request")
+			},
+		},
+		{
+			name:     "not found",
+			giveID:   "1",
+			giveBody: `{"tags":[]}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().UpdateMetadata(mock.Anything, 1, mock.Anything).
+					Once().
+					Return(models.Item{}, models.ErrNotFound)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed update metadata")
+			},
+		},
+		{
+			name:     "invalid metadata",
+			giveID:   "1",
+			giveBody: `{"tags":[""]}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().UpdateMetadata(mock.Anything, 1, mock.Anything).
+					Once().
+					Return(models.Item{}, models.ErrInvalidMetadata)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed update metadata")
+			},
+		},
+		{
+			name:     "store error",
+			giveID:   "1",
+			giveBody: `{}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().UpdateMetadata(mock.Anything, 1, mock.Anything).
+					Once().
+					Return(models.Item{}, errors.New("some error"))
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed update metadata")
+			},
+		},
+		{
+			name:     "success",
+			giveID:   "1",
+			giveBody: `{"metadata":{"author":"Bob","draft":null}}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().UpdateMetadata(mock.Anything, 1, models.MetadataPatch{
+					Metadata: map[string]*string{"author": &author, "draft": nil},
+				}).
+					Once().
+					Return(models.Item{ID: 1, Version: 3, Metadata: map[string]string{"author": "Bob"}, Tags: []string{"blog"}}, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "3", rr.Header().Get("X-Version"))
+				assert.JSONEq(t, `{"metadata":{"author":"Bob"},"tags":["blog"]}`, rr.Body.String())
+			},
+		},
+		{
+			name:     "empty metadata",
+			giveID:   "1",
+			giveBody: `{"metadata":{"author":null},"tags":[]}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().UpdateMetadata(mock.Anything, 1, mock.Anything).Once().Return(models.Item{ID: 1, Version: 4}, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.JSONEq(t, `{"metadata":{},"tags":[]}`, rr.Body.String())
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			require.NotNil(t, store)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			h := &Handler{
+				log:   log,
+				store: store,
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("objectID", tc.giveID)
+
+			req, _ := http.NewRequest(http.MethodPatch, "foo/bar", strings.NewReader(tc.giveBody))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			h.UpdateMetadata(rr, req)
+			tc.checkResult(t, rr)
+		})
+	}
+}
+
+func TestHandler_Stat(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name         string
+		giveID       string
+		prepareStore func(store *mocks.Storage)
+		checkResult  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "invalid object id format",
+			giveID: "invalid",
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed get object id")
+			},
+		},
+		{
+			name:   "not found",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().StatObject(mock.Anything, 1).Once().Return(models.Stat{}, models.ErrNotFound)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed get object stat")
+			},
+		},
+		{
+			name:   "store error",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().StatObject(mock.Anything, 1).Once().Return(models.Stat{}, errors.New("some error"))
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed get object stat")
+			},
+		},
+		{
+			name:   "legacy object",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().StatObject(mock.Anything, 1).Once().Return(models.Stat{ID: 1, Size: 2, Version: 3}, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.JSONEq(t, `{"id":1,"size":2,"version":3,"content_type":"application/json","large":false}`, rr.Body.String())
+			},
+		},
+		{
+			name:   "success",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().StatObject(mock.Anything, 1).Once().Return(models.Stat{
+					ID:          1,
+					Size:        9,
+					Version:     4,
+					Owner:       "client-a",
+					ContentType: "text/plain",
+					Tags:        []string{"draft"},
+					CreatedAt:   created,
+					UpdatedAt:   created.Add(time.Minute),
+					ExpiresAt:   time.Now().Add(time.Hour),
+				}, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+
+				var got Stat
+
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+				assert.Equal(t, int64(9), got.Size)
+				assert.Equal(t, uint64(4), got.Version)
+				assert.Equal(t, "text/plain", got.ContentType)
+				assert.Equal(t, []string{"draft"}, got.Tags)
+				assert.Equal(t, created, *got.CreatedAt)
+				assert.Equal(t, created.Add(time.Minute), *got.UpdatedAt)
+				require.NotNil(t, got.TTLRemaining)
+				assert.InDelta(t, 3600, *got.TTLRemaining, 60)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			require.NotNil(t, store)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			h := &Handler{
+				log:   log,
+				store: store,
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("objectID", tc.giveID)
+
+			req, _ := http.NewRequest(http.MethodGet, "foo/bar", http.NoBody)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			h.Stat(rr, req)
+			tc.checkResult(t, rr)
+		})
+	}
+}
+
+func TestHandler_TTL(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// ttlRequest создаёт запрос к сроку жизни объекта с id 1.
+	ttlRequest := func(method, body string) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("objectID", "1")
+
+		req := httptest.NewRequest(method, "/objects/1/ttl", strings.NewReader(body))
+
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	cases := []struct {
+		name         string
+		prepareStore func(store *mocks.Storage)
+		giveRequest  *http.Request
+		handle       func(h *Handler) http.HandlerFunc
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name: "get ttl",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().StatObject(mock.Anything, 1).
+					Return(models.Stat{ID: 1, Expires: time.Hour, ExpiresAt: at, Sliding: true}, nil).Once()
+			},
+			giveRequest: ttlRequest(http.MethodGet, ""),
+			handle:      func(h *Handler) http.HandlerFunc { return h.TTL },
+			wantCode:    http.StatusOK,
+			wantBody:    `"duration":"1h0m0s","expires_at":"2100-01-01T00:00:00Z"`,
+		},
+		{
+			name: "get ttl of object without ttl",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().StatObject(mock.Anything, 1).Return(models.Stat{ID: 1}, nil).Once()
+			},
+			giveRequest: ttlRequest(http.MethodGet, ""),
+			handle:      func(h *Handler) http.HandlerFunc { return h.TTL },
+			wantCode:    http.StatusOK,
+			wantBody:    `{"sliding":false}`,
+		},
+		{
+			name: "get ttl of missing object",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().StatObject(mock.Anything, 1).Return(models.Stat{}, models.ErrNotFound).Once()
+			},
+			giveRequest: ttlRequest(http.MethodGet, ""),
+			handle:      func(h *Handler) http.HandlerFunc { return h.TTL },
+			wantCode:    http.StatusNotFound,
+		},
+		{
+			name: "set sliding ttl",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().SetTTL(mock.Anything, 1, models.TTL{Duration: time.Hour, Sliding: true}).
+					Return(models.Item{ID: 1, Version: 5, Expires: time.Hour, ExpiresAt: at, Sliding: true}, nil).Once()
+			},
+			giveRequest: ttlRequest(http.MethodPut, `{"duration":"1h","sliding":true}`),
+			handle:      func(h *Handler) http.HandlerFunc { return h.SetTTL },
+			wantCode:    http.StatusOK,
+			wantBody:    `"sliding":true`,
+		},
+		{
+			name: "set expiry time",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().SetTTL(mock.Anything, 1, models.TTL{At: at}).
+					Return(models.Item{ID: 1, Version: 5, ExpiresAt: at}, nil).Once()
+			},
+			giveRequest: ttlRequest(http.MethodPut, `{"expires_at":"2100-01-01T00:00:00Z"}`),
+			handle:      func(h *Handler) http.HandlerFunc { return h.SetTTL },
+			wantCode:    http.StatusOK,
+			wantBody:    `"expires_at":"2100-01-01T00:00:00Z"`,
+		},
+		{
+			name: "set invalid ttl",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().SetTTL(mock.Anything, 1, mock.Anything).
+					Return(models.Item{}, fmt.Errorf("%w: sliding expiry requires a duration", models.ErrInvalidTTL)).Once()
+			},
+			giveRequest: ttlRequest(http.MethodPut, `{"expires_at":"2100-01-01T00:00:00Z","sliding":true}`),
+			handle:      func(h *Handler) http.HandlerFunc { return h.SetTTL },
+			wantCode:    http.StatusBadRequest,
+			wantBody:    "sliding expiry requires a duration",
+		},
+		{
+			name:        "set ttl without duration",
+			giveRequest: ttlRequest(http.MethodPut, `{"sliding":true}`),
+			handle:      func(h *Handler) http.HandlerFunc { return h.SetTTL },
+			wantCode:    http.StatusBadRequest,
+			wantBody:    "duration or expires_at is required",
+		},
+		{
+			name:        "set unparsable duration",
+			giveRequest: ttlRequest(http.MethodPut, `{"duration":"-1h"}`),
+			handle:      func(h *Handler) http.HandlerFunc { return h.SetTTL },
+			wantCode:    http.StatusBadRequest,
+			wantBody:    "duration must be positive",
+		},
+		{
+			name: "delete ttl",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().SetTTL(mock.Anything, 1, models.TTL{}).Return(models.Item{ID: 1, Version: 6}, nil).Once()
+			},
+			giveRequest: ttlRequest(http.MethodDelete, ""),
+			handle:      func(h *Handler) http.HandlerFunc { return h.DeleteTTL },
+			wantCode:    http.StatusNoContent,
+		},
+		{
+			name: "touch",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Touch(mock.Anything, 1).
+					Return(models.Item{ID: 1, Expires: time.Minute, ExpiresAt: time.Now().Add(time.Minute)}, nil).Once()
+			},
+			giveRequest: ttlRequest(http.MethodPost, ""),
+			handle:      func(h *Handler) http.HandlerFunc { return h.Touch },
+			wantCode:    http.StatusOK,
+			wantBody:    `"ttl_remaining":60`,
+		},
+		{
+			name: "touch missing object",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Touch(mock.Anything, 1).Return(models.Item{}, models.ErrNotFound).Once()
+			},
+			giveRequest: ttlRequest(http.MethodPost, ""),
+			handle:      func(h *Handler) http.HandlerFunc { return h.Touch },
+			wantCode:    http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			if tt.prepareStore != nil {
+				tt.prepareStore(store)
+			}
+
+			h := NewHandler(zap.NewNop(), store)
+
+			rr := httptest.NewRecorder()
+			tt.handle(h)(rr, tt.giveRequest)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+// putRequest создаёт запрос на сохранение json-объекта с id 1; заголовки header заменяют заголовки по умолчанию.
+func putRequest(body string, header http.Header) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("objectID", "1")
+
+	req := httptest.NewRequest(http.MethodPut, "/objects/1", strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	req.Header.Set("Content-Type", "application/json")
+
+	for k, v := range header {
+		req.Header.Set(k, v[0])
+	}
+
+	return req
+}
+
+func TestHandler_LargeObject(t *testing.T) {
+	t.Parallel()
+
+	const threshold = 16
+
+	body := `{"large":"` + strings.Repeat("x", 32) + `"}`
+	obj := models.LargeObject{ID: 1, Size: int64(len(body)), Checksum: "sum"}
+
+	// objectRequest создаёт запрос к объекту с id 1.
+	objectRequest := func(method string, body io.Reader) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("objectID", "1")
+
+		req := httptest.NewRequest(method, "/objects/1", body)
+		req.Header.Set("Content-Type", "application/json")
+
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	// readBody проверяет, что в крупный объект записано тело body.
+	readBody := func(t *testing.T) func(context.Context, int, string, string, io.Reader) {
+		t.Helper()
+
+		return func(_ context.Context, _ int, _, _ string, r io.Reader) {
+			raw, err := io.ReadAll(r)
+			assert.NoError(t, err)
+			assert.Equal(t, body, string(raw))
+		}
+	}
+
+	cases := []struct {
+		name        string
+		prepare     func(t *testing.T, store *mocks.Storage, large *mocks.LargeStorage)
+		giveRequest func() *http.Request
+		handle      func(h *Handler) http.HandlerFunc
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			name: "put large body",
+			prepare: func(t *testing.T, _ *mocks.Storage, large *mocks.LargeStorage) {
+				large.EXPECT().Put(mock.Anything, 1, "importer", "application/json", mock.Anything).Run(readBody(t)).Return(obj, true, nil).Once()
+			},
+			giveRequest: func() *http.Request {
+				req := objectRequest(http.MethodPut, strings.NewReader(body))
+				req.Header.Set(clientHeader, "importer")
+
+				return req
+			},
+			handle:   func(h *Handler) http.HandlerFunc { return h.AddObject },
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "put large body of unknown length",
+			prepare: func(t *testing.T, _ *mocks.Storage, large *mocks.LargeStorage) {
+				large.EXPECT().Put(mock.Anything, 1, "", "application/json", mock.Anything).Run(readBody(t)).Return(obj, false, nil).Once()
+			},
+			giveRequest: func() *http.Request {
+				req := objectRequest(http.MethodPut, strings.NewReader(body))
+				req.ContentLength = -1
+
+				return req
+			},
+			handle:   func(h *Handler) http.HandlerFunc { return h.AddObject },
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "put small body of unknown length replaces large object",
+			prepare: func(_ *testing.T, store *mocks.Storage, large *mocks.LargeStorage) {
+				store.EXPECT().SaveObject(mock.Anything, models.Item{ID: 1, Body: []byte(`{}`), ContentType: "application/json"}).
+					Return(1, nil).Once()
+				large.EXPECT().Delete(mock.Anything, 1).Return(nil).Once()
+			},
+			giveRequest: func() *http.Request {
+				req := objectRequest(http.MethodPut, strings.NewReader(`{}`))
+				req.ContentLength = -1
+
+				return req
+			},
+			handle:   func(h *Handler) http.HandlerFunc { return h.AddObject },
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "put too deep large body",
+			prepare: func(_ *testing.T, _ *mocks.Storage, large *mocks.LargeStorage) {
+				large.EXPECT().Put(mock.Anything, 1, "", "application/json", mock.Anything).Return(models.LargeObject{}, false, jsoncheck.ErrTooDeep).Once()
+			},
+			giveRequest: func() *http.Request { return objectRequest(http.MethodPut, strings.NewReader(body)) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.AddObject },
+			wantCode:    http.StatusUnprocessableEntity,
+		},
+		{
+			name: "put too large body",
+			prepare: func(_ *testing.T, _ *mocks.Storage, large *mocks.LargeStorage) {
+				large.EXPECT().Put(mock.Anything, 1, "", "application/json", mock.Anything).Return(models.LargeObject{}, false, models.ErrTooLarge).Once()
+			},
+			giveRequest: func() *http.Request { return objectRequest(http.MethodPut, strings.NewReader(body)) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.AddObject },
+			wantCode:    http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "put large body over quota",
+			prepare: func(_ *testing.T, _ *mocks.Storage, large *mocks.LargeStorage) {
+				large.EXPECT().Put(mock.Anything, 1, "", "application/json", mock.Anything).
+					Return(models.LargeObject{}, false, fmt.Errorf("%w: only 10 bytes are left", storage.ErrQuotaExceeded)).Once()
+			},
+			giveRequest: func() *http.Request { return objectRequest(http.MethodPut, strings.NewReader(body)) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.AddObject },
+			wantCode:    http.StatusInsufficientStorage,
+		},
+		{
+			name: "get range of large object",
+			prepare: func(_ *testing.T, store *mocks.Storage, large *mocks.LargeStorage) {
+				store.EXPECT().GetObject(mock.Anything, 1).Return(models.Item{}, models.ErrNotFound).Once()
+				large.EXPECT().Open(mock.Anything, 1).Return(obj, strings.NewReader(body), nil).Once()
+			},
+			giveRequest: func() *http.Request {
+				req := objectRequest(http.MethodGet, http.NoBody)
+				req.Header.Set("Range", "bytes=2-6")
+
+				return req
+			},
+			handle:   func(h *Handler) http.HandlerFunc { return h.Object },
+			wantCode: http.StatusPartialContent,
+			wantBody: `large`,
+		},
+		{
+			name: "get missing object",
+			prepare: func(_ *testing.T, store *mocks.Storage, large *mocks.LargeStorage) {
+				store.EXPECT().GetObject(mock.Anything, 1).Return(models.Item{}, models.ErrNotFound).Once()
+				large.EXPECT().Open(mock.Anything, 1).Return(models.LargeObject{}, nil, models.ErrNotFound).Once()
+			},
+			giveRequest: func() *http.Request { return objectRequest(http.MethodGet, http.NoBody) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.Object },
+			wantCode:    http.StatusNotFound,
+		},
+		{
+			name: "stat of large object",
+			prepare: func(_ *testing.T, store *mocks.Storage, large *mocks.LargeStorage) {
+				store.EXPECT().StatObject(mock.Anything, 1).Return(models.Stat{}, models.ErrNotFound).Once()
+				large.EXPECT().Open(mock.Anything, 1).Return(obj, strings.NewReader(body), nil).Once()
+			},
+			giveRequest: func() *http.Request { return objectRequest(http.MethodGet, http.NoBody) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.Stat },
+			wantCode:    http.StatusOK,
+			wantBody:    `"checksum":"sum"`,
+		},
+		{
+			name: "delete large object",
+			prepare: func(_ *testing.T, store *mocks.Storage, large *mocks.LargeStorage) {
+				store.EXPECT().DeleteObject(mock.Anything, 1).Return(models.ErrNotFound).Once()
+				large.EXPECT().Delete(mock.Anything, 1).Return(nil).Once()
+			},
+			giveRequest: func() *http.Request { return objectRequest(http.MethodDelete, http.NoBody) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.DeleteObject },
+			wantCode:    http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			large := mocks.NewLargeStorage(t)
+			large.EXPECT().Threshold().Return(threshold).Maybe()
+			tt.prepare(t, store, large)
+
+			h := NewHandler(zap.NewNop(), store, WithLimits(settings.LimitsSettings{MaxJSONDepth: 2}), WithLargeObjects(large))
+
+			rr := httptest.NewRecorder()
+			tt.handle(h)(rr, tt.giveRequest())
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"st-test/internal/settings"
+)
+
+const (
+	defaultMaxBodySize  = 1 << 20
+	defaultMaxJSONDepth = 64
+	defaultMaxJSONKeys  = 10000
+)
+
+// Option описывает необязательную настройку обработчика.
+type Option func(h *Handler)
+
+// WithLimits задаёт ограничения на тело сохраняемого объекта. Нулевые значения заменяются значениями по умолчанию.
+func WithLimits(set settings.LimitsSettings) Option {
+	return func(h *Handler) {
+		if set.MaxBodySize > 0 {
+			h.limits.MaxBodySize = set.MaxBodySize
+		}
+
+		if set.MaxJSONDepth > 0 {
+			h.limits.MaxJSONDepth = set.MaxJSONDepth
+		}
+
+		if set.MaxJSONKeys > 0 {
+			h.limits.MaxJSONKeys = set.MaxJSONKeys
+		}
+
+		if len(set.ContentTypes) > 0 {
+			h.limits.ContentTypes = set.ContentTypes
+		}
+
+		if len(set.JSONContentTypes) > 0 {
+			h.limits.JSONContentTypes = set.JSONContentTypes
+		}
+	}
+}
+
+func defaultLimits() settings.LimitsSettings {
+	return settings.LimitsSettings{
+		MaxBodySize:  defaultMaxBodySize,
+		MaxJSONDepth: defaultMaxJSONDepth,
+		MaxJSONKeys:  defaultMaxJSONKeys,
+	}
+}
+
+// WithLargeObjects включает крупные объекты: тела больше порога large записываются потоково и хранятся частями,
+// а при чтении поддерживаются запросы диапазонов.
+func WithLargeObjects(large LargeStorage) Option {
+	return func(h *Handler) {
+		h.large = large
+	}
+}
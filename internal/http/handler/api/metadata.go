@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// metaHeaderPrefix префикс заголовков с метаданными объекта: X-Meta-Author задаёт ключ author.
+	metaHeaderPrefix = "X-Meta-"
+	// tagsHeader заголовок с тегами объекта через запятую.
+	tagsHeader = "X-Tags"
+)
+
+// MetadataRequest тело запроса на изменение метаданных объекта. Ключи metadata со значением null удаляются,
+// остальные задаются; tags, если переданы, заменяют теги объекта целиком.
+type MetadataRequest struct {
+	Metadata map[string]*string `json:"metadata"`
+	Tags     *[]string          `json:"tags"`
+}
+
+// Metadata метаданные и теги объекта.
+type Metadata struct {
+	Metadata map[string]string `json:"metadata"`
+	Tags     []string          `json:"tags"`
+}
+
+// ToJSON возвращает метаданные как json.
+func (m Metadata) ToJSON() ([]byte, error) {
+	return json.Marshal(m) //nolint:wrapcheck
+}
+
+// UpdateMetadata метод обработки PATCH запросов на изменение метаданных и тегов объекта без перезаписи тела.
+// Возвращает метаданные и теги после изменения и новую версию объекта в заголовке X-Version.
+func (h *Handler) UpdateMetadata(w http.ResponseWriter, r *http.Request) {
+	objectID, err := strconv.Atoi(chi.URLParam(r, "objectID"))
+	if err != nil {
+		h.log.Error("failed get object id", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed get object id", err.Error()))
+
+		return
+	}
+
+	var req MetadataRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("failed decode request", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed decode request", err.Error()))
+
+		return
+	}
+
+	item, err := h.store.UpdateMetadata(r.Context(), objectID, models.MetadataPatch{Metadata: req.Metadata, Tags: req.Tags})
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			responder.JSON(w, httpErr.NewNotFoundError("failed update metadata"))
+		case errors.Is(err, models.ErrInvalidMetadata):
+			responder.JSON(w, httpErr.NewInvalidInput("failed update metadata", err.Error()))
+		default:
+			h.log.Error("failed update metadata", zap.Error(err))
+
+			responder.JSON(w, httpErr.NewInternalError("failed update metadata", err.Error()))
+		}
+
+		return
+	}
+
+	// пустые метаданные и теги отдаются пустыми объектом и списком, а не null.
+	resp := Metadata{Metadata: item.Metadata, Tags: item.Tags}
+	if resp.Metadata == nil {
+		resp.Metadata = map[string]string{}
+	}
+
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+
+	w.Header().Set(versionHeader, strconv.FormatUint(item.Version, 10))
+	responder.JSON(w, resp)
+}
+
+// metadataFromHeader возвращает метаданные и теги объекта из заголовков X-Meta-* и X-Tags в каноническом виде.
+func metadataFromHeader(header http.Header) (map[string]string, []string, error) {
+	meta := make(map[string]string)
+
+	for name, values := range header {
+		if key, ok := strings.CutPrefix(name, metaHeaderPrefix); ok && len(values) > 0 {
+			meta[key] = values[0]
+		}
+	}
+
+	var tags []string
+
+	for _, value := range header.Values(tagsHeader) {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return models.NormalizeMetadata(meta, tags) //nolint:wrapcheck
+}
+
+// setMetadataHeader выставляет заголовки X-Meta-* и X-Tags по метаданным и тегам объекта.
+func setMetadataHeader(w http.ResponseWriter, item models.Item) {
+	for k, v := range item.Metadata {
+		w.Header().Set(metaHeaderPrefix+k, v)
+	}
+
+	if len(item.Tags) > 0 {
+		w.Header().Set(tagsHeader, strings.Join(item.Tags, ","))
+	}
+}
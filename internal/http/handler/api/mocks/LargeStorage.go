@@ -0,0 +1,264 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "st-test/internal/models"
+)
+
+// LargeStorage is an autogenerated mock type for the LargeStorage type
+type LargeStorage struct {
+	mock.Mock
+}
+
+type LargeStorage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *LargeStorage) EXPECT() *LargeStorage_Expecter {
+	return &LargeStorage_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *LargeStorage) Delete(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LargeStorage_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type LargeStorage_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *LargeStorage_Expecter) Delete(ctx interface{}, id interface{}) *LargeStorage_Delete_Call {
+	return &LargeStorage_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
+}
+
+func (_c *LargeStorage_Delete_Call) Run(run func(ctx context.Context, id int)) *LargeStorage_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *LargeStorage_Delete_Call) Return(_a0 error) *LargeStorage_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *LargeStorage_Delete_Call) RunAndReturn(run func(context.Context, int) error) *LargeStorage_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Open provides a mock function with given fields: ctx, id
+func (_m *LargeStorage) Open(ctx context.Context, id int) (models.LargeObject, io.ReadSeeker, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Open")
+	}
+
+	var r0 models.LargeObject
+	var r1 io.ReadSeeker
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.LargeObject, io.ReadSeeker, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.LargeObject); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.LargeObject)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) io.ReadSeeker); ok {
+		r1 = rf(ctx, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(io.ReadSeeker)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int) error); ok {
+		r2 = rf(ctx, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// LargeStorage_Open_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Open'
+type LargeStorage_Open_Call struct {
+	*mock.Call
+}
+
+// Open is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *LargeStorage_Expecter) Open(ctx interface{}, id interface{}) *LargeStorage_Open_Call {
+	return &LargeStorage_Open_Call{Call: _e.mock.On("Open", ctx, id)}
+}
+
+func (_c *LargeStorage_Open_Call) Run(run func(ctx context.Context, id int)) *LargeStorage_Open_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *LargeStorage_Open_Call) Return(_a0 models.LargeObject, _a1 io.ReadSeeker, _a2 error) *LargeStorage_Open_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *LargeStorage_Open_Call) RunAndReturn(run func(context.Context, int) (models.LargeObject, io.ReadSeeker, error)) *LargeStorage_Open_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Put provides a mock function with given fields: ctx, id, owner, contentType, body
+func (_m *LargeStorage) Put(ctx context.Context, id int, owner string, contentType string, body io.Reader) (models.LargeObject, bool, error) {
+	ret := _m.Called(ctx, id, owner, contentType, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Put")
+	}
+
+	var r0 models.LargeObject
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string, io.Reader) (models.LargeObject, bool, error)); ok {
+		return rf(ctx, id, owner, contentType, body)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string, io.Reader) models.LargeObject); ok {
+		r0 = rf(ctx, id, owner, contentType, body)
+	} else {
+		r0 = ret.Get(0).(models.LargeObject)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, string, io.Reader) bool); ok {
+		r1 = rf(ctx, id, owner, contentType, body)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, string, string, io.Reader) error); ok {
+		r2 = rf(ctx, id, owner, contentType, body)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// LargeStorage_Put_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Put'
+type LargeStorage_Put_Call struct {
+	*mock.Call
+}
+
+// Put is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - owner string
+//   - contentType string
+//   - body io.Reader
+func (_e *LargeStorage_Expecter) Put(ctx interface{}, id interface{}, owner interface{}, contentType interface{}, body interface{}) *LargeStorage_Put_Call {
+	return &LargeStorage_Put_Call{Call: _e.mock.On("Put", ctx, id, owner, contentType, body)}
+}
+
+func (_c *LargeStorage_Put_Call) Run(run func(ctx context.Context, id int, owner string, contentType string, body io.Reader)) *LargeStorage_Put_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(string), args[4].(io.Reader))
+	})
+	return _c
+}
+
+func (_c *LargeStorage_Put_Call) Return(_a0 models.LargeObject, _a1 bool, _a2 error) *LargeStorage_Put_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *LargeStorage_Put_Call) RunAndReturn(run func(context.Context, int, string, string, io.Reader) (models.LargeObject, bool, error)) *LargeStorage_Put_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Threshold provides a mock function with given fields:
+func (_m *LargeStorage) Threshold() int64 {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Threshold")
+	}
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	return r0
+}
+
+// LargeStorage_Threshold_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Threshold'
+type LargeStorage_Threshold_Call struct {
+	*mock.Call
+}
+
+// Threshold is a helper method to define mock.On call
+func (_e *LargeStorage_Expecter) Threshold() *LargeStorage_Threshold_Call {
+	return &LargeStorage_Threshold_Call{Call: _e.mock.On("Threshold")}
+}
+
+func (_c *LargeStorage_Threshold_Call) Run(run func()) *LargeStorage_Threshold_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *LargeStorage_Threshold_Call) Return(_a0 int64) *LargeStorage_Threshold_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *LargeStorage_Threshold_Call) RunAndReturn(run func() int64) *LargeStorage_Threshold_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewLargeStorage creates a new instance of LargeStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLargeStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LargeStorage {
+	mock := &LargeStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -22,6 +22,53 @@ func (_m *Storage) EXPECT() *Storage_Expecter {
 	return &Storage_Expecter{mock: &_m.Mock}
 }
 
+// DeleteObject provides a mock function with given fields: ctx, id
+func (_m *Storage) DeleteObject(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteObject")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_DeleteObject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteObject'
+type Storage_DeleteObject_Call struct {
+	*mock.Call
+}
+
+// DeleteObject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Storage_Expecter) DeleteObject(ctx interface{}, id interface{}) *Storage_DeleteObject_Call {
+	return &Storage_DeleteObject_Call{Call: _e.mock.On("DeleteObject", ctx, id)}
+}
+
+func (_c *Storage_DeleteObject_Call) Run(run func(ctx context.Context, id int)) *Storage_DeleteObject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Storage_DeleteObject_Call) Return(_a0 error) *Storage_DeleteObject_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_DeleteObject_Call) RunAndReturn(run func(context.Context, int) error) *Storage_DeleteObject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetObject provides a mock function with given fields: ctx, id
 func (_m *Storage) GetObject(ctx context.Context, id int) (models.Item, error) {
 	ret := _m.Called(ctx, id)
@@ -136,6 +183,294 @@ func (_c *Storage_SaveObject_Call) RunAndReturn(run func(context.Context, models
 	return _c
 }
 
+// SetTTL provides a mock function with given fields: ctx, id, ttl
+func (_m *Storage) SetTTL(ctx context.Context, id int, ttl models.TTL) (models.Item, error) {
+	ret := _m.Called(ctx, id, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTTL")
+	}
+
+	var r0 models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.TTL) (models.Item, error)); ok {
+		return rf(ctx, id, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.TTL) models.Item); ok {
+		r0 = rf(ctx, id, ttl)
+	} else {
+		r0 = ret.Get(0).(models.Item)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, models.TTL) error); ok {
+		r1 = rf(ctx, id, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_SetTTL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetTTL'
+type Storage_SetTTL_Call struct {
+	*mock.Call
+}
+
+// SetTTL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - ttl models.TTL
+func (_e *Storage_Expecter) SetTTL(ctx interface{}, id interface{}, ttl interface{}) *Storage_SetTTL_Call {
+	return &Storage_SetTTL_Call{Call: _e.mock.On("SetTTL", ctx, id, ttl)}
+}
+
+func (_c *Storage_SetTTL_Call) Run(run func(ctx context.Context, id int, ttl models.TTL)) *Storage_SetTTL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(models.TTL))
+	})
+	return _c
+}
+
+func (_c *Storage_SetTTL_Call) Return(_a0 models.Item, _a1 error) *Storage_SetTTL_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_SetTTL_Call) RunAndReturn(run func(context.Context, int, models.TTL) (models.Item, error)) *Storage_SetTTL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StatObject provides a mock function with given fields: ctx, id
+func (_m *Storage) StatObject(ctx context.Context, id int) (models.Stat, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StatObject")
+	}
+
+	var r0 models.Stat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.Stat, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.Stat); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.Stat)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_StatObject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StatObject'
+type Storage_StatObject_Call struct {
+	*mock.Call
+}
+
+// StatObject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Storage_Expecter) StatObject(ctx interface{}, id interface{}) *Storage_StatObject_Call {
+	return &Storage_StatObject_Call{Call: _e.mock.On("StatObject", ctx, id)}
+}
+
+func (_c *Storage_StatObject_Call) Run(run func(ctx context.Context, id int)) *Storage_StatObject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Storage_StatObject_Call) Return(_a0 models.Stat, _a1 error) *Storage_StatObject_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_StatObject_Call) RunAndReturn(run func(context.Context, int) (models.Stat, error)) *Storage_StatObject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Touch provides a mock function with given fields: ctx, id
+func (_m *Storage) Touch(ctx context.Context, id int) (models.Item, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Touch")
+	}
+
+	var r0 models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.Item, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.Item); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.Item)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_Touch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Touch'
+type Storage_Touch_Call struct {
+	*mock.Call
+}
+
+// Touch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Storage_Expecter) Touch(ctx interface{}, id interface{}) *Storage_Touch_Call {
+	return &Storage_Touch_Call{Call: _e.mock.On("Touch", ctx, id)}
+}
+
+func (_c *Storage_Touch_Call) Run(run func(ctx context.Context, id int)) *Storage_Touch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Storage_Touch_Call) Return(_a0 models.Item, _a1 error) *Storage_Touch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_Touch_Call) RunAndReturn(run func(context.Context, int) (models.Item, error)) *Storage_Touch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateMetadata provides a mock function with given fields: ctx, id, patch
+func (_m *Storage) UpdateMetadata(ctx context.Context, id int, patch models.MetadataPatch) (models.Item, error) {
+	ret := _m.Called(ctx, id, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateMetadata")
+	}
+
+	var r0 models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.MetadataPatch) (models.Item, error)); ok {
+		return rf(ctx, id, patch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.MetadataPatch) models.Item); ok {
+		r0 = rf(ctx, id, patch)
+	} else {
+		r0 = ret.Get(0).(models.Item)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, models.MetadataPatch) error); ok {
+		r1 = rf(ctx, id, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_UpdateMetadata_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateMetadata'
+type Storage_UpdateMetadata_Call struct {
+	*mock.Call
+}
+
+// UpdateMetadata is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - patch models.MetadataPatch
+func (_e *Storage_Expecter) UpdateMetadata(ctx interface{}, id interface{}, patch interface{}) *Storage_UpdateMetadata_Call {
+	return &Storage_UpdateMetadata_Call{Call: _e.mock.On("UpdateMetadata", ctx, id, patch)}
+}
+
+func (_c *Storage_UpdateMetadata_Call) Run(run func(ctx context.Context, id int, patch models.MetadataPatch)) *Storage_UpdateMetadata_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(models.MetadataPatch))
+	})
+	return _c
+}
+
+func (_c *Storage_UpdateMetadata_Call) Return(_a0 models.Item, _a1 error) *Storage_UpdateMetadata_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_UpdateMetadata_Call) RunAndReturn(run func(context.Context, int, models.MetadataPatch) (models.Item, error)) *Storage_UpdateMetadata_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WaitObject provides a mock function with given fields: ctx, id, version
+func (_m *Storage) WaitObject(ctx context.Context, id int, version uint64) (models.Item, error) {
+	ret := _m.Called(ctx, id, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WaitObject")
+	}
+
+	var r0 models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, uint64) (models.Item, error)); ok {
+		return rf(ctx, id, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, uint64) models.Item); ok {
+		r0 = rf(ctx, id, version)
+	} else {
+		r0 = ret.Get(0).(models.Item)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, uint64) error); ok {
+		r1 = rf(ctx, id, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_WaitObject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WaitObject'
+type Storage_WaitObject_Call struct {
+	*mock.Call
+}
+
+// WaitObject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - version uint64
+func (_e *Storage_Expecter) WaitObject(ctx interface{}, id interface{}, version interface{}) *Storage_WaitObject_Call {
+	return &Storage_WaitObject_Call{Call: _e.mock.On("WaitObject", ctx, id, version)}
+}
+
+func (_c *Storage_WaitObject_Call) Run(run func(ctx context.Context, id int, version uint64)) *Storage_WaitObject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_WaitObject_Call) Return(_a0 models.Item, _a1 error) *Storage_WaitObject_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_WaitObject_Call) RunAndReturn(run func(context.Context, int, uint64) (models.Item, error)) *Storage_WaitObject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewStorage(t interface {
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/jsoncheck"
+	"st-test/internal/models"
+	"st-test/internal/storage"
+
+	"go.uber.org/zap"
+)
+
+// putObject проверенные заголовки запроса на запись объекта: объект без тела и признак проверки тела как json.
+type putObject struct {
+	item   models.Item
+	isJSON bool
+}
+
+// PutObject проверяет и сохраняет объект id с телом body так же, как запрос PUT /objects/{id} с заголовками header:
+// тип содержимого, метаданные, срок жизни и режим X-TTL-MODE, владелец из X-CLIENT-ID, размер тела и ограничения
+// на json. Крупный объект с тем же id заменяется. Возвращает true, если объект создан; ошибка проверки или записи —
+// handlererrors.HandlerError с ответом, который вернул бы запрос PUT.
+func (h *Handler) PutObject(ctx context.Context, id int, header http.Header, body []byte) (bool, error) {
+	req, err := h.parsePut(id, header)
+	if err != nil {
+		return false, err
+	}
+
+	return h.savePut(ctx, req, body)
+}
+
+// parsePut проверяет заголовки запроса на запись объекта id.
+func (h *Handler) parsePut(id int, header http.Header) (putObject, error) {
+	contentType, isJSON, err := h.types.Check(header.Get("Content-Type"))
+	if err != nil {
+		h.log.Info("failed check content type", zap.Error(err))
+
+		return putObject{}, contentTypeError(err)
+	}
+
+	meta, tags, err := metadataFromHeader(header)
+	if err != nil {
+		h.log.Info("failed check metadata", zap.Error(err))
+
+		return putObject{}, httpErr.NewInvalidInput("failed check metadata", err.Error())
+	}
+
+	ttl, keepTTL, err := ttlFromHeader(header, time.Now())
+	if err != nil {
+		h.log.Info("failed parse ttl", zap.Error(err))
+
+		return putObject{}, httpErr.NewInvalidInput("failed parse ttl", err.Error())
+	}
+
+	return putObject{
+		item: models.Item{
+			ID:          id,
+			Expires:     ttl.Duration,
+			ExpiresAt:   ttl.At,
+			Sliding:     ttl.Sliding,
+			KeepTTL:     keepTTL,
+			Owner:       header.Get(clientHeader),
+			ContentType: contentType,
+			Metadata:    meta,
+			Tags:        tags,
+		},
+		isJSON: isJSON,
+	}, nil
+}
+
+// savePut проверяет тело body и сохраняет объект запроса req. Возвращает true, если объект создан.
+func (h *Handler) savePut(ctx context.Context, req putObject, body []byte) (bool, error) {
+	if int64(len(body)) > h.limits.MaxBodySize {
+		return false, tooLargeError(h.limits.MaxBodySize)
+	}
+
+	// проверяем на валидность json и ограничения на его структуру
+	if req.isJSON {
+		err := jsoncheck.Check(bytes.NewReader(body), h.limits.MaxJSONDepth, h.limits.MaxJSONKeys)
+		if err != nil {
+			if errors.Is(err, jsoncheck.ErrInvalid) {
+				h.log.Error("failed check body on json", zap.Error(err))
+
+				return false, httpErr.NewInvalidInput("failed check body on json", err.Error())
+			}
+
+			h.log.Info("body exceeds json limits", zap.Error(err))
+
+			return false, httpErr.NewUnprocessableError("failed check body on json", err.Error())
+		}
+	}
+
+	// сохраняем объект в хранилище
+	item := req.item
+	item.Body = body
+
+	resObjectID, err := h.store.SaveObject(ctx, item)
+	if err != nil {
+		if errors.Is(err, storage.ErrRejected) {
+			h.log.Info("save object rejected", zap.Error(err))
+
+			return false, httpErr.NewUnprocessableError("failed save object", err.Error())
+		}
+
+		if errors.Is(err, storage.ErrQuotaExceeded) {
+			h.log.Info("save object exceeds quota", zap.Error(err))
+
+			return false, httpErr.NewQuotaExceededError("failed save object", err.Error())
+		}
+
+		h.log.Error("failed save object", zap.Error(err))
+
+		return false, httpErr.NewInternalError("failed save object", err.Error())
+	}
+
+	// крупный объект с тем же id заменяется обычным.
+	replaced := false
+
+	if h.large != nil {
+		err := h.large.Delete(ctx, item.ID)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			h.log.Error("failed delete large object replaced by object", zap.Error(err))
+		}
+
+		replaced = err == nil
+	}
+
+	// если из хранилища вернулся id = 0, значит объект уже был в хранилище и только обновили информацию
+	return resObjectID != 0 && !replaced, nil
+}
+
+// tooLargeError возвращает ответ на тело больше limit байт.
+func tooLargeError(limit int64) httpErr.HandlerError {
+	return httpErr.NewTooLargeError("failed read body", "body must not exceed "+strconv.FormatInt(limit, 10)+" bytes")
+}
+
+// respondError отвечает ошибкой err проверки или записи объекта.
+func respondError(w http.ResponseWriter, err error) {
+	var he httpErr.HandlerError
+	if !errors.As(err, &he) {
+		he = httpErr.NewInternalError("failed save object", err.Error())
+	}
+
+	responder.JSON(w, he)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/mediatype"
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// Stat сведения об объекте без его тела. Время отдаётся в формате RFC 3339 и не отдаётся у объектов,
+// записанных до его появления; TTLRemaining — оставшееся время жизни в секундах, если срок жизни задан.
+type Stat struct {
+	ID           int               `json:"id"`
+	Size         int64             `json:"size"`
+	Version      uint64            `json:"version,omitempty"`
+	ContentType  string            `json:"content_type"`
+	Owner        string            `json:"owner,omitempty"`
+	Checksum     string            `json:"checksum,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	CreatedAt    *time.Time        `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time        `json:"updated_at,omitempty"`
+	ExpiresAt    *time.Time        `json:"expires_at,omitempty"`
+	TTLRemaining *int64            `json:"ttl_remaining,omitempty"`
+	Sliding      bool              `json:"sliding,omitempty"`
+	Large        bool              `json:"large"`
+}
+
+// newStat возвращает сведения об объекте stat к моменту now.
+func newStat(stat models.Stat, now time.Time) Stat {
+	res := Stat{
+		ID:           stat.ID,
+		Size:         stat.Size,
+		Version:      stat.Version,
+		ContentType:  stat.ContentType,
+		Owner:        stat.Owner,
+		Checksum:     stat.Checksum,
+		Metadata:     stat.Metadata,
+		Tags:         stat.Tags,
+		CreatedAt:    timeOrNil(stat.CreatedAt),
+		UpdatedAt:    timeOrNil(stat.UpdatedAt),
+		ExpiresAt:    timeOrNil(stat.ExpiresAt),
+		TTLRemaining: ttlRemaining(stat, now),
+		Sliding:      stat.Sliding,
+		Large:        stat.Large,
+	}
+
+	if res.ContentType == "" {
+		res.ContentType = mediatype.JSON
+	}
+
+	return res
+}
+
+// ToJSON возвращает сведения как json.
+func (s Stat) ToJSON() ([]byte, error) {
+	return json.Marshal(s) //nolint:wrapcheck
+}
+
+// Stat метод обработки GET запросов на получение сведений об объекте без его тела: размера, версии, типа содержимого,
+// метаданных, времени создания и изменения и оставшегося времени жизни. Крупные объекты тоже поддерживаются.
+func (h *Handler) Stat(w http.ResponseWriter, r *http.Request) {
+	objectID, ok := h.objectID(w, r)
+	if !ok {
+		return
+	}
+
+	stat, err := h.store.StatObject(r.Context(), objectID)
+	if errors.Is(err, models.ErrNotFound) && h.large != nil {
+		var obj models.LargeObject
+
+		// тело крупного объекта читается лениво, поэтому неиспользованное чтение ничего не держит.
+		obj, _, err = h.large.Open(r.Context(), objectID)
+		stat = obj.Stat()
+	}
+
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			responder.JSON(w, httpErr.NewNotFoundError("failed get object stat"))
+
+			return
+		}
+
+		h.log.Error("failed get object stat", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed get object stat", err.Error()))
+
+		return
+	}
+
+	responder.JSON(w, newStat(stat, time.Now()))
+}
+
+// timeOrNil возвращает время t в UTC или nil, если оно нулевое.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	t = t.UTC()
+
+	return &t
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// slidingHeader заголовок, включающий скользящий срок жизни: срок из X-EXPIRES отсчитывается от последнего чтения.
+	slidingHeader = "X-EXPIRES-SLIDING"
+	// ttlModeHeader заголовок с выбором, что делать со сроком жизни перезаписываемого объекта: keep или reset.
+	ttlModeHeader = "X-TTL-MODE"
+)
+
+// Режимы срока жизни при перезаписи объекта.
+const (
+	ttlModeKeep  = "keep"
+	ttlModeReset = "reset"
+)
+
+// TTLRequest тело запроса на изменение срока жизни объекта. Задаётся одно из Duration (в формате
+// time.ParseDuration) и ExpiresAt; Sliding включает скользящий срок жизни и требует Duration.
+type TTLRequest struct {
+	Duration  string     `json:"duration"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Sliding   bool       `json:"sliding"`
+}
+
+// TTL срок жизни объекта. У бессрочного объекта заполнен только Sliding; TTLRemaining — оставшееся время жизни
+// в секундах.
+type TTL struct {
+	Duration     string     `json:"duration,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	TTLRemaining *int64     `json:"ttl_remaining,omitempty"`
+	Sliding      bool       `json:"sliding"`
+}
+
+// newTTL возвращает срок жизни объекта stat к моменту now.
+func newTTL(stat models.Stat, now time.Time) TTL {
+	res := TTL{
+		ExpiresAt:    timeOrNil(stat.ExpiresAt),
+		TTLRemaining: ttlRemaining(stat, now),
+		Sliding:      stat.Sliding,
+	}
+
+	if stat.Expires > 0 {
+		res.Duration = stat.Expires.String()
+	}
+
+	return res
+}
+
+// ToJSON возвращает срок жизни как json.
+func (t TTL) ToJSON() ([]byte, error) {
+	return json.Marshal(t) //nolint:wrapcheck
+}
+
+// TTL метод обработки GET запросов на получение срока жизни объекта. Скользящий срок жизни при этом не продлевается.
+func (h *Handler) TTL(w http.ResponseWriter, r *http.Request) {
+	objectID, ok := h.objectID(w, r)
+	if !ok {
+		return
+	}
+
+	stat, err := h.store.StatObject(r.Context(), objectID)
+	if err != nil {
+		h.ttlError(w, "failed get ttl", err)
+
+		return
+	}
+
+	responder.JSON(w, newTTL(stat, time.Now()))
+}
+
+// SetTTL метод обработки PUT запросов на изменение срока жизни объекта. Изменение получает новую версию объекта,
+// которая возвращается в заголовке X-Version.
+func (h *Handler) SetTTL(w http.ResponseWriter, r *http.Request) {
+	objectID, ok := h.objectID(w, r)
+	if !ok {
+		return
+	}
+
+	var req TTLRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("failed decode request", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed decode request", err.Error()))
+
+		return
+	}
+
+	ttl := models.TTL{Sliding: req.Sliding}
+
+	if req.ExpiresAt != nil {
+		ttl.At = *req.ExpiresAt
+	}
+
+	if req.Duration != "" {
+		var err error
+
+		ttl.Duration, err = parseDuration(req.Duration)
+		if err != nil {
+			responder.JSON(w, httpErr.NewInvalidInput("failed set ttl", err.Error()))
+
+			return
+		}
+	}
+
+	if ttl.IsZero() {
+		responder.JSON(w, httpErr.NewInvalidInput("failed set ttl", "duration or expires_at is required"))
+
+		return
+	}
+
+	h.writeTTL(w, r, objectID, ttl)
+}
+
+// DeleteTTL метод обработки DELETE запросов, делающих объект бессрочным.
+func (h *Handler) DeleteTTL(w http.ResponseWriter, r *http.Request) {
+	objectID, ok := h.objectID(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeTTL(w, r, objectID, models.TTL{})
+}
+
+// Touch метод обработки POST запросов, заново отсчитывающих относительный срок жизни объекта от текущего момента.
+// Версия объекта не меняется; у объекта без срока жизни или с абсолютным сроком жизни ничего не меняется.
+func (h *Handler) Touch(w http.ResponseWriter, r *http.Request) {
+	objectID, ok := h.objectID(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.store.Touch(r.Context(), objectID)
+	if err != nil {
+		h.ttlError(w, "failed touch object", err)
+
+		return
+	}
+
+	responder.JSON(w, newTTL(item.Stat(), time.Now()))
+}
+
+// writeTTL задаёт объекту objectID срок жизни ttl и отдаёт его.
+func (h *Handler) writeTTL(w http.ResponseWriter, r *http.Request, objectID int, ttl models.TTL) {
+	item, err := h.store.SetTTL(r.Context(), objectID, ttl)
+	if err != nil {
+		h.ttlError(w, "failed set ttl", err)
+
+		return
+	}
+
+	w.Header().Set(versionHeader, strconv.FormatUint(item.Version, 10))
+
+	if ttl.IsZero() {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	responder.JSON(w, newTTL(item.Stat(), time.Now()))
+}
+
+// ttlError отдаёт ошибку хранилища при работе со сроком жизни объекта.
+func (h *Handler) ttlError(w http.ResponseWriter, title string, err error) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		responder.JSON(w, httpErr.NewNotFoundError(title))
+	case errors.Is(err, models.ErrInvalidTTL):
+		responder.JSON(w, httpErr.NewInvalidInput(title, err.Error()))
+	default:
+		h.log.Error(title, zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError(title, err.Error()))
+	}
+}
+
+// objectID возвращает id объекта из пути запроса. Если id некорректен, отдаёт ошибку и возвращает false.
+func (h *Handler) objectID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	objectID, err := strconv.Atoi(chi.URLParam(r, "objectID"))
+	if err != nil {
+		h.log.Error("failed get object id", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed get object id", err.Error()))
+
+		return 0, false
+	}
+
+	return objectID, true
+}
+
+// ttlFromHeader возвращает срок жизни записываемого объекта из заголовков X-EXPIRES (срок в формате
+// time.ParseDuration), X-EXPIRES-AT (момент в формате RFC 3339) и X-EXPIRES-SLIDING, а также признак того,
+// что перезаписываемый объект сохраняет прежний срок жизни (X-TTL-MODE: keep). Ошибка оборачивает models.ErrInvalidTTL.
+func ttlFromHeader(header http.Header, now time.Time) (models.TTL, bool, error) {
+	var (
+		ttl models.TTL
+		err error
+	)
+
+	if v := header.Get(expiresHeader); v != "" {
+		if ttl.Duration, err = parseDuration(v); err != nil {
+			return models.TTL{}, false, err
+		}
+	}
+
+	if v := header.Get(expiresAtHeader); v != "" {
+		if ttl.At, err = time.Parse(time.RFC3339, v); err != nil {
+			return models.TTL{}, false, fmt.Errorf("%w: %s: %w", models.ErrInvalidTTL, expiresAtHeader, err)
+		}
+	}
+
+	if v := header.Get(slidingHeader); v != "" {
+		if ttl.Sliding, err = strconv.ParseBool(v); err != nil {
+			return models.TTL{}, false, fmt.Errorf("%w: %s: %w", models.ErrInvalidTTL, slidingHeader, err)
+		}
+	}
+
+	var keep bool
+
+	switch mode := header.Get(ttlModeHeader); mode {
+	case "", ttlModeReset:
+	case ttlModeKeep:
+		keep = true
+	default:
+		return models.TTL{}, false, fmt.Errorf("%w: %s must be %q or %q", models.ErrInvalidTTL, ttlModeHeader, ttlModeKeep, ttlModeReset)
+	}
+
+	if err := ttl.Validate(now); err != nil {
+		return models.TTL{}, false, err //nolint:wrapcheck
+	}
+
+	return ttl, keep, nil
+}
+
+// parseDuration разбирает срок жизни в формате time.ParseDuration; срок должен быть положительным.
+func parseDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", models.ErrInvalidTTL, err)
+	}
+
+	if d <= 0 {
+		return 0, fmt.Errorf("%w: duration must be positive", models.ErrInvalidTTL)
+	}
+
+	return d, nil
+}
+
+// ttlRemaining возвращает оставшееся к моменту now время жизни объекта stat в секундах, округлённое вверх,
+// или nil, если срок жизни не задан.
+func ttlRemaining(stat models.Stat, now time.Time) *int64 {
+	ttl, ok := stat.TTL(now)
+	if !ok {
+		return nil
+	}
+
+	seconds := int64(math.Ceil(ttl.Seconds()))
+
+	return &seconds
+}
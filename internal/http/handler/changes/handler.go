@@ -0,0 +1,261 @@
+// Package changes описывает обработчик ленты изменений объектов в формате Server-Sent Events.
+package changes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/models"
+	"st-test/internal/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// lastEventIDHeader заголовок, с помощью которого клиент SSE возобновляет ленту после переподключения.
+	lastEventIDHeader = "Last-Event-ID"
+	// batchSize количество изменений, забираемых из хранилища за один раз.
+	batchSize = 100
+	// heartbeatInterval период отправки комментария, не дающего прокси закрыть простаивающее соединение.
+	heartbeatInterval = 15 * time.Second
+)
+
+// Storage описывает методы хранилища для чтения журнала изменений.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Storage --with-expecter=true
+type Storage interface {
+	Changes(after uint64, limit int) ([]models.Change, <-chan struct{}, error)
+	LastChange() uint64
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log       *zap.Logger
+	store     Storage
+	heartbeat time.Duration
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, store Storage) *Handler {
+	return &Handler{
+		log:       log.Named("changes handler"),
+		store:     store,
+		heartbeat: heartbeatInterval,
+	}
+}
+
+// Event изменение объекта, отправляемое клиенту. Large выставляется для крупных объектов, тело которых не передаётся.
+type Event struct {
+	Seq   uint64          `json:"seq"`
+	Type  string          `json:"type"`
+	ID    int             `json:"id"`
+	Time  time.Time       `json:"time"`
+	Body  json.RawMessage `json:"body,omitempty"`
+	Large bool            `json:"large,omitempty"`
+}
+
+// filter отбирает изменения, которые нужно отправить клиенту.
+type filter struct {
+	fromID, toID *int
+	types        map[models.ChangeType]struct{}
+}
+
+func (f filter) match(c models.Change) bool {
+	if f.fromID != nil && c.ID < *f.fromID {
+		return false
+	}
+
+	if f.toID != nil && c.ID > *f.toID {
+		return false
+	}
+
+	if len(f.types) > 0 {
+		if _, ok := f.types[c.Type]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Stream метод обработки GET запросов на подписку на изменения объектов.
+// Изменения отправляются как события SSE с номером изменения в поле id. Параметры запроса:
+// from_id и to_id — диапазон id объектов, types — типы изменений через запятую,
+// since — номер изменения, после которого начать ленту (заголовок Last-Event-ID имеет приоритет).
+// Без since и Last-Event-ID отправляются только новые изменения.
+func (h *Handler) Stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		responder.JSON(w, httpErr.NewInternalError("failed stream changes", "streaming is not supported"))
+
+		return
+	}
+
+	f, err := parseFilter(r)
+	if err != nil {
+		responder.JSON(w, httpErr.NewInvalidInput("failed parse filter", err.Error()))
+
+		return
+	}
+
+	last, err := h.startSeq(r)
+	if err != nil {
+		responder.JSON(w, httpErr.NewInvalidInput("failed parse last event id", err.Error()))
+
+		return
+	}
+
+	// проверяем, что изменения для возобновления ещё хранятся, до того как начать поток.
+	if _, _, err := h.store.Changes(last, 1); err != nil {
+		if errors.Is(err, storage.ErrChangesExpired) {
+			responder.JSON(w, httpErr.NewGoneError("failed resume changes", err.Error()))
+
+			return
+		}
+
+		responder.JSON(w, httpErr.NewInternalError("failed stream changes", err.Error()))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	h.log.Info("changes stream started", zap.Uint64("after", last))
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		changes, wait, err := h.store.Changes(last, batchSize)
+		if err != nil {
+			// клиент отстал настолько, что нужные изменения вытеснены из журнала.
+			h.log.Info("changes stream reset", zap.Uint64("after", last), zap.Error(err))
+
+			_, _ = fmt.Fprintf(w, "event: reset\ndata: %q\n\n", err.Error())
+			flusher.Flush()
+
+			return
+		}
+
+		for _, c := range changes {
+			last = c.Seq
+
+			if !f.match(c) {
+				continue
+			}
+
+			if err := writeEvent(w, c); err != nil {
+				h.log.Info("changes stream closed", zap.Error(err))
+
+				return
+			}
+		}
+
+		if len(changes) > 0 {
+			flusher.Flush()
+
+			continue
+		}
+
+		select {
+		case <-r.Context().Done():
+			h.log.Info("changes stream closed by client")
+
+			return
+		case <-wait:
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+
+			flusher.Flush()
+		}
+	}
+}
+
+// startSeq возвращает номер изменения, после которого начинается лента.
+func (h *Handler) startSeq(r *http.Request) (uint64, error) {
+	value := r.Header.Get(lastEventIDHeader)
+	if value == "" {
+		value = r.URL.Query().Get("since")
+	}
+
+	if value == "" {
+		return h.store.LastChange(), nil
+	}
+
+	return strconv.ParseUint(value, 10, 64) //nolint:wrapcheck
+}
+
+func parseFilter(r *http.Request) (filter, error) {
+	var f filter
+
+	values := r.URL.Query()
+
+	for name, dst := range map[string]**int{"from_id": &f.fromID, "to_id": &f.toID} {
+		if v := values.Get(name); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				return f, fmt.Errorf("%s: %w", name, err)
+			}
+
+			*dst = &id
+		}
+	}
+
+	if v := values.Get("types"); v != "" {
+		f.types = make(map[models.ChangeType]struct{})
+
+		for _, t := range strings.Split(v, ",") {
+			ct := models.ChangeType(strings.TrimSpace(t))
+
+			switch ct {
+			case models.ChangeCreated, models.ChangeUpdated, models.ChangeDeleted, models.ChangeExpired, models.ChangeTouched:
+				f.types[ct] = struct{}{}
+			default:
+				return f, fmt.Errorf("unknown change type %q", t)
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// writeEvent записывает изменение как событие SSE.
+func writeEvent(w http.ResponseWriter, c models.Change) error {
+	e := Event{
+		Seq:   c.Seq,
+		Type:  string(c.Type),
+		ID:    c.ID,
+		Time:  c.Time,
+		Large: c.Large,
+	}
+
+	if json.Valid(c.Body) {
+		e.Body = c.Body
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", c.Seq, c.Type, data)
+	if err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+
+	return nil
+}
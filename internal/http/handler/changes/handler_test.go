@@ -0,0 +1,195 @@
+package changes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"st-test/internal/http/handler/changes/mocks"
+	"st-test/internal/models"
+	"st-test/internal/storage"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewHandler(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	store := mocks.NewStorage(t)
+	require.NotNil(t, store)
+
+	h := NewHandler(log, store)
+	require.NotNil(t, h)
+}
+
+func TestHandler_Stream(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var never <-chan struct{} = make(chan struct{})
+
+	cases := []struct {
+		name         string
+		giveURL      string
+		giveHeader   http.Header
+		prepareStore func(store *mocks.Storage)
+		checkResult  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "invalid filter",
+			giveURL: "/changes?from_id=abc",
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed parse filter")
+			},
+		},
+		{
+			name:    "unknown change type",
+			giveURL: "/changes?types=created,renamed",
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "unknown change type")
+			},
+		},
+		{
+			name:       "invalid last event id",
+			giveURL:    "/changes",
+			giveHeader: http.Header{lastEventIDHeader: []string{"abc"}},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed parse last event id")
+			},
+		},
+		{
+			name:       "expired changes",
+			giveURL:    "/changes",
+			giveHeader: http.Header{lastEventIDHeader: []string{"1"}},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Changes(uint64(1), 1).Once().Return(nil, nil, storage.ErrChangesExpired)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusGone, rr.Code)
+			},
+		},
+		{
+			name:    "store error",
+			giveURL: "/changes?since=1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Changes(uint64(1), 1).Once().Return(nil, nil, errors.New("some error"))
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			},
+		},
+		{
+			name:    "stream with filter",
+			giveURL: "/changes?from_id=2&to_id=3&types=created,deleted",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().LastChange().Once().Return(uint64(10))
+				store.EXPECT().Changes(uint64(10), 1).Once().Return(nil, never, nil)
+				store.EXPECT().Changes(uint64(10), batchSize).Once().Return([]models.Change{
+					{Seq: 11, Type: models.ChangeCreated, ID: 1, Time: ts, Body: []byte(`{}`)},
+					{Seq: 12, Type: models.ChangeCreated, ID: 2, Time: ts, Body: []byte(`{"a":1}`)},
+					{Seq: 13, Type: models.ChangeUpdated, ID: 2, Time: ts, Body: []byte(`{"a":2}`)},
+					{Seq: 14, Type: models.ChangeDeleted, ID: 3, Time: ts},
+				}, never, nil)
+				store.EXPECT().Changes(uint64(14), batchSize).Return(nil, never, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+				assert.Equal(t, "id: 12\nevent: created\n"+
+					`data: {"seq":12,"type":"created","id":2,"time":"2024-01-02T03:04:05Z","body":{"a":1}}`+"\n\n"+
+					"id: 14\nevent: deleted\n"+
+					`data: {"seq":14,"type":"deleted","id":3,"time":"2024-01-02T03:04:05Z"}`+"\n\n",
+					rr.Body.String())
+			},
+		},
+		{
+			name:       "resume and reset",
+			giveURL:    "/changes",
+			giveHeader: http.Header{lastEventIDHeader: []string{"5"}},
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Changes(uint64(5), 1).Once().Return(nil, never, nil)
+				store.EXPECT().Changes(uint64(5), batchSize).Once().Return([]models.Change{
+					{Seq: 6, Type: models.ChangeExpired, ID: 1, Time: ts},
+				}, never, nil)
+				store.EXPECT().Changes(uint64(6), batchSize).Once().Return(nil, nil, storage.ErrChangesExpired)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.Contains(t, rr.Body.String(), "id: 6\nevent: expired\n")
+				assert.Contains(t, rr.Body.String(), "event: reset\n")
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			require.NotNil(t, store)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			h := &Handler{
+				log:       log,
+				store:     store,
+				heartbeat: time.Hour,
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, tc.giveURL, http.NoBody)
+			for k, v := range tc.giveHeader {
+				req.Header.Set(k, v[0])
+			}
+
+			rr := httptest.NewRecorder()
+
+			h.Stream(rr, req)
+			tc.checkResult(t, rr)
+		})
+	}
+}
+
+func TestHandler_StreamHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	store := mocks.NewStorage(t)
+	store.EXPECT().LastChange().Once().Return(uint64(0))
+	store.EXPECT().Changes(uint64(0), mock.Anything).Return(nil, make(<-chan struct{}), nil)
+
+	h := &Handler{log: log, store: store, heartbeat: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/changes", http.NoBody)
+	rr := httptest.NewRecorder()
+
+	h.Stream(rr, req)
+
+	assert.Contains(t, rr.Body.String(), ": ping\n\n")
+}
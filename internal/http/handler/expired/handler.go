@@ -0,0 +1,162 @@
+// Package expired описывает обработчик области объектов с истёкшим сроком жизни.
+package expired
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/models"
+	"st-test/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultLimit количество объектов в ответе по умолчанию.
+	defaultLimit = 100
+	// maxLimit максимальное количество объектов в ответе.
+	maxLimit = 1000
+)
+
+// Storage описывает методы хранилища для просмотра объектов с истёкшим сроком жизни.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Storage --with-expecter=true
+type Storage interface {
+	ExpiredObjects(ctx context.Context, limit int) ([]models.ExpiredItem, error)
+	ExpiredObject(ctx context.Context, id int) (models.ExpiredItem, error)
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log   *zap.Logger
+	store Storage
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, store Storage) *Handler {
+	return &Handler{
+		log:   log.Named("expired handler"),
+		store: store,
+	}
+}
+
+// Object объект с истёкшим сроком жизни. Тело, которое не является json, в ответ не включается.
+type Object struct {
+	ID          int             `json:"id"`
+	Version     uint64          `json:"version"`
+	Owner       string          `json:"owner,omitempty"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	ExpiredAt   time.Time       `json:"expired_at"`
+	PurgeAt     time.Time       `json:"purge_at"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+}
+
+// ToJSON возвращает результат как json.
+func (o Object) ToJSON() ([]byte, error) {
+	return json.Marshal(o) //nolint:wrapcheck
+}
+
+// Response список объектов с истёкшим сроком жизни.
+type Response struct {
+	Objects []Object `json:"objects"`
+}
+
+// ToJSON возвращает результат как json.
+func (r Response) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+// List метод обработки GET запросов на просмотр объектов с истёкшим сроком жизни.
+// Параметр limit — количество последних истёкших объектов.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLimit
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			responder.JSON(w, httpErr.NewInvalidInput("failed parse limit", v))
+
+			return
+		}
+	}
+
+	items, err := h.store.ExpiredObjects(r.Context(), min(limit, maxLimit))
+	if err != nil {
+		if errors.Is(err, storage.ErrExpiredDisabled) {
+			responder.JSON(w, httpErr.NewNotFoundError("expired area is disabled"))
+
+			return
+		}
+
+		h.log.Error("failed get expired objects", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed get expired objects", err.Error()))
+
+		return
+	}
+
+	resp := Response{Objects: make([]Object, 0, len(items))}
+	for _, item := range items {
+		resp.Objects = append(resp.Objects, newObject(item))
+	}
+
+	responder.JSON(w, resp)
+}
+
+// Object метод обработки GET запросов на получение объекта с истёкшим сроком жизни.
+func (h *Handler) Object(w http.ResponseWriter, r *http.Request) {
+	objectID, err := strconv.Atoi(chi.URLParam(r, "objectID"))
+	if err != nil {
+		h.log.Error("failed get object id", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed get object id", err.Error()))
+
+		return
+	}
+
+	item, err := h.store.ExpiredObject(r.Context(), objectID)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrExpiredDisabled):
+			responder.JSON(w, httpErr.NewNotFoundError("expired area is disabled"))
+		case errors.Is(err, models.ErrNotFound):
+			responder.JSON(w, httpErr.NewNotFoundError("failed get expired object"))
+		default:
+			h.log.Error("failed get expired object", zap.Error(err))
+
+			responder.JSON(w, httpErr.NewInternalError("failed get expired object", err.Error()))
+		}
+
+		return
+	}
+
+	responder.JSON(w, newObject(item))
+}
+
+func newObject(item models.ExpiredItem) Object {
+	obj := Object{
+		ID:          item.ID,
+		Version:     item.Version,
+		Owner:       item.Owner,
+		ExpiresAt:   item.ExpiresAt,
+		ExpiredAt:   item.ExpiredAt,
+		PurgeAt:     item.PurgeAt,
+		ContentType: item.ContentType,
+	}
+
+	if json.Valid(item.Body) {
+		obj.Body = item.Body
+	}
+
+	return obj
+}
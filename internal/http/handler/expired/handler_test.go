@@ -0,0 +1,154 @@
+package expired
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"st-test/internal/http/handler/expired/mocks"
+	"st-test/internal/models"
+	"st-test/internal/storage"
+)
+
+func TestHandler_List(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name         string
+		giveQuery    string
+		prepareStore func(store *mocks.Storage)
+		wantCode     int
+		wantBody     string
+	}{
+		{name: "invalid limit", giveQuery: "?limit=x", wantCode: http.StatusBadRequest},
+		{
+			name: "disabled",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().ExpiredObjects(mock.Anything, defaultLimit).Once().Return(nil, storage.ErrExpiredDisabled)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: "expired area is disabled",
+		},
+		{
+			name: "store error",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().ExpiredObjects(mock.Anything, defaultLimit).Once().Return(nil, errors.New("some error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:      "success",
+			giveQuery: "?limit=5000",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().ExpiredObjects(mock.Anything, maxLimit).Once().Return([]models.ExpiredItem{{
+					Item:      models.Item{ID: 1, Body: []byte(`{"some":"body"}`), Version: 2, ExpiresAt: time.Unix(0, 0).UTC()},
+					ExpiredAt: time.Unix(1, 0).UTC(),
+					PurgeAt:   time.Unix(60, 0).UTC(),
+				}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"objects":[{"id":1,"version":2,"expires_at":"1970-01-01T00:00:00Z","expired_at":"1970-01-01T00:00:01Z",` +
+				`"purge_at":"1970-01-01T00:01:00Z","body":{"some":"body"}}]}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			rr := httptest.NewRecorder()
+			NewHandler(zap.NewNop(), store).List(rr, httptest.NewRequest(http.MethodGet, "/admin/expired"+tc.giveQuery, nil))
+
+			assert.Equal(t, tc.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func TestHandler_Object(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name         string
+		giveID       string
+		prepareStore func(store *mocks.Storage)
+		wantCode     int
+		wantBody     string
+	}{
+		{name: "invalid id", giveID: "x", wantCode: http.StatusBadRequest},
+		{
+			name:   "disabled",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().ExpiredObject(mock.Anything, 1).Once().Return(models.ExpiredItem{}, storage.ErrExpiredDisabled)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: "expired area is disabled",
+		},
+		{
+			name:   "not found",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().ExpiredObject(mock.Anything, 1).Once().Return(models.ExpiredItem{}, models.ErrNotFound)
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:   "store error",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().ExpiredObject(mock.Anything, 1).Once().Return(models.ExpiredItem{}, errors.New("some error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "binary body is omitted",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().ExpiredObject(mock.Anything, 1).Once().Return(models.ExpiredItem{
+					Item:      models.Item{ID: 1, Body: []byte{0x89, 'P', 'N', 'G'}, Version: 3, ContentType: "image/png"},
+					ExpiredAt: time.Unix(1, 0).UTC(),
+					PurgeAt:   time.Unix(60, 0).UTC(),
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"purge_at":"1970-01-01T00:01:00Z","content_type":"image/png"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("objectID", tc.giveID)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/expired/"+tc.giveID, nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			NewHandler(zap.NewNop(), store).Object(rr, req)
+
+			assert.Equal(t, tc.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "st-test/internal/models"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+type Storage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Storage) EXPECT() *Storage_Expecter {
+	return &Storage_Expecter{mock: &_m.Mock}
+}
+
+// ExpiredObject provides a mock function with given fields: ctx, id
+func (_m *Storage) ExpiredObject(ctx context.Context, id int) (models.ExpiredItem, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpiredObject")
+	}
+
+	var r0 models.ExpiredItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.ExpiredItem, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.ExpiredItem); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.ExpiredItem)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ExpiredObject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExpiredObject'
+type Storage_ExpiredObject_Call struct {
+	*mock.Call
+}
+
+// ExpiredObject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Storage_Expecter) ExpiredObject(ctx interface{}, id interface{}) *Storage_ExpiredObject_Call {
+	return &Storage_ExpiredObject_Call{Call: _e.mock.On("ExpiredObject", ctx, id)}
+}
+
+func (_c *Storage_ExpiredObject_Call) Run(run func(ctx context.Context, id int)) *Storage_ExpiredObject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Storage_ExpiredObject_Call) Return(_a0 models.ExpiredItem, _a1 error) *Storage_ExpiredObject_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ExpiredObject_Call) RunAndReturn(run func(context.Context, int) (models.ExpiredItem, error)) *Storage_ExpiredObject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExpiredObjects provides a mock function with given fields: ctx, limit
+func (_m *Storage) ExpiredObjects(ctx context.Context, limit int) ([]models.ExpiredItem, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpiredObjects")
+	}
+
+	var r0 []models.ExpiredItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]models.ExpiredItem, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []models.ExpiredItem); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.ExpiredItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ExpiredObjects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExpiredObjects'
+type Storage_ExpiredObjects_Call struct {
+	*mock.Call
+}
+
+// ExpiredObjects is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+func (_e *Storage_Expecter) ExpiredObjects(ctx interface{}, limit interface{}) *Storage_ExpiredObjects_Call {
+	return &Storage_ExpiredObjects_Call{Call: _e.mock.On("ExpiredObjects", ctx, limit)}
+}
+
+func (_c *Storage_ExpiredObjects_Call) Run(run func(ctx context.Context, limit int)) *Storage_ExpiredObjects_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Storage_ExpiredObjects_Call) Return(_a0 []models.ExpiredItem, _a1 error) *Storage_ExpiredObjects_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ExpiredObjects_Call) RunAndReturn(run func(context.Context, int) ([]models.ExpiredItem, error)) *Storage_ExpiredObjects_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -12,9 +12,18 @@ type HandlerErrorCode string
 func (e HandlerErrorCode) String() string { return string(e) }
 
 const (
-	ErrAppCode      HandlerErrorCode = "ERR_APP_CODE"
-	ErrInvalidInput HandlerErrorCode = "ERR_INVALID_INPUT"
-	ErrNotFound     HandlerErrorCode = "NOT_FOUND"
+	ErrAppCode       HandlerErrorCode = "ERR_APP_CODE"
+	ErrInvalidInput  HandlerErrorCode = "ERR_INVALID_INPUT"
+	ErrNotFound      HandlerErrorCode = "NOT_FOUND"
+	ErrGone          HandlerErrorCode = "GONE"
+	ErrUnprocessable HandlerErrorCode = "UNPROCESSABLE_ENTITY"
+	ErrConflict      HandlerErrorCode = "CONFLICT"
+	ErrTooLarge      HandlerErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrQuota         HandlerErrorCode = "QUOTA_EXCEEDED"
+	ErrMediaType     HandlerErrorCode = "UNSUPPORTED_MEDIA_TYPE"
+	ErrReadOnly      HandlerErrorCode = "READ_ONLY"
+	ErrBadGateway    HandlerErrorCode = "BAD_GATEWAY"
+	ErrNoLeader      HandlerErrorCode = "NO_LEADER"
 )
 
 type HandlerError struct {
@@ -42,6 +51,87 @@ func NewInvalidInput(title, detail string) HandlerError {
 	}
 }
 
+func NewGoneError(title, detail string) HandlerError {
+	return HandlerError{
+		Code:           string(ErrGone),
+		Title:          title,
+		Detail:         detail,
+		httpStatusCode: http.StatusGone,
+	}
+}
+
+func NewUnprocessableError(title, detail string) HandlerError {
+	return HandlerError{
+		Code:           string(ErrUnprocessable),
+		Title:          title,
+		Detail:         detail,
+		httpStatusCode: http.StatusUnprocessableEntity,
+	}
+}
+
+func NewConflictError(title, detail string) HandlerError {
+	return HandlerError{
+		Code:           string(ErrConflict),
+		Title:          title,
+		Detail:         detail,
+		httpStatusCode: http.StatusConflict,
+	}
+}
+
+func NewTooLargeError(title, detail string) HandlerError {
+	return HandlerError{
+		Code:           string(ErrTooLarge),
+		Title:          title,
+		Detail:         detail,
+		httpStatusCode: http.StatusRequestEntityTooLarge,
+	}
+}
+
+func NewQuotaExceededError(title, detail string) HandlerError {
+	return HandlerError{
+		Code:           string(ErrQuota),
+		Title:          title,
+		Detail:         detail,
+		httpStatusCode: http.StatusInsufficientStorage,
+	}
+}
+
+func NewUnsupportedMediaTypeError(title, detail string) HandlerError {
+	return HandlerError{
+		Code:           string(ErrMediaType),
+		Title:          title,
+		Detail:         detail,
+		httpStatusCode: http.StatusUnsupportedMediaType,
+	}
+}
+
+func NewReadOnlyError(title, detail string) HandlerError {
+	return HandlerError{
+		Code:           string(ErrReadOnly),
+		Title:          title,
+		Detail:         detail,
+		httpStatusCode: http.StatusForbidden,
+	}
+}
+
+func NewBadGatewayError(title, detail string) HandlerError {
+	return HandlerError{
+		Code:           string(ErrBadGateway),
+		Title:          title,
+		Detail:         detail,
+		httpStatusCode: http.StatusBadGateway,
+	}
+}
+
+func NewNoLeaderError(title, detail string) HandlerError {
+	return HandlerError{
+		Code:           string(ErrNoLeader),
+		Title:          title,
+		Detail:         detail,
+		httpStatusCode: http.StatusServiceUnavailable,
+	}
+}
+
 func NewInternalError(title, detail string) HandlerError {
 	return HandlerError{
 		Code:           string(ErrAppCode),
@@ -14,18 +14,50 @@ type store interface {
 	Check() error
 }
 
+// replica описывает метод проверки готовности ведомого экземпляра или узла кластера.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=replica --exported --with-expecter=true
+type replica interface {
+	Ready() error
+}
+
 // Handler http-обработчик запросов.
 type Handler struct {
-	log   *zap.Logger
-	store store
+	log     *zap.Logger
+	store   store
+	replica replica
+}
+
+// Option настраивает Handler.
+type Option func(h *Handler)
+
+// WithReplica добавляет в readiness-пробу готовность ведомого: ведомый не готов, пока не загрузил снимок ведущего,
+// слишком отстаёт от него или давно не получал от него вестей.
+func WithReplica(r replica) Option {
+	return func(h *Handler) {
+		h.replica = r
+	}
+}
+
+// WithCluster добавляет в readiness-пробу готовность узла кластера: узел не готов, пока в кластере не выбран ведущий.
+func WithCluster(c replica) Option {
+	return func(h *Handler) {
+		h.replica = c
+	}
 }
 
 // NewHandler конструктор для Handler.
-func NewHandler(log *zap.Logger, store store) *Handler {
-	return &Handler{
+func NewHandler(log *zap.Logger, store store, opts ...Option) *Handler {
+	h := &Handler{
 		log:   log,
 		store: store,
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 // Liveness метод обработки запроса на получение liveness-пробы.
@@ -49,5 +81,14 @@ func (h *Handler) Readiness(w http.ResponseWriter, _ *http.Request) {
 		return
 	}
 
+	if h.replica != nil {
+		if err := h.replica.Ready(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(err.Error()))
+
+			return
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
 }
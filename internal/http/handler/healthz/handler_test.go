@@ -90,9 +90,10 @@ func TestHandler_Readiness(t *testing.T) {
 	require.NotNil(t, log)
 
 	cases := []struct {
-		name         string
-		prepareStore func(store *mocks.Store)
-		checkResult  func(t *testing.T, rr *httptest.ResponseRecorder)
+		name           string
+		prepareStore   func(store *mocks.Store)
+		prepareReplica func(replica *mocks.Replica)
+		checkResult    func(t *testing.T, rr *httptest.ResponseRecorder)
 	}{
 		{
 			name: "success",
@@ -112,6 +113,31 @@ func TestHandler_Readiness(t *testing.T) {
 				assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
 			},
 		},
+		{
+			name: "replica ready",
+			prepareStore: func(store *mocks.Store) {
+				store.EXPECT().Check().Once().Return(nil)
+			},
+			prepareReplica: func(replica *mocks.Replica) {
+				replica.EXPECT().Ready().Once().Return(nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+			},
+		},
+		{
+			name: "replica lagging",
+			prepareStore: func(store *mocks.Store) {
+				store.EXPECT().Check().Once().Return(nil)
+			},
+			prepareReplica: func(replica *mocks.Replica) {
+				replica.EXPECT().Ready().Once().Return(errors.New("replica lags behind the leader"))
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+				assert.Equal(t, "replica lags behind the leader", rr.Body.String())
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -124,7 +150,14 @@ func TestHandler_Readiness(t *testing.T) {
 				tc.prepareStore(store)
 			}
 
-			h := NewHandler(log, store)
+			var opts []Option
+			if tc.prepareReplica != nil {
+				replica := mocks.NewReplica(t)
+				tc.prepareReplica(replica)
+				opts = append(opts, WithReplica(replica))
+			}
+
+			h := NewHandler(log, store, opts...)
 			require.NotNil(t, h)
 
 			rr := httptest.NewRecorder()
@@ -0,0 +1,77 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// Replica is an autogenerated mock type for the replica type
+type Replica struct {
+	mock.Mock
+}
+
+type Replica_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Replica) EXPECT() *Replica_Expecter {
+	return &Replica_Expecter{mock: &_m.Mock}
+}
+
+// Ready provides a mock function with given fields:
+func (_m *Replica) Ready() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ready")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Replica_Ready_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ready'
+type Replica_Ready_Call struct {
+	*mock.Call
+}
+
+// Ready is a helper method to define mock.On call
+func (_e *Replica_Expecter) Ready() *Replica_Ready_Call {
+	return &Replica_Ready_Call{Call: _e.mock.On("Ready")}
+}
+
+func (_c *Replica_Ready_Call) Run(run func()) *Replica_Ready_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Replica_Ready_Call) Return(_a0 error) *Replica_Ready_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Replica_Ready_Call) RunAndReturn(run func() error) *Replica_Ready_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewReplica creates a new instance of Replica. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReplica(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Replica {
+	mock := &Replica{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,168 @@
+// Package membership описывает административный обработчик состояния кластера и изменения его состава.
+package membership
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"st-test/internal/cluster"
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Cluster описывает методы узла кластера для просмотра состояния и изменения состава кластера.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Cluster --with-expecter=true
+type Cluster interface {
+	Status() (cluster.Status, error)
+	AddMember(ctx context.Context, m cluster.Member) error
+	RemoveMember(ctx context.Context, id string) error
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log     *zap.Logger
+	cluster Cluster
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, c Cluster) *Handler {
+	return &Handler{
+		log:     log.Named("membership handler"),
+		cluster: c,
+	}
+}
+
+// Member узел кластера.
+type Member struct {
+	ID          string `json:"id"`
+	RaftAddress string `json:"raft_address"`
+	HTTPAddress string `json:"http_address"`
+	Voter       bool   `json:"voter"`
+	Leader      bool   `json:"leader"`
+}
+
+// Response состояние узла, обработавшего запрос, и узлы кластера.
+type Response struct {
+	ID           string     `json:"id"`
+	State        string     `json:"state"`
+	Leader       string     `json:"leader"`
+	Term         uint64     `json:"term"`
+	LastIndex    uint64     `json:"last_index"`
+	CommitIndex  uint64     `json:"commit_index"`
+	AppliedIndex uint64     `json:"applied_index"`
+	LastContact  *time.Time `json:"last_contact,omitempty"`
+	Members      []Member   `json:"members"`
+}
+
+// ToJSON возвращает результат как json.
+func (r Response) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+// Request тело запроса на добавление узла.
+type Request struct {
+	ID          string `json:"id"`
+	RaftAddress string `json:"raft_address"`
+	HTTPAddress string `json:"http_address"`
+}
+
+// Status метод обработки GET запросов на получение состояния кластера.
+func (h *Handler) Status(w http.ResponseWriter, _ *http.Request) {
+	st, err := h.cluster.Status()
+	if err != nil {
+		h.log.Error("failed get cluster status", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed get cluster status", err.Error()))
+
+		return
+	}
+
+	resp := Response{
+		ID:           st.ID,
+		State:        st.State,
+		Leader:       st.Leader,
+		Term:         st.Term,
+		LastIndex:    st.LastIndex,
+		CommitIndex:  st.CommitIndex,
+		AppliedIndex: st.AppliedIndex,
+		Members:      make([]Member, 0, len(st.Members)),
+	}
+
+	// ведущий не получает вестей от ведущего, поэтому время последней связи у него не задано.
+	if !st.LastContact.IsZero() {
+		resp.LastContact = &st.LastContact
+	}
+
+	for _, m := range st.Members {
+		resp.Members = append(resp.Members, Member{
+			ID:          m.ID,
+			RaftAddress: m.RaftAddress,
+			HTTPAddress: m.HTTPAddress,
+			Voter:       m.Voter,
+			Leader:      m.Leader,
+		})
+	}
+
+	responder.JSON(w, resp)
+}
+
+// AddMember метод обработки POST запросов на добавление узла в кластер.
+func (h *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
+	var req Request
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		responder.JSON(w, httpErr.NewInvalidInput("failed decode request", err.Error()))
+
+		return
+	}
+
+	err = h.cluster.AddMember(r.Context(), cluster.Member{ID: req.ID, RaftAddress: req.RaftAddress, HTTPAddress: req.HTTPAddress})
+	if err != nil {
+		h.respondError(w, "failed add cluster member", err)
+
+		return
+	}
+
+	h.log.Info("cluster member added", zap.String("member", req.ID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveMember метод обработки DELETE запросов на удаление узла из кластера.
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "nodeID")
+
+	err := h.cluster.RemoveMember(r.Context(), id)
+	if err != nil {
+		h.respondError(w, "failed remove cluster member", err)
+
+		return
+	}
+
+	h.log.Info("cluster member removed", zap.String("member", id))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, title string, err error) {
+	switch {
+	case errors.Is(err, cluster.ErrInvalidMember):
+		responder.JSON(w, httpErr.NewInvalidInput(title, err.Error()))
+	case errors.Is(err, cluster.ErrUnknownMember):
+		responder.JSON(w, httpErr.NewNotFoundError(title))
+	case errors.Is(err, cluster.ErrNotLeader):
+		responder.JSON(w, httpErr.NewNoLeaderError(title, err.Error()))
+	default:
+		h.log.Error(title, zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError(title, err.Error()))
+	}
+}
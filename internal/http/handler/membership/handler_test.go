@@ -0,0 +1,152 @@
+package membership
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"st-test/internal/cluster"
+	"st-test/internal/http/handler/membership/mocks"
+)
+
+func TestHandler_Status(t *testing.T) {
+	t.Parallel()
+
+	contact := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	c := mocks.NewCluster(t)
+	c.EXPECT().Status().Return(cluster.Status{
+		ID:           "node-2",
+		State:        "Follower",
+		Leader:       "node-1",
+		Term:         3,
+		LastIndex:    10,
+		CommitIndex:  10,
+		AppliedIndex: 9,
+		LastContact:  contact,
+		Members: []cluster.MemberStatus{
+			{Member: cluster.Member{ID: "node-1", RaftAddress: "10.0.0.1:7000", HTTPAddress: "http://10.0.0.1:8080"}, Voter: true, Leader: true},
+			{Member: cluster.Member{ID: "node-2", RaftAddress: "10.0.0.2:7000", HTTPAddress: "http://10.0.0.2:8080"}, Voter: true},
+		},
+	}, nil).Once()
+
+	rr := httptest.NewRecorder()
+	NewHandler(zap.NewNop(), c).Status(rr, httptest.NewRequest(http.MethodGet, "/cluster", http.NoBody))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{
+		"id": "node-2",
+		"state": "Follower",
+		"leader": "node-1",
+		"term": 3,
+		"last_index": 10,
+		"commit_index": 10,
+		"applied_index": 9,
+		"last_contact": "2024-01-02T03:04:05Z",
+		"members": [
+			{"id": "node-1", "raft_address": "10.0.0.1:7000", "http_address": "http://10.0.0.1:8080", "voter": true, "leader": true},
+			{"id": "node-2", "raft_address": "10.0.0.2:7000", "http_address": "http://10.0.0.2:8080", "voter": true, "leader": false}
+		]
+	}`, rr.Body.String())
+}
+
+func TestHandler_StatusFailed(t *testing.T) {
+	t.Parallel()
+
+	c := mocks.NewCluster(t)
+	c.EXPECT().Status().Return(cluster.Status{}, errors.New("raft is shutdown")).Once()
+
+	rr := httptest.NewRecorder()
+	NewHandler(zap.NewNop(), c).Status(rr, httptest.NewRequest(http.MethodGet, "/cluster", http.NoBody))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Contains(t, rr.Body.String(), "raft is shutdown")
+}
+
+func TestHandler_AddMember(t *testing.T) {
+	t.Parallel()
+
+	member := cluster.Member{ID: "node-4", RaftAddress: "10.0.0.4:7000", HTTPAddress: "http://10.0.0.4:8080"}
+	body := `{"id":"node-4","raft_address":"10.0.0.4:7000","http_address":"http://10.0.0.4:8080"}`
+
+	cases := []struct {
+		name     string
+		giveBody string
+		err      error
+		wantCode int
+	}{
+		{name: "invalid body", giveBody: `{`, wantCode: http.StatusBadRequest},
+		{name: "added", giveBody: body, wantCode: http.StatusNoContent},
+		{
+			name:     "invalid member",
+			giveBody: body,
+			err:      fmt.Errorf("%w: id is empty", cluster.ErrInvalidMember),
+			wantCode: http.StatusBadRequest,
+		},
+		{name: "not leader", giveBody: body, err: cluster.ErrNotLeader, wantCode: http.StatusServiceUnavailable},
+		{name: "internal error", giveBody: body, err: errors.New("timed out"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := mocks.NewCluster(t)
+			if tc.giveBody == body {
+				c.EXPECT().AddMember(mock.Anything, member).Return(tc.err).Once()
+			}
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/cluster/members", strings.NewReader(tc.giveBody))
+
+			NewHandler(zap.NewNop(), c).AddMember(rr, req)
+
+			assert.Equal(t, tc.wantCode, rr.Code, rr.Body.String())
+		})
+	}
+}
+
+func TestHandler_RemoveMember(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{name: "removed", wantCode: http.StatusNoContent},
+		{name: "unknown member", err: fmt.Errorf("%w: node-4", cluster.ErrUnknownMember), wantCode: http.StatusNotFound},
+		{name: "not leader", err: cluster.ErrNotLeader, wantCode: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := mocks.NewCluster(t)
+			c.EXPECT().RemoveMember(mock.Anything, "node-4").Return(tc.err).Once()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("nodeID", "node-4")
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, "/cluster/members/node-4", http.NoBody)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			NewHandler(zap.NewNop(), c).RemoveMember(rr, req)
+
+			assert.Equal(t, tc.wantCode, rr.Code, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	cluster "st-test/internal/cluster"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Cluster is an autogenerated mock type for the Cluster type
+type Cluster struct {
+	mock.Mock
+}
+
+type Cluster_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Cluster) EXPECT() *Cluster_Expecter {
+	return &Cluster_Expecter{mock: &_m.Mock}
+}
+
+// AddMember provides a mock function with given fields: ctx, m
+func (_m *Cluster) AddMember(ctx context.Context, m cluster.Member) error {
+	ret := _m.Called(ctx, m)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddMember")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, cluster.Member) error); ok {
+		r0 = rf(ctx, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Cluster_AddMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddMember'
+type Cluster_AddMember_Call struct {
+	*mock.Call
+}
+
+// AddMember is a helper method to define mock.On call
+//   - ctx context.Context
+//   - m cluster.Member
+func (_e *Cluster_Expecter) AddMember(ctx interface{}, m interface{}) *Cluster_AddMember_Call {
+	return &Cluster_AddMember_Call{Call: _e.mock.On("AddMember", ctx, m)}
+}
+
+func (_c *Cluster_AddMember_Call) Run(run func(ctx context.Context, m cluster.Member)) *Cluster_AddMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(cluster.Member))
+	})
+	return _c
+}
+
+func (_c *Cluster_AddMember_Call) Return(_a0 error) *Cluster_AddMember_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Cluster_AddMember_Call) RunAndReturn(run func(context.Context, cluster.Member) error) *Cluster_AddMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveMember provides a mock function with given fields: ctx, id
+func (_m *Cluster) RemoveMember(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Cluster_RemoveMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveMember'
+type Cluster_RemoveMember_Call struct {
+	*mock.Call
+}
+
+// RemoveMember is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *Cluster_Expecter) RemoveMember(ctx interface{}, id interface{}) *Cluster_RemoveMember_Call {
+	return &Cluster_RemoveMember_Call{Call: _e.mock.On("RemoveMember", ctx, id)}
+}
+
+func (_c *Cluster_RemoveMember_Call) Run(run func(ctx context.Context, id string)) *Cluster_RemoveMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Cluster_RemoveMember_Call) Return(_a0 error) *Cluster_RemoveMember_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Cluster_RemoveMember_Call) RunAndReturn(run func(context.Context, string) error) *Cluster_RemoveMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Status provides a mock function with given fields:
+func (_m *Cluster) Status() (cluster.Status, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Status")
+	}
+
+	var r0 cluster.Status
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (cluster.Status, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() cluster.Status); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(cluster.Status)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Cluster_Status_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Status'
+type Cluster_Status_Call struct {
+	*mock.Call
+}
+
+// Status is a helper method to define mock.On call
+func (_e *Cluster_Expecter) Status() *Cluster_Status_Call {
+	return &Cluster_Status_Call{Call: _e.mock.On("Status")}
+}
+
+func (_c *Cluster_Status_Call) Run(run func()) *Cluster_Status_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Cluster_Status_Call) Return(_a0 cluster.Status, _a1 error) *Cluster_Status_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Cluster_Status_Call) RunAndReturn(run func() (cluster.Status, error)) *Cluster_Status_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewCluster creates a new instance of Cluster. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCluster(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Cluster {
+	mock := &Cluster{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -11,7 +11,9 @@ import (
 func ApplicationType(log *zap.Logger) func(h http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		ch := func(w http.ResponseWriter, r *http.Request) {
-			if r.Method == http.MethodGet {
+			// запросы без тела не проверяем. POST без тела используется для действий над объектами, например восстановления.
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodDelete ||
+				(r.Method == http.MethodPost && r.ContentLength == 0) {
 				h.ServeHTTP(w, r)
 
 				return
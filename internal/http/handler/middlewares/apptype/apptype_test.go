@@ -3,6 +3,7 @@ package apptype
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -33,6 +34,39 @@ func TestApplicationType(t *testing.T) {
 				assert.Equal(t, http.StatusOK, rr.Code)
 			},
 		},
+		{
+			name: "delete",
+			giveRequest: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodDelete, "some url", http.NoBody)
+
+				return req
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+			},
+		},
+		{
+			name: "post without body",
+			giveRequest: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "some url", http.NoBody)
+
+				return req
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+			},
+		},
+		{
+			name: "post with body without app type",
+			giveRequest: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "some url", strings.NewReader(`{}`))
+
+				return req
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+			},
+		},
 		{
 			name: "put without app type",
 			giveRequest: func() *http.Request {
@@ -0,0 +1,87 @@
+// Package leader описывает middleware запросов к узлу кластера, которые обслуживает только ведущий узел.
+package leader
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"st-test/internal/cluster"
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/middlewares/readonly"
+	"st-test/internal/http/handler/responder"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ForwardedHeader заголовок запроса, перенаправленного ведущему другим узлом. Такой запрос повторно
+// не перенаправляется, чтобы узлы не пересылали его друг другу, пока выбирается новый ведущий.
+const ForwardedHeader = "X-Cluster-Forwarded"
+
+// Cluster описывает методы узла кластера для поиска ведущего и проверки лидерства.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Cluster --with-expecter=true
+type Cluster interface {
+	Leader() (cluster.Leader, error)
+	VerifyLeader(ctx context.Context) error
+}
+
+// targetKey ключ контекста запроса с адресом ведущего, которому он перенаправляется.
+type targetKey struct{}
+
+// Forward создаёт middleware, которое обслуживает запрос на месте, если этот узел ведущий, и перенаправляет
+// его ведущему иначе, возвращая ответ ведущего как есть. Если ведущий не выбран или запрос уже перенаправлен
+// другим узлом, возвращается 503.
+func Forward(log *zap.Logger, tp trace.TracerProvider, c Cluster) func(h http.Handler) http.Handler {
+	proxy := readonly.NewProxy(log, tp, func(r *http.Request) *url.URL {
+		u, _ := r.Context().Value(targetKey{}).(*url.URL)
+
+		return u
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l, err := c.Leader()
+			if err != nil {
+				responder.JSON(w, httpErr.NewNoLeaderError("cluster has no leader", err.Error()))
+
+				return
+			}
+
+			if l.Local {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if r.Header.Get(ForwardedHeader) != "" {
+				responder.JSON(w, httpErr.NewNoLeaderError("cluster has no leader", "request was forwarded to a node that is not the leader"))
+
+				return
+			}
+
+			w.Header().Set(readonly.LeaderHeader, l.URL.String())
+			r.Header.Set(ForwardedHeader, "true")
+
+			proxy.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), targetKey{}, l.URL)))
+		})
+	}
+}
+
+// Linearizable создаёт middleware, которое перед обработкой запроса проверяет, что узел по-прежнему ведущий
+// и применил все подтверждённые записи, поэтому ответ учитывает все завершённые до запроса записи.
+// Если проверить лидерство не удалось, возвращается 503.
+func Linearizable(c Cluster) func(h http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := c.VerifyLeader(r.Context()); err != nil {
+				responder.JSON(w, httpErr.NewNoLeaderError("failed verify cluster leadership", err.Error()))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package leader
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+
+	"st-test/internal/cluster"
+	"st-test/internal/http/handler/middlewares/leader/mocks"
+	"st-test/internal/http/handler/middlewares/readonly"
+)
+
+// local обработчик узла, который отвечает 200 с телом local.
+func local() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("local"))
+	})
+}
+
+func TestForward_Local(t *testing.T) {
+	t.Parallel()
+
+	c := mocks.NewCluster(t)
+	c.EXPECT().Leader().Return(cluster.Leader{ID: "node-1", Local: true}, nil)
+
+	rr := httptest.NewRecorder()
+	Forward(zap.NewNop(), noop.NewTracerProvider(), c)(local()).ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/objects/1", strings.NewReader(`{}`)))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "local", rr.Body.String())
+}
+
+func TestForward_Remote(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body) + " " + r.Header.Get(ForwardedHeader)))
+	}))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	c := mocks.NewCluster(t)
+	c.EXPECT().Leader().Return(cluster.Leader{ID: "node-2", URL: u}, nil)
+
+	h := Forward(zap.NewNop(), noop.NewTracerProvider(), c)(local())
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/objects/1", strings.NewReader(`{"a":1}`)))
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, `PUT /objects/1 {"a":1} true`, rr.Body.String())
+	assert.Equal(t, srv.URL, rr.Header().Get(readonly.LeaderHeader))
+
+	// запрос, который уже перенаправил другой узел, повторно не перенаправляется.
+	req := httptest.NewRequest(http.MethodGet, "/objects/1", http.NoBody)
+	req.Header.Set(ForwardedHeader, "true")
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"NO_LEADER"`)
+}
+
+func TestForward_NoLeader(t *testing.T) {
+	t.Parallel()
+
+	c := mocks.NewCluster(t)
+	c.EXPECT().Leader().Return(cluster.Leader{}, cluster.ErrNoLeader)
+
+	rr := httptest.NewRecorder()
+	Forward(zap.NewNop(), noop.NewTracerProvider(), c)(local()).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/objects/1", http.NoBody))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"NO_LEADER"`)
+}
+
+func TestLinearizable(t *testing.T) {
+	t.Parallel()
+
+	c := mocks.NewCluster(t)
+	c.EXPECT().VerifyLeader(mock.Anything).Once().Return(nil)
+	c.EXPECT().VerifyLeader(mock.Anything).Once().Return(errors.Join(cluster.ErrNotLeader, errors.New("leadership lost")))
+
+	h := Linearizable(c)(local())
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/objects/1", http.NoBody))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/objects/1", http.NoBody))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), "leadership lost")
+}
@@ -0,0 +1,138 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	cluster "st-test/internal/cluster"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Cluster is an autogenerated mock type for the Cluster type
+type Cluster struct {
+	mock.Mock
+}
+
+type Cluster_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Cluster) EXPECT() *Cluster_Expecter {
+	return &Cluster_Expecter{mock: &_m.Mock}
+}
+
+// Leader provides a mock function with given fields:
+func (_m *Cluster) Leader() (cluster.Leader, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Leader")
+	}
+
+	var r0 cluster.Leader
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (cluster.Leader, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() cluster.Leader); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(cluster.Leader)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Cluster_Leader_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Leader'
+type Cluster_Leader_Call struct {
+	*mock.Call
+}
+
+// Leader is a helper method to define mock.On call
+func (_e *Cluster_Expecter) Leader() *Cluster_Leader_Call {
+	return &Cluster_Leader_Call{Call: _e.mock.On("Leader")}
+}
+
+func (_c *Cluster_Leader_Call) Run(run func()) *Cluster_Leader_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Cluster_Leader_Call) Return(_a0 cluster.Leader, _a1 error) *Cluster_Leader_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Cluster_Leader_Call) RunAndReturn(run func() (cluster.Leader, error)) *Cluster_Leader_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifyLeader provides a mock function with given fields: ctx
+func (_m *Cluster) VerifyLeader(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyLeader")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Cluster_VerifyLeader_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyLeader'
+type Cluster_VerifyLeader_Call struct {
+	*mock.Call
+}
+
+// VerifyLeader is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Cluster_Expecter) VerifyLeader(ctx interface{}) *Cluster_VerifyLeader_Call {
+	return &Cluster_VerifyLeader_Call{Call: _e.mock.On("VerifyLeader", ctx)}
+}
+
+func (_c *Cluster_VerifyLeader_Call) Run(run func(ctx context.Context)) *Cluster_VerifyLeader_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Cluster_VerifyLeader_Call) Return(_a0 error) *Cluster_VerifyLeader_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Cluster_VerifyLeader_Call) RunAndReturn(run func(context.Context) error) *Cluster_VerifyLeader_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewCluster creates a new instance of Cluster. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCluster(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Cluster {
+	mock := &Cluster{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,78 @@
+// Package metrics описывает middleware для сбора метрик http-запросов.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute метка маршрута для запросов, не попавших ни в один маршрут.
+const unmatchedRoute = "unmatched"
+
+// Metrics метрики http-запросов по методу, маршруту и коду ответа.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+// New создаёт метрики http-запросов и регистрирует их в reg.
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "http",
+			Name:      "requests_total",
+			Help:      "Number of http requests by method, route and status code.",
+		}, []string{"method", "route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of http requests by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "code"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "http",
+			Name:      "requests_in_flight",
+			Help:      "Number of http requests being served.",
+		}),
+	}
+
+	reg.MustRegister(m.requests, m.duration, m.inFlight)
+
+	return m
+}
+
+// Middleware учитывает запрос в метриках. Маршрут берётся из шаблона chi, а не из пути,
+// чтобы id объектов не попадали в метки.
+func (m *Metrics) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		h.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		// обработчик, не записавший ответ, отвечает 200.
+		code := ww.Status()
+		if code == 0 {
+			code = http.StatusOK
+		}
+
+		labels := prometheus.Labels{"method": r.Method, "route": route, "code": strconv.Itoa(code)}
+
+		m.requests.With(labels).Inc()
+		m.duration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_Middleware(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+	m := New(reg)
+
+	mux := chi.NewRouter()
+	mux.Use(m.Middleware)
+	mux.Get("/objects/{objectID}", func(w http.ResponseWriter, _ *http.Request) {
+		// во время обработки запрос учитывается как выполняющийся.
+		assert.InDelta(t, 1, testutil.ToFloat64(m.inFlight), 0)
+
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.Get("/healthz", func(http.ResponseWriter, *http.Request) {})
+
+	for _, path := range []string{"/objects/1", "/objects/2", "/healthz", "/unknown"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.InDelta(t, 2, testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, "/objects/{objectID}", "404")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, "/healthz", "200")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")), 0)
+	assert.InDelta(t, 0, testutil.ToFloat64(m.inFlight), 0)
+
+	count, err := testutil.GatherAndCount(reg, "http_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
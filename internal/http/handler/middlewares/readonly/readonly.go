@@ -0,0 +1,63 @@
+// Package readonly описывает middleware запросов на запись к ведомому экземпляру, который обслуживает только чтение.
+package readonly
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/tracing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// LeaderHeader заголовок ответа с адресом ведущего, которому нужно отправлять запись.
+const LeaderHeader = "X-Leader"
+
+// Reject создаёт middleware, отклоняющее запросы на запись со статусом 403 и адресом ведущего в заголовке X-Leader.
+func Reject(leader *url.URL) func(h http.Handler) http.Handler {
+	return func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set(LeaderHeader, leader.String())
+			responder.JSON(w, httpErr.NewReadOnlyError("failed write object", "instance is a read-only replica of "+leader.String()))
+		})
+	}
+}
+
+// Proxy создаёт middleware, перенаправляющее запросы на запись ведущему и возвращающее его ответ как есть.
+// Ведущему передаются идентификатор запроса и контекст трассировки; если ведущий недоступен, возвращается 502.
+func Proxy(log *zap.Logger, tp trace.TracerProvider, leader *url.URL) func(h http.Handler) http.Handler {
+	proxy := NewProxy(log, tp, func(*http.Request) *url.URL { return leader })
+
+	return func(http.Handler) http.Handler {
+		return proxy
+	}
+}
+
+// NewProxy создаёт обработчик, перенаправляющий запрос ведущему по адресу target(r) и возвращающий его ответ как есть.
+// Ведущему передаются идентификатор запроса и контекст трассировки: запрос к ведущему записывается клиентским
+// спаном, дочерним спану запроса. Если ведущий недоступен, возвращается 502.
+func NewProxy(log *zap.Logger, tp trace.TracerProvider, target func(r *http.Request) *url.URL) http.Handler {
+	log = log.Named("leader proxy")
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target(r.In))
+			r.SetXForwarded()
+
+			if id := middleware.GetReqID(r.In.Context()); id != "" {
+				r.Out.Header.Set(middleware.RequestIDHeader, id)
+			}
+		},
+		Transport: tracing.Transport(tp, nil),
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Warn("failed proxy request to leader", zap.String("path", r.URL.Path), zap.Error(err))
+
+			responder.JSON(w, httpErr.NewBadGatewayError("failed proxy request to leader", err.Error()))
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package readonly
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+// local обработчик ведомого, до которого запросы на запись доходить не должны.
+func local(t *testing.T) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("write must not reach the replica handler")
+	})
+}
+
+func TestReject(t *testing.T) {
+	t.Parallel()
+
+	leader, err := url.Parse("http://leader:8080")
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/objects/1", strings.NewReader(`{}`))
+
+	Reject(leader)(local(t)).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Equal(t, "http://leader:8080", rr.Header().Get(LeaderHeader))
+	assert.Contains(t, rr.Body.String(), `"code":"READ_ONLY"`)
+}
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("X-Seen-Request-Id", r.Header.Get(middleware.RequestIDHeader))
+		w.Header().Set("X-Seen-Traceparent", r.Header.Get("traceparent"))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body)))
+	}))
+	t.Cleanup(srv.Close)
+
+	leader, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	h := middleware.RequestID(Proxy(zap.NewNop(), tp, leader)(local(t)))
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/objects/1", strings.NewReader(`{"a":1}`)).WithContext(ctx)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+
+	h.ServeHTTP(rr, req)
+	span.End()
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, `PUT /objects/1 {"a":1}`, rr.Body.String())
+	assert.Equal(t, "req-1", rr.Header().Get("X-Seen-Request-Id"))
+
+	// запрос к ведущему записывается клиентским спаном, дочерним спану запроса, и ведущий продолжает его трассировку.
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	client := spans[0].SpanContext()
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, span.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, "00-"+client.TraceID().String()+"-"+client.SpanID().String()+"-01", rr.Header().Get("X-Seen-Traceparent"))
+}
+
+func TestProxy_LeaderUnavailable(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	leader, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	srv.Close()
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/objects/1", http.NoBody)
+
+	Proxy(zap.NewNop(), noop.NewTracerProvider(), leader)(local(t)).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"BAD_GATEWAY"`)
+}
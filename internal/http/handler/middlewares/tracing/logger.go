@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"net/http"
+	"time"
+
+	apptracing "st-test/internal/tracing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+// RequestLogger создаёт middleware, которое пишет в log строку о каждом завершённом запросе. Логгер запроса
+// содержит идентификатор запроса и идентификаторы трассировки и спана запроса, поэтому должен подключаться
+// после RequestID и Tracing. Паника обработчика, перехваченная Recoverer, пишется в тот же логгер.
+func RequestLogger(log *zap.Logger) func(h http.Handler) http.Handler {
+	return middleware.RequestLogger(logFormatter{log: log})
+}
+
+// logFormatter создаёт логгер запроса.
+type logFormatter struct {
+	log *zap.Logger
+}
+
+func (f logFormatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	fields := append([]zap.Field{
+		zap.String("request_id", middleware.GetReqID(r.Context())),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.String("remote_addr", r.RemoteAddr),
+	}, apptracing.LogFields(r.Context())...)
+
+	return logEntry{log: f.log.With(fields...)}
+}
+
+// logEntry логгер запроса.
+type logEntry struct {
+	log *zap.Logger
+}
+
+func (e logEntry) Write(status, bytes int, _ http.Header, elapsed time.Duration, _ any) {
+	e.log.Info("request completed", zap.Int("status", status), zap.Int("bytes", bytes), zap.Duration("elapsed", elapsed))
+}
+
+func (e logEntry) Panic(v any, stack []byte) {
+	e.log.Error("request panicked", zap.Any("panic", v), zap.ByteString("stack", stack))
+}
@@ -0,0 +1,58 @@
+// Package tracing описывает middleware для трассировки http-запросов и журналирования их с идентификаторами трассировки.
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName имя трассировщика http-запросов.
+const tracerName = "st-test/internal/http"
+
+// Tracing создаёт middleware, начинающий спан для каждого запроса. Контекст трассировки вызывающего берётся
+// из заголовка W3C traceparent, и спан запроса становится его дочерним; спаны обработчиков и хранилища,
+// начатые с контекстом запроса, становятся дочерними спану запроса.
+func Tracing(tp trace.TracerProvider) func(h http.Handler) http.Handler {
+	tracer := tp.Tracer(tracerName)
+	propagator := propagation.TraceContext{}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			ctx, span := tracer.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			))
+			defer span.End()
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			h.ServeHTTP(ww, r.WithContext(ctx))
+
+			// маршрут известен только после обработки запроса роутером.
+			if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+				span.SetName(r.Method + " " + rctx.RoutePattern())
+				span.SetAttributes(attribute.String("http.route", rctx.RoutePattern()))
+			}
+
+			code := ww.Status()
+			if code == 0 {
+				code = http.StatusOK
+			}
+
+			span.SetAttributes(attribute.Int("http.response.status_code", code))
+
+			if code >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, fmt.Sprintf("status code %d", code))
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTracing(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var handlerSpan trace.SpanContext
+
+	mux := chi.NewRouter()
+	mux.Use(Tracing(tp))
+	mux.Get("/objects/{objectID}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/objects/1", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "GET /objects/{objectID}", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+	assert.Equal(t, spanID, span.Parent().SpanID().String())
+	assert.True(t, span.Parent().IsRemote())
+	assert.Equal(t, span.SpanContext(), handlerSpan)
+	assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	assert.Contains(t, span.Attributes(), attribute.String("http.route", "/objects/{objectID}"))
+	assert.Equal(t, codes.Error, span.Status().Code)
+
+	// без traceparent начинается новая трассировка.
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/objects/2", nil))
+
+	spans = recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.False(t, spans[1].Parent().IsValid())
+	assert.NotEqual(t, traceID, spans[1].SpanContext().TraceID().String())
+}
+
+func TestRequestLogger(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zap.InfoLevel)
+	tp := sdktrace.NewTracerProvider()
+
+	var handlerSpan trace.SpanContext
+
+	mux := chi.NewRouter()
+	mux.Use(middleware.RequestID)
+	mux.Use(Tracing(tp))
+	mux.Use(RequestLogger(zap.New(core)))
+	mux.Use(middleware.Recoverer)
+	mux.Get("/objects/{objectID}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.Get("/panic", func(http.ResponseWriter, *http.Request) {
+		panic("some panic")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/objects/1", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	// строка запроса содержит идентификаторы запроса, трассировки и спана запроса.
+	entries := logs.TakeAll()
+	require.Len(t, entries, 1)
+
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "request completed", entries[0].Message)
+	assert.Equal(t, "req-1", fields["request_id"])
+	assert.Equal(t, handlerSpan.TraceID().String(), fields["trace_id"])
+	assert.Equal(t, handlerSpan.SpanID().String(), fields["span_id"])
+	assert.Equal(t, int64(http.StatusNotFound), fields["status"])
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	panicked := logs.FilterMessage("request panicked").All()
+	require.Len(t, panicked, 1)
+	assert.Contains(t, panicked[0].ContextMap(), "trace_id")
+}
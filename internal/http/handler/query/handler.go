@@ -0,0 +1,385 @@
+// Package query описывает обработчик запросов для выборки, агрегации и полнотекстового поиска объектов.
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/jsonpath"
+	"st-test/internal/models"
+	"st-test/internal/search"
+	"st-test/internal/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultLimit количество объектов в ответе по умолчанию.
+	defaultLimit = 100
+	// maxLimit максимальное количество объектов в ответе.
+	maxLimit = 1000
+	// scanBudget бюджет времени на один запрос. Должен быть меньше таймаута запроса.
+	scanBudget = 500 * time.Millisecond
+	// aggregateMaxScanned максимальное количество объектов хранилища, по которым выполняется агрегация.
+	// Агрегация не возобновляется курсором, поэтому вместо бюджета времени ограничивается объём просмотра.
+	aggregateMaxScanned = 100000
+	// AggregateTimeout таймаут запроса агрегации. Агрегация просматривает всё хранилище, поэтому ей нужно
+	// больше времени, чем общий таймаут; просмотр aggregateMaxScanned объектов должен в него укладываться.
+	AggregateTimeout = 10 * time.Second
+)
+
+// Storage описывает методы хранилища для потокового сканирования, агрегации и полнотекстового поиска объектов.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Storage --with-expecter=true
+type Storage interface {
+	Scan(ctx context.Context, opts storage.ScanOptions, match func(item models.Item) bool) (storage.ScanResult, error)
+	Aggregate(ctx context.Context, opts storage.AggregateOptions) ([]storage.AggregateGroup, error)
+	Search(ctx context.Context, query string, offset, limit int) (storage.SearchResult, error)
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log   *zap.Logger
+	store Storage
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, store Storage) *Handler {
+	return &Handler{
+		log:   log.Named("query handler"),
+		store: store,
+	}
+}
+
+// Request тело запроса на выборку объектов. Объект подходит, если подходит под фильтр Filter, у него есть
+// все пары метаданных Metadata и все теги Tags; должно быть задано хотя бы одно из условий.
+type Request struct {
+	Filter   string            `json:"filter"`
+	Metadata map[string]string `json:"metadata"`
+	Tags     []string          `json:"tags"`
+	Limit    int               `json:"limit"`
+	Cursor   string            `json:"cursor"`
+}
+
+// Object найденный объект. Тело отдаётся только у объектов с телом json.
+type Object struct {
+	ID       int               `json:"id"`
+	Body     json.RawMessage   `json:"body,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+}
+
+// Response результат выборки объектов.
+type Response struct {
+	Objects []Object `json:"objects"`
+	Cursor  string   `json:"cursor,omitempty"`
+	Partial bool     `json:"partial"`
+	Scanned int      `json:"scanned"`
+}
+
+// ToJSON возвращает результат как json.
+func (r Response) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+// AggregateRequest тело запроса на агрегацию объектов.
+type AggregateRequest struct {
+	Op      string `json:"op"`
+	Path    string `json:"path"`
+	GroupBy string `json:"group_by"`
+	Filter  string `json:"filter"`
+}
+
+// Group результат агрегации для одной группы.
+type Group struct {
+	Group any      `json:"group"`
+	Count int      `json:"count"`
+	Value *float64 `json:"value"`
+}
+
+// AggregateResponse результат агрегации объектов.
+type AggregateResponse struct {
+	Op     string  `json:"op"`
+	Groups []Group `json:"groups"`
+}
+
+// ToJSON возвращает результат как json.
+func (r AggregateResponse) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+// SearchHit найденный полнотекстовым поиском объект.
+type SearchHit struct {
+	ID    int             `json:"id"`
+	Score float64         `json:"score"`
+	Body  json.RawMessage `json:"body"`
+}
+
+// SearchResponse страница результатов полнотекстового поиска.
+type SearchResponse struct {
+	Objects []SearchHit `json:"objects"`
+	Total   int         `json:"total"`
+	Offset  int         `json:"offset"`
+	Limit   int         `json:"limit"`
+}
+
+// ToJSON возвращает результат как json.
+func (r SearchResponse) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+// Query метод обработки POST запросов на выборку объектов по JSONPath или фильтру, метаданным и тегам.
+// Если просмотр хранилища не уложился в бюджет времени, возвращается частичный результат и курсор продолжения.
+func (h *Handler) Query(w http.ResponseWriter, r *http.Request) {
+	var req Request
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.log.Error("failed decode request", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed decode request", err.Error()))
+
+		return
+	}
+
+	match, err := matcher(req)
+	if err != nil {
+		h.log.Error("failed parse filter", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed parse filter", err.Error()))
+
+		return
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	limit = min(limit, maxLimit)
+
+	res, err := h.store.Scan(r.Context(), storage.ScanOptions{
+		Cursor: req.Cursor,
+		Limit:  limit,
+		Budget: scanBudget,
+	}, match)
+	if err != nil {
+		if errors.Is(err, storage.ErrInvalidCursor) {
+			responder.JSON(w, httpErr.NewInvalidInput("failed query objects", err.Error()))
+
+			return
+		}
+
+		h.log.Error("failed query objects", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed query objects", err.Error()))
+
+		return
+	}
+
+	resp := Response{
+		Objects: make([]Object, 0, len(res.Items)),
+		Cursor:  res.Cursor,
+		Partial: res.Partial,
+		Scanned: res.Scanned,
+	}
+
+	for _, item := range res.Items {
+		obj := Object{ID: item.ID, Metadata: item.Metadata, Tags: item.Tags}
+		if json.Valid(item.Body) {
+			obj.Body = item.Body
+		}
+
+		resp.Objects = append(resp.Objects, obj)
+	}
+
+	responder.JSON(w, resp)
+}
+
+// matcher возвращает проверку объекта на соответствие условиям запроса req.
+func matcher(req Request) (func(item models.Item) bool, error) {
+	if req.Filter == "" && len(req.Metadata) == 0 && len(req.Tags) == 0 {
+		return nil, errors.New("filter, metadata or tags is required")
+	}
+
+	// ключи метаданных хранятся в нижнем регистре.
+	meta := make(map[string]string, len(req.Metadata))
+	for k, v := range req.Metadata {
+		meta[strings.ToLower(k)] = v
+	}
+
+	if req.Filter == "" {
+		return func(item models.Item) bool {
+			return item.HasMetadata(meta, req.Tags)
+		}, nil
+	}
+
+	expr, err := jsonpath.Parse(req.Filter)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return func(item models.Item) bool {
+		if !item.HasMetadata(meta, req.Tags) {
+			return false
+		}
+
+		ok, err := expr.MatchJSON(item.Body)
+
+		return err == nil && ok
+	}, nil
+}
+
+// Aggregate метод обработки POST запросов на агрегацию (count, sum, avg, min, max) значений объектов
+// с необязательными группировкой и фильтром.
+func (h *Handler) Aggregate(w http.ResponseWriter, r *http.Request) {
+	var req AggregateRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.log.Error("failed decode request", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed decode request", err.Error()))
+
+		return
+	}
+
+	opts, err := aggregateOptions(req)
+	if err != nil {
+		h.log.Error("failed parse aggregate request", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed parse aggregate request", err.Error()))
+
+		return
+	}
+
+	groups, err := h.store.Aggregate(r.Context(), opts)
+	if errors.Is(err, storage.ErrAggregateTooLarge) {
+		h.log.Info("aggregate rejected", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewUnprocessableError("failed aggregate objects", err.Error()))
+
+		return
+	}
+
+	if err != nil {
+		h.log.Error("failed aggregate objects", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed aggregate objects", err.Error()))
+
+		return
+	}
+
+	resp := AggregateResponse{
+		Op:     string(opts.Op),
+		Groups: make([]Group, 0, len(groups)),
+	}
+
+	for _, g := range groups {
+		resp.Groups = append(resp.Groups, Group{Group: g.Group, Count: g.Count, Value: g.Value})
+	}
+
+	responder.JSON(w, resp)
+}
+
+// Search метод обработки GET запросов на полнотекстовый поиск по строковым значениям объектов.
+// Параметры запроса: q — поисковый запрос, limit и offset — размер и смещение страницы.
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+
+	limit, err := intParam(values.Get("limit"), defaultLimit)
+	if err != nil || limit <= 0 {
+		responder.JSON(w, httpErr.NewInvalidInput("failed parse limit", values.Get("limit")))
+
+		return
+	}
+
+	limit = min(limit, maxLimit)
+
+	offset, err := intParam(values.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		responder.JSON(w, httpErr.NewInvalidInput("failed parse offset", values.Get("offset")))
+
+		return
+	}
+
+	res, err := h.store.Search(r.Context(), values.Get("q"), offset, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, search.ErrEmptyQuery), errors.Is(err, search.ErrInvalidQuery):
+			responder.JSON(w, httpErr.NewInvalidInput("failed search objects", err.Error()))
+		case errors.Is(err, storage.ErrSearchDisabled):
+			responder.JSON(w, httpErr.NewNotFoundError("full-text search is disabled"))
+		default:
+			h.log.Error("failed search objects", zap.Error(err))
+
+			responder.JSON(w, httpErr.NewInternalError("failed search objects", err.Error()))
+		}
+
+		return
+	}
+
+	resp := SearchResponse{
+		Objects: make([]SearchHit, 0, len(res.Hits)),
+		Total:   res.Total,
+		Offset:  offset,
+		Limit:   limit,
+	}
+
+	for _, hit := range res.Hits {
+		resp.Objects = append(resp.Objects, SearchHit{ID: hit.Item.ID, Score: hit.Score, Body: hit.Item.Body})
+	}
+
+	responder.JSON(w, resp)
+}
+
+// intParam разбирает числовой параметр запроса. Если параметр не передан, возвращается значение по умолчанию.
+func intParam(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	return strconv.Atoi(value) //nolint:wrapcheck
+}
+
+// aggregateOptions разбирает пути и фильтр запроса и проверяет параметры агрегации.
+func aggregateOptions(req AggregateRequest) (storage.AggregateOptions, error) {
+	opts := storage.AggregateOptions{Op: storage.AggregateOp(req.Op), MaxScanned: aggregateMaxScanned}
+
+	var err error
+
+	if req.Path != "" {
+		opts.Path, err = jsonpath.ParsePath(req.Path)
+		if err != nil {
+			return opts, fmt.Errorf("path: %w", err)
+		}
+	}
+
+	if req.GroupBy != "" {
+		opts.GroupBy, err = jsonpath.ParsePath(req.GroupBy)
+		if err != nil {
+			return opts, fmt.Errorf("group_by: %w", err)
+		}
+	}
+
+	if req.Filter != "" {
+		opts.Filter, err = jsonpath.Parse(req.Filter)
+		if err != nil {
+			return opts, fmt.Errorf("filter: %w", err)
+		}
+	}
+
+	if err := opts.Validate(); err != nil {
+		return opts, err //nolint:wrapcheck
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,442 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"st-test/internal/http/handler/query/mocks"
+	"st-test/internal/models"
+	"st-test/internal/search"
+	"st-test/internal/storage"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewHandler(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	store := mocks.NewStorage(t)
+	require.NotNil(t, store)
+
+	h := NewHandler(log, store)
+	require.NotNil(t, h)
+}
+
+func TestHandler_Query(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	items := []models.Item{
+		{ID: 1, Body: []byte(`{"status":"active"}`), Metadata: map[string]string{"author": "bob"}, Tags: []string{"draft"}},
+		{ID: 2, Body: []byte(`{"status":"deleted"}`), Tags: []string{"draft"}},
+		{ID: 3, Body: []byte(`{"status":"active"}`)},
+		{ID: 4, Body: []byte("plain text"), ContentType: "text/plain", Tags: []string{"draft", "text"}},
+	}
+
+	// scan имитирует хранилище: применяет фильтр к объектам.
+	scan := func(_ context.Context, opts storage.ScanOptions, match func(models.Item) bool) (storage.ScanResult, error) {
+		res := storage.ScanResult{}
+
+		for _, item := range items {
+			res.Scanned++
+
+			if match(item) {
+				res.Items = append(res.Items, item)
+			}
+		}
+
+		return res, nil
+	}
+
+	cases := []struct {
+		name         string
+		giveBody     string
+		prepareStore func(store *mocks.Storage)
+		checkResult  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "invalid request",
+			giveBody: `not json`,
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed decode request")
+			},
+		},
+		{
+			name:     "empty filter",
+			giveBody: `{}`,
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "filter, metadata or tags is required")
+			},
+		},
+		{
+			name:     "invalid filter",
+			giveBody: `{"filter":"$.status = 1"}`,
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed parse filter")
+			},
+		},
+		{
+			name:     "invalid cursor",
+			giveBody: `{"filter":"$.status","cursor":"???"}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Scan(mock.Anything, mock.Anything, mock.Anything).
+					Once().
+					Return(storage.ScanResult{}, storage.ErrInvalidCursor)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed query objects")
+			},
+		},
+		{
+			name:     "store error",
+			giveBody: `{"filter":"$.status"}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Scan(mock.Anything, mock.Anything, mock.Anything).
+					Once().
+					Return(storage.ScanResult{}, errors.New("some error"))
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed query objects")
+			},
+		},
+		{
+			name:     "success",
+			giveBody: `{"filter":"$.status == 'active'","limit":5000}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Scan(mock.Anything, mock.MatchedBy(func(opts storage.ScanOptions) bool {
+					return opts.Limit == maxLimit && opts.Budget == scanBudget
+				}), mock.Anything).
+					RunAndReturn(scan).
+					Once()
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.JSONEq(t,
+					`{"objects":[{"id":1,"body":{"status":"active"},"metadata":{"author":"bob"},"tags":["draft"]},`+
+						`{"id":3,"body":{"status":"active"}}],"partial":false,"scanned":4}`,
+					rr.Body.String())
+			},
+		},
+		{
+			name:     "metadata and tags",
+			giveBody: `{"filter":"$.status == 'active'","metadata":{"Author":"bob"},"tags":["draft"]}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Scan(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(scan).Once()
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.JSONEq(t,
+					`{"objects":[{"id":1,"body":{"status":"active"},"metadata":{"author":"bob"},"tags":["draft"]}],"partial":false,"scanned":4}`,
+					rr.Body.String())
+			},
+		},
+		{
+			name:     "tags without filter",
+			giveBody: `{"tags":["draft"]}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Scan(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(scan).Once()
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				// тело не json не отдаётся.
+				assert.JSONEq(t, `{"objects":[`+
+					`{"id":1,"body":{"status":"active"},"metadata":{"author":"bob"},"tags":["draft"]},`+
+					`{"id":2,"body":{"status":"deleted"},"tags":["draft"]},`+
+					`{"id":4,"tags":["draft","text"]}],"partial":false,"scanned":4}`,
+					rr.Body.String())
+			},
+		},
+		{
+			name:     "partial result",
+			giveBody: `{"filter":"$.status"}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Scan(mock.Anything, mock.Anything, mock.Anything).
+					Once().
+					Return(storage.ScanResult{Cursor: "Mg", Partial: true, Scanned: 2}, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.JSONEq(t, `{"objects":[],"cursor":"Mg","partial":true,"scanned":2}`, rr.Body.String())
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			require.NotNil(t, store)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			h := &Handler{
+				log:   log,
+				store: store,
+			}
+
+			var (
+				req, _ = http.NewRequest(http.MethodPost, "/objects:query", bytes.NewBufferString(tc.giveBody))
+				rr     = httptest.NewRecorder()
+			)
+
+			h.Query(rr, req)
+			tc.checkResult(t, rr)
+		})
+	}
+}
+
+func TestHandler_Aggregate(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	total := float64(42)
+
+	cases := []struct {
+		name         string
+		giveBody     string
+		prepareStore func(store *mocks.Storage)
+		checkResult  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "invalid request",
+			giveBody: `not json`,
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed decode request")
+			},
+		},
+		{
+			name:     "unsupported op",
+			giveBody: `{"op":"median","path":"$.amount"}`,
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "unsupported aggregate operation")
+			},
+		},
+		{
+			name:     "invalid path",
+			giveBody: `{"op":"sum","path":"amount"}`,
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed parse aggregate request")
+			},
+		},
+		{
+			name:     "invalid filter",
+			giveBody: `{"op":"count","filter":"$.a ="}`,
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "filter")
+			},
+		},
+		{
+			name:     "store error",
+			giveBody: `{"op":"count"}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Aggregate(mock.Anything, mock.Anything).
+					Once().
+					Return(nil, errors.New("some error"))
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed aggregate objects")
+			},
+		},
+		{
+			name:     "too many objects",
+			giveBody: `{"op":"count"}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Aggregate(mock.Anything, mock.MatchedBy(func(opts storage.AggregateOptions) bool {
+					return opts.MaxScanned == aggregateMaxScanned
+				})).
+					Once().
+					Return(nil, fmt.Errorf("%w: 100001 objects", storage.ErrAggregateTooLarge))
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+				assert.Contains(t, rr.Body.String(), "too many objects to aggregate")
+			},
+		},
+		{
+			name:     "success",
+			giveBody: `{"op":"sum","path":"$.amount","group_by":"$.status","filter":"$.amount > 0"}`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Aggregate(mock.Anything, mock.MatchedBy(func(opts storage.AggregateOptions) bool {
+					return opts.Op == storage.AggregateSum && opts.Path != nil && opts.GroupBy != nil && opts.Filter != nil
+				})).
+					Once().
+					Return([]storage.AggregateGroup{{Group: "new", Count: 2, Value: &total}, {Count: 1}}, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.JSONEq(t,
+					`{"op":"sum","groups":[{"group":"new","count":2,"value":42},{"group":null,"count":1,"value":null}]}`,
+					rr.Body.String())
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			require.NotNil(t, store)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			h := &Handler{
+				log:   log,
+				store: store,
+			}
+
+			var (
+				req, _ = http.NewRequest(http.MethodPost, "/objects:aggregate", bytes.NewBufferString(tc.giveBody))
+				rr     = httptest.NewRecorder()
+			)
+
+			h.Aggregate(rr, req)
+			tc.checkResult(t, rr)
+		})
+	}
+}
+
+func TestHandler_Search(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	cases := []struct {
+		name         string
+		giveURL      string
+		prepareStore func(store *mocks.Storage)
+		checkResult  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "invalid limit",
+			giveURL: "/objects:search?q=fox&limit=abc",
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed parse limit")
+			},
+		},
+		{
+			name:    "invalid offset",
+			giveURL: "/objects:search?q=fox&offset=-1",
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed parse offset")
+			},
+		},
+		{
+			name:    "empty query",
+			giveURL: "/objects:search",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Search(mock.Anything, "", 0, defaultLimit).
+					Once().
+					Return(storage.SearchResult{}, search.ErrEmptyQuery)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "empty search query")
+			},
+		},
+		{
+			name:    "search disabled",
+			giveURL: "/objects:search?q=fox",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Search(mock.Anything, "fox", 0, defaultLimit).
+					Once().
+					Return(storage.SearchResult{}, storage.ErrSearchDisabled)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+			},
+		},
+		{
+			name:    "store error",
+			giveURL: "/objects:search?q=fox",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Search(mock.Anything, "fox", 0, defaultLimit).
+					Once().
+					Return(storage.SearchResult{}, errors.New("some error"))
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed search objects")
+			},
+		},
+		{
+			name:    "success",
+			giveURL: `/objects:search?q=%22brown+fox%22+qu*&offset=10&limit=5`,
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Search(mock.Anything, `"brown fox" qu*`, 10, 5).
+					Once().
+					Return(storage.SearchResult{
+						Hits:  []storage.SearchHit{{Item: models.Item{ID: 1, Body: []byte(`{"a":"quick brown fox"}`)}, Score: 1.5}},
+						Total: 11,
+					}, nil)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.JSONEq(t,
+					`{"objects":[{"id":1,"score":1.5,"body":{"a":"quick brown fox"}}],"total":11,"offset":10,"limit":5}`,
+					rr.Body.String())
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			require.NotNil(t, store)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			h := &Handler{
+				log:   log,
+				store: store,
+			}
+
+			var (
+				req, _ = http.NewRequest(http.MethodGet, tc.giveURL, http.NoBody)
+				rr     = httptest.NewRecorder()
+			)
+
+			h.Search(rr, req)
+			tc.checkResult(t, rr)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "st-test/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	storage "st-test/internal/storage"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+type Storage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Storage) EXPECT() *Storage_Expecter {
+	return &Storage_Expecter{mock: &_m.Mock}
+}
+
+// Aggregate provides a mock function with given fields: ctx, opts
+func (_m *Storage) Aggregate(ctx context.Context, opts storage.AggregateOptions) ([]storage.AggregateGroup, error) {
+	ret := _m.Called(ctx, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Aggregate")
+	}
+
+	var r0 []storage.AggregateGroup
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.AggregateOptions) ([]storage.AggregateGroup, error)); ok {
+		return rf(ctx, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, storage.AggregateOptions) []storage.AggregateGroup); ok {
+		r0 = rf(ctx, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.AggregateGroup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, storage.AggregateOptions) error); ok {
+		r1 = rf(ctx, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_Aggregate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Aggregate'
+type Storage_Aggregate_Call struct {
+	*mock.Call
+}
+
+// Aggregate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - opts storage.AggregateOptions
+func (_e *Storage_Expecter) Aggregate(ctx interface{}, opts interface{}) *Storage_Aggregate_Call {
+	return &Storage_Aggregate_Call{Call: _e.mock.On("Aggregate", ctx, opts)}
+}
+
+func (_c *Storage_Aggregate_Call) Run(run func(ctx context.Context, opts storage.AggregateOptions)) *Storage_Aggregate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.AggregateOptions))
+	})
+	return _c
+}
+
+func (_c *Storage_Aggregate_Call) Return(_a0 []storage.AggregateGroup, _a1 error) *Storage_Aggregate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_Aggregate_Call) RunAndReturn(run func(context.Context, storage.AggregateOptions) ([]storage.AggregateGroup, error)) *Storage_Aggregate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Scan provides a mock function with given fields: ctx, opts, match
+func (_m *Storage) Scan(ctx context.Context, opts storage.ScanOptions, match func(models.Item) bool) (storage.ScanResult, error) {
+	ret := _m.Called(ctx, opts, match)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Scan")
+	}
+
+	var r0 storage.ScanResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.ScanOptions, func(models.Item) bool) (storage.ScanResult, error)); ok {
+		return rf(ctx, opts, match)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, storage.ScanOptions, func(models.Item) bool) storage.ScanResult); ok {
+		r0 = rf(ctx, opts, match)
+	} else {
+		r0 = ret.Get(0).(storage.ScanResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, storage.ScanOptions, func(models.Item) bool) error); ok {
+		r1 = rf(ctx, opts, match)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_Scan_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Scan'
+type Storage_Scan_Call struct {
+	*mock.Call
+}
+
+// Scan is a helper method to define mock.On call
+//   - ctx context.Context
+//   - opts storage.ScanOptions
+//   - match func(models.Item) bool
+func (_e *Storage_Expecter) Scan(ctx interface{}, opts interface{}, match interface{}) *Storage_Scan_Call {
+	return &Storage_Scan_Call{Call: _e.mock.On("Scan", ctx, opts, match)}
+}
+
+func (_c *Storage_Scan_Call) Run(run func(ctx context.Context, opts storage.ScanOptions, match func(models.Item) bool)) *Storage_Scan_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.ScanOptions), args[2].(func(models.Item) bool))
+	})
+	return _c
+}
+
+func (_c *Storage_Scan_Call) Return(_a0 storage.ScanResult, _a1 error) *Storage_Scan_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_Scan_Call) RunAndReturn(run func(context.Context, storage.ScanOptions, func(models.Item) bool) (storage.ScanResult, error)) *Storage_Scan_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Search provides a mock function with given fields: ctx, _a1, offset, limit
+func (_m *Storage) Search(ctx context.Context, _a1 string, offset int, limit int) (storage.SearchResult, error) {
+	ret := _m.Called(ctx, _a1, offset, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 storage.SearchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) (storage.SearchResult, error)); ok {
+		return rf(ctx, _a1, offset, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) storage.SearchResult); ok {
+		r0 = rf(ctx, _a1, offset, limit)
+	} else {
+		r0 = ret.Get(0).(storage.SearchResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, _a1, offset, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_Search_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Search'
+type Storage_Search_Call struct {
+	*mock.Call
+}
+
+// Search is a helper method to define mock.On call
+//   - ctx context.Context
+//   - _a1 string
+//   - offset int
+//   - limit int
+func (_e *Storage_Expecter) Search(ctx interface{}, _a1 interface{}, offset interface{}, limit interface{}) *Storage_Search_Call {
+	return &Storage_Search_Call{Call: _e.mock.On("Search", ctx, _a1, offset, limit)}
+}
+
+func (_c *Storage_Search_Call) Run(run func(ctx context.Context, _a1 string, offset int, limit int)) *Storage_Search_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Storage_Search_Call) Return(_a0 storage.SearchResult, _a1 error) *Storage_Search_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_Search_Call) RunAndReturn(run func(context.Context, string, int, int) (storage.SearchResult, error)) *Storage_Search_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
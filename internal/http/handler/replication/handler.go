@@ -0,0 +1,202 @@
+// Package replication описывает обработчики ведущего, через которые ведомые экземпляры загружают снимок
+// хранилища и получают ленту изменений.
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/models"
+	"st-test/internal/replica"
+	"st-test/internal/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// batchSize количество изменений, забираемых из хранилища за один раз.
+	batchSize = 100
+	// defaultHeartbeat период отправки номера последнего изменения при простое ленты.
+	defaultHeartbeat = 5 * time.Second
+)
+
+// Storage описывает методы хранилища ведущего для репликации.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Storage --with-expecter=true
+type Storage interface {
+	Snapshot(ctx context.Context) (models.Snapshot, error)
+	Changes(after uint64, limit int) ([]models.Change, <-chan struct{}, error)
+	LastChange() uint64
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log       *zap.Logger
+	store     Storage
+	heartbeat time.Duration
+}
+
+// Option настраивает Handler.
+type Option func(h *Handler)
+
+// WithHeartbeat задаёт период отправки номера последнего изменения при простое ленты. По нему ведомые считают
+// отставание и понимают, что ведущий на связи, поэтому период должен быть меньше допустимого молчания ведущего.
+func WithHeartbeat(d time.Duration) Option {
+	return func(h *Handler) {
+		if d > 0 {
+			h.heartbeat = d
+		}
+	}
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, store Storage, opts ...Option) *Handler {
+	h := &Handler{
+		log:       log.Named("replication handler"),
+		store:     store,
+		heartbeat: defaultHeartbeat,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Snapshot метод обработки GET запросов на получение снимка всех объектов хранилища.
+// Изменения после номера снимка ведомый получает через Changes.
+func (h *Handler) Snapshot(w http.ResponseWriter, r *http.Request) {
+	snap, err := h.store.Snapshot(r.Context())
+	if err != nil {
+		h.log.Error("failed get snapshot", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed get snapshot", err.Error()))
+
+		return
+	}
+
+	h.log.Info("snapshot sent", zap.Uint64("seq", snap.Seq), zap.Int("count", len(snap.Items)))
+
+	responder.JSON(w, replica.NewSnapshot(snap))
+}
+
+// Changes метод обработки GET запросов на получение ленты изменений после номера since в формате Server-Sent Events.
+// Изменения отправляются с полным состоянием объекта; в начале ленты и при простое отправляется номер последнего
+// изменения ведущего. Если изменения после since уже вытеснены из журнала, возвращается 410, а если вытеснены
+// во время ленты — событие reset; в обоих случаях ведомому нужно заново загрузить снимок.
+func (h *Handler) Changes(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		responder.JSON(w, httpErr.NewInternalError("failed stream changes", "streaming is not supported"))
+
+		return
+	}
+
+	last, err := strconv.ParseUint(r.URL.Query().Get("since"), 10, 64)
+	if err != nil {
+		responder.JSON(w, httpErr.NewInvalidInput("failed parse since", err.Error()))
+
+		return
+	}
+
+	if _, _, err := h.store.Changes(last, 1); err != nil {
+		if errors.Is(err, storage.ErrChangesExpired) {
+			responder.JSON(w, httpErr.NewGoneError("failed resume changes", err.Error()))
+
+			return
+		}
+
+		responder.JSON(w, httpErr.NewInternalError("failed stream changes", err.Error()))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	h.log.Info("replication stream started", zap.Uint64("after", last))
+
+	if err := h.writeHeartbeat(w); err != nil {
+		return
+	}
+
+	flusher.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		changes, wait, err := h.store.Changes(last, batchSize)
+		if err != nil {
+			h.log.Info("replication stream reset", zap.Uint64("after", last), zap.Error(err))
+
+			_, _ = fmt.Fprintf(w, "event: %s\ndata: %q\n\n", replica.EventReset, err.Error())
+			flusher.Flush()
+
+			return
+		}
+
+		for _, c := range changes {
+			last = c.Seq
+
+			if err := writeEvent(w, replica.EventChange, c.Seq, replica.NewChange(c)); err != nil {
+				h.log.Info("replication stream closed", zap.Error(err))
+
+				return
+			}
+		}
+
+		if len(changes) > 0 {
+			flusher.Flush()
+
+			continue
+		}
+
+		select {
+		case <-r.Context().Done():
+			h.log.Info("replication stream closed by follower")
+
+			return
+		case <-wait:
+		case <-ticker.C:
+			if err := h.writeHeartbeat(w); err != nil {
+				return
+			}
+
+			flusher.Flush()
+		}
+	}
+}
+
+// writeHeartbeat записывает событие с номером последнего изменения хранилища.
+func (h *Handler) writeHeartbeat(w http.ResponseWriter) error {
+	seq := h.store.LastChange()
+
+	return writeEvent(w, replica.EventHeartbeat, seq, replica.Heartbeat{Seq: seq, Time: time.Now()})
+}
+
+// writeEvent записывает событие SSE с номером изменения в поле id.
+func writeEvent(w http.ResponseWriter, event string, seq uint64, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", seq, event, data)
+	if err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"st-test/internal/http/handler/replication/mocks"
+	"st-test/internal/models"
+	"st-test/internal/storage"
+)
+
+func TestHandler_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		giveSnap models.Snapshot
+		giveErr  error
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "success",
+			giveSnap: models.Snapshot{Seq: 7, Items: []models.Item{
+				{ID: 1, Body: []byte(`{}`), Version: 7, Owner: "alice", CreatedAt: ts, UpdatedAt: ts},
+			}},
+			wantCode: http.StatusOK,
+			wantBody: `{"seq":7,"objects":[{"id":1,"body":"e30=","expires_at":"0001-01-01T00:00:00Z","version":7,` +
+				`"owner":"alice","created_at":"2024-01-02T03:04:05Z","updated_at":"2024-01-02T03:04:05Z"}]}`,
+		},
+		{
+			name:     "store error",
+			giveErr:  errors.New("some error"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: "failed get snapshot",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			store.EXPECT().Snapshot(mock.Anything).Return(tc.giveSnap, tc.giveErr).Once()
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/replication/snapshot", http.NoBody)
+
+			NewHandler(zap.NewNop(), store).Snapshot(rr, req)
+
+			assert.Equal(t, tc.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func TestHandler_Changes(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	never := make(<-chan struct{})
+
+	cases := []struct {
+		name         string
+		giveURL      string
+		prepareStore func(store *mocks.Storage)
+		checkResult  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "invalid since",
+			giveURL: "/replication/changes?since=abc",
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), "failed parse since")
+			},
+		},
+		{
+			name:    "expired changes",
+			giveURL: "/replication/changes?since=1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Changes(uint64(1), 1).Once().Return(nil, nil, storage.ErrChangesExpired)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusGone, rr.Code)
+			},
+		},
+		{
+			name:    "stream and reset",
+			giveURL: "/replication/changes?since=5",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Changes(uint64(5), 1).Once().Return(nil, never, nil)
+				store.EXPECT().LastChange().Once().Return(uint64(6))
+				store.EXPECT().Changes(uint64(5), batchSize).Once().Return([]models.Change{
+					{Seq: 6, Type: models.ChangeUpdated, ID: 1, Time: ts, Item: models.Item{ID: 1, Body: []byte(`{}`), Version: 6}},
+				}, never, nil)
+				store.EXPECT().Changes(uint64(6), batchSize).Once().Return(nil, nil, storage.ErrChangesExpired)
+			},
+			checkResult: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+				assert.Contains(t, rr.Body.String(), "id: 6\nevent: heartbeat\ndata: {\"seq\":6,")
+				assert.Contains(t, rr.Body.String(), "id: 6\nevent: change\n"+
+					`data: {"seq":6,"type":"updated","id":1,"time":"2024-01-02T03:04:05Z","object":{"id":1,"body":"e30=",`)
+				assert.Contains(t, rr.Body.String(), "event: reset\n")
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			h := NewHandler(zap.NewNop(), store, WithHeartbeat(time.Hour))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, tc.giveURL, http.NoBody)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			h.Changes(rr, req)
+			tc.checkResult(t, rr)
+		})
+	}
+}
+
+func TestHandler_ChangesHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	store := mocks.NewStorage(t)
+	store.EXPECT().Changes(uint64(3), mock.Anything).Return(nil, make(<-chan struct{}), nil)
+	store.EXPECT().LastChange().Return(uint64(3))
+
+	h := NewHandler(zap.NewNop(), store, WithHeartbeat(10*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/replication/changes?since=3", http.NoBody)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+
+	h.Changes(rr, req)
+
+	assert.GreaterOrEqual(t, strings.Count(rr.Body.String(), "event: heartbeat\n"), 2)
+}
@@ -0,0 +1,175 @@
+// Package scrub описывает административный обработчик проверки объектов по контрольным суммам и карантина.
+package scrub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/models"
+	"st-test/internal/scrubber"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultLimit количество объектов в ответе по умолчанию.
+	defaultLimit = 100
+	// maxLimit максимальное количество объектов в ответе.
+	maxLimit = 1000
+)
+
+// Scrubber описывает методы запуска проверки и просмотра её результатов.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Scrubber --with-expecter=true
+type Scrubber interface {
+	Scrub(ctx context.Context) (models.ScrubReport, error)
+	Last() (models.ScrubReport, error)
+	Quarantined(limit int) ([]models.QuarantinedItem, error)
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log      *zap.Logger
+	scrubber Scrubber
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, scrubber Scrubber) *Handler {
+	return &Handler{
+		log:      log.Named("scrub handler"),
+		scrubber: scrubber,
+	}
+}
+
+// Quarantined объект, убранный в карантин.
+type Quarantined struct {
+	ID            int64     `json:"id"`
+	Source        string    `json:"source"`
+	ObjectID      int       `json:"object_id"`
+	Version       uint64    `json:"version"`
+	Reason        string    `json:"reason"`
+	QuarantinedAt time.Time `json:"quarantined_at"`
+}
+
+func newQuarantined(items []models.QuarantinedItem) []Quarantined {
+	res := make([]Quarantined, 0, len(items))
+
+	for _, i := range items {
+		res = append(res, Quarantined{
+			ID:            i.ID,
+			Source:        i.Source,
+			ObjectID:      i.ObjectID,
+			Version:       i.Version,
+			Reason:        i.Reason,
+			QuarantinedAt: i.QuarantinedAt,
+		})
+	}
+
+	return res
+}
+
+// Report отчёт о проверке. Error содержит ошибки объектов, которые не удалось проверить.
+type Report struct {
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Checked    int           `json:"checked"`
+	Unreadable int           `json:"unreadable"`
+	Corrupted  []Quarantined `json:"corrupted"`
+	Error      string        `json:"error,omitempty"`
+}
+
+func newReport(r models.ScrubReport, err error) Report {
+	report := Report{
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+		Checked:    r.Checked,
+		Unreadable: r.Unreadable,
+		Corrupted:  newQuarantined(r.Corrupted),
+	}
+
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	return report
+}
+
+// ToJSON возвращает отчёт как json.
+func (r Report) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+// QuarantineResponse список объектов в карантине.
+type QuarantineResponse struct {
+	Objects []Quarantined `json:"objects"`
+}
+
+// ToJSON возвращает результат как json.
+func (r QuarantineResponse) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+// Scrub метод обработки POST запросов на запуск проверки всех объектов. Отвечает после завершения проверки.
+func (h *Handler) Scrub(w http.ResponseWriter, r *http.Request) {
+	report, err := h.scrubber.Scrub(r.Context())
+	if err != nil && r.Context().Err() != nil {
+		responder.JSON(w, httpErr.NewInternalError("scrub interrupted", err.Error()))
+
+		return
+	}
+
+	responder.JSON(w, newReport(report, err))
+}
+
+// Last метод обработки GET запросов на получение отчёта о последней проверке.
+func (h *Handler) Last(w http.ResponseWriter, _ *http.Request) {
+	report, err := h.scrubber.Last()
+	if err != nil {
+		if errors.Is(err, scrubber.ErrNoReport) {
+			responder.JSON(w, httpErr.NewNotFoundError(err.Error()))
+
+			return
+		}
+
+		h.log.Error("failed get scrub report", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed get scrub report", err.Error()))
+
+		return
+	}
+
+	responder.JSON(w, newReport(report, nil))
+}
+
+// Quarantine метод обработки GET запросов на просмотр карантина. Параметр limit — количество последних объектов.
+func (h *Handler) Quarantine(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLimit
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			responder.JSON(w, httpErr.NewInvalidInput("failed parse limit", v))
+
+			return
+		}
+	}
+
+	items, err := h.scrubber.Quarantined(min(limit, maxLimit))
+	if err != nil {
+		h.log.Error("failed get quarantine", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed get quarantine", err.Error()))
+
+		return
+	}
+
+	responder.JSON(w, QuarantineResponse{Objects: newQuarantined(items)})
+}
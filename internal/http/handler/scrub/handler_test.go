@@ -0,0 +1,130 @@
+package scrub
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"st-test/internal/http/handler/scrub/mocks"
+	"st-test/internal/models"
+	"st-test/internal/scrubber"
+)
+
+func TestHandler_Scrub(t *testing.T) {
+	t.Parallel()
+
+	s := mocks.NewScrubber(t)
+	s.EXPECT().Scrub(mock.Anything).Return(models.ScrubReport{
+		Checked:    4,
+		Unreadable: 1,
+		Corrupted:  []models.QuarantinedItem{{ID: 1, Source: "storage", ObjectID: 2, Reason: "checksum mismatch"}},
+	}, errors.New("storage key 3: unknown encryption key")).Once()
+
+	rr := httptest.NewRecorder()
+	NewHandler(zap.NewNop(), s).Scrub(rr, httptest.NewRequest(http.MethodPost, "/admin/scrub", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"checked":4`)
+	assert.Contains(t, rr.Body.String(), `"object_id":2`)
+	assert.Contains(t, rr.Body.String(), `"error":"storage key 3: unknown encryption key"`)
+}
+
+func TestHandler_Last(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name            string
+		prepareScrubber func(s *mocks.Scrubber)
+		wantCode        int
+		wantBody        string
+	}{
+		{
+			name: "no report",
+			prepareScrubber: func(s *mocks.Scrubber) {
+				s.EXPECT().Last().Return(models.ScrubReport{}, scrubber.ErrNoReport).Once()
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "report",
+			prepareScrubber: func(s *mocks.Scrubber) {
+				s.EXPECT().Last().Return(models.ScrubReport{Checked: 10}, nil).Once()
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"corrupted":[]`,
+		},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := mocks.NewScrubber(t)
+			tt.prepareScrubber(s)
+
+			rr := httptest.NewRecorder()
+			NewHandler(zap.NewNop(), s).Last(rr, httptest.NewRequest(http.MethodGet, "/admin/scrub", nil))
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestHandler_Quarantine(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name            string
+		giveQuery       string
+		prepareScrubber func(s *mocks.Scrubber)
+		wantCode        int
+		wantBody        string
+	}{
+		{name: "invalid limit", giveQuery: "?limit=x", wantCode: http.StatusBadRequest},
+		{
+			name:      "objects",
+			giveQuery: "?limit=5000",
+			prepareScrubber: func(s *mocks.Scrubber) {
+				s.EXPECT().Quarantined(maxLimit).Return([]models.QuarantinedItem{
+					{ID: 1, Source: "trash", ObjectID: 5, Reason: "checksum mismatch"},
+				}, nil).Once()
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"source":"trash"`,
+		},
+		{
+			name: "internal error",
+			prepareScrubber: func(s *mocks.Scrubber) {
+				s.EXPECT().Quarantined(defaultLimit).Return(nil, errors.New("db is down")).Once()
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: "db is down",
+		},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := mocks.NewScrubber(t)
+			if tt.prepareScrubber != nil {
+				tt.prepareScrubber(s)
+			}
+
+			rr := httptest.NewRecorder()
+			NewHandler(zap.NewNop(), s).Quarantine(rr,
+				httptest.NewRequest(http.MethodGet, "/admin/quarantine"+tt.giveQuery, nil))
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "st-test/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Scrubber is an autogenerated mock type for the Scrubber type
+type Scrubber struct {
+	mock.Mock
+}
+
+type Scrubber_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Scrubber) EXPECT() *Scrubber_Expecter {
+	return &Scrubber_Expecter{mock: &_m.Mock}
+}
+
+// Last provides a mock function with given fields:
+func (_m *Scrubber) Last() (models.ScrubReport, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Last")
+	}
+
+	var r0 models.ScrubReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (models.ScrubReport, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() models.ScrubReport); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(models.ScrubReport)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Scrubber_Last_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Last'
+type Scrubber_Last_Call struct {
+	*mock.Call
+}
+
+// Last is a helper method to define mock.On call
+func (_e *Scrubber_Expecter) Last() *Scrubber_Last_Call {
+	return &Scrubber_Last_Call{Call: _e.mock.On("Last")}
+}
+
+func (_c *Scrubber_Last_Call) Run(run func()) *Scrubber_Last_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Scrubber_Last_Call) Return(_a0 models.ScrubReport, _a1 error) *Scrubber_Last_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Scrubber_Last_Call) RunAndReturn(run func() (models.ScrubReport, error)) *Scrubber_Last_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Quarantined provides a mock function with given fields: limit
+func (_m *Scrubber) Quarantined(limit int) ([]models.QuarantinedItem, error) {
+	ret := _m.Called(limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Quarantined")
+	}
+
+	var r0 []models.QuarantinedItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) ([]models.QuarantinedItem, error)); ok {
+		return rf(limit)
+	}
+	if rf, ok := ret.Get(0).(func(int) []models.QuarantinedItem); ok {
+		r0 = rf(limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.QuarantinedItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Scrubber_Quarantined_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Quarantined'
+type Scrubber_Quarantined_Call struct {
+	*mock.Call
+}
+
+// Quarantined is a helper method to define mock.On call
+//   - limit int
+func (_e *Scrubber_Expecter) Quarantined(limit interface{}) *Scrubber_Quarantined_Call {
+	return &Scrubber_Quarantined_Call{Call: _e.mock.On("Quarantined", limit)}
+}
+
+func (_c *Scrubber_Quarantined_Call) Run(run func(limit int)) *Scrubber_Quarantined_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int))
+	})
+	return _c
+}
+
+func (_c *Scrubber_Quarantined_Call) Return(_a0 []models.QuarantinedItem, _a1 error) *Scrubber_Quarantined_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Scrubber_Quarantined_Call) RunAndReturn(run func(int) ([]models.QuarantinedItem, error)) *Scrubber_Quarantined_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Scrub provides a mock function with given fields: ctx
+func (_m *Scrubber) Scrub(ctx context.Context) (models.ScrubReport, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Scrub")
+	}
+
+	var r0 models.ScrubReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (models.ScrubReport, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) models.ScrubReport); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(models.ScrubReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Scrubber_Scrub_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Scrub'
+type Scrubber_Scrub_Call struct {
+	*mock.Call
+}
+
+// Scrub is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Scrubber_Expecter) Scrub(ctx interface{}) *Scrubber_Scrub_Call {
+	return &Scrubber_Scrub_Call{Call: _e.mock.On("Scrub", ctx)}
+}
+
+func (_c *Scrubber_Scrub_Call) Run(run func(ctx context.Context)) *Scrubber_Scrub_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Scrubber_Scrub_Call) Return(_a0 models.ScrubReport, _a1 error) *Scrubber_Scrub_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Scrubber_Scrub_Call) RunAndReturn(run func(context.Context) (models.ScrubReport, error)) *Scrubber_Scrub_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewScrubber creates a new instance of Scrubber. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewScrubber(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Scrubber {
+	mock := &Scrubber{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,159 @@
+// Package stats описывает административный обработчик просмотра сводных сведений о хранилище.
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/models"
+	"st-test/internal/replica"
+
+	"go.uber.org/zap"
+)
+
+// Storage описывает методы хранилища для получения сводных сведений.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Storage --with-expecter=true
+type Storage interface {
+	Stats(ctx context.Context) (models.StoreStats, error)
+}
+
+// Replica описывает состояние репликации ведомого экземпляра.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Replica --with-expecter=true
+type Replica interface {
+	Status() replica.Status
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log     *zap.Logger
+	store   Storage
+	replica Replica
+}
+
+// Option настраивает Handler.
+type Option func(h *Handler)
+
+// WithReplica добавляет в сводные сведения состояние репликации ведомого экземпляра.
+func WithReplica(r Replica) Option {
+	return func(h *Handler) {
+		h.replica = r
+	}
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, store Storage, opts ...Option) *Handler {
+	h := &Handler{
+		log:   log.Named("stats handler"),
+		store: store,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Bucket корзина гистограммы размеров тел объектов. У последней корзины нет верхней границы.
+type Bucket struct {
+	Le    *int64 `json:"le"`
+	Count int    `json:"count"`
+}
+
+// Response сводные сведения о хранилище. Отсутствующие моменты времени возвращаются как null.
+type Response struct {
+	Objects      int          `json:"objects"`
+	Bytes        int64        `json:"bytes"`
+	Sizes        []Bucket     `json:"sizes"`
+	WithTTL      int          `json:"with_ttl"`
+	NextExpiry   *time.Time   `json:"next_expiry"`
+	Reads        uint64       `json:"reads"`
+	Writes       uint64       `json:"writes"`
+	Misses       uint64       `json:"misses"`
+	LastSnapshot *time.Time   `json:"last_snapshot"`
+	RepoBytes    int64        `json:"repo_bytes"`
+	Replication  *Replication `json:"replication,omitempty"`
+}
+
+// Replication состояние репликации ведомого: номер последнего применённого изменения, последний известный номер
+// изменения ведущего и отставание от него в изменениях и секундах. Возвращается только ведомыми экземплярами.
+type Replication struct {
+	Leader       string     `json:"leader"`
+	Bootstrapped bool       `json:"bootstrapped"`
+	AppliedSeq   uint64     `json:"applied_seq"`
+	LeaderSeq    uint64     `json:"leader_seq"`
+	Lag          uint64     `json:"lag"`
+	LagSeconds   float64    `json:"lag_seconds"`
+	LastContact  *time.Time `json:"last_contact"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// ToJSON возвращает результат как json.
+func (r Response) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+// Stats метод обработки GET запросов на получение сводных сведений о хранилище.
+func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.store.Stats(r.Context())
+	if err != nil {
+		h.log.Error("failed get stats", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed get stats", err.Error()))
+
+		return
+	}
+
+	resp := Response{
+		Objects:      stats.Objects,
+		Bytes:        stats.Bytes,
+		Sizes:        make([]Bucket, 0, len(stats.Sizes)),
+		WithTTL:      stats.WithTTL,
+		NextExpiry:   timeOrNil(stats.NextExpiry),
+		Reads:        stats.Reads,
+		Writes:       stats.Writes,
+		Misses:       stats.Misses,
+		LastSnapshot: timeOrNil(stats.LastSnapshot),
+		RepoBytes:    stats.RepoBytes,
+	}
+
+	for _, b := range stats.Sizes {
+		bucket := Bucket{Count: b.Count}
+		if b.Le != 0 {
+			bucket.Le = &b.Le
+		}
+
+		resp.Sizes = append(resp.Sizes, bucket)
+	}
+
+	if h.replica != nil {
+		st := h.replica.Status()
+
+		resp.Replication = &Replication{
+			Leader:       st.Leader,
+			Bootstrapped: st.Bootstrapped,
+			AppliedSeq:   st.Applied,
+			LeaderSeq:    st.LeaderSeq,
+			Lag:          st.Lag(),
+			LagSeconds:   st.LagTime(time.Now()).Seconds(),
+			LastContact:  timeOrNil(st.LastContact),
+			Error:        st.Error,
+		}
+	}
+
+	responder.JSON(w, resp)
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
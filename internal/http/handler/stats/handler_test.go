@@ -0,0 +1,94 @@
+package stats
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"st-test/internal/http/handler/stats/mocks"
+	"st-test/internal/models"
+	"st-test/internal/replica"
+)
+
+func TestHandler_Stats(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		giveStats models.StoreStats
+		giveErr   error
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name: "success",
+			giveStats: models.StoreStats{
+				Objects:    2,
+				Bytes:      2050,
+				Sizes:      []models.SizeBucket{{Le: 1024, Count: 1}, {Count: 1}},
+				WithTTL:    1,
+				NextExpiry: time.Unix(60, 0).UTC(),
+				Reads:      3,
+				Writes:     2,
+				Misses:     1,
+				RepoBytes:  4096,
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"objects":2,"bytes":2050,"sizes":[{"le":1024,"count":1},{"le":null,"count":1}],"with_ttl":1,` +
+				`"next_expiry":"1970-01-01T00:01:00Z","reads":3,"writes":2,"misses":1,"last_snapshot":null,"repo_bytes":4096}`,
+		},
+		{
+			name:     "store error",
+			giveErr:  errors.New("some error"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: "failed get stats",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			store.EXPECT().Stats(mock.Anything).Return(tc.giveStats, tc.giveErr).Once()
+
+			rr := httptest.NewRecorder()
+			NewHandler(zap.NewNop(), store).Stats(rr, httptest.NewRequest(http.MethodGet, "/admin/stats", nil))
+
+			assert.Equal(t, tc.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func TestHandler_StatsReplica(t *testing.T) {
+	t.Parallel()
+
+	store := mocks.NewStorage(t)
+	store.EXPECT().Stats(mock.Anything).Return(models.StoreStats{}, nil).Once()
+
+	contact := time.Unix(120, 0).UTC()
+
+	r := mocks.NewReplica(t)
+	r.EXPECT().Status().Return(replica.Status{
+		Leader:       "http://leader:8080",
+		Bootstrapped: true,
+		Applied:      10,
+		LeaderSeq:    10,
+		CaughtUp:     contact,
+		LastContact:  contact,
+	}).Once()
+
+	rr := httptest.NewRecorder()
+	NewHandler(zap.NewNop(), store, WithReplica(r)).Stats(rr, httptest.NewRequest(http.MethodGet, "/admin/stats", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"replication":{"leader":"http://leader:8080","bootstrapped":true,`+
+		`"applied_seq":10,"leader_seq":10,"lag":0,"lag_seconds":0,"last_contact":"1970-01-01T00:02:00Z"}`)
+}
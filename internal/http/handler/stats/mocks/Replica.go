@@ -0,0 +1,81 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	replica "st-test/internal/replica"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Replica is an autogenerated mock type for the Replica type
+type Replica struct {
+	mock.Mock
+}
+
+type Replica_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Replica) EXPECT() *Replica_Expecter {
+	return &Replica_Expecter{mock: &_m.Mock}
+}
+
+// Status provides a mock function with given fields:
+func (_m *Replica) Status() replica.Status {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Status")
+	}
+
+	var r0 replica.Status
+	if rf, ok := ret.Get(0).(func() replica.Status); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(replica.Status)
+	}
+
+	return r0
+}
+
+// Replica_Status_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Status'
+type Replica_Status_Call struct {
+	*mock.Call
+}
+
+// Status is a helper method to define mock.On call
+func (_e *Replica_Expecter) Status() *Replica_Status_Call {
+	return &Replica_Status_Call{Call: _e.mock.On("Status")}
+}
+
+func (_c *Replica_Status_Call) Run(run func()) *Replica_Status_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Replica_Status_Call) Return(_a0 replica.Status) *Replica_Status_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Replica_Status_Call) RunAndReturn(run func() replica.Status) *Replica_Status_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewReplica creates a new instance of Replica. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReplica(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Replica {
+	mock := &Replica{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
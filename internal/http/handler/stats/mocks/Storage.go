@@ -0,0 +1,93 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "st-test/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+type Storage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Storage) EXPECT() *Storage_Expecter {
+	return &Storage_Expecter{mock: &_m.Mock}
+}
+
+// Stats provides a mock function with given fields: ctx
+func (_m *Storage) Stats(ctx context.Context) (models.StoreStats, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 models.StoreStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (models.StoreStats, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) models.StoreStats); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(models.StoreStats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_Stats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stats'
+type Storage_Stats_Call struct {
+	*mock.Call
+}
+
+// Stats is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Storage_Expecter) Stats(ctx interface{}) *Storage_Stats_Call {
+	return &Storage_Stats_Call{Call: _e.mock.On("Stats", ctx)}
+}
+
+func (_c *Storage_Stats_Call) Run(run func(ctx context.Context)) *Storage_Stats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Storage_Stats_Call) Return(_a0 models.StoreStats, _a1 error) *Storage_Stats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_Stats_Call) RunAndReturn(run func(context.Context) (models.StoreStats, error)) *Storage_Stats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,158 @@
+// Package trash описывает обработчик корзины удалённых объектов.
+package trash
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/models"
+	"st-test/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// versionHeader заголовок с версией восстановленного объекта.
+	versionHeader = "X-VERSION"
+	// defaultLimit количество объектов в ответе по умолчанию.
+	defaultLimit = 100
+	// maxLimit максимальное количество объектов в ответе.
+	maxLimit = 1000
+)
+
+// Storage описывает методы хранилища для просмотра корзины и восстановления объектов.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Storage --with-expecter=true
+type Storage interface {
+	Trash(ctx context.Context, limit int) ([]models.TrashedItem, error)
+	RestoreObject(ctx context.Context, id int) (models.Item, error)
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log   *zap.Logger
+	store Storage
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, store Storage) *Handler {
+	return &Handler{
+		log:   log.Named("trash handler"),
+		store: store,
+	}
+}
+
+// Object удалённый объект. Тело, которое не является json, в список не включается.
+type Object struct {
+	ID          int             `json:"id"`
+	Version     uint64          `json:"version"`
+	DeletedAt   time.Time       `json:"deleted_at"`
+	PurgeAt     time.Time       `json:"purge_at"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+}
+
+// Response содержимое корзины.
+type Response struct {
+	Objects []Object `json:"objects"`
+}
+
+// ToJSON возвращает результат как json.
+func (r Response) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+// List метод обработки GET запросов на просмотр корзины. Параметр limit — количество последних удалённых объектов.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLimit
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			responder.JSON(w, httpErr.NewInvalidInput("failed parse limit", v))
+
+			return
+		}
+	}
+
+	items, err := h.store.Trash(r.Context(), min(limit, maxLimit))
+	if err != nil {
+		if errors.Is(err, storage.ErrTrashDisabled) {
+			responder.JSON(w, httpErr.NewNotFoundError("trash is disabled"))
+
+			return
+		}
+
+		h.log.Error("failed get trash", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed get trash", err.Error()))
+
+		return
+	}
+
+	resp := Response{Objects: make([]Object, 0, len(items))}
+
+	for _, item := range items {
+		obj := Object{
+			ID:          item.ID,
+			Version:     item.Version,
+			DeletedAt:   item.DeletedAt,
+			PurgeAt:     item.PurgeAt,
+			ContentType: item.ContentType,
+		}
+
+		if json.Valid(item.Body) {
+			obj.Body = item.Body
+		}
+
+		resp.Objects = append(resp.Objects, obj)
+	}
+
+	responder.JSON(w, resp)
+}
+
+// Restore метод обработки POST запросов на восстановление объекта из корзины.
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	objectID, err := strconv.Atoi(chi.URLParam(r, "objectID"))
+	if err != nil {
+		h.log.Error("failed get object id", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed get object id", err.Error()))
+
+		return
+	}
+
+	item, err := h.store.RestoreObject(r.Context(), objectID)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrTrashDisabled):
+			responder.JSON(w, httpErr.NewNotFoundError("trash is disabled"))
+		case errors.Is(err, models.ErrNotFound):
+			responder.JSON(w, httpErr.NewNotFoundError("failed restore object"))
+		case errors.Is(err, storage.ErrAlreadyExists):
+			responder.JSON(w, httpErr.NewConflictError("failed restore object", err.Error()))
+		case errors.Is(err, storage.ErrQuotaExceeded):
+			responder.JSON(w, httpErr.NewQuotaExceededError("failed restore object", err.Error()))
+		default:
+			h.log.Error("failed restore object", zap.Error(err))
+
+			responder.JSON(w, httpErr.NewInternalError("failed restore object", err.Error()))
+		}
+
+		return
+	}
+
+	h.log.Info("restore object successful", zap.Int("id", objectID))
+
+	w.Header().Set(versionHeader, strconv.FormatUint(item.Version, 10))
+	w.WriteHeader(http.StatusNoContent)
+}
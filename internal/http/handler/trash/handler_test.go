@@ -0,0 +1,165 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"st-test/internal/http/handler/trash/mocks"
+	"st-test/internal/models"
+	"st-test/internal/storage"
+)
+
+func TestHandler_List(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name         string
+		giveQuery    string
+		prepareStore func(store *mocks.Storage)
+		wantCode     int
+		wantBody     string
+	}{
+		{name: "invalid limit", giveQuery: "?limit=-1", wantCode: http.StatusBadRequest},
+		{
+			name: "disabled",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Trash(mock.Anything, defaultLimit).Once().Return(nil, storage.ErrTrashDisabled)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: "trash is disabled",
+		},
+		{
+			name: "store error",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Trash(mock.Anything, defaultLimit).Once().Return(nil, errors.New("some error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:      "success",
+			giveQuery: "?limit=5000",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Trash(mock.Anything, maxLimit).Once().Return([]models.TrashedItem{{
+					Item:      models.Item{ID: 1, Body: []byte(`{"some":"body"}`), Version: 2},
+					DeletedAt: time.Unix(0, 0).UTC(),
+					PurgeAt:   time.Unix(60, 0).UTC(),
+				}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"objects":[{"id":1,"version":2,"deleted_at":"1970-01-01T00:00:00Z","purge_at":"1970-01-01T00:01:00Z","body":{"some":"body"}}]}`,
+		},
+		{
+			name: "binary body is omitted",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Trash(mock.Anything, defaultLimit).Once().Return([]models.TrashedItem{{
+					Item:      models.Item{ID: 1, Body: []byte{0x89, 'P', 'N', 'G'}, Version: 2, ContentType: "image/png"},
+					DeletedAt: time.Unix(0, 0).UTC(),
+					PurgeAt:   time.Unix(60, 0).UTC(),
+				}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"purge_at":"1970-01-01T00:01:00Z","content_type":"image/png"}]}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			rr := httptest.NewRecorder()
+			NewHandler(zap.NewNop(), store).List(rr, httptest.NewRequest(http.MethodGet, "/trash"+tc.giveQuery, nil))
+
+			assert.Equal(t, tc.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func TestHandler_Restore(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name         string
+		giveID       string
+		prepareStore func(store *mocks.Storage)
+		wantCode     int
+	}{
+		{name: "invalid id", giveID: "x", wantCode: http.StatusBadRequest},
+		{
+			name:   "not in trash",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().RestoreObject(mock.Anything, 1).Once().Return(models.Item{}, models.ErrNotFound)
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:   "already exists",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().RestoreObject(mock.Anything, 1).Once().Return(models.Item{}, storage.ErrAlreadyExists)
+			},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:   "quota exceeded",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().RestoreObject(mock.Anything, 1).Once().Return(models.Item{}, storage.ErrQuotaExceeded)
+			},
+			wantCode: http.StatusInsufficientStorage,
+		},
+		{
+			name:   "store error",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().RestoreObject(mock.Anything, 1).Once().Return(models.Item{}, errors.New("some error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "restored",
+			giveID: "1",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().RestoreObject(mock.Anything, 1).Once().Return(models.Item{ID: 1, Version: 7}, nil)
+			},
+			wantCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			if tc.prepareStore != nil {
+				tc.prepareStore(store)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("objectID", tc.giveID)
+
+			req := httptest.NewRequest(http.MethodPost, "/trash/"+tc.giveID+":restore", nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			NewHandler(zap.NewNop(), store).Restore(rr, req)
+
+			assert.Equal(t, tc.wantCode, rr.Code)
+		})
+	}
+}
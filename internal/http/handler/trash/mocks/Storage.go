@@ -0,0 +1,153 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "st-test/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+type Storage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Storage) EXPECT() *Storage_Expecter {
+	return &Storage_Expecter{mock: &_m.Mock}
+}
+
+// RestoreObject provides a mock function with given fields: ctx, id
+func (_m *Storage) RestoreObject(ctx context.Context, id int) (models.Item, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreObject")
+	}
+
+	var r0 models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.Item, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.Item); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.Item)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_RestoreObject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreObject'
+type Storage_RestoreObject_Call struct {
+	*mock.Call
+}
+
+// RestoreObject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Storage_Expecter) RestoreObject(ctx interface{}, id interface{}) *Storage_RestoreObject_Call {
+	return &Storage_RestoreObject_Call{Call: _e.mock.On("RestoreObject", ctx, id)}
+}
+
+func (_c *Storage_RestoreObject_Call) Run(run func(ctx context.Context, id int)) *Storage_RestoreObject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Storage_RestoreObject_Call) Return(_a0 models.Item, _a1 error) *Storage_RestoreObject_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_RestoreObject_Call) RunAndReturn(run func(context.Context, int) (models.Item, error)) *Storage_RestoreObject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Trash provides a mock function with given fields: ctx, limit
+func (_m *Storage) Trash(ctx context.Context, limit int) ([]models.TrashedItem, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Trash")
+	}
+
+	var r0 []models.TrashedItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]models.TrashedItem, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []models.TrashedItem); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TrashedItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_Trash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Trash'
+type Storage_Trash_Call struct {
+	*mock.Call
+}
+
+// Trash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+func (_e *Storage_Expecter) Trash(ctx interface{}, limit interface{}) *Storage_Trash_Call {
+	return &Storage_Trash_Call{Call: _e.mock.On("Trash", ctx, limit)}
+}
+
+func (_c *Storage_Trash_Call) Run(run func(ctx context.Context, limit int)) *Storage_Trash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Storage_Trash_Call) Return(_a0 []models.TrashedItem, _a1 error) *Storage_Trash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_Trash_Call) RunAndReturn(run func(context.Context, int) ([]models.TrashedItem, error)) *Storage_Trash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
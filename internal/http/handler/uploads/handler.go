@@ -0,0 +1,234 @@
+// Package uploads описывает обработчик возобновляемых загрузок крупных объектов.
+// Загрузка создаётся для объекта, затем тело дописывается частями с указанием смещения в заголовке Upload-Offset.
+// После обрыва клиент узнаёт принятое смещение и продолжает с него. Завершение проверяет тело и сохраняет объект.
+// Тип содержимого объекта задаётся при создании загрузки заголовком Upload-Content-Type, по умолчанию это json.
+package uploads
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/jsoncheck"
+	"st-test/internal/mediatype"
+	"st-test/internal/models"
+	"st-test/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// offsetHeader заголовок со смещением, с которого дописывается тело, и количеством принятых байт.
+	offsetHeader = "Upload-Offset"
+	// contentTypeHeader заголовок с типом содержимого объекта. Content-Type самих запросов к загрузке
+	// относится к их телу, поэтому тип объекта передаётся отдельно.
+	contentTypeHeader = "Upload-Content-Type"
+	// clientHeader заголовок с идентификатором клиента, записывающего объект.
+	clientHeader = "X-CLIENT-ID"
+)
+
+// Storage описывает методы хранилища крупных объектов для загрузки частями.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Storage --with-expecter=true
+type Storage interface {
+	CreateUpload(ctx context.Context, id int, owner, contentType string) (models.Upload, error)
+	Upload(ctx context.Context, uploadID string) (models.Upload, error)
+	Append(ctx context.Context, uploadID string, offset int64, body io.Reader) (models.Upload, error)
+	Complete(ctx context.Context, uploadID string) (models.LargeObject, bool, error)
+	Abort(ctx context.Context, uploadID string) error
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log   *zap.Logger
+	store Storage
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, store Storage) *Handler {
+	return &Handler{
+		log:   log.Named("uploads handler"),
+		store: store,
+	}
+}
+
+// Upload состояние загрузки.
+type Upload struct {
+	ID          string    `json:"id"`
+	ObjectID    int       `json:"object_id"`
+	Offset      int64     `json:"offset"`
+	ContentType string    `json:"content_type"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	status      int
+}
+
+func newUpload(up models.Upload) Upload {
+	return Upload{
+		ID:          up.ID,
+		ObjectID:    up.ObjectID,
+		Offset:      up.Offset,
+		ContentType: up.ContentType,
+		CreatedAt:   up.CreatedAt,
+		UpdatedAt:   up.UpdatedAt,
+	}
+}
+
+// ToJSON возвращает загрузку как json.
+func (u Upload) ToJSON() ([]byte, error) {
+	return json.Marshal(u) //nolint:wrapcheck
+}
+
+// StatusCode возвращает статус код.
+func (u Upload) StatusCode() int {
+	return u.status
+}
+
+// Create метод обработки POST запросов на создание загрузки объекта. Недопустимый тип содержимого объекта
+// отклоняется с 415.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	objectID, err := strconv.Atoi(chi.URLParam(r, "objectID"))
+	if err != nil {
+		h.log.Error("failed get object id", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed get object id", err.Error()))
+
+		return
+	}
+
+	contentType := r.Header.Get(contentTypeHeader)
+	if contentType == "" {
+		contentType = mediatype.JSON
+	}
+
+	up, err := h.store.CreateUpload(r.Context(), objectID, r.Header.Get(clientHeader), contentType)
+	if err != nil {
+		switch {
+		case errors.Is(err, mediatype.ErrUnsupported):
+			responder.JSON(w, httpErr.NewUnsupportedMediaTypeError("failed create upload", err.Error()))
+		case errors.Is(err, mediatype.ErrInvalid):
+			responder.JSON(w, httpErr.NewInvalidInput("failed create upload", err.Error()))
+		default:
+			h.log.Error("failed create upload", zap.Error(err))
+
+			responder.JSON(w, httpErr.NewInternalError("failed create upload", err.Error()))
+		}
+
+		return
+	}
+
+	resp := newUpload(up)
+	resp.status = http.StatusCreated
+
+	w.Header().Set("Location", "/uploads/"+up.ID)
+	w.Header().Set(offsetHeader, "0")
+	responder.JSON(w, resp)
+}
+
+// Get метод обработки GET запросов на получение состояния загрузки.
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	up, err := h.store.Upload(r.Context(), chi.URLParam(r, "uploadID"))
+	if err != nil {
+		h.uploadError(w, "failed get upload", err)
+
+		return
+	}
+
+	w.Header().Set(offsetHeader, strconv.FormatInt(up.Offset, 10))
+	responder.JSON(w, newUpload(up))
+}
+
+// Append метод обработки PATCH запросов на дописывание тела загрузки с места, указанного в заголовке Upload-Offset.
+// Если смещение не совпадает с количеством принятых байт, возвращается 409 с принятым смещением в заголовке;
+// принятое при обрыве соединения сохраняется.
+func (h *Handler) Append(w http.ResponseWriter, r *http.Request) {
+	offset, err := strconv.ParseInt(r.Header.Get(offsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		responder.JSON(w, httpErr.NewInvalidInput("failed parse "+offsetHeader,
+			offsetHeader+" must be a non-negative number of bytes"))
+
+		return
+	}
+
+	defer r.Body.Close()
+
+	up, err := h.store.Append(r.Context(), chi.URLParam(r, "uploadID"), offset, r.Body)
+	if err != nil {
+		// при несовпадении смещения клиенту сообщается, с какого места продолжить.
+		if errors.Is(err, models.ErrOffsetMismatch) {
+			w.Header().Set(offsetHeader, strconv.FormatInt(up.Offset, 10))
+		}
+
+		h.uploadError(w, "failed append to upload", err)
+
+		return
+	}
+
+	w.Header().Set(offsetHeader, strconv.FormatInt(up.Offset, 10))
+	responder.JSON(w, newUpload(up))
+}
+
+// Complete метод обработки POST запросов на завершение загрузки. Тело проверяется так же, как при сохранении
+// объекта; при успехе возвращается 200 для нового объекта и 204 для обновлённого.
+func (h *Handler) Complete(w http.ResponseWriter, r *http.Request) {
+	obj, created, err := h.store.Complete(r.Context(), chi.URLParam(r, "uploadID"))
+	if err != nil {
+		switch {
+		case errors.Is(err, jsoncheck.ErrInvalid):
+			responder.JSON(w, httpErr.NewInvalidInput("failed check body on json", err.Error()))
+		case errors.Is(err, jsoncheck.ErrTooDeep), errors.Is(err, jsoncheck.ErrTooManyKeys):
+			responder.JSON(w, httpErr.NewUnprocessableError("failed check body on json", err.Error()))
+		default:
+			h.uploadError(w, "failed complete upload", err)
+		}
+
+		return
+	}
+
+	h.log.Info("upload completed", zap.Int("id", obj.ID), zap.Int64("size", obj.Size))
+
+	if !created {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Abort метод обработки DELETE запросов на отмену загрузки.
+func (h *Handler) Abort(w http.ResponseWriter, r *http.Request) {
+	err := h.store.Abort(r.Context(), chi.URLParam(r, "uploadID"))
+	if err != nil {
+		h.uploadError(w, "failed abort upload", err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// uploadError отвечает на ошибку хранилища при работе с загрузкой.
+func (h *Handler) uploadError(w http.ResponseWriter, title string, err error) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		responder.JSON(w, httpErr.NewNotFoundError(title))
+	case errors.Is(err, models.ErrOffsetMismatch):
+		responder.JSON(w, httpErr.NewConflictError(title, err.Error()))
+	case errors.Is(err, models.ErrTooLarge):
+		responder.JSON(w, httpErr.NewTooLargeError(title, err.Error()))
+	case errors.Is(err, storage.ErrQuotaExceeded):
+		responder.JSON(w, httpErr.NewQuotaExceededError(title, err.Error()))
+	default:
+		h.log.Error(title, zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError(title, err.Error()))
+	}
+}
@@ -0,0 +1,245 @@
+package uploads
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"st-test/internal/http/handler/uploads/mocks"
+	"st-test/internal/jsoncheck"
+	"st-test/internal/mediatype"
+	"st-test/internal/models"
+)
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	up := models.Upload{
+		ID: "abc", ObjectID: 1, Offset: 10, ContentType: "application/json",
+		CreatedAt: time.Unix(0, 0).UTC(), UpdatedAt: time.Unix(60, 0).UTC(),
+	}
+
+	// request создаёт запрос с параметрами пути params.
+	request := func(method, body string, params map[string]string) *http.Request {
+		rctx := chi.NewRouteContext()
+		for k, v := range params {
+			rctx.URLParams.Add(k, v)
+		}
+
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	upload := map[string]string{"uploadID": "abc"}
+
+	cases := []struct {
+		name         string
+		prepareStore func(store *mocks.Storage)
+		giveRequest  func() *http.Request
+		handle       func(h *Handler) http.HandlerFunc
+		wantCode     int
+		wantBody     string
+		wantOffset   string
+	}{
+		{
+			name: "create",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().CreateUpload(mock.Anything, 1, "importer", "application/json").Once().
+					Return(models.Upload{ID: "abc", ObjectID: 1, ContentType: "application/json"}, nil)
+			},
+			giveRequest: func() *http.Request {
+				req := request(http.MethodPost, "", map[string]string{"objectID": "1"})
+				req.Header.Set(clientHeader, "importer")
+
+				return req
+			},
+			handle:     func(h *Handler) http.HandlerFunc { return h.Create },
+			wantCode:   http.StatusCreated,
+			wantBody:   `"id":"abc","object_id":1,"offset":0,"content_type":"application/json"`,
+			wantOffset: "0",
+		},
+		{
+			name: "create with content type",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().CreateUpload(mock.Anything, 1, "", "image/png").Once().
+					Return(models.Upload{ID: "abc", ObjectID: 1, ContentType: "image/png"}, nil)
+			},
+			giveRequest: func() *http.Request {
+				req := request(http.MethodPost, "", map[string]string{"objectID": "1"})
+				req.Header.Set(contentTypeHeader, "image/png")
+
+				return req
+			},
+			handle:     func(h *Handler) http.HandlerFunc { return h.Create },
+			wantCode:   http.StatusCreated,
+			wantBody:   `"content_type":"image/png"`,
+			wantOffset: "0",
+		},
+		{
+			name: "create with unsupported content type",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().CreateUpload(mock.Anything, 1, "", "video/mp4").Once().
+					Return(models.Upload{}, fmt.Errorf("%w: video/mp4", mediatype.ErrUnsupported))
+			},
+			giveRequest: func() *http.Request {
+				req := request(http.MethodPost, "", map[string]string{"objectID": "1"})
+				req.Header.Set(contentTypeHeader, "video/mp4")
+
+				return req
+			},
+			handle:   func(h *Handler) http.HandlerFunc { return h.Create },
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "create with invalid object id",
+			giveRequest: func() *http.Request { return request(http.MethodPost, "", map[string]string{"objectID": "x"}) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.Create },
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name: "get",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Upload(mock.Anything, "abc").Once().Return(up, nil)
+			},
+			giveRequest: func() *http.Request { return request(http.MethodGet, "", upload) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.Get },
+			wantCode:    http.StatusOK,
+			wantBody: `{"id":"abc","object_id":1,"offset":10,"content_type":"application/json",` +
+				`"created_at":"1970-01-01T00:00:00Z","updated_at":"1970-01-01T00:01:00Z"}`,
+			wantOffset: "10",
+		},
+		{
+			name: "get missing",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Upload(mock.Anything, "abc").Once().Return(models.Upload{}, models.ErrNotFound)
+			},
+			giveRequest: func() *http.Request { return request(http.MethodGet, "", upload) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.Get },
+			wantCode:    http.StatusNotFound,
+		},
+		{
+			name: "append",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Append(mock.Anything, "abc", int64(10), mock.Anything).Once().Return(models.Upload{Offset: 15}, nil)
+			},
+			giveRequest: func() *http.Request {
+				req := request(http.MethodPatch, `{"a":`, upload)
+				req.Header.Set(offsetHeader, "10")
+
+				return req
+			},
+			handle:     func(h *Handler) http.HandlerFunc { return h.Append },
+			wantCode:   http.StatusOK,
+			wantOffset: "15",
+		},
+		{
+			name:        "append without offset",
+			giveRequest: func() *http.Request { return request(http.MethodPatch, `{}`, upload) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.Append },
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name: "append at wrong offset",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Append(mock.Anything, "abc", int64(0), mock.Anything).Once().Return(up, models.ErrOffsetMismatch)
+			},
+			giveRequest: func() *http.Request {
+				req := request(http.MethodPatch, `{}`, upload)
+				req.Header.Set(offsetHeader, "0")
+
+				return req
+			},
+			handle:     func(h *Handler) http.HandlerFunc { return h.Append },
+			wantCode:   http.StatusConflict,
+			wantOffset: "10",
+		},
+		{
+			name: "append too much",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Append(mock.Anything, "abc", int64(0), mock.Anything).Once().Return(up, models.ErrTooLarge)
+			},
+			giveRequest: func() *http.Request {
+				req := request(http.MethodPatch, `{}`, upload)
+				req.Header.Set(offsetHeader, "0")
+
+				return req
+			},
+			handle:   func(h *Handler) http.HandlerFunc { return h.Append },
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "complete new object",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Complete(mock.Anything, "abc").Once().Return(models.LargeObject{ID: 1}, true, nil)
+			},
+			giveRequest: func() *http.Request { return request(http.MethodPost, "", upload) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.Complete },
+			wantCode:    http.StatusOK,
+		},
+		{
+			name: "complete existing object",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Complete(mock.Anything, "abc").Once().Return(models.LargeObject{ID: 1}, false, nil)
+			},
+			giveRequest: func() *http.Request { return request(http.MethodPost, "", upload) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.Complete },
+			wantCode:    http.StatusNoContent,
+		},
+		{
+			name: "complete invalid body",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Complete(mock.Anything, "abc").Once().Return(models.LargeObject{}, false, jsoncheck.ErrInvalid)
+			},
+			giveRequest: func() *http.Request { return request(http.MethodPost, "", upload) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.Complete },
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name: "complete too deep body",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Complete(mock.Anything, "abc").Once().Return(models.LargeObject{}, false, jsoncheck.ErrTooDeep)
+			},
+			giveRequest: func() *http.Request { return request(http.MethodPost, "", upload) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.Complete },
+			wantCode:    http.StatusUnprocessableEntity,
+		},
+		{
+			name: "abort",
+			prepareStore: func(store *mocks.Storage) {
+				store.EXPECT().Abort(mock.Anything, "abc").Once().Return(nil)
+			},
+			giveRequest: func() *http.Request { return request(http.MethodDelete, "", upload) },
+			handle:      func(h *Handler) http.HandlerFunc { return h.Abort },
+			wantCode:    http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			if tt.prepareStore != nil {
+				tt.prepareStore(store)
+			}
+
+			rr := httptest.NewRecorder()
+			tt.handle(NewHandler(zap.NewNop(), store))(rr, tt.giveRequest())
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+			assert.Equal(t, tt.wantOffset, rr.Header().Get(offsetHeader))
+		})
+	}
+}
@@ -0,0 +1,325 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "st-test/internal/models"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+type Storage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Storage) EXPECT() *Storage_Expecter {
+	return &Storage_Expecter{mock: &_m.Mock}
+}
+
+// Abort provides a mock function with given fields: ctx, uploadID
+func (_m *Storage) Abort(ctx context.Context, uploadID string) error {
+	ret := _m.Called(ctx, uploadID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Abort")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, uploadID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_Abort_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Abort'
+type Storage_Abort_Call struct {
+	*mock.Call
+}
+
+// Abort is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uploadID string
+func (_e *Storage_Expecter) Abort(ctx interface{}, uploadID interface{}) *Storage_Abort_Call {
+	return &Storage_Abort_Call{Call: _e.mock.On("Abort", ctx, uploadID)}
+}
+
+func (_c *Storage_Abort_Call) Run(run func(ctx context.Context, uploadID string)) *Storage_Abort_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Storage_Abort_Call) Return(_a0 error) *Storage_Abort_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_Abort_Call) RunAndReturn(run func(context.Context, string) error) *Storage_Abort_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Append provides a mock function with given fields: ctx, uploadID, offset, body
+func (_m *Storage) Append(ctx context.Context, uploadID string, offset int64, body io.Reader) (models.Upload, error) {
+	ret := _m.Called(ctx, uploadID, offset, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Append")
+	}
+
+	var r0 models.Upload
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, io.Reader) (models.Upload, error)); ok {
+		return rf(ctx, uploadID, offset, body)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, io.Reader) models.Upload); ok {
+		r0 = rf(ctx, uploadID, offset, body)
+	} else {
+		r0 = ret.Get(0).(models.Upload)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, io.Reader) error); ok {
+		r1 = rf(ctx, uploadID, offset, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_Append_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Append'
+type Storage_Append_Call struct {
+	*mock.Call
+}
+
+// Append is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uploadID string
+//   - offset int64
+//   - body io.Reader
+func (_e *Storage_Expecter) Append(ctx interface{}, uploadID interface{}, offset interface{}, body interface{}) *Storage_Append_Call {
+	return &Storage_Append_Call{Call: _e.mock.On("Append", ctx, uploadID, offset, body)}
+}
+
+func (_c *Storage_Append_Call) Run(run func(ctx context.Context, uploadID string, offset int64, body io.Reader)) *Storage_Append_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(io.Reader))
+	})
+	return _c
+}
+
+func (_c *Storage_Append_Call) Return(_a0 models.Upload, _a1 error) *Storage_Append_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_Append_Call) RunAndReturn(run func(context.Context, string, int64, io.Reader) (models.Upload, error)) *Storage_Append_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Complete provides a mock function with given fields: ctx, uploadID
+func (_m *Storage) Complete(ctx context.Context, uploadID string) (models.LargeObject, bool, error) {
+	ret := _m.Called(ctx, uploadID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Complete")
+	}
+
+	var r0 models.LargeObject
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.LargeObject, bool, error)); ok {
+		return rf(ctx, uploadID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.LargeObject); ok {
+		r0 = rf(ctx, uploadID)
+	} else {
+		r0 = ret.Get(0).(models.LargeObject)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) bool); ok {
+		r1 = rf(ctx, uploadID)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, uploadID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Storage_Complete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Complete'
+type Storage_Complete_Call struct {
+	*mock.Call
+}
+
+// Complete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uploadID string
+func (_e *Storage_Expecter) Complete(ctx interface{}, uploadID interface{}) *Storage_Complete_Call {
+	return &Storage_Complete_Call{Call: _e.mock.On("Complete", ctx, uploadID)}
+}
+
+func (_c *Storage_Complete_Call) Run(run func(ctx context.Context, uploadID string)) *Storage_Complete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Storage_Complete_Call) Return(_a0 models.LargeObject, _a1 bool, _a2 error) *Storage_Complete_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Storage_Complete_Call) RunAndReturn(run func(context.Context, string) (models.LargeObject, bool, error)) *Storage_Complete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateUpload provides a mock function with given fields: ctx, id, owner, contentType
+func (_m *Storage) CreateUpload(ctx context.Context, id int, owner string, contentType string) (models.Upload, error) {
+	ret := _m.Called(ctx, id, owner, contentType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUpload")
+	}
+
+	var r0 models.Upload
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) (models.Upload, error)); ok {
+		return rf(ctx, id, owner, contentType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) models.Upload); ok {
+		r0 = rf(ctx, id, owner, contentType)
+	} else {
+		r0 = ret.Get(0).(models.Upload)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, string) error); ok {
+		r1 = rf(ctx, id, owner, contentType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_CreateUpload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateUpload'
+type Storage_CreateUpload_Call struct {
+	*mock.Call
+}
+
+// CreateUpload is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - owner string
+//   - contentType string
+func (_e *Storage_Expecter) CreateUpload(ctx interface{}, id interface{}, owner interface{}, contentType interface{}) *Storage_CreateUpload_Call {
+	return &Storage_CreateUpload_Call{Call: _e.mock.On("CreateUpload", ctx, id, owner, contentType)}
+}
+
+func (_c *Storage_CreateUpload_Call) Run(run func(ctx context.Context, id int, owner string, contentType string)) *Storage_CreateUpload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *Storage_CreateUpload_Call) Return(_a0 models.Upload, _a1 error) *Storage_CreateUpload_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_CreateUpload_Call) RunAndReturn(run func(context.Context, int, string, string) (models.Upload, error)) *Storage_CreateUpload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upload provides a mock function with given fields: ctx, uploadID
+func (_m *Storage) Upload(ctx context.Context, uploadID string) (models.Upload, error) {
+	ret := _m.Called(ctx, uploadID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upload")
+	}
+
+	var r0 models.Upload
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Upload, error)); ok {
+		return rf(ctx, uploadID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Upload); ok {
+		r0 = rf(ctx, uploadID)
+	} else {
+		r0 = ret.Get(0).(models.Upload)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uploadID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_Upload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upload'
+type Storage_Upload_Call struct {
+	*mock.Call
+}
+
+// Upload is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uploadID string
+func (_e *Storage_Expecter) Upload(ctx interface{}, uploadID interface{}) *Storage_Upload_Call {
+	return &Storage_Upload_Call{Call: _e.mock.On("Upload", ctx, uploadID)}
+}
+
+func (_c *Storage_Upload_Call) Run(run func(ctx context.Context, uploadID string)) *Storage_Upload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Storage_Upload_Call) Return(_a0 models.Upload, _a1 error) *Storage_Upload_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_Upload_Call) RunAndReturn(run func(context.Context, string) (models.Upload, error)) *Storage_Upload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,99 @@
+// Package usage описывает административный обработчик просмотра объёма, занимаемого клиентами, и их квот.
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// Storage описывает методы хранилища для получения объёма, занимаемого клиентами.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Storage --with-expecter=true
+type Storage interface {
+	Usage(ctx context.Context) []models.Usage
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log   *zap.Logger
+	store Storage
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, store Storage) *Handler {
+	return &Handler{
+		log:   log.Named("usage handler"),
+		store: store,
+	}
+}
+
+// Client объём, занимаемый объектами клиента, и его квота. Нулевое ограничение означает его отсутствие.
+// SharedQuota означает, что квота общая для всех клиентов без отдельной квоты.
+type Client struct {
+	Client      string `json:"client"`
+	Objects     int    `json:"objects"`
+	Bytes       int64  `json:"bytes"`
+	MaxObjects  int    `json:"max_objects"`
+	MaxBytes    int64  `json:"max_bytes"`
+	SharedQuota bool   `json:"shared_quota"`
+}
+
+// Response объём, занимаемый клиентами.
+type Response struct {
+	Clients []Client `json:"clients"`
+}
+
+// ToJSON возвращает результат как json.
+func (r Response) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+func newClient(u models.Usage) Client {
+	return Client{
+		Client:      u.Client,
+		Objects:     u.Objects,
+		Bytes:       u.Bytes,
+		MaxObjects:  u.Quota.MaxObjects,
+		MaxBytes:    u.Quota.MaxBytes,
+		SharedQuota: u.Shared,
+	}
+}
+
+// Usage метод обработки GET запросов на получение объёма, занимаемого клиентами.
+// С параметром client возвращается только указанный клиент; клиент без объектов и отдельной квоты не найден.
+func (h *Handler) Usage(w http.ResponseWriter, r *http.Request) {
+	usage := h.store.Usage(r.Context())
+
+	resp := Response{Clients: make([]Client, 0, len(usage))}
+
+	if !r.URL.Query().Has("client") {
+		for _, u := range usage {
+			resp.Clients = append(resp.Clients, newClient(u))
+		}
+
+		responder.JSON(w, resp)
+
+		return
+	}
+
+	client := r.URL.Query().Get("client")
+
+	for _, u := range usage {
+		if u.Client == client {
+			resp.Clients = append(resp.Clients, newClient(u))
+
+			responder.JSON(w, resp)
+
+			return
+		}
+	}
+
+	responder.JSON(w, httpErr.NewNotFoundError("client has no objects"))
+}
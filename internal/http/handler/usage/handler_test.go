@@ -0,0 +1,65 @@
+package usage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"st-test/internal/http/handler/usage/mocks"
+	"st-test/internal/models"
+)
+
+func TestHandler_Usage(t *testing.T) {
+	t.Parallel()
+
+	usage := []models.Usage{
+		{Client: "", Objects: 1, Bytes: 2, Quota: models.Quota{MaxObjects: 10}, Shared: true},
+		{Client: "importer", Objects: 3, Bytes: 40, Quota: models.Quota{MaxBytes: 100}},
+	}
+
+	cases := []struct {
+		name      string
+		giveQuery string
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:     "all clients",
+			wantCode: http.StatusOK,
+			wantBody: `{"clients":[{"client":"","objects":1,"bytes":2,"max_objects":10,"max_bytes":0,"shared_quota":true},` +
+				`{"client":"importer","objects":3,"bytes":40,"max_objects":0,"max_bytes":100,"shared_quota":false}]}`,
+		},
+		{
+			name:      "anonymous client",
+			giveQuery: "?client=",
+			wantCode:  http.StatusOK,
+			wantBody:  `{"clients":[{"client":"","objects":1,"bytes":2,"max_objects":10,"max_bytes":0,"shared_quota":true}]}`,
+		},
+		{
+			name:      "unknown client",
+			giveQuery: "?client=other",
+			wantCode:  http.StatusNotFound,
+			wantBody:  "client has no objects",
+		},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := mocks.NewStorage(t)
+			store.EXPECT().Usage(mock.Anything).Return(usage).Once()
+
+			rr := httptest.NewRecorder()
+			NewHandler(zap.NewNop(), store).Usage(rr, httptest.NewRequest(http.MethodGet, "/admin/usage"+tt.giveQuery, nil))
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+		})
+	}
+}
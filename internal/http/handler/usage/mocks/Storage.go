@@ -0,0 +1,85 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "st-test/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+type Storage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Storage) EXPECT() *Storage_Expecter {
+	return &Storage_Expecter{mock: &_m.Mock}
+}
+
+// Usage provides a mock function with given fields: ctx
+func (_m *Storage) Usage(ctx context.Context) []models.Usage {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Usage")
+	}
+
+	var r0 []models.Usage
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Usage); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Usage)
+		}
+	}
+
+	return r0
+}
+
+// Storage_Usage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Usage'
+type Storage_Usage_Call struct {
+	*mock.Call
+}
+
+// Usage is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Storage_Expecter) Usage(ctx interface{}) *Storage_Usage_Call {
+	return &Storage_Usage_Call{Call: _e.mock.On("Usage", ctx)}
+}
+
+func (_c *Storage_Usage_Call) Run(run func(ctx context.Context)) *Storage_Usage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Storage_Usage_Call) Return(_a0 []models.Usage) *Storage_Usage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_Usage_Call) RunAndReturn(run func(context.Context) []models.Usage) *Storage_Usage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,268 @@
+// Package webhooks описывает административный обработчик подписок на изменения объектов и очереди их доставки.
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpErr "st-test/internal/http/handler/handlererrors"
+	"st-test/internal/http/handler/responder"
+	"st-test/internal/models"
+	"st-test/internal/webhook"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultLimit количество событий в ответе по умолчанию.
+	defaultLimit = 100
+	// maxLimit максимальное количество событий в ответе.
+	maxLimit = 1000
+)
+
+// Dispatcher описывает методы управления подписками и просмотра очереди доставки.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Dispatcher --with-expecter=true
+type Dispatcher interface {
+	Subscribe(w models.Webhook) (models.Webhook, error)
+	Unsubscribe(id int64) error
+	Webhooks() []models.Webhook
+	Deliveries(status models.DeliveryStatus, limit int) ([]models.Delivery, error)
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log        *zap.Logger
+	dispatcher Dispatcher
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, dispatcher Dispatcher) *Handler {
+	return &Handler{
+		log:        log.Named("webhooks handler"),
+		dispatcher: dispatcher,
+	}
+}
+
+// Request тело запроса на создание подписки.
+type Request struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Keys   []string `json:"keys"`
+	Secret string   `json:"secret"`
+}
+
+// Webhook подписка. Секрет возвращается только при создании подписки.
+type Webhook struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Keys      []string  `json:"keys"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	status    int
+}
+
+func newWebhook(w models.Webhook) Webhook {
+	events := make([]string, 0, len(w.Events))
+	for _, e := range w.Events {
+		events = append(events, string(e))
+	}
+
+	keys := w.Keys
+	if keys == nil {
+		keys = make([]string, 0)
+	}
+
+	return Webhook{ID: w.ID, URL: w.URL, Events: events, Keys: keys, CreatedAt: w.CreatedAt}
+}
+
+// ToJSON возвращает подписку как json.
+func (w Webhook) ToJSON() ([]byte, error) {
+	return json.Marshal(w) //nolint:wrapcheck
+}
+
+// StatusCode возвращает статус код.
+func (w Webhook) StatusCode() int {
+	return w.status
+}
+
+// WebhooksResponse список подписок.
+type WebhooksResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// ToJSON возвращает результат как json.
+func (r WebhooksResponse) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+// Delivery событие в очереди доставки.
+type Delivery struct {
+	ID            int64           `json:"id"`
+	WebhookID     int64           `json:"webhook_id"`
+	Event         string          `json:"event"`
+	ObjectID      int             `json:"object_id"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// DeliveriesResponse список событий в очереди доставки.
+type DeliveriesResponse struct {
+	Deliveries []Delivery `json:"deliveries"`
+}
+
+// ToJSON возвращает результат как json.
+func (r DeliveriesResponse) ToJSON() ([]byte, error) {
+	return json.Marshal(r) //nolint:wrapcheck
+}
+
+// Create метод обработки POST запросов на создание подписки.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req Request
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.log.Error("failed decode request", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInvalidInput("failed decode request", err.Error()))
+
+		return
+	}
+
+	events := make([]models.ChangeType, 0, len(req.Events))
+	for _, e := range req.Events {
+		events = append(events, models.ChangeType(e))
+	}
+
+	created, err := h.dispatcher.Subscribe(models.Webhook{
+		URL:    req.URL,
+		Events: events,
+		Keys:   req.Keys,
+		Secret: req.Secret,
+	})
+	if err != nil {
+		if errors.Is(err, webhook.ErrInvalidWebhook) {
+			responder.JSON(w, httpErr.NewInvalidInput("failed create webhook", err.Error()))
+
+			return
+		}
+
+		h.log.Error("failed create webhook", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed create webhook", err.Error()))
+
+		return
+	}
+
+	resp := newWebhook(created)
+	resp.Secret = created.Secret
+	resp.status = http.StatusCreated
+
+	responder.JSON(w, resp)
+}
+
+// List метод обработки GET запросов на получение подписок.
+func (h *Handler) List(w http.ResponseWriter, _ *http.Request) {
+	webhooks := h.dispatcher.Webhooks()
+
+	resp := WebhooksResponse{Webhooks: make([]Webhook, 0, len(webhooks))}
+	for _, wh := range webhooks {
+		resp.Webhooks = append(resp.Webhooks, newWebhook(wh))
+	}
+
+	responder.JSON(w, resp)
+}
+
+// Delete метод обработки DELETE запросов на удаление подписки вместе с её недоставленными событиями.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil {
+		responder.JSON(w, httpErr.NewInvalidInput("failed get webhook id", err.Error()))
+
+		return
+	}
+
+	err = h.dispatcher.Unsubscribe(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			responder.JSON(w, httpErr.NewNotFoundError("failed delete webhook"))
+
+			return
+		}
+
+		h.log.Error("failed delete webhook", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed delete webhook", err.Error()))
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries метод обработки GET запросов на просмотр очереди доставки.
+// Параметры запроса: status — pending или failed, limit — количество последних событий.
+func (h *Handler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+
+	status := models.DeliveryStatus(values.Get("status"))
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryFailed:
+	default:
+		responder.JSON(w, httpErr.NewInvalidInput("failed parse status", string(status)))
+
+		return
+	}
+
+	limit := defaultLimit
+
+	if v := values.Get("limit"); v != "" {
+		var err error
+
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			responder.JSON(w, httpErr.NewInvalidInput("failed parse limit", v))
+
+			return
+		}
+	}
+
+	deliveries, err := h.dispatcher.Deliveries(status, min(limit, maxLimit))
+	if err != nil {
+		h.log.Error("failed get deliveries", zap.Error(err))
+
+		responder.JSON(w, httpErr.NewInternalError("failed get deliveries", err.Error()))
+
+		return
+	}
+
+	resp := DeliveriesResponse{Deliveries: make([]Delivery, 0, len(deliveries))}
+
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, Delivery{
+			ID:            d.ID,
+			WebhookID:     d.WebhookID,
+			Event:         string(d.Event),
+			ObjectID:      d.ObjectID,
+			Status:        string(d.Status),
+			Attempts:      d.Attempts,
+			NextAttemptAt: d.NextAttemptAt,
+			LastError:     d.LastError,
+			CreatedAt:     d.CreatedAt,
+			UpdatedAt:     d.UpdatedAt,
+			Payload:       d.Payload,
+		})
+	}
+
+	responder.JSON(w, resp)
+}
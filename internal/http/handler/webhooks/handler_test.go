@@ -0,0 +1,222 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"st-test/internal/http/handler/webhooks/mocks"
+	"st-test/internal/models"
+	"st-test/internal/webhook"
+)
+
+func TestHandler_Create(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name              string
+		giveBody          string
+		prepareDispatcher func(d *mocks.Dispatcher)
+		wantCode          int
+		wantBody          string
+	}{
+		{
+			name:     "invalid body",
+			giveBody: `{`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "failed decode request",
+		},
+		{
+			name:     "invalid webhook",
+			giveBody: `{"url":"ftp://x"}`,
+			prepareDispatcher: func(d *mocks.Dispatcher) {
+				d.EXPECT().Subscribe(mock.Anything).
+					Return(models.Webhook{}, fmt.Errorf("%w: bad url", webhook.ErrInvalidWebhook)).Once()
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: "bad url",
+		},
+		{
+			name:     "internal error",
+			giveBody: `{"url":"http://x"}`,
+			prepareDispatcher: func(d *mocks.Dispatcher) {
+				d.EXPECT().Subscribe(mock.Anything).Return(models.Webhook{}, errors.New("db is down")).Once()
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: "db is down",
+		},
+		{
+			name:     "created",
+			giveBody: `{"url":"http://x","events":["created"],"keys":["1-5"]}`,
+			prepareDispatcher: func(d *mocks.Dispatcher) {
+				d.EXPECT().Subscribe(models.Webhook{
+					URL:    "http://x",
+					Events: []models.ChangeType{models.ChangeCreated},
+					Keys:   []string{"1-5"},
+				}).Return(models.Webhook{
+					ID:     7,
+					URL:    "http://x",
+					Events: []models.ChangeType{models.ChangeCreated},
+					Keys:   []string{"1-5"},
+					Secret: "generated",
+				}, nil).Once()
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `"secret":"generated"`,
+		},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := mocks.NewDispatcher(t)
+			if tt.prepareDispatcher != nil {
+				tt.prepareDispatcher(d)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/webhooks", bytes.NewBufferString(tt.giveBody))
+			rr := httptest.NewRecorder()
+
+			NewHandler(zap.NewNop(), d).Create(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestHandler_List(t *testing.T) {
+	t.Parallel()
+
+	d := mocks.NewDispatcher(t)
+	d.EXPECT().Webhooks().Return([]models.Webhook{{ID: 1, URL: "http://x", Secret: "hidden"}}).Once()
+
+	rr := httptest.NewRecorder()
+	NewHandler(zap.NewNop(), d).List(rr, httptest.NewRequest(http.MethodGet, "/admin/webhooks", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"url":"http://x"`)
+	assert.NotContains(t, rr.Body.String(), "hidden")
+}
+
+func TestHandler_Delete(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name              string
+		giveID            string
+		prepareDispatcher func(d *mocks.Dispatcher)
+		wantCode          int
+	}{
+		{name: "invalid id", giveID: "x", wantCode: http.StatusBadRequest},
+		{
+			name:   "not found",
+			giveID: "1",
+			prepareDispatcher: func(d *mocks.Dispatcher) {
+				d.EXPECT().Unsubscribe(int64(1)).Return(models.ErrNotFound).Once()
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:   "deleted",
+			giveID: "2",
+			prepareDispatcher: func(d *mocks.Dispatcher) {
+				d.EXPECT().Unsubscribe(int64(2)).Return(nil).Once()
+			},
+			wantCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := mocks.NewDispatcher(t)
+			if tt.prepareDispatcher != nil {
+				tt.prepareDispatcher(d)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("webhookID", tt.giveID)
+
+			req := httptest.NewRequest(http.MethodDelete, "/admin/webhooks/"+tt.giveID, nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			NewHandler(zap.NewNop(), d).Delete(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
+
+func TestHandler_Deliveries(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name              string
+		giveQuery         string
+		prepareDispatcher func(d *mocks.Dispatcher)
+		wantCode          int
+		wantBody          string
+	}{
+		{name: "invalid status", giveQuery: "?status=delivered", wantCode: http.StatusBadRequest},
+		{name: "invalid limit", giveQuery: "?limit=0", wantCode: http.StatusBadRequest},
+		{
+			name:      "failed deliveries",
+			giveQuery: "?status=failed&limit=5000",
+			prepareDispatcher: func(d *mocks.Dispatcher) {
+				d.EXPECT().Deliveries(models.DeliveryFailed, maxLimit).Return([]models.Delivery{{
+					ID:        3,
+					WebhookID: 1,
+					Event:     models.ChangeDeleted,
+					Status:    models.DeliveryFailed,
+					Attempts:  8,
+					LastError: "unexpected response status 500",
+					Payload:   []byte(`{"id":1}`),
+				}}, nil).Once()
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"last_error":"unexpected response status 500"`,
+		},
+		{
+			name: "internal error",
+			prepareDispatcher: func(d *mocks.Dispatcher) {
+				d.EXPECT().Deliveries(models.DeliveryStatus(""), defaultLimit).Return(nil, errors.New("db is down")).Once()
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := mocks.NewDispatcher(t)
+			if tt.prepareDispatcher != nil {
+				tt.prepareDispatcher(d)
+			}
+
+			rr := httptest.NewRecorder()
+			NewHandler(zap.NewNop(), d).Deliveries(rr,
+				httptest.NewRequest(http.MethodGet, "/admin/webhooks/deliveries"+tt.giveQuery, nil))
+
+			require.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,244 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "st-test/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Dispatcher is an autogenerated mock type for the Dispatcher type
+type Dispatcher struct {
+	mock.Mock
+}
+
+type Dispatcher_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Dispatcher) EXPECT() *Dispatcher_Expecter {
+	return &Dispatcher_Expecter{mock: &_m.Mock}
+}
+
+// Deliveries provides a mock function with given fields: status, limit
+func (_m *Dispatcher) Deliveries(status models.DeliveryStatus, limit int) ([]models.Delivery, error) {
+	ret := _m.Called(status, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Deliveries")
+	}
+
+	var r0 []models.Delivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.DeliveryStatus, int) ([]models.Delivery, error)); ok {
+		return rf(status, limit)
+	}
+	if rf, ok := ret.Get(0).(func(models.DeliveryStatus, int) []models.Delivery); ok {
+		r0 = rf(status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Delivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.DeliveryStatus, int) error); ok {
+		r1 = rf(status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Dispatcher_Deliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Deliveries'
+type Dispatcher_Deliveries_Call struct {
+	*mock.Call
+}
+
+// Deliveries is a helper method to define mock.On call
+//   - status models.DeliveryStatus
+//   - limit int
+func (_e *Dispatcher_Expecter) Deliveries(status interface{}, limit interface{}) *Dispatcher_Deliveries_Call {
+	return &Dispatcher_Deliveries_Call{Call: _e.mock.On("Deliveries", status, limit)}
+}
+
+func (_c *Dispatcher_Deliveries_Call) Run(run func(status models.DeliveryStatus, limit int)) *Dispatcher_Deliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.DeliveryStatus), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Dispatcher_Deliveries_Call) Return(_a0 []models.Delivery, _a1 error) *Dispatcher_Deliveries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Dispatcher_Deliveries_Call) RunAndReturn(run func(models.DeliveryStatus, int) ([]models.Delivery, error)) *Dispatcher_Deliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Subscribe provides a mock function with given fields: w
+func (_m *Dispatcher) Subscribe(w models.Webhook) (models.Webhook, error) {
+	ret := _m.Called(w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 models.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Webhook) (models.Webhook, error)); ok {
+		return rf(w)
+	}
+	if rf, ok := ret.Get(0).(func(models.Webhook) models.Webhook); ok {
+		r0 = rf(w)
+	} else {
+		r0 = ret.Get(0).(models.Webhook)
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Webhook) error); ok {
+		r1 = rf(w)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Dispatcher_Subscribe_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Subscribe'
+type Dispatcher_Subscribe_Call struct {
+	*mock.Call
+}
+
+// Subscribe is a helper method to define mock.On call
+//   - w models.Webhook
+func (_e *Dispatcher_Expecter) Subscribe(w interface{}) *Dispatcher_Subscribe_Call {
+	return &Dispatcher_Subscribe_Call{Call: _e.mock.On("Subscribe", w)}
+}
+
+func (_c *Dispatcher_Subscribe_Call) Run(run func(w models.Webhook)) *Dispatcher_Subscribe_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.Webhook))
+	})
+	return _c
+}
+
+func (_c *Dispatcher_Subscribe_Call) Return(_a0 models.Webhook, _a1 error) *Dispatcher_Subscribe_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Dispatcher_Subscribe_Call) RunAndReturn(run func(models.Webhook) (models.Webhook, error)) *Dispatcher_Subscribe_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Unsubscribe provides a mock function with given fields: id
+func (_m *Dispatcher) Unsubscribe(id int64) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unsubscribe")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Dispatcher_Unsubscribe_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Unsubscribe'
+type Dispatcher_Unsubscribe_Call struct {
+	*mock.Call
+}
+
+// Unsubscribe is a helper method to define mock.On call
+//   - id int64
+func (_e *Dispatcher_Expecter) Unsubscribe(id interface{}) *Dispatcher_Unsubscribe_Call {
+	return &Dispatcher_Unsubscribe_Call{Call: _e.mock.On("Unsubscribe", id)}
+}
+
+func (_c *Dispatcher_Unsubscribe_Call) Run(run func(id int64)) *Dispatcher_Unsubscribe_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int64))
+	})
+	return _c
+}
+
+func (_c *Dispatcher_Unsubscribe_Call) Return(_a0 error) *Dispatcher_Unsubscribe_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Dispatcher_Unsubscribe_Call) RunAndReturn(run func(int64) error) *Dispatcher_Unsubscribe_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Webhooks provides a mock function with given fields:
+func (_m *Dispatcher) Webhooks() []models.Webhook {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Webhooks")
+	}
+
+	var r0 []models.Webhook
+	if rf, ok := ret.Get(0).(func() []models.Webhook); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Webhook)
+		}
+	}
+
+	return r0
+}
+
+// Dispatcher_Webhooks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Webhooks'
+type Dispatcher_Webhooks_Call struct {
+	*mock.Call
+}
+
+// Webhooks is a helper method to define mock.On call
+func (_e *Dispatcher_Expecter) Webhooks() *Dispatcher_Webhooks_Call {
+	return &Dispatcher_Webhooks_Call{Call: _e.mock.On("Webhooks")}
+}
+
+func (_c *Dispatcher_Webhooks_Call) Run(run func()) *Dispatcher_Webhooks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Dispatcher_Webhooks_Call) Return(_a0 []models.Webhook) *Dispatcher_Webhooks_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Dispatcher_Webhooks_Call) RunAndReturn(run func() []models.Webhook) *Dispatcher_Webhooks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewDispatcher creates a new instance of Dispatcher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDispatcher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Dispatcher {
+	mock := &Dispatcher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,406 @@
+// Package ws описывает обработчик WebSocket-подключений для подписки на изменения объектов и их записи.
+//
+// Клиент отправляет json-сообщения:
+//
+//	{"op":"subscribe","keys":["42","10-20","7*"]}   подписка на объект, диапазон id или шаблон id
+//	{"op":"unsubscribe","keys":["42"]}              отписка
+//	{"op":"put","id":1,"body":{...},"expires":"1m","request_id":"r1"}  запись объекта
+//
+// Запись проходит те же проверки, что и запрос PUT /objects/{id}: поля content_type, metadata, tags, expires,
+// expires_at, sliding и ttl_mode соответствуют заголовкам запроса, владельцем объекта становится клиент
+// из заголовка X-CLIENT-ID запроса на подключение.
+//
+// Сервер отправляет сообщения с полем type: change, subscribed, unsubscribed, ack и error.
+// Медленный клиент, который не успевает забирать сообщения, отключается, не замедляя запись в хранилище.
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"st-test/internal/keyfilter"
+	"st-test/internal/models"
+	"st-test/internal/storage"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// sendQueueSize количество сообщений, которое может ожидать отправки клиенту.
+	// При переполнении очереди клиент считается медленным и отключается.
+	sendQueueSize = 256
+	// batchSize количество изменений, забираемых из хранилища за один раз.
+	batchSize = 100
+	// writeTimeout время на отправку одного сообщения клиенту.
+	writeTimeout = 5 * time.Second
+	// pingInterval период отправки ping-сообщений.
+	pingInterval = 30 * time.Second
+	// pongTimeout время ожидания ответа на ping.
+	pongTimeout = 2 * pingInterval
+	// maxMessageSize максимальный размер сообщения от клиента.
+	maxMessageSize = 1 << 20
+)
+
+// Storage описывает методы хранилища для чтения журнала изменений.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Storage --with-expecter=true
+type Storage interface {
+	Changes(after uint64, limit int) ([]models.Change, <-chan struct{}, error)
+	LastChange() uint64
+}
+
+// Objects описывает запись объекта с теми же проверками, что и у запроса PUT /objects/{id} с заголовками header.
+// Возвращает true, если объект создан.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Objects --with-expecter=true
+type Objects interface {
+	PutObject(ctx context.Context, id int, header http.Header, body []byte) (bool, error)
+}
+
+// Handler http-обработчик запросов.
+type Handler struct {
+	log      *zap.Logger
+	store    Storage
+	objects  Objects
+	upgrader websocket.Upgrader
+	// origins дополнительные источники (Origin), которым разрешено подключение, кроме источника с хостом запроса.
+	origins map[string]struct{}
+}
+
+// Option описывает необязательную настройку обработчика.
+type Option func(h *Handler)
+
+// WithAllowedOrigins разрешает подключение со страниц источников origins (например https://app.example.com)
+// в дополнение к источнику с хостом самого запроса.
+func WithAllowedOrigins(origins []string) Option {
+	return func(h *Handler) {
+		for _, o := range origins {
+			h.origins[strings.ToLower(strings.TrimSuffix(o, "/"))] = struct{}{}
+		}
+	}
+}
+
+// NewHandler конструктор для Handler.
+func NewHandler(log *zap.Logger, store Storage, objects Objects, opts ...Option) *Handler {
+	h := &Handler{
+		log:     log.Named("ws handler"),
+		store:   store,
+		objects: objects,
+		origins: make(map[string]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		CheckOrigin:     h.checkOrigin,
+	}
+
+	return h
+}
+
+// checkOrigin разрешает подключения без заголовка Origin (не из браузера), с источника, хост которого совпадает
+// с хостом запроса, и с разрешённых источников. Остальные подключения отклоняются, чтобы чужая страница не могла
+// писать объекты от имени браузера пользователя.
+func (h *Handler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	if _, ok := h.origins[strings.ToLower(origin)]; ok {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// Connect метод обработки GET запросов на установку WebSocket-подключения.
+func (h *Handler) Connect(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade сам отвечает клиенту в случае ошибки.
+		h.log.Error("failed upgrade connection", zap.Error(err))
+
+		return
+	}
+
+	s := newSession(h.log, h.store, h.objects, r.Header.Get(clientHeader), conn)
+	s.run()
+}
+
+// session состояние одного WebSocket-подключения.
+type session struct {
+	log     *zap.Logger
+	store   Storage
+	objects Objects
+	// client клиент из заголовка X-CLIENT-ID, в квоте которого учитываются записанные объекты.
+	client string
+	conn   *websocket.Conn
+	send   chan any
+
+	m    sync.Mutex
+	subs keyfilter.Set
+
+	done      chan struct{}
+	closeOnce sync.Once
+	reason    string
+}
+
+func newSession(log *zap.Logger, store Storage, objects Objects, client string, conn *websocket.Conn) *session {
+	return &session{
+		log:     log,
+		store:   store,
+		objects: objects,
+		client:  client,
+		conn:    conn,
+		send:    make(chan any, sendQueueSize),
+		subs:    make(keyfilter.Set),
+		done:    make(chan struct{}),
+	}
+}
+
+// run обслуживает подключение до его закрытия.
+func (s *session) run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		s.readLoop(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		s.feedLoop()
+	}()
+
+	s.writeLoop()
+
+	cancel()
+	s.close("")
+	_ = s.conn.Close()
+
+	wg.Wait()
+
+	s.log.Info("websocket session closed", zap.String("reason", s.reason))
+}
+
+// close завершает сессию. Повторные вызовы игнорируются.
+func (s *session) close(reason string) {
+	s.closeOnce.Do(func() {
+		s.reason = reason
+		close(s.done)
+	})
+}
+
+// enqueue ставит сообщение в очередь на отправку, не блокируясь.
+// Если очередь переполнена, клиент отключается как медленный.
+func (s *session) enqueue(msg any) bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+	}
+
+	select {
+	case s.send <- msg:
+		return true
+	default:
+		s.close(closeSlowConsumer)
+
+		return false
+	}
+}
+
+// writeLoop отправляет сообщения из очереди и ping-сообщения до закрытия сессии.
+func (s *session) writeLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			s.writeClose()
+
+			return
+		case msg := <-s.send:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+			if err := s.conn.WriteJSON(msg); err != nil {
+				s.close("write failed: " + err.Error())
+
+				return
+			}
+		case <-ticker.C:
+			deadline := time.Now().Add(writeTimeout)
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				s.close("ping failed: " + err.Error())
+
+				return
+			}
+		}
+	}
+}
+
+// writeClose отправляет клиенту сообщение о закрытии с причиной.
+func (s *session) writeClose() {
+	code := websocket.CloseNormalClosure
+	if s.reason == closeSlowConsumer || s.reason == closeLagging {
+		code = websocket.ClosePolicyViolation
+	}
+
+	msg := websocket.FormatCloseMessage(code, s.reason)
+	_ = s.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeTimeout))
+}
+
+// readLoop читает и обрабатывает команды клиента.
+func (s *session) readLoop(ctx context.Context) {
+	s.conn.SetReadLimit(maxMessageSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(pongTimeout)) //nolint:wrapcheck
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			s.close("")
+
+			return
+		}
+
+		var cmd command
+
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			s.enqueue(errorMessage("", "invalid message: "+err.Error()))
+
+			continue
+		}
+
+		s.handle(ctx, cmd)
+	}
+}
+
+// handle выполняет команду клиента.
+func (s *session) handle(ctx context.Context, cmd command) {
+	switch cmd.Op {
+	case opSubscribe, opUnsubscribe:
+		if len(cmd.Keys) == 0 {
+			s.enqueue(errorMessage(cmd.RequestID, "keys are required"))
+
+			return
+		}
+
+		keys, err := keyfilter.ParseAll(cmd.Keys)
+		if err != nil {
+			s.enqueue(errorMessage(cmd.RequestID, err.Error()))
+
+			return
+		}
+
+		s.m.Lock()
+		if cmd.Op == opSubscribe {
+			s.subs.Add(keys...)
+		} else {
+			s.subs.Remove(keys...)
+		}
+		s.m.Unlock()
+
+		s.enqueue(subscriptionMessage{Type: cmd.Op + "d", RequestID: cmd.RequestID, Keys: cmd.Keys})
+	case opPut:
+		s.put(ctx, cmd)
+	default:
+		s.enqueue(errorMessage(cmd.RequestID, "unknown op: "+cmd.Op))
+	}
+}
+
+// put сохраняет объект, переданный клиентом, с проверками запроса PUT.
+func (s *session) put(ctx context.Context, cmd command) {
+	if cmd.ID == nil {
+		s.enqueue(errorMessage(cmd.RequestID, "id is required"))
+
+		return
+	}
+
+	if len(cmd.Body) == 0 {
+		s.enqueue(errorMessage(cmd.RequestID, "body is required"))
+
+		return
+	}
+
+	created, err := s.objects.PutObject(ctx, *cmd.ID, cmd.header(s.client), cmd.Body)
+	if err != nil {
+		s.enqueue(errorMessage(cmd.RequestID, err.Error()))
+
+		return
+	}
+
+	status := "created"
+	if !created {
+		status = "updated"
+	}
+
+	s.enqueue(ackMessage{Type: "ack", RequestID: cmd.RequestID, ID: *cmd.ID, Status: status})
+}
+
+// feedLoop забирает изменения из журнала хранилища и отправляет клиенту те, на которые он подписан.
+func (s *session) feedLoop() {
+	last := s.store.LastChange()
+
+	for {
+		changes, wait, err := s.store.Changes(last, batchSize)
+		if err != nil {
+			if errors.Is(err, storage.ErrChangesExpired) {
+				s.close(closeLagging)
+			} else {
+				s.close("read changes: " + err.Error())
+			}
+
+			return
+		}
+
+		for _, c := range changes {
+			last = c.Seq
+
+			s.m.Lock()
+			ok := s.subs.Match(c.ID)
+			s.m.Unlock()
+
+			if ok && !s.enqueue(newChangeMessage(c)) {
+				return
+			}
+		}
+
+		if len(changes) > 0 {
+			continue
+		}
+
+		select {
+		case <-s.done:
+			return
+		case <-wait:
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"st-test/internal/http/handler/api"
+	"st-test/internal/http/handler/ws/mocks"
+	"st-test/internal/models"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
+	storagemocks "st-test/internal/storage/mocks"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewHandler(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	store := mocks.NewStorage(t)
+	require.NotNil(t, store)
+
+	h := NewHandler(log, store, mocks.NewObjects(t))
+	require.NotNil(t, h)
+}
+
+// dial запускает тестовый сервер с обработчиком и подключается к нему с заголовками header.
+func dial(t *testing.T, store Storage, objects Objects, header http.Header) *websocket.Conn {
+	t.Helper()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(NewHandler(log, store, objects).Connect))
+	t.Cleanup(srv.Close)
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), header)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func read(t *testing.T, conn *websocket.Conn) map[string]any {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+
+	var msg map[string]any
+
+	require.NoError(t, conn.ReadJSON(&msg))
+
+	return msg
+}
+
+func TestHandler_Connect(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	repo := storagemocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	store := storage.NewStore(log, repo)
+	conn := dial(t, store, api.NewHandler(log, store), nil)
+
+	require.NoError(t, conn.WriteJSON(map[string]any{"op": "subscribe", "keys": []string{"1", "10-20"}, "request_id": "s1"}))
+
+	msg := read(t, conn)
+	assert.Equal(t, "subscribed", msg["type"])
+	assert.Equal(t, "s1", msg["request_id"])
+
+	// запись через подключение приходит подписчику как изменение.
+	require.NoError(t, conn.WriteJSON(map[string]any{"op": "put", "id": 15, "body": map[string]any{"a": 1}, "request_id": "p1"}))
+
+	got := []map[string]any{read(t, conn), read(t, conn)}
+	types := []any{got[0]["type"], got[1]["type"]}
+	assert.ElementsMatch(t, []any{"ack", "change"}, types)
+
+	for _, m := range got {
+		if m["type"] == "change" {
+			assert.Equal(t, "created", m["change"])
+			assert.Equal(t, float64(15), m["id"])
+			assert.Equal(t, map[string]any{"a": float64(1)}, m["body"])
+		} else {
+			assert.Equal(t, "p1", m["request_id"])
+			assert.Equal(t, "created", m["status"])
+		}
+	}
+
+	// изменения объектов без подписки не приходят.
+	_, err = store.SaveObject(context.Background(), models.Item{ID: 2, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	_, err = store.SaveObject(context.Background(), models.Item{ID: 1, Body: []byte(`{"b":2}`)})
+	require.NoError(t, err)
+
+	msg = read(t, conn)
+	assert.Equal(t, "change", msg["type"])
+	assert.Equal(t, float64(1), msg["id"])
+
+	require.NoError(t, conn.WriteJSON(map[string]any{"op": "unsubscribe", "keys": []string{"1"}}))
+	assert.Equal(t, "unsubscribed", read(t, conn)["type"])
+
+	require.NoError(t, store.DeleteObject(context.Background(), 1))
+	require.NoError(t, store.DeleteObject(context.Background(), 15))
+
+	msg = read(t, conn)
+	assert.Equal(t, "deleted", msg["change"])
+	assert.Equal(t, float64(15), msg["id"])
+	assert.NotContains(t, msg, "body")
+}
+
+func TestHandler_ConnectPut(t *testing.T) {
+	t.Parallel()
+
+	log := zap.NewNop()
+
+	repo := storagemocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	store := storage.NewStore(log, repo)
+	objects := api.NewHandler(log, store, api.WithLimits(settings.LimitsSettings{MaxBodySize: 64, MaxJSONDepth: 2}))
+	conn := dial(t, store, objects, http.Header{"X-Client-Id": []string{"c1"}})
+
+	// запись проходит проверки запроса PUT и сохраняет тип содержимого, метаданные, срок жизни и владельца.
+	require.NoError(t, conn.WriteJSON(map[string]any{
+		"op": "put", "id": 1, "body": map[string]any{"a": 1}, "request_id": "p1",
+		"content_type": "application/merge-patch+json", "metadata": map[string]string{"Author": "bob"}, "tags": []string{"x"},
+		"expires": "1h", "sliding": true,
+	}))
+
+	msg := read(t, conn)
+	assert.Equal(t, "ack", msg["type"])
+	assert.Equal(t, "created", msg["status"])
+
+	// перезапись с ttl_mode keep сохраняет скользящий срок жизни.
+	require.NoError(t, conn.WriteJSON(map[string]any{"op": "put", "id": 1, "body": map[string]any{"a": 2}, "ttl_mode": "keep"}))
+	assert.Equal(t, "updated", read(t, conn)["status"])
+
+	stat, err := store.StatObject(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "c1", stat.Owner)
+	assert.Equal(t, "application/json", stat.ContentType)
+	assert.True(t, stat.Sliding)
+	assert.Equal(t, time.Hour, stat.Expires)
+
+	cases := []struct {
+		name        string
+		give        map[string]any
+		wantMessage string
+	}{
+		{name: "invalid expires", give: map[string]any{"expires": "soon"}, wantMessage: "failed parse ttl"},
+		{name: "invalid ttl mode", give: map[string]any{"ttl_mode": "extend"}, wantMessage: "failed parse ttl"},
+		{name: "invalid metadata", give: map[string]any{"metadata": map[string]string{"a b": "c"}}, wantMessage: "failed check metadata"},
+		{name: "unknown content type", give: map[string]any{"content_type": "json"}, wantMessage: "Content-Type"},
+		{name: "too deep json", give: map[string]any{"body": map[string]any{"a": map[string]any{"b": map[string]any{}}}}, wantMessage: "json"},
+		{name: "too large body", give: map[string]any{"body": map[string]any{"a": strings.Repeat("x", 64)}}, wantMessage: "PAYLOAD_TOO_LARGE"},
+	}
+
+	for _, tc := range cases {
+		cmd := map[string]any{"op": "put", "id": 2, "body": map[string]any{}}
+		for k, v := range tc.give {
+			cmd[k] = v
+		}
+
+		require.NoError(t, conn.WriteJSON(cmd), tc.name)
+
+		msg := read(t, conn)
+		assert.Equal(t, "error", msg["type"], tc.name)
+		assert.Contains(t, msg["message"], tc.wantMessage, tc.name)
+	}
+
+	_, err = store.StatObject(context.Background(), 2)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestHandler_ConnectErrors(t *testing.T) {
+	t.Parallel()
+
+	store := mocks.NewStorage(t)
+	store.EXPECT().LastChange().Once().Return(uint64(0))
+	store.EXPECT().Changes(uint64(0), batchSize).Maybe().Return(nil, make(<-chan struct{}), nil)
+
+	objects := mocks.NewObjects(t)
+	objects.EXPECT().PutObject(mock.Anything, 1, mock.Anything, []byte(`{}`)).
+		Once().
+		Return(false, errors.New("failed save object: some error"))
+
+	conn := dial(t, store, objects, nil)
+
+	cases := []struct {
+		name        string
+		give        string
+		wantMessage string
+	}{
+		{name: "invalid json", give: `{"op":`, wantMessage: "invalid message"},
+		{name: "unknown op", give: `{"op":"rename"}`, wantMessage: "unknown op"},
+		{name: "invalid key", give: `{"op":"subscribe","keys":["abc"]}`, wantMessage: "invalid key filter"},
+		{name: "subscribe without keys", give: `{"op":"subscribe"}`, wantMessage: "keys are required"},
+		{name: "put without id", give: `{"op":"put","body":{}}`, wantMessage: "id is required"},
+		{name: "put without body", give: `{"op":"put","id":1}`, wantMessage: "body is required"},
+		{name: "store error", give: `{"op":"put","id":1,"body":{}}`, wantMessage: "failed save object"},
+	}
+
+	for _, tc := range cases {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(tc.give)), tc.name)
+
+		msg := read(t, conn)
+		assert.Equal(t, "error", msg["type"], tc.name)
+		assert.Contains(t, msg["message"], tc.wantMessage, tc.name)
+	}
+}
+
+func TestSession_enqueue(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	s := newSession(log, mocks.NewStorage(t), mocks.NewObjects(t), "", nil)
+	s.send = make(chan any, 1)
+
+	require.True(t, s.enqueue("first"))
+	require.False(t, s.enqueue("second"))
+
+	select {
+	case <-s.done:
+	default:
+		t.Fatal("slow consumer must be disconnected")
+	}
+
+	require.Equal(t, closeSlowConsumer, s.reason)
+	require.False(t, s.enqueue("third"))
+}
+
+func TestHandler_CheckOrigin(t *testing.T) {
+	t.Parallel()
+
+	h := NewHandler(zap.NewNop(), mocks.NewStorage(t), mocks.NewObjects(t), WithAllowedOrigins([]string{"https://app.example.com/"}))
+
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{name: "no origin", want: true},
+		{name: "same host", origin: "http://storage.local:8080", want: true},
+		{name: "allowed origin", origin: "https://APP.example.com", want: true},
+		{name: "other origin", origin: "https://evil.example.com"},
+		{name: "same host on other port", origin: "http://storage.local:9090"},
+		{name: "invalid origin", origin: "://"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "http://storage.local:8080/ws", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+
+			assert.Equal(t, tt.want, h.checkOrigin(r))
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"st-test/internal/models"
+)
+
+// Команды клиента.
+const (
+	opSubscribe   = "subscribe"
+	opUnsubscribe = "unsubscribe"
+	opPut         = "put"
+)
+
+// Причины закрытия подключения сервером.
+const (
+	closeSlowConsumer = "slow consumer"
+	closeLagging      = "changes are no longer retained"
+)
+
+// Заголовки запроса PUT /objects/{id}, которым соответствуют поля команды put.
+const (
+	clientHeader      = "X-CLIENT-ID"
+	expiresHeader     = "X-EXPIRES"
+	expiresAtHeader   = "X-EXPIRES-AT"
+	slidingHeader     = "X-EXPIRES-SLIDING"
+	ttlModeHeader     = "X-TTL-MODE"
+	metaHeaderPrefix  = "X-Meta-"
+	tagsHeader        = "X-Tags"
+	jsonContentType   = "application/json"
+	contentTypeHeader = "Content-Type"
+)
+
+// command сообщение от клиента.
+type command struct {
+	Op          string            `json:"op"`
+	RequestID   string            `json:"request_id"`
+	Keys        []string          `json:"keys"`
+	ID          *int              `json:"id"`
+	Body        json.RawMessage   `json:"body"`
+	ContentType string            `json:"content_type"`
+	Metadata    map[string]string `json:"metadata"`
+	Tags        []string          `json:"tags"`
+	Expires     string            `json:"expires"`
+	ExpiresAt   string            `json:"expires_at"`
+	Sliding     bool              `json:"sliding"`
+	TTLMode     string            `json:"ttl_mode"`
+}
+
+// header возвращает заголовки запроса PUT, соответствующие команде put клиента client.
+// Тело команды — json, поэтому тип содержимого по умолчанию application/json.
+func (c command) header(client string) http.Header {
+	h := make(http.Header)
+
+	h.Set(contentTypeHeader, jsonContentType)
+	if c.ContentType != "" {
+		h.Set(contentTypeHeader, c.ContentType)
+	}
+
+	for k, v := range c.Metadata {
+		h.Set(metaHeaderPrefix+k, v)
+	}
+
+	if len(c.Tags) > 0 {
+		h.Set(tagsHeader, strings.Join(c.Tags, ","))
+	}
+
+	for name, value := range map[string]string{
+		clientHeader:    client,
+		expiresHeader:   c.Expires,
+		expiresAtHeader: c.ExpiresAt,
+		ttlModeHeader:   c.TTLMode,
+	} {
+		if value != "" {
+			h.Set(name, value)
+		}
+	}
+
+	if c.Sliding {
+		h.Set(slidingHeader, strconv.FormatBool(c.Sliding))
+	}
+
+	return h
+}
+
+// changeMessage изменение объекта, на который подписан клиент. Large выставляется для крупных объектов без тела.
+type changeMessage struct {
+	Type   string          `json:"type"`
+	Seq    uint64          `json:"seq"`
+	Change string          `json:"change"`
+	ID     int             `json:"id"`
+	Time   time.Time       `json:"time"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Large  bool            `json:"large,omitempty"`
+}
+
+func newChangeMessage(c models.Change) changeMessage {
+	msg := changeMessage{
+		Type:   "change",
+		Seq:    c.Seq,
+		Change: string(c.Type),
+		ID:     c.ID,
+		Time:   c.Time,
+		Large:  c.Large,
+	}
+
+	// новое тело отправляется только для созданных и обновлённых объектов.
+	if (c.Type == models.ChangeCreated || c.Type == models.ChangeUpdated) && json.Valid(c.Body) {
+		msg.Body = c.Body
+	}
+
+	return msg
+}
+
+// subscriptionMessage подтверждение подписки или отписки.
+type subscriptionMessage struct {
+	Type      string   `json:"type"`
+	RequestID string   `json:"request_id,omitempty"`
+	Keys      []string `json:"keys"`
+}
+
+// ackMessage подтверждение записи объекта.
+type ackMessage struct {
+	Type      string `json:"type"`
+	RequestID string `json:"request_id,omitempty"`
+	ID        int    `json:"id"`
+	Status    string `json:"status"`
+}
+
+// errorMsg ошибка обработки команды клиента.
+type errorMsg struct {
+	Type      string `json:"type"`
+	RequestID string `json:"request_id,omitempty"`
+	Message   string `json:"message"`
+}
+
+func errorMessage(requestID, message string) errorMsg {
+	return errorMsg{Type: "error", RequestID: requestID, Message: message}
+}
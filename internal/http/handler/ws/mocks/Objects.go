@@ -0,0 +1,96 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	http "net/http"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Objects is an autogenerated mock type for the Objects type
+type Objects struct {
+	mock.Mock
+}
+
+type Objects_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Objects) EXPECT() *Objects_Expecter {
+	return &Objects_Expecter{mock: &_m.Mock}
+}
+
+// PutObject provides a mock function with given fields: ctx, id, header, body
+func (_m *Objects) PutObject(ctx context.Context, id int, header http.Header, body []byte) (bool, error) {
+	ret := _m.Called(ctx, id, header, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PutObject")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, http.Header, []byte) (bool, error)); ok {
+		return rf(ctx, id, header, body)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, http.Header, []byte) bool); ok {
+		r0 = rf(ctx, id, header, body)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, http.Header, []byte) error); ok {
+		r1 = rf(ctx, id, header, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Objects_PutObject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PutObject'
+type Objects_PutObject_Call struct {
+	*mock.Call
+}
+
+// PutObject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - header http.Header
+//   - body []byte
+func (_e *Objects_Expecter) PutObject(ctx interface{}, id interface{}, header interface{}, body interface{}) *Objects_PutObject_Call {
+	return &Objects_PutObject_Call{Call: _e.mock.On("PutObject", ctx, id, header, body)}
+}
+
+func (_c *Objects_PutObject_Call) Run(run func(ctx context.Context, id int, header http.Header, body []byte)) *Objects_PutObject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(http.Header), args[3].([]byte))
+	})
+	return _c
+}
+
+func (_c *Objects_PutObject_Call) Return(_a0 bool, _a1 error) *Objects_PutObject_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Objects_PutObject_Call) RunAndReturn(run func(context.Context, int, http.Header, []byte) (bool, error)) *Objects_PutObject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewObjects creates a new instance of Objects. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewObjects(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Objects {
+	mock := &Objects{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
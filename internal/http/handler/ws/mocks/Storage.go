@@ -0,0 +1,149 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "st-test/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+type Storage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Storage) EXPECT() *Storage_Expecter {
+	return &Storage_Expecter{mock: &_m.Mock}
+}
+
+// Changes provides a mock function with given fields: after, limit
+func (_m *Storage) Changes(after uint64, limit int) ([]models.Change, <-chan struct{}, error) {
+	ret := _m.Called(after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Changes")
+	}
+
+	var r0 []models.Change
+	var r1 <-chan struct{}
+	var r2 error
+	if rf, ok := ret.Get(0).(func(uint64, int) ([]models.Change, <-chan struct{}, error)); ok {
+		return rf(after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uint64, int) []models.Change); ok {
+		r0 = rf(after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Change)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64, int) <-chan struct{}); ok {
+		r1 = rf(after, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(<-chan struct{})
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(uint64, int) error); ok {
+		r2 = rf(after, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Storage_Changes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Changes'
+type Storage_Changes_Call struct {
+	*mock.Call
+}
+
+// Changes is a helper method to define mock.On call
+//   - after uint64
+//   - limit int
+func (_e *Storage_Expecter) Changes(after interface{}, limit interface{}) *Storage_Changes_Call {
+	return &Storage_Changes_Call{Call: _e.mock.On("Changes", after, limit)}
+}
+
+func (_c *Storage_Changes_Call) Run(run func(after uint64, limit int)) *Storage_Changes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Storage_Changes_Call) Return(_a0 []models.Change, _a1 <-chan struct{}, _a2 error) *Storage_Changes_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Storage_Changes_Call) RunAndReturn(run func(uint64, int) ([]models.Change, <-chan struct{}, error)) *Storage_Changes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LastChange provides a mock function with given fields:
+func (_m *Storage) LastChange() uint64 {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LastChange")
+	}
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	return r0
+}
+
+// Storage_LastChange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LastChange'
+type Storage_LastChange_Call struct {
+	*mock.Call
+}
+
+// LastChange is a helper method to define mock.On call
+func (_e *Storage_Expecter) LastChange() *Storage_LastChange_Call {
+	return &Storage_LastChange_Call{Call: _e.mock.On("LastChange")}
+}
+
+func (_c *Storage_LastChange_Call) Run(run func()) *Storage_LastChange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Storage_LastChange_Call) Return(_a0 uint64) *Storage_LastChange_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_LastChange_Call) RunAndReturn(run func() uint64) *Storage_LastChange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
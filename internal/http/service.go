@@ -8,15 +8,37 @@ import (
 	"strconv"
 	"time"
 
+	"st-test/internal/cluster"
 	"st-test/internal/http/handler/api"
+	"st-test/internal/http/handler/changes"
+	"st-test/internal/http/handler/expired"
 	"st-test/internal/http/handler/healthz"
+	"st-test/internal/http/handler/membership"
 	"st-test/internal/http/handler/middlewares/apptype"
+	"st-test/internal/http/handler/middlewares/leader"
+	"st-test/internal/http/handler/middlewares/metrics"
+	"st-test/internal/http/handler/middlewares/readonly"
+	"st-test/internal/http/handler/middlewares/tracing"
+	"st-test/internal/http/handler/query"
+	"st-test/internal/http/handler/replication"
+	"st-test/internal/http/handler/scrub"
+	"st-test/internal/http/handler/stats"
+	"st-test/internal/http/handler/trash"
+	"st-test/internal/http/handler/uploads"
+	"st-test/internal/http/handler/usage"
+	"st-test/internal/http/handler/webhooks"
+	"st-test/internal/http/handler/ws"
+	"st-test/internal/largeobject"
+	"st-test/internal/replica"
 	"st-test/internal/settings"
 	"st-test/internal/storage"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 )
 
@@ -27,32 +49,250 @@ type Service struct {
 	settings *settings.APISettings
 }
 
+// Option дополнительная настройка сервиса.
+type Option func(o *options)
+
+type options struct {
+	webhooks webhooks.Dispatcher
+	scrubber scrub.Scrubber
+	large    *largeobject.Store
+	registry *prometheus.Registry
+	tracer   trace.TracerProvider
+	replica  *replica.Follower
+	proxy    bool
+	cluster  *cluster.Node
+}
+
+// WithWebhooks включает административные обработчики подписок на изменения объектов.
+func WithWebhooks(d webhooks.Dispatcher) Option {
+	return func(o *options) {
+		o.webhooks = d
+	}
+}
+
+// WithScrubber включает административные обработчики проверки объектов и карантина.
+func WithScrubber(s scrub.Scrubber) Option {
+	return func(o *options) {
+		o.scrubber = s
+	}
+}
+
+// WithLargeObjects включает крупные объекты: потоковую запись и чтение по частям и возобновляемые загрузки.
+func WithLargeObjects(s *largeobject.Store) Option {
+	return func(o *options) {
+		o.large = s
+	}
+}
+
+// WithMetrics регистрирует метрики http-запросов в reg и отдаёт содержимое reg по /metrics.
+// Без этой настройки используется отдельный реестр только с метриками http-запросов.
+func WithMetrics(reg *prometheus.Registry) Option {
+	return func(o *options) {
+		o.registry = reg
+	}
+}
+
+// WithTracing включает трассировку запросов: для каждого запроса начинается спан, продолжающий трассировку
+// из заголовка traceparent.
+func WithTracing(tp trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracer = tp
+	}
+}
+
+// WithReplica переводит сервис в режим ведомого экземпляра: запросы на запись отклоняются или, с proxyWrites,
+// перенаправляются ведущему, а состояние репликации добавляется в /admin/stats и readiness-пробу.
+func WithReplica(f *replica.Follower, proxyWrites bool) Option {
+	return func(o *options) {
+		o.replica = f
+		o.proxy = proxyWrites
+	}
+}
+
+// WithCluster переводит сервис в режим узла кластера: запись и чтение объектов обслуживает ведущий узел,
+// остальные узлы перенаправляют ему запросы. Перед чтением ведущий проверяет, что лидерство не потеряно,
+// поэтому чтение линеаризуемо. Добавляются обработчики состояния и состава кластера.
+func WithCluster(n *cluster.Node) Option {
+	return func(o *options) {
+		o.cluster = n
+	}
+}
+
 // NewService получает логгер, настройки и хранилище и создаёт объект Сервис.
-func NewService(log *zap.Logger, set *settings.APISettings, store *storage.Store) *Service {
+func NewService(log *zap.Logger, set *settings.APISettings, store *storage.Store, opts ...Option) *Service {
 	serLog := log.Named("http-service")
 
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.registry == nil {
+		o.registry = prometheus.NewRegistry()
+	}
+
+	if o.tracer == nil {
+		o.tracer = noop.NewTracerProvider()
+	}
+
 	mux := chi.NewRouter()
 
 	// A good base middleware stack
 	mux.Use(middleware.RequestID)
 	mux.Use(middleware.RealIP)
-	mux.Use(middleware.Logger)
+	mux.Use(tracing.Tracing(o.tracer))
+	mux.Use(metrics.New(o.registry).Middleware)
+	mux.Use(tracing.RequestLogger(serLog))
 	mux.Use(middleware.Recoverer)
-	mux.Use(middleware.Timeout(time.Second))
 
-	mux.Use(apptype.ApplicationType(log))
+	// запросы api принимают только json; тела объектов и загрузок могут быть любого типа и проверяются обработчиками.
+	jsonOnly := apptype.ApplicationType(log)
 
 	// api handlers
-	apiHandler := api.NewHandler(log, store)
+	apiOpts := []api.Option{api.WithLimits(set.Limits)}
+
+	// запись и чтение крупного объекта может занять больше общего таймаута.
+	putTimeout, getTimeout := time.Second, api.MaxWaitTimeout+time.Second
+
+	if o.large != nil {
+		apiOpts = append(apiOpts, api.WithLargeObjects(o.large))
+		putTimeout, getTimeout = o.large.Timeout(), max(getTimeout, o.large.Timeout())
+	}
+
+	// запись на ведомом экземпляре отклоняется или перенаправляется ведущему, запись и чтение на узле кластера
+	// перенаправляются ведущему узлу.
+	writes := func(h http.Handler) http.Handler { return h }
+	reads := func(h http.Handler) http.Handler { return h }
+
+	var (
+		statsOpts   []stats.Option
+		healthzOpts []healthz.Option
+	)
+
+	if o.replica != nil {
+		writes = readonly.Reject(o.replica.Leader())
+		if o.proxy {
+			writes = readonly.Proxy(log, o.tracer, o.replica.Leader())
+		}
+
+		statsOpts = append(statsOpts, stats.WithReplica(o.replica))
+		healthzOpts = append(healthzOpts, healthz.WithReplica(o.replica))
+	}
+
+	if o.cluster != nil {
+		forward, linearizable := leader.Forward(log, o.tracer, o.cluster), leader.Linearizable(o.cluster)
+
+		writes = forward
+		reads = func(h http.Handler) http.Handler { return forward(linearizable(h)) }
+
+		healthzOpts = append(healthzOpts, healthz.WithCluster(o.cluster))
+	}
+
+	apiHandler := api.NewHandler(log, store, apiOpts...)
+	queryHandler := query.NewHandler(log, store)
+	trashHandler := trash.NewHandler(log, store)
+
+	mux.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(time.Second))
+		r.Use(jsonOnly)
+
+		r.With(writes).Delete("/objects"+"/{objectID}", apiHandler.DeleteObject)
+		r.With(writes).Patch("/objects"+"/{objectID}", apiHandler.UpdateMetadata)
+		r.With(reads).Get("/objects"+"/{objectID}/meta", apiHandler.Stat)
+		r.With(reads).Get("/objects"+"/{objectID}/ttl", apiHandler.TTL)
+		r.With(writes).Put("/objects"+"/{objectID}/ttl", apiHandler.SetTTL)
+		r.With(writes).Delete("/objects"+"/{objectID}/ttl", apiHandler.DeleteTTL)
+		r.With(writes).Post("/objects"+"/{objectID}:touch", apiHandler.Touch)
+
+		// query handlers
+		r.With(reads).Post("/objects:query", queryHandler.Query)
+		r.With(reads).Get("/objects:search", queryHandler.Search)
+
+		// trash handlers
+		r.With(reads).Get("/trash", trashHandler.List)
+		r.With(writes).Post("/trash"+"/{objectID}:restore", trashHandler.Restore)
+
+		// admin handlers
+		usageHandler := usage.NewHandler(log, store)
+		r.Get("/admin/usage", usageHandler.Usage)
+		r.Get("/admin/stats", stats.NewHandler(log, store, statsOpts...).Stats)
+
+		expiredHandler := expired.NewHandler(log, store)
+		r.Get("/admin/expired", expiredHandler.List)
+		r.Get("/admin/expired"+"/{objectID}", expiredHandler.Object)
+
+		if o.webhooks != nil {
+			webhooksHandler := webhooks.NewHandler(log, o.webhooks)
+			r.Get("/admin/webhooks", webhooksHandler.List)
+			r.Post("/admin/webhooks", webhooksHandler.Create)
+			r.Delete("/admin/webhooks"+"/{webhookID}", webhooksHandler.Delete)
+			r.Get("/admin/webhooks/deliveries", webhooksHandler.Deliveries)
+		}
+
+		// состояние кластера отдаёт узел, получивший запрос; состав кластера меняет ведущий узел.
+		if o.cluster != nil {
+			membershipHandler := membership.NewHandler(log, o.cluster)
+			r.Get("/cluster", membershipHandler.Status)
+			r.With(writes).Post("/cluster/members", membershipHandler.AddMember)
+			r.With(writes).Delete("/cluster/members"+"/{nodeID}", membershipHandler.RemoveMember)
+		}
+
+		if o.scrubber != nil {
+			scrubHandler := scrub.NewHandler(log, o.scrubber)
+			r.Get("/admin/scrub", scrubHandler.Last)
+			r.Get("/admin/quarantine", scrubHandler.Quarantine)
+		}
+	})
+
+	// проверка всех объектов может занять больше общего таймаута, поэтому он к ней не применяется.
+	if o.scrubber != nil {
+		mux.With(jsonOnly).Post("/admin/scrub", scrub.NewHandler(log, o.scrubber).Scrub)
+	}
+
+	// агрегация просматривает всё хранилище, поэтому у неё свой таймаут.
+	mux.With(middleware.Timeout(query.AggregateTimeout), jsonOnly, reads).Post("/objects:aggregate", queryHandler.Aggregate)
+
+	mux.With(middleware.Timeout(putTimeout), writes).Put("/objects"+"/{objectID}", apiHandler.AddObject)
+
+	// получение объекта поддерживает ожидание новой версии, поэтому вместо общего таймаута
+	// используется максимальное время ожидания.
+	mux.With(middleware.Timeout(getTimeout), reads).Get("/objects"+"/{objectID}", apiHandler.Object)
+	mux.With(middleware.Timeout(getTimeout), reads).Head("/objects"+"/{objectID}", apiHandler.Object)
+
+	// uploads handlers
+	if o.large != nil {
+		uploadsHandler := uploads.NewHandler(log, o.large)
+
+		mux.Group(func(r chi.Router) {
+			r.Use(middleware.Timeout(o.large.Timeout()))
+
+			r.With(writes).Post("/objects"+"/{objectID}/uploads", uploadsHandler.Create)
+			r.Get("/uploads"+"/{uploadID}", uploadsHandler.Get)
+			r.With(writes).Patch("/uploads"+"/{uploadID}", uploadsHandler.Append)
+			r.With(writes).Post("/uploads"+"/{uploadID}:complete", uploadsHandler.Complete)
+			r.With(writes).Delete("/uploads"+"/{uploadID}", uploadsHandler.Abort)
+		})
+	}
+
+	// changes handlers. Лента изменений — долгоживущее соединение, поэтому общий таймаут к ней не применяется.
+	changesHandler := changes.NewHandler(log, store)
+	mux.Get("/changes", changesHandler.Stream)
+
+	// replication handlers. Через них ведомые экземпляры загружают снимок и получают ленту изменений.
+	replicationHandler := replication.NewHandler(log, store)
+	mux.Get(replica.SnapshotPath, replicationHandler.Snapshot)
+	mux.Get(replica.ChangesPath, replicationHandler.Changes)
 
-	mux.Put("/objects"+"/{objectID}", apiHandler.AddObject)
-	mux.Get("/objects"+"/{objectID}", apiHandler.Object)
+	// websocket handlers. Через подключение объекты записываются, поэтому на ведомом экземпляре и узле кластера
+	// оно, как и запись, отклоняется или перенаправляется ведущему.
+	wsHandler := ws.NewHandler(log, store, apiHandler, ws.WithAllowedOrigins(set.AllowedOrigins))
+	mux.With(writes).Get("/ws", wsHandler.Connect)
 
 	// metrics handler
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", promhttp.InstrumentMetricHandler(o.registry, promhttp.HandlerFor(o.registry, promhttp.HandlerOpts{})))
 
 	// healthchecks handlers
-	healtzHandler := healthz.NewHandler(log, store)
+	healtzHandler := healthz.NewHandler(log, store, healthzOpts...)
 	mux.Get("/probes/liveness", healtzHandler.Liveness)
 	mux.Get("/probes/readiness", healtzHandler.Readiness)
 
@@ -0,0 +1,393 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"st-test/internal/cluster"
+	"st-test/internal/http/handler/membership"
+	"st-test/internal/http/handler/middlewares/readonly"
+	"st-test/internal/http/handler/stats"
+	"st-test/internal/models"
+	"st-test/internal/replica"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
+	"st-test/internal/storage/mocks"
+)
+
+// instance экземпляр сервиса на localhost со своим хранилищем.
+type instance struct {
+	store *storage.Store
+	srv   *httptest.Server
+}
+
+// startInstance запускает экземпляр с пустым хранилищем; перед запуском сервиса вызывается prepare.
+func startInstance(t *testing.T, storeOpts []storage.Option, prepare func(store *storage.Store) []Option) instance {
+	t.Helper()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+	repo.EXPECT().Size(mock.Anything).Maybe().Return(int64(0), nil)
+
+	store := storage.NewStore(zap.NewNop(), repo, storeOpts...)
+
+	var opts []Option
+	if prepare != nil {
+		opts = prepare(store)
+	}
+
+	srv := httptest.NewServer(NewService(zap.NewNop(), &settings.APISettings{}, store, opts...).server.Handler)
+	t.Cleanup(srv.Close)
+
+	return instance{store: store, srv: srv}
+}
+
+// startFollower запускает ведомый экземпляр ведущего leader.
+func startFollower(t *testing.T, leader string, proxyWrites bool) (instance, *replica.Follower) {
+	t.Helper()
+
+	var f *replica.Follower
+
+	inst := startInstance(t, []storage.Option{storage.WithReplica()}, func(store *storage.Store) []Option {
+		var err error
+
+		f, err = replica.NewFollower(zap.NewNop(), store, settings.ReplicaSettings{Leader: leader, Retry: 10 * time.Millisecond})
+		require.NoError(t, err)
+
+		f.Run()
+		t.Cleanup(f.Stop)
+
+		return []Option{WithReplica(f, proxyWrites)}
+	})
+
+	return inst, f
+}
+
+func do(t *testing.T, method, url, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+func TestService_Replication(t *testing.T) {
+	t.Parallel()
+
+	leader := startInstance(t, nil, nil)
+
+	resp := do(t, http.MethodPut, leader.srv.URL+"/objects/1", `{"a":1}`)
+	require.Less(t, resp.StatusCode, http.StatusMultipleChoices, readBody(t, resp))
+
+	rejecting, rejectingFollower := startFollower(t, leader.srv.URL, false)
+	proxying, proxyingFollower := startFollower(t, leader.srv.URL, true)
+
+	// снимок ведущего загружается при запуске.
+	require.Eventually(t, func() bool {
+		resp := do(t, http.MethodGet, rejecting.srv.URL+"/objects/1", "")
+
+		return resp.StatusCode == http.StatusOK && readBody(t, resp) == `{"a":1}`
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// запись в ведомый отклоняется или перенаправляется ведущему.
+	resp = do(t, http.MethodPut, rejecting.srv.URL+"/objects/2", `{"b":2}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, leader.srv.URL, resp.Header.Get(readonly.LeaderHeader))
+
+	// через WebSocket-подключение объекты записываются, поэтому оно отклоняется, как и запись.
+	resp = do(t, http.MethodGet, rejecting.srv.URL+"/ws", "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = do(t, http.MethodPut, proxying.srv.URL+"/objects/2", `{"b":2}`)
+	require.Less(t, resp.StatusCode, http.StatusMultipleChoices, readBody(t, resp))
+
+	resp = do(t, http.MethodDelete, proxying.srv.URL+"/objects/1", "")
+	require.Less(t, resp.StatusCode, http.StatusMultipleChoices, readBody(t, resp))
+
+	// изменения ведущего доходят до обоих ведомых.
+	for _, f := range []*replica.Follower{rejectingFollower, proxyingFollower} {
+		require.Eventually(t, func() bool {
+			st := f.Status()
+
+			return st.Bootstrapped && st.Applied == leader.store.LastChange() && st.Lag() == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	for _, inst := range []instance{rejecting, proxying} {
+		resp = do(t, http.MethodGet, inst.srv.URL+"/objects/2", "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `{"b":2}`, readBody(t, resp))
+
+		resp = do(t, http.MethodGet, inst.srv.URL+"/objects/1", "")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = do(t, http.MethodGet, inst.srv.URL+"/probes/readiness", "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	resp = do(t, http.MethodGet, rejecting.srv.URL+"/admin/stats", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var got stats.Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.NotNil(t, got.Replication)
+	assert.Equal(t, leader.srv.URL, got.Replication.Leader)
+	assert.Equal(t, leader.store.LastChange(), got.Replication.AppliedSeq)
+	assert.Equal(t, uint64(0), got.Replication.Lag)
+	assert.Equal(t, 1, got.Objects)
+
+	// ведущий не отдаёт сведения о репликации.
+	resp = do(t, http.MethodGet, leader.srv.URL+"/admin/stats", "")
+	assert.NotContains(t, readBody(t, resp), `"replication"`)
+}
+
+func TestService_ReplicaSlidingTTL(t *testing.T) {
+	t.Parallel()
+
+	leader := startInstance(t, nil, nil)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, leader.srv.URL+"/objects/1", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-EXPIRES", "1s")
+	req.Header.Set("X-EXPIRES-SLIDING", "true")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Less(t, resp.StatusCode, http.StatusMultipleChoices, readBody(t, resp))
+	require.NoError(t, resp.Body.Close())
+
+	follower, f := startFollower(t, leader.srv.URL, false)
+
+	require.Eventually(t, func() bool { return f.Status().Bootstrapped }, 5*time.Second, 10*time.Millisecond)
+
+	// чтение на ведомом срок жизни не продлевает.
+	before, err := follower.store.StatObject(context.Background(), 1)
+	require.NoError(t, err)
+
+	resp = do(t, http.MethodGet, follower.srv.URL+"/objects/1", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	after, err := follower.store.StatObject(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, before.ExpiresAt, after.ExpiresAt)
+
+	// чтения на ведущем продлевают срок жизни дольше исходного, и ведомый получает продление.
+	deadline := time.Now().Add(1500 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		resp = do(t, http.MethodGet, leader.srv.URL+"/objects/1", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	require.Eventually(t, func() bool { return f.Status().Applied == leader.store.LastChange() }, 5*time.Second, 10*time.Millisecond)
+
+	leaderStat, err := leader.store.StatObject(context.Background(), 1)
+	require.NoError(t, err)
+
+	followerStat, err := follower.store.StatObject(context.Background(), 1)
+	require.NoError(t, err)
+	assert.True(t, leaderStat.ExpiresAt.Equal(followerStat.ExpiresAt))
+	assert.Equal(t, leaderStat.Version, followerStat.Version)
+
+	resp = do(t, http.MethodGet, follower.srv.URL+"/objects/1", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestService_ReplicaNotReady(t *testing.T) {
+	t.Parallel()
+
+	leader := httptest.NewServer(http.NotFoundHandler())
+	leader.Close()
+
+	follower, _ := startFollower(t, leader.URL, false)
+
+	resp := do(t, http.MethodGet, follower.srv.URL+"/probes/readiness", "")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Contains(t, readBody(t, resp), "snapshot")
+}
+
+// clusterNode узел кластера вместе с его http-сервером.
+type clusterNode struct {
+	instance
+	node *cluster.Node
+}
+
+// startCluster запускает кластер из size узлов на свободных портах localhost.
+func startCluster(t *testing.T, size int) []clusterNode {
+	t.Helper()
+
+	servers := make([]*httptest.Server, 0, size)
+	peers := make([]settings.PeerSettings, 0, size)
+
+	for i := range size {
+		srv := httptest.NewUnstartedServer(nil)
+		t.Cleanup(srv.Close)
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		raftAddr := l.Addr().String()
+		require.NoError(t, l.Close())
+
+		servers = append(servers, srv)
+		peers = append(peers, settings.PeerSettings{
+			ID:          fmt.Sprintf("node-%d", i+1),
+			RaftAddress: raftAddr,
+			HTTPAddress: "http://" + srv.Listener.Addr().String(),
+		})
+	}
+
+	nodes := make([]clusterNode, 0, size)
+
+	for i, p := range peers {
+		node, err := cluster.NewNode(zap.NewNop(), settings.ClusterSettings{
+			Enabled:          true,
+			NodeID:           p.ID,
+			RaftAddress:      p.RaftAddress,
+			HTTPAddress:      p.HTTPAddress,
+			Dir:              t.TempDir(),
+			Peers:            peers,
+			HeartbeatTimeout: 100 * time.Millisecond,
+			ElectionTimeout:  100 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		repo := mocks.NewRepo(t)
+		repo.EXPECT().Size(mock.Anything).Maybe().Return(int64(0), nil)
+		repo.EXPECT().ReadAll(mock.Anything).Maybe().Return(nil, models.ErrNotFound)
+		repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+		store := storage.NewStore(zap.NewNop(), repo, storage.WithProposer(node))
+		require.NoError(t, node.Start(store))
+		t.Cleanup(node.Stop)
+
+		srv := servers[i]
+		srv.Config.Handler = NewService(zap.NewNop(), &settings.APISettings{}, store, WithCluster(node)).server.Handler
+		srv.Start()
+
+		nodes = append(nodes, clusterNode{instance: instance{store: store, srv: srv}, node: node})
+	}
+
+	return nodes
+}
+
+// clusterLeader дожидается выбора ведущего и возвращает его.
+func clusterLeader(t *testing.T, nodes []clusterNode) clusterNode {
+	t.Helper()
+
+	var leader clusterNode
+
+	require.Eventually(t, func() bool {
+		for _, n := range nodes {
+			if l, err := n.node.Leader(); err == nil && l.Local {
+				leader = n
+
+				return true
+			}
+		}
+
+		return false
+	}, 10*time.Second, 10*time.Millisecond)
+
+	return leader
+}
+
+func TestService_Cluster(t *testing.T) {
+	t.Parallel()
+
+	nodes := startCluster(t, 3)
+	leader := clusterLeader(t, nodes)
+
+	var followers []clusterNode
+
+	for _, n := range nodes {
+		if n.srv != leader.srv {
+			followers = append(followers, n)
+		}
+	}
+
+	// запись через любой узел перенаправляется ведущему и реплицируется на все узлы.
+	resp := do(t, http.MethodPut, followers[0].srv.URL+"/objects/1", `{"a":1}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, readBody(t, resp))
+	assert.Equal(t, leader.srv.URL, resp.Header.Get(readonly.LeaderHeader))
+
+	// чтение сразу после записи видит её на любом узле.
+	for _, n := range nodes {
+		resp = do(t, http.MethodGet, n.srv.URL+"/objects/1", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `{"a":1}`, readBody(t, resp))
+
+		resp = do(t, http.MethodGet, n.srv.URL+"/probes/readiness", "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	for _, n := range nodes {
+		require.Eventually(t, func() bool {
+			_, err := n.store.StatObject(context.Background(), 1)
+
+			return err == nil
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	resp = do(t, http.MethodGet, followers[1].srv.URL+"/cluster", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var status membership.Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	assert.Equal(t, "Follower", status.State)
+	assert.Len(t, status.Members, 3)
+
+	// после остановки ведущего оставшиеся узлы выбирают нового и продолжают обслуживать запись.
+	leader.node.Stop()
+
+	next := clusterLeader(t, followers)
+
+	for _, n := range followers {
+		if n.srv == next.srv {
+			continue
+		}
+
+		require.Eventually(t, func() bool {
+			l, err := n.node.Leader()
+
+			return err == nil && l.ID != status.Leader
+		}, 10*time.Second, 10*time.Millisecond)
+
+		resp = do(t, http.MethodDelete, n.srv.URL+"/objects/1", "")
+		require.Equal(t, http.StatusNoContent, resp.StatusCode, readBody(t, resp))
+	}
+
+	resp = do(t, http.MethodGet, next.srv.URL+"/objects/1", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,99 @@
+// Package jsoncheck описывает потоковую проверку json: синтаксиса, глубины вложенности и количества ключей.
+// Проверка не держит документ в памяти целиком и подходит для тел произвольного размера.
+package jsoncheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrInvalid возвращается когда документ не является валидным json.
+	ErrInvalid = errors.New("invalid json")
+	// ErrTooDeep возвращается когда вложенность документа превышает допустимую.
+	ErrTooDeep = errors.New("json is nested too deep")
+	// ErrTooManyKeys возвращается когда ключей в документе больше допустимого.
+	ErrTooManyKeys = errors.New("json has too many keys")
+)
+
+// frame открытый массив или объект при обходе json.
+type frame struct {
+	object bool
+	// wantKey следующий токен объекта является ключом.
+	wantKey bool
+}
+
+// Check читает r до конца и проверяет, что в нём ровно один валидный json, глубина вложенности которого
+// не больше maxDepth, а ключей во всех объектах в сумме не больше maxKeys.
+// Ошибки синтаксиса оборачивают ErrInvalid, ошибки чтения r возвращаются как есть.
+func Check(r io.Reader, maxDepth, maxKeys int) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var (
+		stack []frame
+		keys  int
+		// done первое значение верхнего уровня прочитано целиком.
+		done bool
+	)
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			if !done {
+				return fmt.Errorf("%w: unexpected end of input", ErrInvalid)
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			var syntax *json.SyntaxError
+			if errors.As(err, &syntax) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("%w: %w", ErrInvalid, err)
+			}
+
+			return err //nolint:wrapcheck
+		}
+
+		if done {
+			return fmt.Errorf("%w: unexpected data after top-level value", ErrInvalid)
+		}
+
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			done = len(stack) == 0
+
+			continue
+		}
+
+		if n := len(stack); n > 0 && stack[n-1].object {
+			if stack[n-1].wantKey {
+				stack[n-1].wantKey = false
+
+				keys++
+				if keys > maxKeys {
+					return fmt.Errorf("%w: at most %d keys are allowed", ErrTooManyKeys, maxKeys)
+				}
+
+				continue
+			}
+
+			stack[n-1].wantKey = true
+		}
+
+		if d, ok := tok.(json.Delim); ok {
+			stack = append(stack, frame{object: d == '{', wantKey: true})
+
+			if len(stack) > maxDepth {
+				return fmt.Errorf("%w: at most %d levels are allowed", ErrTooDeep, maxDepth)
+			}
+
+			continue
+		}
+
+		done = len(stack) == 0
+	}
+}
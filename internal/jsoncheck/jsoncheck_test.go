@@ -0,0 +1,54 @@
+package jsoncheck
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read failed")
+
+	cases := []struct {
+		name    string
+		give    io.Reader
+		wantErr error
+	}{
+		{name: "scalar", give: strings.NewReader(`1`)},
+		{name: "flat object", give: strings.NewReader(`{"a":1,"b":"c","d":null}`)},
+		{name: "string values look like keys", give: strings.NewReader(`{"a":"b","c":["d","e","f","g"]}`)},
+		{name: "keys in nested objects", give: strings.NewReader(`[{"a":1},{"b":2},{"c":{"d":3}},{"e":4}]`), wantErr: ErrTooManyKeys},
+		{name: "deep arrays", give: strings.NewReader(strings.Repeat("[", 4) + strings.Repeat("]", 4)), wantErr: ErrTooDeep},
+		{name: "max depth", give: strings.NewReader(`{"a":{"b":[1]}}`)},
+		{name: "deep objects", give: strings.NewReader(`{"a":{"b":{"c":{}}}}`), wantErr: ErrTooDeep},
+		{name: "trailing whitespace", give: strings.NewReader("{}\n")},
+		{name: "empty", give: strings.NewReader(``), wantErr: ErrInvalid},
+		{name: "unterminated object", give: strings.NewReader(`{"a":1`), wantErr: ErrInvalid},
+		{name: "missing colon", give: strings.NewReader(`{"a" 1}`), wantErr: ErrInvalid},
+		{name: "two values", give: strings.NewReader(`{}{}`), wantErr: ErrInvalid},
+		{name: "one byte at a time", give: iotest.OneByteReader(strings.NewReader(`{"a":[1,{"b":2}]}`))},
+		{name: "read error", give: iotest.ErrReader(errRead), wantErr: errRead},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := Check(tt.give, 3, 4)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package jsonpath
+
+import (
+	"regexp"
+	"sort"
+)
+
+// node узел дерева выражения. eval возвращает найденные значения: для путей — все совпадения,
+// для литералов — одно значение, для логических операторов — одно значение типа bool.
+type node interface {
+	eval(root, current any) []any
+}
+
+type segmentKind int
+
+const (
+	segName segmentKind = iota
+	segIndex
+	segWildcard
+	segFilter
+)
+
+// segment один шаг пути.
+type segment struct {
+	kind      segmentKind
+	name      string
+	index     int
+	filter    node
+	recursive bool
+}
+
+// pathNode путь от корня документа ($) или от текущего элемента фильтра (@).
+type pathNode struct {
+	relative bool
+	segments []segment
+}
+
+func (n *pathNode) eval(root, current any) []any {
+	start := root
+	if n.relative {
+		start = current
+	}
+
+	nodes := []any{start}
+
+	for _, seg := range n.segments {
+		next := make([]any, 0, len(nodes))
+
+		for _, v := range nodes {
+			if seg.recursive {
+				for _, d := range descendants(v) {
+					next = seg.apply(root, d, next)
+				}
+
+				continue
+			}
+
+			next = seg.apply(root, v, next)
+		}
+
+		nodes = next
+		if len(nodes) == 0 {
+			break
+		}
+	}
+
+	return nodes
+}
+
+// apply применяет шаг пути к значению и добавляет результаты в dst.
+func (s segment) apply(root, v any, dst []any) []any {
+	switch s.kind {
+	case segName:
+		if obj, ok := v.(map[string]any); ok {
+			if child, ok := obj[s.name]; ok {
+				dst = append(dst, child)
+			}
+		}
+	case segIndex:
+		if arr, ok := v.([]any); ok {
+			idx := s.index
+			if idx < 0 {
+				idx += len(arr)
+			}
+
+			if idx >= 0 && idx < len(arr) {
+				dst = append(dst, arr[idx])
+			}
+		}
+	case segWildcard:
+		dst = append(dst, children(v)...)
+	case segFilter:
+		for _, child := range children(v) {
+			if truthy(s.filter.eval(root, child)) {
+				dst = append(dst, child)
+			}
+		}
+	}
+
+	return dst
+}
+
+// children возвращает непосредственных потомков значения. Для объектов порядок определяется ключами.
+func children(v any) []any {
+	switch t := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		res := make([]any, 0, len(t))
+		for _, k := range keys {
+			res = append(res, t[k])
+		}
+
+		return res
+	case []any:
+		return t
+	default:
+		return nil
+	}
+}
+
+// descendants возвращает само значение и всех его потомков.
+func descendants(v any) []any {
+	res := []any{v}
+
+	for _, child := range children(v) {
+		res = append(res, descendants(child)...)
+	}
+
+	return res
+}
+
+// literalNode литерал выражения.
+type literalNode struct {
+	value any
+}
+
+func (n *literalNode) eval(_, _ any) []any {
+	return []any{n.value}
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n *andNode) eval(root, current any) []any {
+	return []any{truthy(n.left.eval(root, current)) && truthy(n.right.eval(root, current))}
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n *orNode) eval(root, current any) []any {
+	return []any{truthy(n.left.eval(root, current)) || truthy(n.right.eval(root, current))}
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(root, current any) []any {
+	return []any{!truthy(n.operand.eval(root, current))}
+}
+
+// cmpNode сравнение двух операндов. Сравнение истинно, если ему удовлетворяет хотя бы одна пара значений.
+type cmpNode struct {
+	op          string
+	left, right node
+	re          *regexp.Regexp
+}
+
+func (n *cmpNode) eval(root, current any) []any {
+	left := n.left.eval(root, current)
+	right := n.right.eval(root, current)
+
+	for _, l := range left {
+		for _, r := range right {
+			if n.compare(l, r) {
+				return []any{true}
+			}
+		}
+	}
+
+	return []any{false}
+}
+
+func (n *cmpNode) compare(l, r any) bool {
+	if n.re != nil {
+		s, ok := l.(string)
+
+		return ok && n.re.MatchString(s)
+	}
+
+	c, comparable := compareValues(l, r)
+
+	switch n.op {
+	case "==":
+		return comparable && c == 0
+	case "!=":
+		return !comparable || c != 0
+	case "<":
+		return comparable && ordered(l) && c < 0
+	case "<=":
+		return comparable && ordered(l) && c <= 0
+	case ">":
+		return comparable && ordered(l) && c > 0
+	case ">=":
+		return comparable && ordered(l) && c >= 0
+	default:
+		return false
+	}
+}
+
+// compareValues сравнивает два значения одного типа. Второй результат false, если значения несравнимы.
+func compareValues(l, r any) (int, bool) {
+	switch lv := l.(type) {
+	case float64:
+		rv, ok := r.(float64)
+		if !ok {
+			return 0, false
+		}
+
+		switch {
+		case lv < rv:
+			return -1, true
+		case lv > rv:
+			return 1, true
+		default:
+			return 0, true
+		}
+	case string:
+		rv, ok := r.(string)
+		if !ok {
+			return 0, false
+		}
+
+		switch {
+		case lv < rv:
+			return -1, true
+		case lv > rv:
+			return 1, true
+		default:
+			return 0, true
+		}
+	case bool:
+		rv, ok := r.(bool)
+		if !ok || lv != rv {
+			return 1, ok
+		}
+
+		return 0, true
+	case nil:
+		if r == nil {
+			return 0, true
+		}
+
+		return 0, false
+	default:
+		// объекты и массивы сравниваются только на неравенство.
+		return 1, false
+	}
+}
+
+// ordered сообщает, можно ли сравнивать значение на больше/меньше.
+func ordered(v any) bool {
+	switch v.(type) {
+	case float64, string:
+		return true
+	default:
+		return false
+	}
+}
+
+// truthy интерпретирует результат вычисления узла как логическое значение.
+// Результат логического оператора — его значение, результат пути — наличие хотя бы одного совпадения.
+func truthy(values []any) bool {
+	if len(values) == 1 {
+		if b, ok := values[0].(bool); ok {
+			return b
+		}
+	}
+
+	return len(values) > 0
+}
@@ -0,0 +1,118 @@
+// Package jsonpath реализует разбор и вычисление JSONPath-выражений и простых фильтров над JSON-документами.
+//
+// Поддерживаемый синтаксис путей: $ (корень), @ (текущий элемент фильтра), .name, ['name'], [n], [*], .*, ..name
+// и фильтры [?(выражение)]. Выражение фильтра состоит из путей и литералов (строки, числа, true, false, null),
+// операторов сравнения ==, !=, <, <=, >, >=, =~ (регулярное выражение) и логических операторов &&, || и !.
+// Путь без сравнения истинен, если он находит хотя бы одно значение; единственное найденное логическое значение
+// используется как есть.
+package jsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrSyntax возвращается когда выражение не удалось разобрать.
+var ErrSyntax = errors.New("jsonpath syntax error")
+
+// Expr скомпилированное выражение фильтра.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse разбирает выражение фильтра. Выражение может быть как JSONPath (например, $.items[?(@.price > 10)]),
+// так и логическим условием (например, $.status == 'active' && $.count > 1).
+func Parse(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("%w: unexpected %q at %d", ErrSyntax, t.text, t.pos)
+	}
+
+	return &Expr{src: src, root: root}, nil
+}
+
+// String возвращает исходный текст выражения.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Match проверяет, удовлетворяет ли разобранный JSON-документ выражению.
+func (e *Expr) Match(doc any) bool {
+	return truthy(e.root.eval(doc, doc))
+}
+
+// MatchJSON разбирает JSON-документ и проверяет, удовлетворяет ли он выражению.
+func (e *Expr) MatchJSON(raw []byte) (bool, error) {
+	doc, err := Decode(raw)
+	if err != nil {
+		return false, err
+	}
+
+	return e.Match(doc), nil
+}
+
+// Path скомпилированный JSONPath для выборки значений из документа.
+type Path struct {
+	src  string
+	path *pathNode
+}
+
+// ParsePath разбирает JSONPath, который должен начинаться с $.
+func ParsePath(src string) (*Path, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	if t := p.peek(); t.kind != tokRoot {
+		return nil, fmt.Errorf("%w: path must start with '$'", ErrSyntax)
+	}
+
+	pn, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("%w: unexpected %q at %d", ErrSyntax, t.text, t.pos)
+	}
+
+	return &Path{src: src, path: pn}, nil
+}
+
+// String возвращает исходный текст пути.
+func (p *Path) String() string {
+	return p.src
+}
+
+// Select возвращает все значения документа, найденные по пути.
+func (p *Path) Select(doc any) []any {
+	return p.path.eval(doc, doc)
+}
+
+// Decode разбирает JSON-документ в представление, с которым работают выражения.
+// Числа представляются как float64, объекты как map[string]any, массивы как []any.
+func Decode(raw []byte) (any, error) {
+	var doc any
+
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("decode document: %w", err)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,128 @@
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDocument = `{
+	"status": "active",
+	"count": 3,
+	"enabled": true,
+	"owner": {"name": "bob", "tags": ["a", "b"]},
+	"items": [
+		{"name": "apple", "price": 5},
+		{"name": "melon", "price": 15}
+	]
+}`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		give    string
+		wantErr bool
+	}{
+		{name: "path", give: "$.status"},
+		{name: "comparison", give: "$.status == 'active'"},
+		{name: "logical", give: "$.count > 1 && ($.enabled || !$.missing)"},
+		{name: "filter", give: "$.items[?(@.price > 10)]"},
+		{name: "regexp", give: `$.owner.name =~ "^b"`},
+		{name: "empty", give: "", wantErr: true},
+		{name: "single equals", give: "$.status = 'active'", wantErr: true},
+		{name: "unterminated string", give: "$.status == 'active", wantErr: true},
+		{name: "unclosed bracket", give: "$.items[0", wantErr: true},
+		{name: "invalid regexp", give: "$.status =~ '('", wantErr: true},
+		{name: "trailing tokens", give: "$.status 'active'", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := Parse(tc.give)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrSyntax)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.give, expr.String())
+		})
+	}
+}
+
+func TestExpr_MatchJSON(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		give string
+		want bool
+	}{
+		{name: "existing path", give: "$.owner.name", want: true},
+		{name: "missing path", give: "$.owner.age", want: false},
+		{name: "boolean path", give: "$.enabled", want: true},
+		{name: "string equals", give: "$.status == 'active'", want: true},
+		{name: "string not equals", give: `$.status != "active"`, want: false},
+		{name: "number greater", give: "$.count > 2", want: true},
+		{name: "number less or equal", give: "$.count <= 2", want: false},
+		{name: "mixed types", give: "$.count == '3'", want: false},
+		{name: "and", give: "$.count >= 3 && $.status == 'active'", want: true},
+		{name: "or", give: "$.count > 10 || $.owner.name == 'bob'", want: true},
+		{name: "not", give: "!$.missing", want: true},
+		{name: "index", give: "$.items[1].name == 'melon'", want: true},
+		{name: "negative index", give: "$.items[-1].price == 15", want: true},
+		{name: "bracket name", give: "$['owner']['tags'][0] == 'a'", want: true},
+		{name: "wildcard", give: "$.owner.tags[*] == 'b'", want: true},
+		{name: "filter matches", give: "$.items[?(@.price > 10)]", want: true},
+		{name: "filter does not match", give: "$.items[?(@.price > 100)]", want: false},
+		{name: "recursive descent", give: "$..price == 5", want: true},
+		{name: "regexp", give: "$.owner.name =~ '^b.b$'", want: true},
+		{name: "null", give: "$.missing == null", want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := Parse(tc.give)
+			require.NoError(t, err)
+
+			got, err := expr.MatchJSON([]byte(testDocument))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	expr, err := Parse("$.status")
+	require.NoError(t, err)
+
+	_, err = expr.MatchJSON([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestPath_Select(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Decode([]byte(testDocument))
+	require.NoError(t, err)
+
+	p, err := ParsePath("$.items[*].price")
+	require.NoError(t, err)
+	require.Equal(t, []any{float64(5), float64(15)}, p.Select(doc))
+
+	p, err = ParsePath("$.owner.*")
+	require.NoError(t, err)
+	require.Len(t, p.Select(doc), 2)
+
+	_, err = ParsePath("@.price")
+	require.ErrorIs(t, err, ErrSyntax)
+
+	_, err = ParsePath("$.price > 1")
+	require.ErrorIs(t, err, ErrSyntax)
+}
@@ -0,0 +1,188 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind тип лексемы выражения.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokRoot
+	tokCurrent
+	tokDot
+	tokDotDot
+	tokLBracket
+	tokRBracket
+	tokLParen
+	tokRParen
+	tokQuestion
+	tokStar
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokNot
+	tokAnd
+	tokOr
+)
+
+// token лексема выражения.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex разбивает выражение на лексемы.
+func lex(src string) ([]token, error) { //nolint:funlen,gocognit,cyclop
+	tokens := make([]token, 0, len(src)/2)
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '$':
+			tokens = append(tokens, token{kind: tokRoot, text: "$", pos: i})
+			i++
+		case c == '@':
+			tokens = append(tokens, token{kind: tokCurrent, text: "@", pos: i})
+			i++
+		case c == '.':
+			if strings.HasPrefix(src[i:], "..") {
+				tokens = append(tokens, token{kind: tokDotDot, text: "..", pos: i})
+				i += 2
+
+				continue
+			}
+
+			tokens = append(tokens, token{kind: tokDot, text: ".", pos: i})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokLBracket, text: "[", pos: i})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokRBracket, text: "]", pos: i})
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case c == '?':
+			tokens = append(tokens, token{kind: tokQuestion, text: "?", pos: i})
+			i++
+		case c == '*':
+			tokens = append(tokens, token{kind: tokStar, text: "*", pos: i})
+			i++
+		case c == '&' || c == '|':
+			if i+1 >= len(src) || src[i+1] != c {
+				return nil, fmt.Errorf("%w: unexpected %q at %d", ErrSyntax, c, i)
+			}
+
+			kind := tokAnd
+			if c == '|' {
+				kind = tokOr
+			}
+
+			tokens = append(tokens, token{kind: kind, text: src[i : i+2], pos: i})
+			i += 2
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(src) && (src[i+1] == '=' || (c == '=' && src[i+1] == '~')) {
+				op = src[i : i+2]
+			}
+
+			switch op {
+			case "=":
+				return nil, fmt.Errorf("%w: unexpected '=' at %d, use '=='", ErrSyntax, i)
+			case "!":
+				tokens = append(tokens, token{kind: tokNot, text: op, pos: i})
+			default:
+				tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			}
+
+			i += len(op)
+		case c == '\'' || c == '"':
+			s, n, err := lexString(src[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w at %d", err, i)
+			}
+
+			tokens = append(tokens, token{kind: tokString, text: s, pos: i})
+			i += n
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(src) && strings.IndexByte("0123456789.eE+-", src[j]) >= 0 {
+				// '.' после числа может быть только дробной частью, а знак — только в экспоненте.
+				if (src[j] == '+' || src[j] == '-') && src[j-1] != 'e' && src[j-1] != 'E' {
+					break
+				}
+
+				j++
+			}
+
+			tokens = append(tokens, token{kind: tokNumber, text: src[i:j], pos: i})
+			i = j
+		case isIdentRune(rune(c)):
+			j := i
+			for j < len(src) && isIdentRune(rune(src[j])) {
+				j++
+			}
+
+			tokens = append(tokens, token{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q at %d", ErrSyntax, c, i)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokEOF, pos: len(src)})
+
+	return tokens, nil
+}
+
+// lexString разбирает строковый литерал в одинарных или двойных кавычках и возвращает его значение и длину.
+func lexString(src string) (string, int, error) {
+	quote := src[0]
+
+	var sb strings.Builder
+
+	for i := 1; i < len(src); i++ {
+		c := src[i]
+
+		switch {
+		case c == '\\':
+			if i+1 >= len(src) {
+				return "", 0, fmt.Errorf("%w: unterminated string", ErrSyntax)
+			}
+
+			i++
+
+			switch src[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(src[i])
+			}
+		case c == quote:
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", 0, fmt.Errorf("%w: unterminated string", ErrSyntax)
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '-' || r > unicode.MaxASCII || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
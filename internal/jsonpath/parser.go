@@ -0,0 +1,276 @@
+package jsonpath
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// parser рекурсивный нисходящий разборщик выражений.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("%w: expected %s at %d, got %q", ErrSyntax, what, t.pos, t.text)
+	}
+
+	return t, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOr {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &orNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokAnd {
+		p.next()
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &andNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &notNode{operand: operand}, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	if p.peek().kind == tokLParen {
+		p.next()
+
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.expect(tokRParen, "')'"); err != nil {
+			return nil, err
+		}
+
+		return inner, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != tokOp {
+		return left, nil
+	}
+
+	op := p.next()
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := &cmpNode{op: op.text, left: left, right: right}
+
+	if op.text == "=~" {
+		lit, ok := right.(*literalNode)
+		if !ok {
+			return nil, fmt.Errorf("%w: right side of '=~' must be a string literal", ErrSyntax)
+		}
+
+		pattern, ok := lit.value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: right side of '=~' must be a string literal", ErrSyntax)
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid regexp: %w", ErrSyntax, err)
+		}
+
+		cmp.re = re
+	}
+
+	return cmp, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.peek()
+
+	switch t.kind {
+	case tokRoot, tokCurrent:
+		return p.parsePath()
+	case tokString:
+		p.next()
+
+		return &literalNode{value: t.text}, nil
+	case tokNumber:
+		p.next()
+
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %q at %d", ErrSyntax, t.text, t.pos)
+		}
+
+		return &literalNode{value: f}, nil
+	case tokIdent:
+		p.next()
+
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+	default:
+	}
+
+	return nil, fmt.Errorf("%w: unexpected %q at %d", ErrSyntax, t.text, t.pos)
+}
+
+func (p *parser) parsePath() (*pathNode, error) {
+	start := p.next()
+
+	pn := &pathNode{relative: start.kind == tokCurrent}
+
+	for {
+		switch p.peek().kind {
+		case tokDot, tokDotDot:
+			seg, err := p.parseDotSegment()
+			if err != nil {
+				return nil, err
+			}
+
+			pn.segments = append(pn.segments, seg)
+		case tokLBracket:
+			seg, err := p.parseBracketSegment()
+			if err != nil {
+				return nil, err
+			}
+
+			pn.segments = append(pn.segments, seg)
+		default:
+			return pn, nil
+		}
+	}
+}
+
+func (p *parser) parseDotSegment() (segment, error) {
+	dot := p.next()
+	seg := segment{recursive: dot.kind == tokDotDot}
+
+	t := p.next()
+
+	switch t.kind {
+	case tokStar:
+		seg.kind = segWildcard
+	case tokIdent, tokNumber:
+		seg.kind = segName
+		seg.name = t.text
+	default:
+		return seg, fmt.Errorf("%w: expected name after %q at %d", ErrSyntax, dot.text, t.pos)
+	}
+
+	return seg, nil
+}
+
+func (p *parser) parseBracketSegment() (segment, error) {
+	p.next()
+
+	var seg segment
+
+	t := p.next()
+
+	switch t.kind {
+	case tokStar:
+		seg.kind = segWildcard
+	case tokString:
+		seg.kind = segName
+		seg.name = t.text
+	case tokNumber:
+		idx, err := strconv.Atoi(t.text)
+		if err != nil {
+			return seg, fmt.Errorf("%w: invalid index %q at %d", ErrSyntax, t.text, t.pos)
+		}
+
+		seg.kind = segIndex
+		seg.index = idx
+	case tokQuestion:
+		if _, err := p.expect(tokLParen, "'('"); err != nil {
+			return seg, err
+		}
+
+		filter, err := p.parseOr()
+		if err != nil {
+			return seg, err
+		}
+
+		if _, err := p.expect(tokRParen, "')'"); err != nil {
+			return seg, err
+		}
+
+		seg.kind = segFilter
+		seg.filter = filter
+	default:
+		return seg, fmt.Errorf("%w: unexpected %q at %d", ErrSyntax, t.text, t.pos)
+	}
+
+	if _, err := p.expect(tokRBracket, "']'"); err != nil {
+		return seg, err
+	}
+
+	return seg, nil
+}
@@ -0,0 +1,111 @@
+// Package keyfilter описывает фильтры id объектов: конкретный id ("42"), диапазон ("10-20") или шаблон ("7*").
+package keyfilter
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidKey возвращается когда фильтр не удалось разобрать.
+var ErrInvalidKey = errors.New("invalid key filter")
+
+// Key фильтр id объектов.
+type Key struct {
+	raw      string
+	from, to int
+	pattern  string
+}
+
+// String возвращает исходный текст фильтра.
+func (k Key) String() string {
+	return k.raw
+}
+
+// Match сообщает, подходит ли id под фильтр.
+func (k Key) Match(id int) bool {
+	if k.pattern != "" {
+		ok, _ := path.Match(k.pattern, strconv.Itoa(id))
+
+		return ok
+	}
+
+	return id >= k.from && id <= k.to
+}
+
+// Parse разбирает фильтр вида "42", "10-20" или "7*". Шаблон применяется к десятичной записи id.
+func Parse(raw string) (Key, error) {
+	raw = strings.TrimSpace(raw)
+
+	if id, err := strconv.Atoi(raw); err == nil {
+		return Key{raw: raw, from: id, to: id}, nil
+	}
+
+	if strings.ContainsAny(raw, "*?[") {
+		if _, err := path.Match(raw, ""); err != nil {
+			return Key{}, fmt.Errorf("%w %q: %w", ErrInvalidKey, raw, err)
+		}
+
+		return Key{raw: raw, pattern: raw}, nil
+	}
+
+	// первый символ пропускаем, чтобы не спутать минус отрицательного id с разделителем диапазона.
+	if i := strings.Index(raw[min(1, len(raw)):], "-"); i >= 0 {
+		sep := i + 1
+
+		from, errFrom := strconv.Atoi(raw[:sep])
+		to, errTo := strconv.Atoi(raw[sep+1:])
+
+		if errFrom == nil && errTo == nil && from <= to {
+			return Key{raw: raw, from: from, to: to}, nil
+		}
+	}
+
+	return Key{}, fmt.Errorf("%w %q", ErrInvalidKey, raw)
+}
+
+// ParseAll разбирает список фильтров.
+func ParseAll(raw []string) ([]Key, error) {
+	keys := make([]Key, 0, len(raw))
+
+	for _, r := range raw {
+		k, err := Parse(r)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+// Set набор фильтров. id подходит под набор, если подходит хотя бы под один фильтр.
+type Set map[string]Key
+
+// Add добавляет фильтры в набор.
+func (s Set) Add(keys ...Key) {
+	for _, k := range keys {
+		s[k.raw] = k
+	}
+}
+
+// Remove удаляет фильтры из набора.
+func (s Set) Remove(keys ...Key) {
+	for _, k := range keys {
+		delete(s, k.raw)
+	}
+}
+
+// Match сообщает, подходит ли id хотя бы под один фильтр набора.
+func (s Set) Match(id int) bool {
+	for _, k := range s {
+		if k.Match(id) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,35 @@
+package keyfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAll(t *testing.T) {
+	t.Parallel()
+
+	keys, err := ParseAll([]string{"42", "-3", "10-20", "-5--1", "7*"})
+	require.NoError(t, err)
+	require.Equal(t, "10-20", keys[2].String())
+
+	s := make(Set)
+	s.Add(keys...)
+
+	for _, id := range []int{42, -3, 10, 15, 20, -5, -1, 7, 70, 789} {
+		assert.True(t, s.Match(id), id)
+	}
+
+	for _, id := range []int{41, 9, 21, -6, 0, 1700} {
+		assert.False(t, s.Match(id), id)
+	}
+
+	s.Remove(keys[0])
+	assert.False(t, s.Match(42))
+
+	for _, give := range []string{"", "abc", "20-10", "1-2-3", "[1"} {
+		_, err := Parse(give)
+		assert.ErrorIs(t, err, ErrInvalidKey, give)
+	}
+}
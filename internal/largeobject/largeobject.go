@@ -0,0 +1,375 @@
+// Package largeobject описывает хранение крупных объектов, тела которых не помещаются в память:
+// потоковую запись с проверкой json на лету, чтение частями и возобновляемые загрузки.
+package largeobject
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+	"time"
+
+	"st-test/internal/jsoncheck"
+	"st-test/internal/mediatype"
+	"st-test/internal/models"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultThreshold = 1 << 20
+	defaultMaxSize   = 1 << 30
+	defaultChunkSize = 256 << 10
+	defaultUploadTTL = 24 * time.Hour
+	defaultTimeout   = 10 * time.Minute
+	// ограничения на структуру json по умолчанию такие же, как у обычных объектов.
+	defaultMaxJSONDepth = 64
+	defaultMaxJSONKeys  = 10000
+	// purgeInterval период отмены заброшенных загрузок.
+	purgeInterval = time.Minute
+)
+
+// Repo описывает методы хранилища для хранения крупных объектов частями.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Repo --with-expecter=true
+type Repo interface {
+	CreateUpload(key int, owner, contentType string) (models.Upload, error)
+	Upload(id string) (models.Upload, error)
+	AppendUpload(ctx context.Context, id string, offset int64, body io.Reader, chunkSize int, limit int64) (models.Upload, error)
+	OpenUpload(ctx context.Context, id string) (models.Upload, io.ReadSeeker, error)
+	CommitUpload(id, sum string) (models.LargeObject, bool, error)
+	DeleteUpload(id string) error
+	PurgeUploads(before time.Time) (int, error)
+	OpenLargeObject(ctx context.Context, key int) (models.LargeObject, io.ReadSeeker, error)
+	DeleteLargeObject(key int) error
+	LargeObjects() ([]models.LargeObject, error)
+}
+
+// Objects описывает хранилище обычных объектов. Оно учитывает крупные объекты в квотах клиентов и публикует
+// их изменения; объект с тем же id, что и у записанного крупного объекта, убирается из него,
+// чтобы по одному id всегда был один объект.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Objects --with-expecter=true
+type Objects interface {
+	LoadLarge(ctx context.Context, objs []models.LargeObject)
+	ReserveLarge(ctx context.Context, uploadID string, id int, owner string, size int64) (int64, error)
+	ReleaseLarge(ctx context.Context, uploadID string)
+	PutLarge(ctx context.Context, uploadID string, obj models.LargeObject) bool
+	DeleteLarge(ctx context.Context, id int)
+}
+
+// Store хранит крупные объекты и периодически отменяет заброшенные загрузки.
+type Store struct {
+	log     *zap.Logger
+	repo    Repo
+	objects Objects
+	set     settings.LargeObjectSettings
+	// maxDepth и maxKeys ограничения на структуру json тела.
+	maxDepth, maxKeys int
+	// types правила приёма тел по типу содержимого.
+	types mediatype.Policy
+
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewStore конструктор для Store. Ограничения на структуру json и допустимые типы содержимого берутся из limits;
+// нулевые значения настроек заменяются значениями по умолчанию.
+func NewStore(
+	log *zap.Logger, repo Repo, objects Objects, set settings.LargeObjectSettings, limits settings.LimitsSettings,
+) *Store {
+	if set.Threshold <= 0 {
+		set.Threshold = defaultThreshold
+	}
+
+	if set.MaxSize <= 0 {
+		set.MaxSize = defaultMaxSize
+	}
+
+	if set.ChunkSize <= 0 {
+		set.ChunkSize = defaultChunkSize
+	}
+
+	if set.UploadTTL <= 0 {
+		set.UploadTTL = defaultUploadTTL
+	}
+
+	if set.Timeout <= 0 {
+		set.Timeout = defaultTimeout
+	}
+
+	if limits.MaxJSONDepth <= 0 {
+		limits.MaxJSONDepth = defaultMaxJSONDepth
+	}
+
+	if limits.MaxJSONKeys <= 0 {
+		limits.MaxJSONKeys = defaultMaxJSONKeys
+	}
+
+	return &Store{
+		log:      log.Named("large objects"),
+		repo:     repo,
+		objects:  objects,
+		set:      set,
+		maxDepth: limits.MaxJSONDepth,
+		maxKeys:  limits.MaxJSONKeys,
+		types:    mediatype.NewPolicy(limits.ContentTypes, limits.JSONContentTypes),
+		done:     make(chan struct{}),
+	}
+}
+
+// Threshold возвращает размер тела, начиная с которого объект считается крупным.
+func (s *Store) Threshold() int64 {
+	return s.set.Threshold
+}
+
+// Timeout возвращает время на запись или чтение одного крупного объекта.
+func (s *Store) Timeout() time.Duration {
+	return s.set.Timeout
+}
+
+// Load учитывает сохранённые крупные объекты в квотах хранилища обычных объектов. Вызывается при запуске до Run.
+func (s *Store) Load(ctx context.Context) error {
+	objs, err := s.repo.LargeObjects()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	s.objects.LoadLarge(ctx, objs)
+
+	return nil
+}
+
+// Run запускает периодическую отмену заброшенных загрузок.
+func (s *Store) Run() {
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(purgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.done:
+				return
+			case now := <-ticker.C:
+				s.purge(now)
+			}
+		}
+	}()
+}
+
+// Stop останавливает отмену заброшенных загрузок.
+func (s *Store) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+
+	s.wg.Wait()
+}
+
+// purge отменяет загрузки, которые не продолжались дольше UploadTTL к моменту now.
+func (s *Store) purge(now time.Time) {
+	n, err := s.repo.PurgeUploads(now.Add(-s.set.UploadTTL))
+	if err != nil {
+		s.log.Error("cannot purge stale uploads", zap.Error(err))
+	}
+
+	if n > 0 {
+		s.log.Info("stale uploads purged", zap.Int("count", n))
+	}
+}
+
+// Put записывает тело body с типом содержимого contentType как крупный объект id клиента owner, проверяя json
+// по мере чтения, если тип содержимого — json. Тело не держится в памяти целиком. Возвращает объект и признак того,
+// что объекта с таким id раньше не было. Недопустимый тип содержимого возвращает ошибку, оборачивающую
+// mediatype.ErrInvalid или mediatype.ErrUnsupported, невалидный json — jsoncheck.ErrInvalid, нарушение ограничений
+// на структуру — jsoncheck.ErrTooDeep или jsoncheck.ErrTooManyKeys, слишком большое тело — models.ErrTooLarge,
+// тело, не помещающееся в квоту клиента, — storage.ErrQuotaExceeded.
+func (s *Store) Put(ctx context.Context, id int, owner, contentType string, body io.Reader) (models.LargeObject, bool, error) {
+	contentType, isJSON, err := s.types.Check(contentType)
+	if err != nil {
+		return models.LargeObject{}, false, err //nolint:wrapcheck
+	}
+
+	up, err := s.repo.CreateUpload(id, owner, contentType)
+	if err != nil {
+		return models.LargeObject{}, false, err //nolint:wrapcheck
+	}
+
+	// место в квоте занимается до записи тела, и запись ограничивается тем, что в квоте осталось.
+	remaining, err := s.objects.ReserveLarge(ctx, up.ID, id, owner, 0)
+	if err != nil {
+		s.abort(ctx, up.ID)
+
+		return models.LargeObject{}, false, err //nolint:wrapcheck
+	}
+
+	limit := s.set.MaxSize
+	quotaLimited := remaining >= 0 && remaining < limit
+
+	if quotaLimited {
+		limit = remaining
+	}
+
+	sum := sha256.New()
+
+	var written models.Upload
+
+	if isJSON {
+		written, err = s.appendJSON(ctx, up.ID, io.TeeReader(body, sum), limit)
+	} else {
+		written, err = s.repo.AppendUpload(ctx, up.ID, 0, io.TeeReader(body, sum), s.set.ChunkSize, limit)
+	}
+
+	if err == nil {
+		obj, created, commitErr := s.commit(ctx, written, sum)
+		if commitErr == nil {
+			return obj, created, nil
+		}
+
+		err = commitErr
+	}
+
+	s.abort(ctx, up.ID)
+
+	if quotaLimited && errors.Is(err, models.ErrTooLarge) {
+		err = fmt.Errorf("%w: only %d bytes are left", storage.ErrQuotaExceeded, remaining)
+	}
+
+	return models.LargeObject{}, false, err //nolint:wrapcheck
+}
+
+// appendJSON записывает тело body в загрузку uploadID, одновременно проверяя json, и возвращает загрузку.
+func (s *Store) appendJSON(ctx context.Context, uploadID string, body io.Reader, limit int64) (models.Upload, error) {
+	// проверка, найдя ошибку, закрывает канал с этой ошибкой, и запись тоже прерывается.
+	pr, pw := io.Pipe()
+	checked := make(chan error, 1)
+
+	go func() {
+		err := jsoncheck.Check(pr, s.maxDepth, s.maxKeys)
+		pr.CloseWithError(err)
+		checked <- err
+	}()
+
+	up, err := s.repo.AppendUpload(ctx, uploadID, 0, io.TeeReader(body, pw), s.set.ChunkSize, limit)
+	pw.CloseWithError(err)
+
+	if checkErr := <-checked; err == nil {
+		err = checkErr
+	}
+
+	return up, err //nolint:wrapcheck
+}
+
+// Open возвращает крупный объект и чтение его тела.
+func (s *Store) Open(ctx context.Context, id int) (models.LargeObject, io.ReadSeeker, error) {
+	return s.repo.OpenLargeObject(ctx, id) //nolint:wrapcheck
+}
+
+// Delete удаляет крупный объект и публикует его удаление.
+func (s *Store) Delete(ctx context.Context, id int) error {
+	if err := s.repo.DeleteLargeObject(id); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	s.objects.DeleteLarge(ctx, id)
+
+	return nil
+}
+
+// CreateUpload начинает возобновляемую загрузку крупного объекта id с типом содержимого contentType клиента owner.
+// Ошибки проверки типа содержимого такие же, как у Put.
+func (s *Store) CreateUpload(_ context.Context, id int, owner, contentType string) (models.Upload, error) {
+	contentType, _, err := s.types.Check(contentType)
+	if err != nil {
+		return models.Upload{}, err //nolint:wrapcheck
+	}
+
+	return s.repo.CreateUpload(id, owner, contentType) //nolint:wrapcheck
+}
+
+// Upload возвращает загрузку по id; по её смещению клиент узнаёт, с какого места продолжить.
+func (s *Store) Upload(_ context.Context, uploadID string) (models.Upload, error) {
+	return s.repo.Upload(uploadID) //nolint:wrapcheck
+}
+
+// Append дописывает body к загрузке, начиная с offset. Если offset не совпадает с уже принятым объёмом,
+// возвращается models.ErrOffsetMismatch, если загрузка превысит допустимый размер — models.ErrTooLarge.
+func (s *Store) Append(ctx context.Context, uploadID string, offset int64, body io.Reader) (models.Upload, error) {
+	return s.repo.AppendUpload(ctx, uploadID, offset, body, s.set.ChunkSize, s.set.MaxSize) //nolint:wrapcheck
+}
+
+// Complete проверяет принятое тело загрузки и делает его телом крупного объекта.
+// Ошибки проверки json и квоты такие же, как у Put; загрузка с невалидным телом или не поместившаяся в квоту
+// остаётся, и её можно отменить.
+func (s *Store) Complete(ctx context.Context, uploadID string) (models.LargeObject, bool, error) {
+	up, body, err := s.repo.OpenUpload(ctx, uploadID)
+	if err != nil {
+		return models.LargeObject{}, false, err //nolint:wrapcheck
+	}
+
+	sum := sha256.New()
+
+	if s.types.IsJSON(up.ContentType) {
+		err = jsoncheck.Check(io.TeeReader(body, sum), s.maxDepth, s.maxKeys)
+	} else {
+		_, err = io.Copy(sum, body)
+	}
+
+	if err != nil {
+		return models.LargeObject{}, false, err //nolint:wrapcheck
+	}
+
+	return s.commit(ctx, up, sum)
+}
+
+// Abort отменяет загрузку вместе с принятыми частями.
+func (s *Store) Abort(ctx context.Context, uploadID string) error {
+	if err := s.repo.DeleteUpload(uploadID); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	s.objects.ReleaseLarge(ctx, uploadID)
+
+	return nil
+}
+
+// commit занимает в квоте место под принятое тело загрузки up, завершает её и заменяет в хранилище обычных объектов
+// объект с тем же id крупным.
+func (s *Store) commit(ctx context.Context, up models.Upload, sum hash.Hash) (models.LargeObject, bool, error) {
+	if _, err := s.objects.ReserveLarge(ctx, up.ID, up.ObjectID, up.Owner, up.Offset); err != nil {
+		return models.LargeObject{}, false, err //nolint:wrapcheck
+	}
+
+	obj, _, err := s.repo.CommitUpload(up.ID, hex.EncodeToString(sum.Sum(nil)))
+	if err != nil {
+		s.objects.ReleaseLarge(ctx, up.ID)
+
+		return obj, false, err //nolint:wrapcheck
+	}
+
+	created := s.objects.PutLarge(ctx, up.ID, obj)
+
+	s.log.Info("large object saved", zap.Int("id", obj.ID), zap.Int64("size", obj.Size))
+
+	return obj, created, nil
+}
+
+// abort отменяет загрузку, запись которой не удалась, и освобождает её место в квоте.
+func (s *Store) abort(ctx context.Context, uploadID string) {
+	if err := s.repo.DeleteUpload(uploadID); err != nil {
+		s.log.Error("cannot delete failed upload", zap.String("upload", uploadID), zap.Error(err))
+	}
+
+	s.objects.ReleaseLarge(ctx, uploadID)
+}
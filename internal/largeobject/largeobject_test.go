@@ -0,0 +1,272 @@
+package largeobject
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"st-test/internal/jsoncheck"
+	"st-test/internal/largeobject/mocks"
+	"st-test/internal/mediatype"
+	"st-test/internal/models"
+	"st-test/internal/repo"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
+)
+
+// largeBody возвращает json-массив из n элементов.
+func largeBody(n int) string {
+	return "[" + strings.TrimSuffix(strings.Repeat(`{"key":"value"},`, n), ",") + "]"
+}
+
+func testStore(t *testing.T, objects Objects) (*Store, *repo.Repo) {
+	t.Helper()
+
+	r, err := repo.NewRepo(settings.LocalStorageSettings{Path: filepath.Join(t.TempDir(), "storage.db")})
+	require.NoError(t, err)
+
+	t.Cleanup(r.Close)
+
+	s := NewStore(zap.NewNop(), r, objects,
+		settings.LargeObjectSettings{ChunkSize: 64, MaxSize: 4096},
+		settings.LimitsSettings{
+			MaxJSONDepth: 3,
+			MaxJSONKeys:  1000,
+			ContentTypes: []string{"application/json", "application/octet-stream"},
+		})
+
+	return s, r
+}
+
+func readAll(t *testing.T, s *Store, id int) string {
+	t.Helper()
+
+	_, body, err := s.Open(context.Background(), id)
+	require.NoError(t, err)
+
+	raw, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	return string(raw)
+}
+
+func TestStore_Put(t *testing.T) {
+	t.Parallel()
+
+	body := largeBody(50)
+	size := int64(len(body))
+
+	objects := mocks.NewObjects(t)
+	objects.EXPECT().ReserveLarge(mock.Anything, mock.Anything, 1, "importer", int64(0)).Return(-1, nil).Once()
+	objects.EXPECT().ReserveLarge(mock.Anything, mock.Anything, 1, "importer", size).Return(-1, nil).Once()
+	objects.EXPECT().PutLarge(mock.Anything, mock.Anything, mock.MatchedBy(func(obj models.LargeObject) bool {
+		return obj.ID == 1 && obj.Size == size
+	})).Return(true).Once()
+	objects.EXPECT().ReserveLarge(mock.Anything, mock.Anything, 2, "", int64(0)).Return(-1, nil).Once()
+	objects.EXPECT().ReserveLarge(mock.Anything, mock.Anything, 2, "", size).Return(-1, nil).Once()
+	objects.EXPECT().PutLarge(mock.Anything, mock.Anything, mock.MatchedBy(func(obj models.LargeObject) bool {
+		return obj.ID == 2
+	})).Return(false).Once()
+	objects.EXPECT().DeleteLarge(mock.Anything, 1).Once()
+
+	s, r := testStore(t, objects)
+	ctx := context.Background()
+
+	obj, created, err := s.Put(ctx, 1, "importer", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Equal(t, size, obj.Size)
+	require.Equal(t, "importer", obj.Owner)
+
+	sum := sha256.Sum256([]byte(body))
+	require.Equal(t, hex.EncodeToString(sum[:]), obj.Checksum)
+	require.Equal(t, body, readAll(t, s, 1))
+
+	// крупный объект заменяет обычный объект с тем же id.
+	_, created, err = s.Put(ctx, 2, "", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	require.False(t, created)
+
+	// чтение с произвольного места.
+	_, rd, err := s.Open(ctx, 1)
+	require.NoError(t, err)
+
+	_, err = rd.Seek(int64(len(body)-10), io.SeekStart)
+	require.NoError(t, err)
+
+	tail, err := io.ReadAll(rd)
+	require.NoError(t, err)
+	require.Equal(t, body[len(body)-10:], string(tail))
+
+	require.NoError(t, s.Delete(ctx, 1))
+
+	_, _, err = s.Open(ctx, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	// незавершённых загрузок не остаётся.
+	n, err := r.PurgeUploads(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestStore_PutInvalid(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		give    string
+		wantErr error
+	}{
+		{name: "invalid json", give: largeBody(10) + "]", wantErr: jsoncheck.ErrInvalid},
+		{name: "truncated json", give: largeBody(10)[:100], wantErr: jsoncheck.ErrInvalid},
+		{name: "too deep", give: `[[[[` + strings.Repeat(" ", 200) + `]]]]`, wantErr: jsoncheck.ErrTooDeep},
+		{name: "too large", give: largeBody(500), wantErr: models.ErrTooLarge},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			objects := mocks.NewObjects(t)
+			objects.EXPECT().ReserveLarge(mock.Anything, mock.Anything, 1, "", int64(0)).Return(-1, nil).Once()
+			objects.EXPECT().ReleaseLarge(mock.Anything, mock.Anything).Once()
+
+			s, r := testStore(t, objects)
+
+			_, _, err := s.Put(context.Background(), 1, "", "application/json", strings.NewReader(tt.give))
+			require.ErrorIs(t, err, tt.wantErr)
+
+			_, _, err = s.Open(context.Background(), 1)
+			require.ErrorIs(t, err, models.ErrNotFound)
+
+			n, err := r.PurgeUploads(time.Now().Add(time.Hour))
+			require.NoError(t, err)
+			require.Zero(t, n)
+		})
+	}
+}
+
+func TestStore_PutBinary(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Repeat("\x00\xff", 100)
+
+	objects := mocks.NewObjects(t)
+	objects.EXPECT().ReserveLarge(mock.Anything, mock.Anything, 1, "", mock.Anything).Return(-1, nil).Twice()
+	objects.EXPECT().PutLarge(mock.Anything, mock.Anything, mock.Anything).Return(true).Once()
+
+	s, _ := testStore(t, objects)
+	ctx := context.Background()
+
+	// тело не json не проверяется, а тип содержимого сохраняется вместе с объектом.
+	obj, _, err := s.Put(ctx, 1, "", "Application/Octet-Stream", strings.NewReader(body))
+	require.NoError(t, err)
+	require.Equal(t, "application/octet-stream", obj.ContentType)
+	require.Equal(t, body, readAll(t, s, 1))
+
+	_, _, err = s.Put(ctx, 2, "", "image/png", strings.NewReader(body))
+	require.ErrorIs(t, err, mediatype.ErrUnsupported)
+
+	_, err = s.CreateUpload(ctx, 2, "", "image/png")
+	require.ErrorIs(t, err, mediatype.ErrUnsupported)
+}
+
+func TestStore_ResumableUpload(t *testing.T) {
+	t.Parallel()
+
+	body := largeBody(20)
+
+	objects := mocks.NewObjects(t)
+	objects.EXPECT().ReserveLarge(mock.Anything, mock.Anything, 7, "", int64(len(body))).Return(-1, nil).Once()
+	objects.EXPECT().PutLarge(mock.Anything, mock.Anything, mock.Anything).Return(true).Once()
+
+	s, _ := testStore(t, objects)
+	ctx := context.Background()
+
+	up, err := s.CreateUpload(ctx, 7, "", "application/json")
+	require.NoError(t, err)
+
+	up, err = s.Append(ctx, up.ID, 0, strings.NewReader(body[:100]))
+	require.NoError(t, err)
+	require.Equal(t, int64(100), up.Offset)
+
+	// продолжение не с того места отклоняется.
+	_, err = s.Append(ctx, up.ID, 50, strings.NewReader(body[50:]))
+	require.ErrorIs(t, err, models.ErrOffsetMismatch)
+
+	// незавершённое тело не проходит проверку, загрузку можно продолжить.
+	_, _, err = s.Complete(ctx, up.ID)
+	require.ErrorIs(t, err, jsoncheck.ErrInvalid)
+
+	up, err = s.Upload(ctx, up.ID)
+	require.NoError(t, err)
+
+	_, err = s.Append(ctx, up.ID, up.Offset, strings.NewReader(body[up.Offset:]))
+	require.NoError(t, err)
+
+	obj, created, err := s.Complete(ctx, up.ID)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Equal(t, 7, obj.ID)
+	require.Equal(t, body, readAll(t, s, 7))
+
+	_, err = s.Upload(ctx, up.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestStore_PutQuota(t *testing.T) {
+	t.Parallel()
+
+	objects := mocks.NewObjects(t)
+	// в квоте клиента осталось 100 байт: запись прерывается, как только тело их превысит.
+	objects.EXPECT().ReserveLarge(mock.Anything, mock.Anything, 1, "importer", int64(0)).Return(100, nil).Once()
+	objects.EXPECT().ReserveLarge(mock.Anything, mock.Anything, 2, "importer", int64(0)).
+		Return(0, storage.ErrQuotaExceeded).Once()
+	objects.EXPECT().ReleaseLarge(mock.Anything, mock.Anything).Twice()
+
+	s, r := testStore(t, objects)
+	ctx := context.Background()
+
+	_, _, err := s.Put(ctx, 1, "importer", "application/json", strings.NewReader(largeBody(50)))
+	require.ErrorIs(t, err, storage.ErrQuotaExceeded)
+
+	_, _, err = s.Put(ctx, 2, "importer", "application/json", strings.NewReader(largeBody(1)))
+	require.ErrorIs(t, err, storage.ErrQuotaExceeded)
+
+	_, _, err = s.Open(ctx, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	n, err := r.PurgeUploads(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestStore_Purge(t *testing.T) {
+	t.Parallel()
+
+	s, _ := testStore(t, mocks.NewObjects(t))
+	ctx := context.Background()
+
+	up, err := s.CreateUpload(ctx, 1, "", "application/json")
+	require.NoError(t, err)
+
+	s.purge(time.Now())
+
+	_, err = s.Upload(ctx, up.ID)
+	require.NoError(t, err)
+
+	s.purge(time.Now().Add(s.set.UploadTTL + time.Second))
+
+	_, err = s.Upload(ctx, up.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
@@ -0,0 +1,248 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "st-test/internal/models"
+)
+
+// Objects is an autogenerated mock type for the Objects type
+type Objects struct {
+	mock.Mock
+}
+
+type Objects_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Objects) EXPECT() *Objects_Expecter {
+	return &Objects_Expecter{mock: &_m.Mock}
+}
+
+// DeleteLarge provides a mock function with given fields: ctx, id
+func (_m *Objects) DeleteLarge(ctx context.Context, id int) {
+	_m.Called(ctx, id)
+}
+
+// Objects_DeleteLarge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteLarge'
+type Objects_DeleteLarge_Call struct {
+	*mock.Call
+}
+
+// DeleteLarge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Objects_Expecter) DeleteLarge(ctx interface{}, id interface{}) *Objects_DeleteLarge_Call {
+	return &Objects_DeleteLarge_Call{Call: _e.mock.On("DeleteLarge", ctx, id)}
+}
+
+func (_c *Objects_DeleteLarge_Call) Run(run func(ctx context.Context, id int)) *Objects_DeleteLarge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Objects_DeleteLarge_Call) Return() *Objects_DeleteLarge_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Objects_DeleteLarge_Call) RunAndReturn(run func(context.Context, int)) *Objects_DeleteLarge_Call {
+	_c.Run(run)
+	return _c
+}
+
+// LoadLarge provides a mock function with given fields: ctx, objs
+func (_m *Objects) LoadLarge(ctx context.Context, objs []models.LargeObject) {
+	_m.Called(ctx, objs)
+}
+
+// Objects_LoadLarge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoadLarge'
+type Objects_LoadLarge_Call struct {
+	*mock.Call
+}
+
+// LoadLarge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - objs []models.LargeObject
+func (_e *Objects_Expecter) LoadLarge(ctx interface{}, objs interface{}) *Objects_LoadLarge_Call {
+	return &Objects_LoadLarge_Call{Call: _e.mock.On("LoadLarge", ctx, objs)}
+}
+
+func (_c *Objects_LoadLarge_Call) Run(run func(ctx context.Context, objs []models.LargeObject)) *Objects_LoadLarge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]models.LargeObject))
+	})
+	return _c
+}
+
+func (_c *Objects_LoadLarge_Call) Return() *Objects_LoadLarge_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Objects_LoadLarge_Call) RunAndReturn(run func(context.Context, []models.LargeObject)) *Objects_LoadLarge_Call {
+	_c.Run(run)
+	return _c
+}
+
+// PutLarge provides a mock function with given fields: ctx, uploadID, obj
+func (_m *Objects) PutLarge(ctx context.Context, uploadID string, obj models.LargeObject) bool {
+	ret := _m.Called(ctx, uploadID, obj)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PutLarge")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.LargeObject) bool); ok {
+		r0 = rf(ctx, uploadID, obj)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// Objects_PutLarge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PutLarge'
+type Objects_PutLarge_Call struct {
+	*mock.Call
+}
+
+// PutLarge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uploadID string
+//   - obj models.LargeObject
+func (_e *Objects_Expecter) PutLarge(ctx interface{}, uploadID interface{}, obj interface{}) *Objects_PutLarge_Call {
+	return &Objects_PutLarge_Call{Call: _e.mock.On("PutLarge", ctx, uploadID, obj)}
+}
+
+func (_c *Objects_PutLarge_Call) Run(run func(ctx context.Context, uploadID string, obj models.LargeObject)) *Objects_PutLarge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(models.LargeObject))
+	})
+	return _c
+}
+
+func (_c *Objects_PutLarge_Call) Return(_a0 bool) *Objects_PutLarge_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Objects_PutLarge_Call) RunAndReturn(run func(context.Context, string, models.LargeObject) bool) *Objects_PutLarge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReleaseLarge provides a mock function with given fields: ctx, uploadID
+func (_m *Objects) ReleaseLarge(ctx context.Context, uploadID string) {
+	_m.Called(ctx, uploadID)
+}
+
+// Objects_ReleaseLarge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReleaseLarge'
+type Objects_ReleaseLarge_Call struct {
+	*mock.Call
+}
+
+// ReleaseLarge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uploadID string
+func (_e *Objects_Expecter) ReleaseLarge(ctx interface{}, uploadID interface{}) *Objects_ReleaseLarge_Call {
+	return &Objects_ReleaseLarge_Call{Call: _e.mock.On("ReleaseLarge", ctx, uploadID)}
+}
+
+func (_c *Objects_ReleaseLarge_Call) Run(run func(ctx context.Context, uploadID string)) *Objects_ReleaseLarge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Objects_ReleaseLarge_Call) Return() *Objects_ReleaseLarge_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Objects_ReleaseLarge_Call) RunAndReturn(run func(context.Context, string)) *Objects_ReleaseLarge_Call {
+	_c.Run(run)
+	return _c
+}
+
+// ReserveLarge provides a mock function with given fields: ctx, uploadID, id, owner, size
+func (_m *Objects) ReserveLarge(ctx context.Context, uploadID string, id int, owner string, size int64) (int64, error) {
+	ret := _m.Called(ctx, uploadID, id, owner, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReserveLarge")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, string, int64) (int64, error)); ok {
+		return rf(ctx, uploadID, id, owner, size)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, string, int64) int64); ok {
+		r0 = rf(ctx, uploadID, id, owner, size)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, string, int64) error); ok {
+		r1 = rf(ctx, uploadID, id, owner, size)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Objects_ReserveLarge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReserveLarge'
+type Objects_ReserveLarge_Call struct {
+	*mock.Call
+}
+
+// ReserveLarge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uploadID string
+//   - id int
+//   - owner string
+//   - size int64
+func (_e *Objects_Expecter) ReserveLarge(ctx interface{}, uploadID interface{}, id interface{}, owner interface{}, size interface{}) *Objects_ReserveLarge_Call {
+	return &Objects_ReserveLarge_Call{Call: _e.mock.On("ReserveLarge", ctx, uploadID, id, owner, size)}
+}
+
+func (_c *Objects_ReserveLarge_Call) Run(run func(ctx context.Context, uploadID string, id int, owner string, size int64)) *Objects_ReserveLarge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(string), args[4].(int64))
+	})
+	return _c
+}
+
+func (_c *Objects_ReserveLarge_Call) Return(_a0 int64, _a1 error) *Objects_ReserveLarge_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Objects_ReserveLarge_Call) RunAndReturn(run func(context.Context, string, int, string, int64) (int64, error)) *Objects_ReserveLarge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewObjects creates a new instance of Objects. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewObjects(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Objects {
+	mock := &Objects{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
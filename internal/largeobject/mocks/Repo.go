@@ -0,0 +1,617 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "st-test/internal/models"
+
+	time "time"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+type Repo_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Repo) EXPECT() *Repo_Expecter {
+	return &Repo_Expecter{mock: &_m.Mock}
+}
+
+// AppendUpload provides a mock function with given fields: ctx, id, offset, body, chunkSize, limit
+func (_m *Repo) AppendUpload(ctx context.Context, id string, offset int64, body io.Reader, chunkSize int, limit int64) (models.Upload, error) {
+	ret := _m.Called(ctx, id, offset, body, chunkSize, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AppendUpload")
+	}
+
+	var r0 models.Upload
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, io.Reader, int, int64) (models.Upload, error)); ok {
+		return rf(ctx, id, offset, body, chunkSize, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, io.Reader, int, int64) models.Upload); ok {
+		r0 = rf(ctx, id, offset, body, chunkSize, limit)
+	} else {
+		r0 = ret.Get(0).(models.Upload)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, io.Reader, int, int64) error); ok {
+		r1 = rf(ctx, id, offset, body, chunkSize, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_AppendUpload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AppendUpload'
+type Repo_AppendUpload_Call struct {
+	*mock.Call
+}
+
+// AppendUpload is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - offset int64
+//   - body io.Reader
+//   - chunkSize int
+//   - limit int64
+func (_e *Repo_Expecter) AppendUpload(ctx interface{}, id interface{}, offset interface{}, body interface{}, chunkSize interface{}, limit interface{}) *Repo_AppendUpload_Call {
+	return &Repo_AppendUpload_Call{Call: _e.mock.On("AppendUpload", ctx, id, offset, body, chunkSize, limit)}
+}
+
+func (_c *Repo_AppendUpload_Call) Run(run func(ctx context.Context, id string, offset int64, body io.Reader, chunkSize int, limit int64)) *Repo_AppendUpload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(io.Reader), args[4].(int), args[5].(int64))
+	})
+	return _c
+}
+
+func (_c *Repo_AppendUpload_Call) Return(_a0 models.Upload, _a1 error) *Repo_AppendUpload_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_AppendUpload_Call) RunAndReturn(run func(context.Context, string, int64, io.Reader, int, int64) (models.Upload, error)) *Repo_AppendUpload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CommitUpload provides a mock function with given fields: id, sum
+func (_m *Repo) CommitUpload(id string, sum string) (models.LargeObject, bool, error) {
+	ret := _m.Called(id, sum)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CommitUpload")
+	}
+
+	var r0 models.LargeObject
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, string) (models.LargeObject, bool, error)); ok {
+		return rf(id, sum)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) models.LargeObject); ok {
+		r0 = rf(id, sum)
+	} else {
+		r0 = ret.Get(0).(models.LargeObject)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) bool); ok {
+		r1 = rf(id, sum)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string) error); ok {
+		r2 = rf(id, sum)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repo_CommitUpload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CommitUpload'
+type Repo_CommitUpload_Call struct {
+	*mock.Call
+}
+
+// CommitUpload is a helper method to define mock.On call
+//   - id string
+//   - sum string
+func (_e *Repo_Expecter) CommitUpload(id interface{}, sum interface{}) *Repo_CommitUpload_Call {
+	return &Repo_CommitUpload_Call{Call: _e.mock.On("CommitUpload", id, sum)}
+}
+
+func (_c *Repo_CommitUpload_Call) Run(run func(id string, sum string)) *Repo_CommitUpload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Repo_CommitUpload_Call) Return(_a0 models.LargeObject, _a1 bool, _a2 error) *Repo_CommitUpload_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repo_CommitUpload_Call) RunAndReturn(run func(string, string) (models.LargeObject, bool, error)) *Repo_CommitUpload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateUpload provides a mock function with given fields: key, owner, contentType
+func (_m *Repo) CreateUpload(key int, owner string, contentType string) (models.Upload, error) {
+	ret := _m.Called(key, owner, contentType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUpload")
+	}
+
+	var r0 models.Upload
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, string, string) (models.Upload, error)); ok {
+		return rf(key, owner, contentType)
+	}
+	if rf, ok := ret.Get(0).(func(int, string, string) models.Upload); ok {
+		r0 = rf(key, owner, contentType)
+	} else {
+		r0 = ret.Get(0).(models.Upload)
+	}
+
+	if rf, ok := ret.Get(1).(func(int, string, string) error); ok {
+		r1 = rf(key, owner, contentType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_CreateUpload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateUpload'
+type Repo_CreateUpload_Call struct {
+	*mock.Call
+}
+
+// CreateUpload is a helper method to define mock.On call
+//   - key int
+//   - owner string
+//   - contentType string
+func (_e *Repo_Expecter) CreateUpload(key interface{}, owner interface{}, contentType interface{}) *Repo_CreateUpload_Call {
+	return &Repo_CreateUpload_Call{Call: _e.mock.On("CreateUpload", key, owner, contentType)}
+}
+
+func (_c *Repo_CreateUpload_Call) Run(run func(key int, owner string, contentType string)) *Repo_CreateUpload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Repo_CreateUpload_Call) Return(_a0 models.Upload, _a1 error) *Repo_CreateUpload_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_CreateUpload_Call) RunAndReturn(run func(int, string, string) (models.Upload, error)) *Repo_CreateUpload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteLargeObject provides a mock function with given fields: key
+func (_m *Repo) DeleteLargeObject(key int) error {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteLargeObject")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repo_DeleteLargeObject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteLargeObject'
+type Repo_DeleteLargeObject_Call struct {
+	*mock.Call
+}
+
+// DeleteLargeObject is a helper method to define mock.On call
+//   - key int
+func (_e *Repo_Expecter) DeleteLargeObject(key interface{}) *Repo_DeleteLargeObject_Call {
+	return &Repo_DeleteLargeObject_Call{Call: _e.mock.On("DeleteLargeObject", key)}
+}
+
+func (_c *Repo_DeleteLargeObject_Call) Run(run func(key int)) *Repo_DeleteLargeObject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int))
+	})
+	return _c
+}
+
+func (_c *Repo_DeleteLargeObject_Call) Return(_a0 error) *Repo_DeleteLargeObject_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repo_DeleteLargeObject_Call) RunAndReturn(run func(int) error) *Repo_DeleteLargeObject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteUpload provides a mock function with given fields: id
+func (_m *Repo) DeleteUpload(id string) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUpload")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repo_DeleteUpload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUpload'
+type Repo_DeleteUpload_Call struct {
+	*mock.Call
+}
+
+// DeleteUpload is a helper method to define mock.On call
+//   - id string
+func (_e *Repo_Expecter) DeleteUpload(id interface{}) *Repo_DeleteUpload_Call {
+	return &Repo_DeleteUpload_Call{Call: _e.mock.On("DeleteUpload", id)}
+}
+
+func (_c *Repo_DeleteUpload_Call) Run(run func(id string)) *Repo_DeleteUpload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Repo_DeleteUpload_Call) Return(_a0 error) *Repo_DeleteUpload_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repo_DeleteUpload_Call) RunAndReturn(run func(string) error) *Repo_DeleteUpload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LargeObjects provides a mock function with given fields:
+func (_m *Repo) LargeObjects() ([]models.LargeObject, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LargeObjects")
+	}
+
+	var r0 []models.LargeObject
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]models.LargeObject, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []models.LargeObject); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LargeObject)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_LargeObjects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LargeObjects'
+type Repo_LargeObjects_Call struct {
+	*mock.Call
+}
+
+// LargeObjects is a helper method to define mock.On call
+func (_e *Repo_Expecter) LargeObjects() *Repo_LargeObjects_Call {
+	return &Repo_LargeObjects_Call{Call: _e.mock.On("LargeObjects")}
+}
+
+func (_c *Repo_LargeObjects_Call) Run(run func()) *Repo_LargeObjects_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Repo_LargeObjects_Call) Return(_a0 []models.LargeObject, _a1 error) *Repo_LargeObjects_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_LargeObjects_Call) RunAndReturn(run func() ([]models.LargeObject, error)) *Repo_LargeObjects_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OpenLargeObject provides a mock function with given fields: ctx, key
+func (_m *Repo) OpenLargeObject(ctx context.Context, key int) (models.LargeObject, io.ReadSeeker, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OpenLargeObject")
+	}
+
+	var r0 models.LargeObject
+	var r1 io.ReadSeeker
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.LargeObject, io.ReadSeeker, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.LargeObject); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(models.LargeObject)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) io.ReadSeeker); ok {
+		r1 = rf(ctx, key)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(io.ReadSeeker)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int) error); ok {
+		r2 = rf(ctx, key)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repo_OpenLargeObject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OpenLargeObject'
+type Repo_OpenLargeObject_Call struct {
+	*mock.Call
+}
+
+// OpenLargeObject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key int
+func (_e *Repo_Expecter) OpenLargeObject(ctx interface{}, key interface{}) *Repo_OpenLargeObject_Call {
+	return &Repo_OpenLargeObject_Call{Call: _e.mock.On("OpenLargeObject", ctx, key)}
+}
+
+func (_c *Repo_OpenLargeObject_Call) Run(run func(ctx context.Context, key int)) *Repo_OpenLargeObject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repo_OpenLargeObject_Call) Return(_a0 models.LargeObject, _a1 io.ReadSeeker, _a2 error) *Repo_OpenLargeObject_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repo_OpenLargeObject_Call) RunAndReturn(run func(context.Context, int) (models.LargeObject, io.ReadSeeker, error)) *Repo_OpenLargeObject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OpenUpload provides a mock function with given fields: ctx, id
+func (_m *Repo) OpenUpload(ctx context.Context, id string) (models.Upload, io.ReadSeeker, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OpenUpload")
+	}
+
+	var r0 models.Upload
+	var r1 io.ReadSeeker
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Upload, io.ReadSeeker, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Upload); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.Upload)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) io.ReadSeeker); ok {
+		r1 = rf(ctx, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(io.ReadSeeker)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repo_OpenUpload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OpenUpload'
+type Repo_OpenUpload_Call struct {
+	*mock.Call
+}
+
+// OpenUpload is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *Repo_Expecter) OpenUpload(ctx interface{}, id interface{}) *Repo_OpenUpload_Call {
+	return &Repo_OpenUpload_Call{Call: _e.mock.On("OpenUpload", ctx, id)}
+}
+
+func (_c *Repo_OpenUpload_Call) Run(run func(ctx context.Context, id string)) *Repo_OpenUpload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Repo_OpenUpload_Call) Return(_a0 models.Upload, _a1 io.ReadSeeker, _a2 error) *Repo_OpenUpload_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repo_OpenUpload_Call) RunAndReturn(run func(context.Context, string) (models.Upload, io.ReadSeeker, error)) *Repo_OpenUpload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PurgeUploads provides a mock function with given fields: before
+func (_m *Repo) PurgeUploads(before time.Time) (int, error) {
+	ret := _m.Called(before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeUploads")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int, error)); ok {
+		return rf(before)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int); ok {
+		r0 = rf(before)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_PurgeUploads_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeUploads'
+type Repo_PurgeUploads_Call struct {
+	*mock.Call
+}
+
+// PurgeUploads is a helper method to define mock.On call
+//   - before time.Time
+func (_e *Repo_Expecter) PurgeUploads(before interface{}) *Repo_PurgeUploads_Call {
+	return &Repo_PurgeUploads_Call{Call: _e.mock.On("PurgeUploads", before)}
+}
+
+func (_c *Repo_PurgeUploads_Call) Run(run func(before time.Time)) *Repo_PurgeUploads_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Repo_PurgeUploads_Call) Return(_a0 int, _a1 error) *Repo_PurgeUploads_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_PurgeUploads_Call) RunAndReturn(run func(time.Time) (int, error)) *Repo_PurgeUploads_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upload provides a mock function with given fields: id
+func (_m *Repo) Upload(id string) (models.Upload, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upload")
+	}
+
+	var r0 models.Upload
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (models.Upload, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) models.Upload); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(models.Upload)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_Upload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upload'
+type Repo_Upload_Call struct {
+	*mock.Call
+}
+
+// Upload is a helper method to define mock.On call
+//   - id string
+func (_e *Repo_Expecter) Upload(id interface{}) *Repo_Upload_Call {
+	return &Repo_Upload_Call{Call: _e.mock.On("Upload", id)}
+}
+
+func (_c *Repo_Upload_Call) Run(run func(id string)) *Repo_Upload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Repo_Upload_Call) Return(_a0 models.Upload, _a1 error) *Repo_Upload_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_Upload_Call) RunAndReturn(run func(string) (models.Upload, error)) *Repo_Upload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
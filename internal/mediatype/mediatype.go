@@ -0,0 +1,107 @@
+// Package mediatype описывает разбор типа содержимого объектов и списки допустимых типов содержимого.
+package mediatype
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// JSON тип содержимого json. Объекты, записанные до появления типа содержимого, считаются json.
+const JSON = "application/json"
+
+var (
+	// ErrInvalid возвращается когда значение заголовка Content-Type не удалось разобрать.
+	ErrInvalid = errors.New("invalid media type")
+	// ErrUnsupported возвращается когда тип содержимого не входит в список допустимых.
+	ErrUnsupported = errors.New("unsupported media type")
+)
+
+// Parse разбирает значение заголовка Content-Type. Возвращает значение в каноническом виде (тип в нижнем регистре,
+// параметры в порядке имён) и сам тип без параметров.
+func Parse(value string) (string, string, error) {
+	mediaType, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %q: %w", ErrInvalid, value, err)
+	}
+
+	if !strings.Contains(mediaType, "/") {
+		return "", "", fmt.Errorf("%w: %q: subtype is required", ErrInvalid, value)
+	}
+
+	return mime.FormatMediaType(mediaType, params), mediaType, nil
+}
+
+// List список типов содержимого. Элемент списка — тип целиком (image/png), все подтипы одного типа (image/*)
+// или суффикс структурированного синтаксиса (+json), под который подходят типы вида application/ld+json.
+type List []string
+
+// Match сообщает, подходит ли тип mediaType без параметров под один из элементов списка.
+func (l List) Match(mediaType string) bool {
+	mediaType = strings.ToLower(mediaType)
+
+	for _, pattern := range l {
+		pattern = strings.ToLower(pattern)
+
+		switch {
+		case strings.HasPrefix(pattern, "+"):
+			if strings.HasSuffix(mediaType, pattern) {
+				return true
+			}
+		case strings.HasSuffix(pattern, "/*"):
+			if strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		case pattern == mediaType:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Policy правила приёма тел по типу содержимого.
+type Policy struct {
+	// allowed допустимые типы; пустой список допускает любой тип.
+	allowed List
+	// json типы, тело которых проверяется как json.
+	json List
+}
+
+// NewPolicy конструктор для Policy. Если список json пуст, как json проверяются тела самого json и типов
+// структурированного синтаксиса json (RFC 6839), например application/ld+json.
+func NewPolicy(allowed, json []string) Policy {
+	if len(json) == 0 {
+		json = List{JSON, "+json"}
+	}
+
+	return Policy{allowed: allowed, json: json}
+}
+
+// Check разбирает значение заголовка Content-Type и проверяет, что тип допустим. Возвращает значение
+// в каноническом виде и признак того, что тело надо проверить как json.
+func (p Policy) Check(value string) (string, bool, error) {
+	contentType, mediaType, err := Parse(value)
+	if err != nil {
+		return "", false, err
+	}
+
+	if len(p.allowed) > 0 && !p.allowed.Match(mediaType) {
+		return "", false, fmt.Errorf("%w: %s", ErrUnsupported, mediaType)
+	}
+
+	return contentType, p.json.Match(mediaType), nil
+}
+
+// IsJSON сообщает, надо ли проверять тело с уже сохранённым типом содержимого contentType как json.
+// Пустой тип у тел, сохранённых до появления типа содержимого, и неразборчивый тип считаются json.
+func (p Policy) IsJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	_, mediaType, err := Parse(contentType)
+
+	return err != nil || p.json.Match(mediaType)
+}
@@ -0,0 +1,114 @@
+package mediatype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		give          string
+		wantValue     string
+		wantMediaType string
+		wantErr       error
+	}{
+		{name: "plain", give: "image/png", wantValue: "image/png", wantMediaType: "image/png"},
+		{
+			name:          "params",
+			give:          `Text/Plain; Charset=UTF-8`,
+			wantValue:     "text/plain; charset=UTF-8",
+			wantMediaType: "text/plain",
+		},
+		{name: "empty", give: "", wantErr: ErrInvalid},
+		{name: "without subtype", give: "text", wantErr: ErrInvalid},
+		{name: "broken params", give: "text/plain; charset", wantErr: ErrInvalid},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			value, mediaType, err := Parse(tt.give)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.wantValue, value)
+			require.Equal(t, tt.wantMediaType, mediaType)
+		})
+	}
+}
+
+func TestList_Match(t *testing.T) {
+	t.Parallel()
+
+	list := List{"application/json", "+json", "image/*"}
+
+	cases := []struct {
+		give string
+		want bool
+	}{
+		{give: "application/json", want: true},
+		{give: "Application/JSON", want: true},
+		{give: "application/ld+json", want: true},
+		{give: "image/png", want: true},
+		{give: "text/plain", want: false},
+		{give: "application/jsonx", want: false},
+		{give: "imagex/png", want: false},
+	}
+
+	for _, tt := range cases {
+		require.Equal(t, tt.want, list.Match(tt.give), tt.give)
+	}
+
+	require.False(t, List(nil).Match("application/json"))
+}
+
+func TestPolicy_Check(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		policy    Policy
+		give      string
+		wantValue string
+		wantJSON  bool
+		wantErr   error
+	}{
+		{name: "json by default", policy: NewPolicy(nil, nil), give: "application/json", wantValue: "application/json", wantJSON: true},
+		{name: "json suffix", policy: NewPolicy(nil, nil), give: "application/ld+json", wantValue: "application/ld+json", wantJSON: true},
+		{name: "any type", policy: NewPolicy(nil, nil), give: "image/png", wantValue: "image/png"},
+		{name: "allowed", policy: NewPolicy([]string{"image/*"}, nil), give: "image/png", wantValue: "image/png"},
+		{name: "not allowed", policy: NewPolicy([]string{"image/*"}, nil), give: "text/plain", wantErr: ErrUnsupported},
+		{name: "custom json", policy: NewPolicy(nil, []string{"text/json"}), give: "text/json", wantValue: "text/json", wantJSON: true},
+		{name: "custom json replaces default", policy: NewPolicy(nil, []string{"text/json"}), give: "application/json",
+			wantValue: "application/json"},
+		{name: "invalid", policy: NewPolicy(nil, nil), give: "", wantErr: ErrInvalid},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			value, isJSON, err := tt.policy.Check(tt.give)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.wantValue, value)
+			require.Equal(t, tt.wantJSON, isJSON)
+		})
+	}
+}
+
+func TestPolicy_IsJSON(t *testing.T) {
+	t.Parallel()
+
+	p := NewPolicy([]string{"image/*"}, nil)
+
+	require.True(t, p.IsJSON(""))
+	require.True(t, p.IsJSON("application/json; charset=utf-8"))
+	// тип, который больше не допускается, не мешает определить формат уже сохранённого тела.
+	require.False(t, p.IsJSON("text/plain"))
+	require.False(t, p.IsJSON("image/png"))
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// ChangeType тип изменения объекта в хранилище.
+type ChangeType string
+
+// Типы изменений объектов.
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+	ChangeExpired ChangeType = "expired"
+	// ChangeTouched скользящий или относительный срок жизни объекта отсчитан заново; версия объекта не меняется.
+	ChangeTouched ChangeType = "touched"
+)
+
+// Change описывает изменение объекта в хранилище. Seq монотонно возрастает в пределах работы хранилища.
+// Для удалённых и истёкших объектов Body содержит последнее сохранённое тело.
+// Item полное состояние объекта после изменения (для удалённых и истёкших — последнее сохранённое);
+// версия созданного или изменённого объекта равна Seq. По нему ведомые экземпляры воспроизводят изменение.
+// Large выставляется для изменений крупных объектов: их тело хранится вне хранилища, поэтому Body пустое.
+type Change struct {
+	Seq   uint64
+	Type  ChangeType
+	ID    int
+	Body  []byte
+	Item  Item
+	Time  time.Time
+	Large bool
+}
+
+// Snapshot согласованный снимок всех объектов хранилища: Seq номер последнего изменения, учтённого в Items.
+// Trash объекты корзины; nil, если снимок снят без корзины.
+type Snapshot struct {
+	Seq   uint64
+	Items []Item
+	Trash []TrashedItem
+}
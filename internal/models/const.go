@@ -2,5 +2,13 @@ package models
 
 import "errors"
 
-// ErrNotFound возвращается когда не найден объект.
-var ErrNotFound = errors.New("object not found")
+var (
+	// ErrNotFound возвращается когда не найден объект.
+	ErrNotFound = errors.New("object not found")
+	// ErrCorrupted возвращается когда сохранённый объект повреждён и не может быть отдан.
+	ErrCorrupted = errors.New("object is corrupted")
+	// ErrTooLarge возвращается когда тело объекта превышает допустимый размер.
+	ErrTooLarge = errors.New("object is too large")
+	// ErrOffsetMismatch возвращается когда продолжение загрузки начинается не с того места, где она остановилась.
+	ErrOffsetMismatch = errors.New("upload offset mismatch")
+)
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// ExpiredItem объект с истёкшим сроком жизни, оставленный для отладки. Хранится до PurgeAt.
+type ExpiredItem struct {
+	Item
+	ExpiredAt time.Time
+	PurgeAt   time.Time
+}
@@ -6,13 +6,57 @@ import (
 )
 
 // Item описывает объект, включает в себя id, тело объекта как массив байт и дату, через которую надо удалить объект.
+// ExpiresAt вычисляется хранилищем при сохранении объекта с ненулевым Expires, иначе задаёт абсолютный момент
+// истечения. У объекта со скользящим сроком жизни (Sliding) ExpiresAt сдвигается на Expires при каждом чтении.
+// KeepTTL при перезаписи сохраняет срок жизни прежнего объекта вместо заданного; в хранилище не сохраняется.
+// Version номер изменения хранилища, которым объект был записан в последний раз; растёт с каждой записью.
+// Owner клиент, записавший объект последним; объект учитывается в его квоте.
+// ContentType тип содержимого тела из заголовка Content-Type; пустой у объектов, записанных до его появления,
+// тело которых всегда json.
+// Metadata пользовательские метаданные объекта, Tags его теги; их можно изменить, не перезаписывая тело.
+// CreatedAt время создания объекта, UpdatedAt время последнего изменения его тела или метаданных;
+// выставляются хранилищем и нулевые у объектов, записанных до их появления.
 type Item struct {
-	ID      int
-	Body    []byte
-	Expires time.Duration
+	ID          int
+	Body        []byte
+	Expires     time.Duration
+	ExpiresAt   time.Time
+	Sliding     bool
+	KeepTTL     bool
+	Version     uint64
+	Owner       string
+	ContentType string
+	Metadata    map[string]string
+	Tags        []string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
-// ToJSON возвращает объект как массив байт. Но т.к. у нас объект и так любое валидный json-объект, то данный метод является реализацией интерфейса.
+// Expired сообщает, истёк ли срок жизни объекта к моменту now.
+func (i Item) Expired(now time.Time) bool {
+	return !i.ExpiresAt.IsZero() && !now.Before(i.ExpiresAt)
+}
+
+// Stat возвращает сведения об объекте без его тела.
+func (i Item) Stat() Stat {
+	return Stat{
+		ID:          i.ID,
+		Size:        int64(len(i.Body)),
+		Version:     i.Version,
+		Owner:       i.Owner,
+		ContentType: i.ContentType,
+		Metadata:    i.Metadata,
+		Tags:        i.Tags,
+		CreatedAt:   i.CreatedAt,
+		UpdatedAt:   i.UpdatedAt,
+		ExpiresAt:   i.ExpiresAt,
+		Expires:     i.Expires,
+		Sliding:     i.Sliding,
+	}
+}
+
+// ToJSON возвращает тело объекта как есть. Тело может быть не json, тогда тип содержимого ответа задаётся
+// заранее по ContentType, а данный метод является реализацией интерфейса.
 func (i Item) ToJSON() ([]byte, error) {
 	return i.Body, nil
 }
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// LargeObject крупный объект, тело которого хранится на диске частями и не загружается в память целиком.
+// Checksum — контрольная сумма sha256 всего тела, ContentType — тип содержимого тела, как у Item.
+type LargeObject struct {
+	ID          int
+	Size        int64
+	Checksum    string
+	Owner       string
+	ContentType string
+	UpdatedAt   time.Time
+}
+
+// Upload незавершённая загрузка крупного объекта. Offset — количество уже принятых байт тела;
+// прерванную загрузку можно продолжить с этого места. ContentType — тип содержимого будущего объекта.
+type Upload struct {
+	ID          string
+	ObjectID    int
+	Offset      int64
+	Owner       string
+	ContentType string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
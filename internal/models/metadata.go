@@ -0,0 +1,151 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxMetadataSize максимальный суммарный размер ключей и значений метаданных объекта в байтах.
+	MaxMetadataSize = 8 << 10
+	// MaxTags максимальное количество тегов объекта.
+	MaxTags = 50
+	// maxKeyLength и maxTagLength максимальная длина ключа метаданных и тега.
+	maxKeyLength = 128
+	maxTagLength = 128
+)
+
+// ErrInvalidMetadata возвращается когда метаданные или теги объекта не прошли проверку.
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+// MetadataPatch изменение метаданных и тегов объекта без перезаписи тела. Ключи Metadata со значением nil
+// удаляются, остальные задаются; теги заменяются целиком, если Tags не nil.
+type MetadataPatch struct {
+	Metadata map[string]*string
+	Tags     *[]string
+}
+
+// Apply возвращает объект item с применённым изменением. Результат проверяется так же, как в NormalizeMetadata.
+func (p MetadataPatch) Apply(item Item) (Item, error) {
+	meta := make(map[string]string, len(item.Metadata)+len(p.Metadata))
+
+	for k, v := range item.Metadata {
+		meta[k] = v
+	}
+
+	for k, v := range p.Metadata {
+		k = strings.ToLower(k)
+
+		if v == nil {
+			delete(meta, k)
+
+			continue
+		}
+
+		meta[k] = *v
+	}
+
+	tags := item.Tags
+	if p.Tags != nil {
+		tags = *p.Tags
+	}
+
+	var err error
+
+	item.Metadata, item.Tags, err = NormalizeMetadata(meta, tags)
+	if err != nil {
+		return Item{}, err
+	}
+
+	return item, nil
+}
+
+// NormalizeMetadata проверяет метаданные и теги объекта и приводит их к каноническому виду: ключи метаданных
+// в нижнем регистре, теги без повторов в порядке возрастания. Пустые метаданные и теги заменяются на nil.
+// Ключ метаданных состоит из латинских букв, цифр, '-', '_' и '.'; значения и теги не содержат управляющих символов,
+// теги к тому же не содержат запятых. Ошибка оборачивает ErrInvalidMetadata.
+func NormalizeMetadata(meta map[string]string, tags []string) (map[string]string, []string, error) {
+	var (
+		normalized map[string]string
+		size       int
+	)
+
+	for k, v := range meta {
+		k = strings.ToLower(k)
+
+		if k == "" || len(k) > maxKeyLength || strings.IndexFunc(k, invalidKeyRune) >= 0 {
+			return nil, nil, fmt.Errorf("%w: key %q", ErrInvalidMetadata, k)
+		}
+
+		if strings.IndexFunc(v, unicode.IsControl) >= 0 {
+			return nil, nil, fmt.Errorf("%w: value of key %q contains control characters", ErrInvalidMetadata, k)
+		}
+
+		size += len(k) + len(v)
+		if size > MaxMetadataSize {
+			return nil, nil, fmt.Errorf("%w: metadata must not exceed %d bytes", ErrInvalidMetadata, MaxMetadataSize)
+		}
+
+		if normalized == nil {
+			normalized = make(map[string]string, len(meta))
+		}
+
+		normalized[k] = v
+	}
+
+	sorted, err := normalizeTags(tags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return normalized, sorted, nil
+}
+
+// normalizeTags проверяет теги и возвращает их без повторов в порядке возрастания.
+func normalizeTags(tags []string) ([]string, error) {
+	var sorted []string
+
+	for _, tag := range tags {
+		if tag == "" || len(tag) > maxTagLength || strings.ContainsRune(tag, ',') ||
+			strings.IndexFunc(tag, unicode.IsControl) >= 0 {
+			return nil, fmt.Errorf("%w: tag %q", ErrInvalidMetadata, tag)
+		}
+
+		sorted = append(sorted, tag)
+	}
+
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	if len(sorted) > MaxTags {
+		return nil, fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidMetadata, MaxTags)
+	}
+
+	return sorted, nil
+}
+
+// HasMetadata сообщает, есть ли у объекта все пары метаданных meta и все теги tags.
+// Ключи meta должны быть в каноническом виде.
+func (i Item) HasMetadata(meta map[string]string, tags []string) bool {
+	for k, v := range meta {
+		if got, ok := i.Metadata[k]; !ok || got != v {
+			return false
+		}
+	}
+
+	for _, tag := range tags {
+		if _, ok := slices.BinarySearch(i.Tags, tag); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// invalidKeyRune сообщает, что символ не допускается в ключе метаданных.
+func invalidKeyRune(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.')
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// QuarantinedItem повреждённый объект, убранный из хранилища в карантин.
+// Source — таблица, из которой убран объект, Reason — причина, по которой объект признан повреждённым.
+type QuarantinedItem struct {
+	ID            int64
+	Source        string
+	ObjectID      int
+	Version       uint64
+	Reason        string
+	QuarantinedAt time.Time
+}
+
+// ScrubReport результат проверки всех сохранённых объектов.
+type ScrubReport struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Checked количество проверенных тел; одно общее тело может принадлежать нескольким объектам.
+	Checked int
+	// Corrupted объекты, признанные повреждёнными и убранные в карантин.
+	Corrupted []QuarantinedItem
+	// Unreadable количество объектов, которые не удалось проверить, например, из-за отсутствующего ключа шифрования.
+	Unreadable int
+}
@@ -0,0 +1,17 @@
+package models
+
+// Quota ограничения на объекты одного клиента. Нулевое значение поля означает отсутствие ограничения.
+type Quota struct {
+	MaxObjects int
+	MaxBytes   int64
+}
+
+// Usage объём, занимаемый объектами клиента, и действующая для него квота. Shared означает, что квота общая
+// для всех клиентов без отдельной квоты и ограничивает их суммарный объём.
+type Usage struct {
+	Client  string
+	Objects int
+	Bytes   int64
+	Quota   Quota
+	Shared  bool
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Stat сведения об объекте без его тела: размер тела в байтах, версия, владелец, тип содержимого, метаданные и время
+// создания, изменения и истечения срока жизни. Expires и Sliding описывают срок жизни так же, как у Item.
+// Large выставлен у крупных объектов, у которых нет версии, метаданных и срока жизни.
+type Stat struct {
+	ID          int
+	Size        int64
+	Version     uint64
+	Owner       string
+	ContentType string
+	Checksum    string
+	Metadata    map[string]string
+	Tags        []string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ExpiresAt   time.Time
+	Expires     time.Duration
+	Sliding     bool
+	Large       bool
+}
+
+// TTL возвращает оставшееся к моменту now время жизни объекта и признак того, что срок жизни задан.
+func (s Stat) TTL(now time.Time) (time.Duration, bool) {
+	if s.ExpiresAt.IsZero() {
+		return 0, false
+	}
+
+	return max(s.ExpiresAt.Sub(now), 0), true
+}
+
+// Stat возвращает сведения о крупном объекте.
+func (o LargeObject) Stat() Stat {
+	return Stat{
+		ID:          o.ID,
+		Size:        o.Size,
+		Owner:       o.Owner,
+		ContentType: o.ContentType,
+		Checksum:    o.Checksum,
+		UpdatedAt:   o.UpdatedAt,
+		Large:       true,
+	}
+}
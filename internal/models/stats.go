@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// SizeBucket количество объектов, размер тела которых не больше Le и больше границы предыдущей корзины.
+// Нулевая граница у последней корзины означает её отсутствие.
+type SizeBucket struct {
+	Le    int64
+	Count int
+}
+
+// StoreStats сводные сведения о хранилище. Объекты с истёкшим сроком жизни не учитываются.
+type StoreStats struct {
+	Objects int
+	// Bytes суммарный размер тел объектов без учёта дедупликации.
+	Bytes int64
+	Sizes []SizeBucket
+	// WithTTL количество объектов со сроком жизни; NextExpiry ближайший момент истечения, нулевой без таких объектов.
+	WithTTL    int
+	NextExpiry time.Time
+	// Reads, Writes и Misses количество успешных чтений, записей и чтений отсутствующих объектов с запуска.
+	Reads  uint64
+	Writes uint64
+	Misses uint64
+	// LastSnapshot время последней записи объектов в репозиторий, нулевое, если записи с запуска не было.
+	LastSnapshot time.Time
+	// RepoBytes размер файла репозитория.
+	RepoBytes int64
+}
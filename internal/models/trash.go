@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// TrashedItem удалённый объект в корзине. Объект можно восстановить до PurgeAt.
+type TrashedItem struct {
+	Item
+	DeletedAt time.Time
+	PurgeAt   time.Time
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTTL возвращается когда срок жизни объекта задан неверно.
+var ErrInvalidTTL = errors.New("invalid ttl")
+
+// TTL срок жизни объекта. Duration — срок от момента записи, а у скользящего срока (Sliding) — от последнего
+// чтения объекта; At — абсолютный момент истечения. Задаётся одно из Duration и At; нулевой TTL означает
+// бессрочный объект.
+type TTL struct {
+	Duration time.Duration
+	At       time.Time
+	Sliding  bool
+}
+
+// IsZero сообщает, что срок жизни не задан.
+func (t TTL) IsZero() bool {
+	return t.Duration == 0 && t.At.IsZero()
+}
+
+// Validate проверяет срок жизни к моменту now. Ошибка оборачивает ErrInvalidTTL.
+func (t TTL) Validate(now time.Time) error {
+	switch {
+	case t.Duration < 0:
+		return fmt.Errorf("%w: duration must be positive", ErrInvalidTTL)
+	case t.Duration > 0 && !t.At.IsZero():
+		return fmt.Errorf("%w: duration and expiry time are mutually exclusive", ErrInvalidTTL)
+	case !t.At.IsZero() && !t.At.After(now):
+		return fmt.Errorf("%w: expiry time must be in the future", ErrInvalidTTL)
+	case t.Sliding && t.Duration == 0:
+		return fmt.Errorf("%w: sliding expiry requires a duration", ErrInvalidTTL)
+	}
+
+	return nil
+}
+
+// WithTTL возвращает объект со сроком жизни ttl, отсчитанным от момента now.
+func (i Item) WithTTL(ttl TTL, now time.Time) Item {
+	i.Expires, i.ExpiresAt, i.Sliding = ttl.Duration, ttl.At, ttl.Sliding
+
+	if ttl.Duration > 0 {
+		i.ExpiresAt = now.Add(ttl.Duration)
+	}
+
+	return i
+}
+
+// TTL возвращает срок жизни объекта. У объекта с относительным сроком жизни At не заполняется.
+func (i Item) TTL() TTL {
+	if i.Expires > 0 {
+		return TTL{Duration: i.Expires, Sliding: i.Sliding}
+	}
+
+	return TTL{At: i.ExpiresAt}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Webhook подписка внешнего сервиса на изменения объектов.
+// Пустые Events и Keys означают подписку на все типы изменений и все объекты.
+// Secret используется для подписи доставляемых событий.
+type Webhook struct {
+	ID        int64
+	URL       string
+	Events    []ChangeType
+	Keys      []string
+	Secret    string
+	CreatedAt time.Time
+}
+
+// DeliveryStatus состояние доставки события подписчику.
+type DeliveryStatus string
+
+// Состояния доставки. Успешно доставленные события удаляются из очереди.
+const (
+	DeliveryPending DeliveryStatus = "pending"
+	DeliveryFailed  DeliveryStatus = "failed"
+)
+
+// Delivery событие в очереди доставки подписчику.
+type Delivery struct {
+	ID            int64
+	WebhookID     int64
+	Event         ChangeType
+	ObjectID      int
+	Payload       []byte
+	Status        DeliveryStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,426 @@
+package replica
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
+	"st-test/internal/tracing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxLag       = 1000
+	defaultMaxStaleness = 30 * time.Second
+	defaultRetry        = time.Second
+	// maxRetry наибольшая пауза между попытками подключиться к ведущему.
+	maxRetry = 30 * time.Second
+	// snapshotTimeout время на загрузку снимка ведущего.
+	snapshotTimeout = 5 * time.Minute
+)
+
+var (
+	// ErrInvalidLeader возвращается когда адрес ведущего не является абсолютным http или https url.
+	ErrInvalidLeader = errors.New("leader must be an absolute http or https url")
+	// ErrNotBootstrapped возвращается когда ведомый ещё не загрузил снимок ведущего.
+	ErrNotBootstrapped = errors.New("replica has not loaded the leader snapshot yet")
+	// ErrLagging возвращается когда ведомый отстаёт от ведущего больше допустимого.
+	ErrLagging = errors.New("replica lags behind the leader")
+	// ErrStale возвращается когда ведомый слишком долго не получал вестей от ведущего.
+	ErrStale = errors.New("replica has not heard from the leader for too long")
+
+	// errResync означает, что ведомому нужно заново загрузить снимок ведущего.
+	errResync = errors.New("replica must reload the leader snapshot")
+)
+
+// Store описывает методы хранилища ведомого для применения снимка и изменений ведущего.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Store --with-expecter=true
+type Store interface {
+	ApplySnapshot(ctx context.Context, snap models.Snapshot) error
+	ApplyChange(ctx context.Context, c models.Change) error
+}
+
+// Status состояние репликации ведомого. Applied номер последнего применённого изменения, LeaderSeq последний
+// известный номер изменения ведущего, CaughtUp время, когда ведомый последний раз догнал ведущего,
+// LastContact время последних вестей от ведущего, Error последняя ошибка репликации.
+type Status struct {
+	Leader       string
+	Bootstrapped bool
+	Applied      uint64
+	LeaderSeq    uint64
+	CaughtUp     time.Time
+	LastContact  time.Time
+	Error        string
+}
+
+// Lag возвращает отставание ведомого от ведущего в изменениях.
+func (s Status) Lag() uint64 {
+	if s.LeaderSeq <= s.Applied {
+		return 0
+	}
+
+	return s.LeaderSeq - s.Applied
+}
+
+// LagTime возвращает, сколько времени ведомый отстаёт от ведущего к моменту now.
+func (s Status) LagTime(now time.Time) time.Duration {
+	if s.Lag() == 0 || s.CaughtUp.IsZero() {
+		return 0
+	}
+
+	return now.Sub(s.CaughtUp)
+}
+
+// Follower ведомый экземпляр: загружает снимок ведущего и применяет его ленту изменений к своему хранилищу.
+// Если изменения ведущего уже вытеснены из его журнала, снимок загружается заново.
+type Follower struct {
+	log    *zap.Logger
+	store  Store
+	client *http.Client
+	leader *url.URL
+	set    settings.ReplicaSettings
+
+	m      sync.Mutex
+	status Status
+
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// Option дополнительная настройка Follower.
+type Option func(f *Follower)
+
+// WithTracer включает трассировку запросов к ведущему: каждый запрос записывается клиентским спаном,
+// а контекст трассировки передаётся ведущему в заголовке traceparent.
+func WithTracer(tp trace.TracerProvider) Option {
+	return func(f *Follower) {
+		f.client.Transport = tracing.Transport(tp, f.client.Transport)
+	}
+}
+
+// NewFollower конструктор для Follower.
+func NewFollower(log *zap.Logger, store Store, set settings.ReplicaSettings, opts ...Option) (*Follower, error) {
+	leader, err := url.Parse(set.Leader)
+	if err != nil || (leader.Scheme != "http" && leader.Scheme != "https") || leader.Host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidLeader, set.Leader)
+	}
+
+	if set.MaxLag == 0 {
+		set.MaxLag = defaultMaxLag
+	}
+
+	if set.MaxStaleness <= 0 {
+		set.MaxStaleness = defaultMaxStaleness
+	}
+
+	if set.Retry <= 0 {
+		set.Retry = defaultRetry
+	}
+
+	f := &Follower{
+		log:    log.Named("replica"),
+		store:  store,
+		client: &http.Client{},
+		leader: leader,
+		set:    set,
+		status: Status{Leader: leader.String()},
+		done:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f, nil
+}
+
+// Leader возвращает адрес ведущего.
+func (f *Follower) Leader() *url.URL {
+	u := *f.leader
+
+	return &u
+}
+
+// Run запускает репликацию в фоне.
+func (f *Follower) Run() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	f.wg.Add(1)
+
+	go func() {
+		defer f.wg.Done()
+		defer cancel()
+
+		f.run(ctx)
+	}()
+
+	go func() {
+		<-f.done
+		cancel()
+	}()
+}
+
+// Stop останавливает репликацию и дожидается применения текущего изменения.
+func (f *Follower) Stop() {
+	f.stopOnce.Do(func() {
+		close(f.done)
+	})
+
+	f.wg.Wait()
+}
+
+// Status возвращает состояние репликации.
+func (f *Follower) Status() Status {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	return f.status
+}
+
+// Ready сообщает, может ли ведомый обслуживать чтение: снимок загружен, ведущий недавно выходил на связь
+// и отставание не больше допустимого.
+func (f *Follower) Ready() error {
+	st := f.Status()
+
+	if !st.Bootstrapped {
+		return ErrNotBootstrapped
+	}
+
+	if since := time.Since(st.LastContact); since > f.set.MaxStaleness {
+		return fmt.Errorf("%w: last contact %s ago", ErrStale, since.Round(time.Second))
+	}
+
+	if lag := st.Lag(); lag > f.set.MaxLag {
+		return fmt.Errorf("%w: %d changes behind", ErrLagging, lag)
+	}
+
+	return nil
+}
+
+// run загружает снимок и применяет ленту изменений, пока не будет остановлен. После ошибок подключается заново
+// с растущей паузой; пауза сбрасывается, как только ведущий снова выходит на связь.
+func (f *Follower) run(ctx context.Context) {
+	retry := f.set.Retry
+
+	for {
+		contact := f.Status().LastContact
+
+		err := f.sync(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if errors.Is(err, errResync) {
+			f.log.Info("reloading leader snapshot", zap.Error(err))
+			f.update(func(st *Status) { st.Bootstrapped = false })
+
+			continue
+		}
+
+		f.log.Warn("replication interrupted", zap.Error(err), zap.Duration("retry", retry))
+		f.update(func(st *Status) { st.Error = err.Error() })
+
+		if !f.Status().LastContact.Equal(contact) {
+			retry = f.set.Retry
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+
+		retry = min(retry*2, maxRetry)
+	}
+}
+
+// sync загружает снимок, если он ещё не загружен, и применяет ленту изменений до разрыва соединения.
+func (f *Follower) sync(ctx context.Context) error {
+	if !f.Status().Bootstrapped {
+		if err := f.bootstrap(ctx); err != nil {
+			return err
+		}
+	}
+
+	return f.tail(ctx)
+}
+
+// bootstrap загружает снимок ведущего и заменяет им объекты хранилища.
+func (f *Follower) bootstrap(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+	defer cancel()
+
+	resp, err := f.get(ctx, SnapshotPath, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get snapshot: unexpected status %d", resp.StatusCode)
+	}
+
+	var snap Snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&snap); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	if err := f.store.ApplySnapshot(ctx, snap.Model()); err != nil {
+		return fmt.Errorf("apply snapshot: %w", err)
+	}
+
+	now := time.Now()
+
+	f.update(func(st *Status) {
+		st.Bootstrapped = true
+		st.Applied, st.LeaderSeq = snap.Seq, snap.Seq
+		st.CaughtUp, st.LastContact = now, now
+		st.Error = ""
+	})
+
+	f.log.Info("leader snapshot loaded", zap.Uint64("seq", snap.Seq), zap.Int("count", len(snap.Objects)))
+
+	return nil
+}
+
+// tail применяет ленту изменений ведущего после последнего применённого изменения до разрыва соединения.
+func (f *Follower) tail(ctx context.Context) error {
+	query := url.Values{"since": {strconv.FormatUint(f.Status().Applied, 10)}}
+
+	resp, err := f.get(ctx, ChangesPath, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusGone:
+		return fmt.Errorf("%w: changes are no longer retained by the leader", errResync)
+	default:
+		return fmt.Errorf("get changes: unexpected status %d", resp.StatusCode)
+	}
+
+	f.update(func(st *Status) { st.Error = "" })
+
+	reader := bufio.NewReader(resp.Body)
+
+	var (
+		event string
+		data  []byte
+	)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return fmt.Errorf("read changes: %w", err)
+		}
+
+		line = bytes.TrimRight(line, "\r\n")
+
+		switch {
+		case len(line) == 0:
+			if event != "" {
+				if err := f.handle(ctx, event, data); err != nil {
+					return err
+				}
+			}
+
+			event, data = "", data[:0]
+		case bytes.HasPrefix(line, []byte("event:")):
+			event = string(bytes.TrimSpace(line[len("event:"):]))
+		case bytes.HasPrefix(line, []byte("data:")):
+			data = append(data, bytes.TrimSpace(line[len("data:"):])...)
+		}
+	}
+}
+
+// handle обрабатывает событие ленты изменений ведущего.
+func (f *Follower) handle(ctx context.Context, event string, data []byte) error {
+	switch event {
+	case EventChange:
+		var c Change
+		if err := json.Unmarshal(data, &c); err != nil {
+			return fmt.Errorf("decode change: %w", err)
+		}
+
+		if err := f.store.ApplyChange(ctx, c.Model()); err != nil {
+			if errors.Is(err, storage.ErrReplicaGap) {
+				return fmt.Errorf("%w: %w", errResync, err)
+			}
+
+			return fmt.Errorf("apply change %d: %w", c.Seq, err)
+		}
+
+		f.contact(c.Seq, func(st *Status) { st.Applied = max(st.Applied, c.Seq) })
+	case EventHeartbeat:
+		var hb Heartbeat
+		if err := json.Unmarshal(data, &hb); err != nil {
+			return fmt.Errorf("decode heartbeat: %w", err)
+		}
+
+		f.contact(hb.Seq, nil)
+	case EventReset:
+		return fmt.Errorf("%w: changes stream was reset by the leader", errResync)
+	}
+
+	return nil
+}
+
+// contact отмечает вести от ведущего с номером изменения seq и применяет fn к состоянию репликации.
+func (f *Follower) contact(seq uint64, fn func(st *Status)) {
+	now := time.Now()
+
+	f.update(func(st *Status) {
+		if fn != nil {
+			fn(st)
+		}
+
+		st.LeaderSeq = max(st.LeaderSeq, seq)
+		st.LastContact = now
+
+		if st.Applied >= st.LeaderSeq {
+			st.CaughtUp = now
+		}
+	})
+}
+
+func (f *Follower) update(fn func(st *Status)) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	fn(&f.status)
+}
+
+// get выполняет GET запрос к ведущему.
+func (f *Follower) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := f.leader.JoinPath(path)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request leader: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,218 @@
+package replica
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/replica/mocks"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func TestNewFollower(t *testing.T) {
+	t.Parallel()
+
+	for _, leader := range []string{"", "leader:8080", "ftp://leader", "http://"} {
+		_, err := NewFollower(zap.NewNop(), mocks.NewStore(t), settings.ReplicaSettings{Leader: leader})
+		require.ErrorIs(t, err, ErrInvalidLeader, leader)
+	}
+
+	f, err := NewFollower(zap.NewNop(), mocks.NewStore(t), settings.ReplicaSettings{Leader: "http://leader:8080/base"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://leader:8080/base", f.Leader().String())
+	require.ErrorIs(t, f.Ready(), ErrNotBootstrapped)
+}
+
+func TestStatus_Lag(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	st := Status{Applied: 5, LeaderSeq: 5, CaughtUp: now.Add(-time.Minute)}
+	assert.Equal(t, uint64(0), st.Lag())
+	assert.Equal(t, time.Duration(0), st.LagTime(now))
+
+	st.LeaderSeq = 8
+	assert.Equal(t, uint64(3), st.Lag())
+	assert.Equal(t, time.Minute, st.LagTime(now))
+}
+
+// leader поддельный ведущий: отдаёт снимок с номером seq и ленту изменений, которую пишет stream.
+type leader struct {
+	snapshots atomic.Int32
+	seq       uint64
+	stream    func(w http.ResponseWriter, r *http.Request)
+	// traceparent заголовок traceparent последнего запроса снимка.
+	traceparent atomic.Value
+}
+
+func (l *leader) start(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(SnapshotPath, func(w http.ResponseWriter, r *http.Request) {
+		l.snapshots.Add(1)
+		l.traceparent.Store(r.Header.Get("traceparent"))
+
+		_, _ = fmt.Fprintf(w, `{"seq":%d,"objects":[{"id":1,"body":"e30=","version":%d}]}`, l.seq, l.seq)
+	})
+	mux.HandleFunc(ChangesPath, func(w http.ResponseWriter, r *http.Request) {
+		l.stream(w, r)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func startFollower(t *testing.T, store Store, url string, opts ...Option) *Follower {
+	t.Helper()
+
+	f, err := NewFollower(zap.NewNop(), store, settings.ReplicaSettings{Leader: url, MaxLag: 1, Retry: time.Millisecond}, opts...)
+	require.NoError(t, err)
+
+	f.Run()
+	t.Cleanup(f.Stop)
+
+	return f
+}
+
+func TestFollower_Replicate(t *testing.T) {
+	t.Parallel()
+
+	l := &leader{seq: 5, stream: func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("since") != "5" {
+			w.WriteHeader(http.StatusGone)
+
+			return
+		}
+
+		_, _ = fmt.Fprint(w, "id: 7\nevent: heartbeat\ndata: {\"seq\":7}\n\n")
+		_, _ = fmt.Fprint(w, "id: 6\nevent: change\ndata: {\"seq\":6,\"type\":\"updated\",\"id\":1,\"object\":{\"id\":1,\"body\":\"e30=\"}}\n\n")
+		w.(http.Flusher).Flush()
+
+		<-r.Context().Done()
+	}}
+	srv := l.start(t)
+
+	store := mocks.NewStore(t)
+	store.EXPECT().ApplySnapshot(mock.Anything, mock.MatchedBy(func(snap models.Snapshot) bool {
+		return snap.Seq == 5 && len(snap.Items) == 1 && snap.Items[0].Version == 5
+	})).Return(nil)
+	store.EXPECT().ApplyChange(mock.Anything, mock.MatchedBy(func(c models.Change) bool {
+		return c.Seq == 6 && c.Type == models.ChangeUpdated && string(c.Item.Body) == `{}`
+	})).Return(nil)
+
+	f := startFollower(t, store, srv.URL)
+
+	require.Eventually(t, func() bool { return f.Status().Applied == 6 }, time.Second, time.Millisecond)
+
+	st := f.Status()
+	assert.True(t, st.Bootstrapped)
+	assert.Equal(t, uint64(7), st.LeaderSeq)
+	assert.Equal(t, uint64(1), st.Lag())
+	require.NoError(t, f.Ready())
+}
+
+func TestFollower_Tracing(t *testing.T) {
+	t.Parallel()
+
+	l := &leader{seq: 5, stream: func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}}
+	srv := l.start(t)
+
+	store := mocks.NewStore(t)
+	store.EXPECT().ApplySnapshot(mock.Anything, mock.Anything).Return(nil)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	f := startFollower(t, store, srv.URL, WithTracer(tp))
+
+	require.Eventually(t, func() bool { return f.Status().Bootstrapped && len(recorder.Ended()) > 0 }, time.Second, time.Millisecond)
+
+	// запрос снимка записывается клиентским спаном, и ведущий получает его контекст трассировки.
+	span := recorder.Ended()[0]
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+
+	sc := span.SpanContext()
+	assert.Equal(t, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01", l.traceparent.Load())
+}
+
+func TestFollower_Resync(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		stream func(w http.ResponseWriter, r *http.Request)
+		apply  error
+	}{
+		{
+			name: "changes are gone",
+			stream: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusGone)
+			},
+		},
+		{
+			name: "stream reset",
+			stream: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = fmt.Fprint(w, "event: reset\ndata: \"gone\"\n\n")
+			},
+		},
+		{
+			name: "gap in changes",
+			stream: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = fmt.Fprint(w, "id: 9\nevent: change\ndata: {\"seq\":9,\"type\":\"deleted\",\"id\":1}\n\n")
+			},
+			apply: storage.ErrReplicaGap,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			l := &leader{seq: 5, stream: tc.stream}
+			srv := l.start(t)
+
+			store := mocks.NewStore(t)
+			store.EXPECT().ApplySnapshot(mock.Anything, mock.Anything).Return(nil)
+
+			if tc.apply != nil {
+				store.EXPECT().ApplyChange(mock.Anything, mock.Anything).Return(tc.apply)
+			}
+
+			startFollower(t, store, srv.URL)
+
+			// ведомый заново загружает снимок, не дожидаясь паузы перед переподключением.
+			require.Eventually(t, func() bool { return l.snapshots.Load() >= 3 }, time.Second, time.Millisecond)
+		})
+	}
+}
+
+func TestFollower_LeaderUnavailable(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	f := startFollower(t, mocks.NewStore(t), srv.URL)
+
+	require.Eventually(t, func() bool { return f.Status().Error != "" }, time.Second, time.Millisecond)
+	require.ErrorIs(t, f.Ready(), ErrNotBootstrapped)
+}
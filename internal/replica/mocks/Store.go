@@ -0,0 +1,131 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "st-test/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Store is an autogenerated mock type for the Store type
+type Store struct {
+	mock.Mock
+}
+
+type Store_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Store) EXPECT() *Store_Expecter {
+	return &Store_Expecter{mock: &_m.Mock}
+}
+
+// ApplyChange provides a mock function with given fields: ctx, c
+func (_m *Store) ApplyChange(ctx context.Context, c models.Change) error {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyChange")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Change) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Store_ApplyChange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ApplyChange'
+type Store_ApplyChange_Call struct {
+	*mock.Call
+}
+
+// ApplyChange is a helper method to define mock.On call
+//   - ctx context.Context
+//   - c models.Change
+func (_e *Store_Expecter) ApplyChange(ctx interface{}, c interface{}) *Store_ApplyChange_Call {
+	return &Store_ApplyChange_Call{Call: _e.mock.On("ApplyChange", ctx, c)}
+}
+
+func (_c *Store_ApplyChange_Call) Run(run func(ctx context.Context, c models.Change)) *Store_ApplyChange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.Change))
+	})
+	return _c
+}
+
+func (_c *Store_ApplyChange_Call) Return(_a0 error) *Store_ApplyChange_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Store_ApplyChange_Call) RunAndReturn(run func(context.Context, models.Change) error) *Store_ApplyChange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ApplySnapshot provides a mock function with given fields: ctx, snap
+func (_m *Store) ApplySnapshot(ctx context.Context, snap models.Snapshot) error {
+	ret := _m.Called(ctx, snap)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplySnapshot")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Snapshot) error); ok {
+		r0 = rf(ctx, snap)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Store_ApplySnapshot_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ApplySnapshot'
+type Store_ApplySnapshot_Call struct {
+	*mock.Call
+}
+
+// ApplySnapshot is a helper method to define mock.On call
+//   - ctx context.Context
+//   - snap models.Snapshot
+func (_e *Store_Expecter) ApplySnapshot(ctx interface{}, snap interface{}) *Store_ApplySnapshot_Call {
+	return &Store_ApplySnapshot_Call{Call: _e.mock.On("ApplySnapshot", ctx, snap)}
+}
+
+func (_c *Store_ApplySnapshot_Call) Run(run func(ctx context.Context, snap models.Snapshot)) *Store_ApplySnapshot_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.Snapshot))
+	})
+	return _c
+}
+
+func (_c *Store_ApplySnapshot_Call) Return(_a0 error) *Store_ApplySnapshot_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Store_ApplySnapshot_Call) RunAndReturn(run func(context.Context, models.Snapshot) error) *Store_ApplySnapshot_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewStore creates a new instance of Store. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Store {
+	mock := &Store{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,159 @@
+// Package replica описывает репликацию хранилища с ведущего экземпляра на ведомые: формат снимка и ленты
+// изменений ведущего и ведомого, который загружает снимок и затем применяет изменения к своему хранилищу.
+package replica
+
+import (
+	"encoding/json"
+	"time"
+
+	"st-test/internal/models"
+)
+
+const (
+	// SnapshotPath путь, по которому ведущий отдаёт снимок хранилища.
+	SnapshotPath = "/replication/snapshot"
+	// ChangesPath путь, по которому ведущий отдаёт ленту изменений в формате Server-Sent Events.
+	ChangesPath = "/replication/changes"
+
+	// EventChange событие ленты с изменением объекта.
+	EventChange = "change"
+	// EventHeartbeat событие ленты с номером последнего изменения ведущего, отправляемое при простое.
+	EventHeartbeat = "heartbeat"
+	// EventReset событие ленты, после которого ведомому нужно заново загрузить снимок.
+	EventReset = "reset"
+)
+
+// Object объект хранилища со всеми сведениями о нём; тело кодируется в base64.
+type Object struct {
+	ID          int               `json:"id"`
+	Body        []byte            `json:"body"`
+	Expires     time.Duration     `json:"expires,omitempty"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+	Sliding     bool              `json:"sliding,omitempty"`
+	Version     uint64            `json:"version"`
+	Owner       string            `json:"owner,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// NewObject возвращает объект для передачи ведомому.
+func NewObject(item models.Item) Object {
+	return Object{
+		ID:          item.ID,
+		Body:        item.Body,
+		Expires:     item.Expires,
+		ExpiresAt:   item.ExpiresAt,
+		Sliding:     item.Sliding,
+		Version:     item.Version,
+		Owner:       item.Owner,
+		ContentType: item.ContentType,
+		Metadata:    item.Metadata,
+		Tags:        item.Tags,
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+	}
+}
+
+// Item возвращает объект хранилища.
+func (o Object) Item() models.Item {
+	return models.Item{
+		ID:          o.ID,
+		Body:        o.Body,
+		Expires:     o.Expires,
+		ExpiresAt:   o.ExpiresAt,
+		Sliding:     o.Sliding,
+		Version:     o.Version,
+		Owner:       o.Owner,
+		ContentType: o.ContentType,
+		Metadata:    o.Metadata,
+		Tags:        o.Tags,
+		CreatedAt:   o.CreatedAt,
+		UpdatedAt:   o.UpdatedAt,
+	}
+}
+
+// Snapshot снимок хранилища ведущего: Seq номер последнего изменения, учтённого в Objects.
+type Snapshot struct {
+	Seq     uint64   `json:"seq"`
+	Objects []Object `json:"objects"`
+}
+
+// NewSnapshot возвращает снимок для передачи ведомому.
+func NewSnapshot(snap models.Snapshot) Snapshot {
+	res := Snapshot{
+		Seq:     snap.Seq,
+		Objects: make([]Object, 0, len(snap.Items)),
+	}
+
+	for _, item := range snap.Items {
+		res.Objects = append(res.Objects, NewObject(item))
+	}
+
+	return res
+}
+
+// ToJSON возвращает снимок как json.
+func (s Snapshot) ToJSON() ([]byte, error) {
+	return json.Marshal(s) //nolint:wrapcheck
+}
+
+// Model возвращает снимок хранилища.
+func (s Snapshot) Model() models.Snapshot {
+	res := models.Snapshot{
+		Seq:   s.Seq,
+		Items: make([]models.Item, 0, len(s.Objects)),
+	}
+
+	for _, o := range s.Objects {
+		res.Items = append(res.Items, o.Item())
+	}
+
+	return res
+}
+
+// Change изменение объекта в ленте ведущего. Object содержит состояние объекта после изменения.
+// Large выставляется для изменений крупных объектов, тела которых не передаются.
+type Change struct {
+	Seq    uint64    `json:"seq"`
+	Type   string    `json:"type"`
+	ID     int       `json:"id"`
+	Time   time.Time `json:"time"`
+	Object Object    `json:"object"`
+	Large  bool      `json:"large,omitempty"`
+}
+
+// NewChange возвращает изменение для передачи ведомому.
+func NewChange(c models.Change) Change {
+	return Change{
+		Seq:    c.Seq,
+		Type:   string(c.Type),
+		ID:     c.ID,
+		Time:   c.Time,
+		Object: NewObject(c.Item),
+		Large:  c.Large,
+	}
+}
+
+// Model возвращает изменение хранилища.
+func (c Change) Model() models.Change {
+	item := c.Object.Item()
+
+	return models.Change{
+		Seq:   c.Seq,
+		Type:  models.ChangeType(c.Type),
+		ID:    c.ID,
+		Body:  item.Body,
+		Item:  item,
+		Time:  c.Time,
+		Large: c.Large,
+	}
+}
+
+// Heartbeat событие простоя ленты: Seq номер последнего изменения ведущего, Time время ведущего.
+type Heartbeat struct {
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+}
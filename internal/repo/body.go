@@ -0,0 +1,488 @@
+package repo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"st-test/internal/codec"
+	"st-test/internal/encryption"
+	"st-test/internal/models"
+	"st-test/internal/tracing"
+)
+
+// Тело объекта хранится один раз в таблице blobs под своей контрольной суммой, а строки storage и trash ссылаются
+// на него колонкой blob; refs — количество ссылающихся строк. Строки, записанные до появления blobs,
+// хранят тело в собственной колонке value и имеют пустой blob. При включённом шифровании контрольная сумма — MAC
+// на ключе из файла ключей, поэтому по ней нельзя проверить, хранится ли известное содержимое.
+const blobsSchema = `CREATE TABLE IF NOT EXISTS blobs (
+	hash TEXT PRIMARY KEY,
+	value BLOB NOT NULL,
+	codec TEXT NOT NULL DEFAULT '',
+	key_id TEXT NOT NULL DEFAULT '',
+	refs INTEGER NOT NULL DEFAULT 0
+)`
+
+// bodyTable таблица, в которой хранятся сжатые, зашифрованные и проверяемые по контрольной сумме тела.
+type bodyTable struct {
+	name string
+	// key колонка с ключом строки, sum — с контрольной суммой тела.
+	key, sum string
+	// own условие, отбирающее строки с собственным телом.
+	own string
+}
+
+// bodyTables таблицы, тела в которых перешифровываются и проверяются.
+var bodyTables = []bodyTable{
+	{name: "storage", key: "key", sum: "checksum", own: "blob = ''"},
+	{name: "trash", key: "key", sum: "checksum", own: "blob = ''"},
+	{name: "blobs", key: "hash", sum: "hash", own: "1"},
+	{name: "chunks", key: "object || ':' || seq", sum: "checksum", own: "1"},
+}
+
+// storedBody тело объекта в том виде, в котором оно лежит в таблице.
+type storedBody struct {
+	data  []byte
+	codec string
+	keyID string
+	sum   string
+	// blob ссылка на общее тело; пустая, если тело хранится в самой строке.
+	blob string
+}
+
+// bodyColumns выражения выборки тела строки таблицы t с подставленным общим телом из b.
+const bodyColumns = "COALESCE(b.value, t.value), COALESCE(b.codec, t.codec), COALESCE(b.key_id, t.key_id), " +
+	"t.checksum, t.blob"
+
+// bodyJoin присоединяет к таблице t общие тела, на которые она ссылается.
+const bodyJoin = " t LEFT JOIN blobs b ON b.hash = t.blob"
+
+// macPrefix префикс контрольной суммы, вычисленной как MAC: hmac:<id ключа>:<MAC в hex>.
+const macPrefix = "hmac:"
+
+// checksum возвращает контрольную сумму sha256 несжатого и незашифрованного тела объекта.
+func checksum(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// sum возвращает контрольную сумму тела для записи: MAC на основном ключе, если заданы ключи, иначе sha256.
+func (r *Repo) sum(body []byte) (string, error) {
+	if r.keys == nil {
+		return checksum(body), nil
+	}
+
+	keyID := r.keys.Primary()
+
+	mac, err := r.keys.MAC(keyID, body)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	return macPrefix + keyID + ":" + hex.EncodeToString(mac), nil
+}
+
+// sumKeyPrefix возвращает начало контрольных сумм, вычисленных на основном ключе.
+func (r *Repo) sumKeyPrefix() string {
+	return macPrefix + r.keys.Primary() + ":"
+}
+
+// verify сверяет тело с контрольной суммой sum: sha256 или MAC на ключе, id которого записан в sum.
+func (r *Repo) verify(body []byte, sum string) error {
+	tagged, ok := strings.CutPrefix(sum, macPrefix)
+	if !ok {
+		if checksum(body) != sum {
+			return fmt.Errorf("%w: checksum mismatch", models.ErrCorrupted)
+		}
+
+		return nil
+	}
+
+	i := strings.LastIndex(tagged, ":")
+	if i < 0 {
+		return fmt.Errorf("%w: malformed checksum", models.ErrCorrupted)
+	}
+
+	keyID := tagged[:i]
+
+	if r.keys == nil {
+		return fmt.Errorf("%w %q: encryption keyfile is not configured", encryption.ErrUnknownKey, keyID)
+	}
+
+	want, err := hex.DecodeString(tagged[i+1:])
+	if err != nil {
+		return fmt.Errorf("%w: malformed checksum: %w", models.ErrCorrupted, err)
+	}
+
+	got, err := r.keys.MAC(keyID, body)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if !hmac.Equal(got, want) {
+		return fmt.Errorf("%w: checksum mismatch", models.ErrCorrupted)
+	}
+
+	return nil
+}
+
+// additionalData привязывает шифротекст к строке таблицы, чтобы его нельзя было подставить в другую строку.
+func additionalData(table, key string) []byte {
+	return []byte(table + ":" + key)
+}
+
+// encodeBody сжимает тело и, если заданы ключи, шифрует его основным ключом с дополнительными данными aad.
+// Возвращает данные для записи, кодек и id ключа; пустой id означает, что данные не зашифрованы.
+func (r *Repo) encodeBody(aad, body []byte) ([]byte, codec.Codec, string, error) {
+	data, c, err := r.codec.Encode(body)
+	if err != nil {
+		return nil, codec.None, "", err //nolint:wrapcheck
+	}
+
+	if r.keys == nil {
+		return data, c, "", nil
+	}
+
+	data, keyID, err := r.keys.Encrypt(data, aad)
+	if err != nil {
+		return nil, codec.None, "", err //nolint:wrapcheck
+	}
+
+	return data, c, keyID, nil
+}
+
+// decodeBody расшифровывает и распаковывает тело и сверяет его с контрольной суммой sum.
+// Пустая sum означает строку, записанную до появления контрольных сумм; такая строка не проверяется.
+// Ошибки, означающие повреждение данных, оборачивают models.ErrCorrupted.
+func (r *Repo) decodeBody(aad, data []byte, c codec.Codec, keyID, sum string) ([]byte, error) {
+	if keyID != "" {
+		if r.keys == nil {
+			return nil, fmt.Errorf("%w %q: encryption keyfile is not configured", encryption.ErrUnknownKey, keyID)
+		}
+
+		var err error
+
+		data, err = r.keys.Decrypt(data, keyID, aad)
+		if err != nil {
+			if errors.Is(err, encryption.ErrDecrypt) {
+				return nil, fmt.Errorf("%w: %w", models.ErrCorrupted, err)
+			}
+
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	body, err := r.codec.Decode(data, c)
+	if err != nil {
+		if errors.Is(err, codec.ErrCorrupted) {
+			return nil, fmt.Errorf("%w: %w", models.ErrCorrupted, err)
+		}
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	if sum != "" {
+		if err := r.verify(body, sum); err != nil {
+			return nil, err
+		}
+	}
+
+	return body, nil
+}
+
+// decode возвращает тело строки key таблицы table, собственное или общее.
+func (r *Repo) decode(table string, key int, b storedBody) ([]byte, error) {
+	aad := additionalData(table, strconv.Itoa(key))
+	if b.blob != "" {
+		aad = additionalData("blobs", b.blob)
+	}
+
+	return r.decodeBody(aad, b.data, codec.Codec(b.codec), b.keyID, b.sum)
+}
+
+// acquireBlob добавляет ссылку на общее тело body и возвращает его контрольную сумму.
+// Если такого тела ещё нет, оно сжимается, шифруется и сохраняется.
+func (r *Repo) acquireBlob(tx *sql.Tx, body []byte) (string, error) {
+	hash, err := r.sum(body)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := tx.Exec("UPDATE blobs SET refs = refs + 1 WHERE hash = ?", hash)
+	if err != nil {
+		return "", fmt.Errorf("referencing blob: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return hash, nil
+	}
+
+	data, c, keyID, err := r.encodeBody(additionalData("blobs", hash), body)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec("INSERT INTO blobs (hash, value, codec, key_id, refs) VALUES (?, ?, ?, ?, 1)",
+		hash, data, string(c), keyID)
+	if err != nil {
+		return "", fmt.Errorf("inserting blob: %w", err)
+	}
+
+	return hash, nil
+}
+
+// releaseBlobs убирает ссылки строк таблицы table, отобранных условием where, на общие тела
+// и удаляет тела, на которые больше никто не ссылается. Вызывается до удаления самих строк.
+func releaseBlobs(tx *sql.Tx, table, where string, args ...any) error {
+	_, err := tx.Exec(`UPDATE blobs SET refs = refs - (
+			SELECT COUNT(*) FROM `+table+` WHERE blob = blobs.hash AND (`+where+`)
+		) WHERE hash IN (SELECT blob FROM `+table+` WHERE `+where+`)`,
+		append(append([]any{}, args...), args...)...)
+	if err != nil {
+		return fmt.Errorf("releasing blobs of %s: %w", table, err)
+	}
+
+	_, err = tx.Exec("DELETE FROM blobs WHERE refs <= 0")
+	if err != nil {
+		return fmt.Errorf("deleting orphaned blobs: %w", err)
+	}
+
+	return nil
+}
+
+// clearTable удаляет все строки таблицы table вместе со ссылками на общие тела в транзакции tx.
+func clearTable(tx *sql.Tx, table string) error {
+	if err := releaseBlobs(tx, table, "1"); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+		return fmt.Errorf("deleting from %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// deleteRows удаляет строки таблицы table, отобранные условием where, вместе со ссылками на общие тела.
+func (r *Repo) deleteRows(table, where string, args ...any) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin delete from %s: %w", table, err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := releaseBlobs(tx, table, where, args...); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM "+table+" WHERE "+where, args...)
+	if err != nil {
+		return fmt.Errorf("deleting from %s: %w", table, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit delete from %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// Reencrypt перешифровывает основным ключом тела, зашифрованные другими ключами или не зашифрованные вовсе,
+// а также тела с контрольной суммой не на основном ключе, порциями по batch строк. Общее тело при этом
+// переносится под новую контрольную сумму вместе со ссылками на него.
+// Работает параллельно с чтением и записью и останавливается при отмене ctx.
+// Возвращает количество перешифрованных тел; тела, которые не удалось расшифровать, пропускаются
+// и перечисляются в ошибке.
+func (r *Repo) Reencrypt(ctx context.Context, batch int) (int, error) {
+	if r.keys == nil {
+		return 0, nil
+	}
+
+	var (
+		total int
+		errs  []error
+	)
+
+	for _, table := range bodyTables {
+		n, err := r.reencryptTable(ctx, table, batch)
+		total += n
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return total, errors.Join(errs...)
+}
+
+func (r *Repo) reencryptTable(ctx context.Context, table bodyTable, batch int) (_ int, err error) {
+	ctx, span := r.startSpan(ctx, "reencrypt", table.name)
+	defer func() { tracing.End(span, err) }()
+
+	type row struct {
+		rowid int64
+		key   string
+		storedBody
+	}
+
+	var (
+		total int
+		last  int64
+		errs  []error
+	)
+
+	primary, prefix := r.keys.Primary(), r.sumKeyPrefix()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err //nolint:wrapcheck
+		}
+
+		rows, err := r.db.QueryContext(ctx, fmt.Sprintf(
+			"SELECT rowid, %s, value, codec, key_id, %s FROM %s WHERE (key_id != ? OR substr(%s, 1, ?) != ?) AND %s "+
+				"AND rowid > ? ORDER BY rowid LIMIT ?",
+			table.key, table.sum, table.name, table.sum, table.own),
+			primary, len(prefix), prefix, last, batch)
+		if err != nil {
+			return total, fmt.Errorf("read %s rows to reencrypt: %w", table.name, err)
+		}
+
+		pending := make([]row, 0, batch)
+
+		for rows.Next() {
+			var rw row
+
+			if err := rows.Scan(&rw.rowid, &rw.key, &rw.data, &rw.codec, &rw.keyID, &rw.sum); err != nil {
+				rows.Close()
+
+				return total, fmt.Errorf("scan %s row to reencrypt: %w", table.name, err)
+			}
+
+			pending = append(pending, rw)
+		}
+
+		rows.Close()
+
+		if len(pending) == 0 {
+			return total, errors.Join(errs...)
+		}
+
+		for _, rw := range pending {
+			last = rw.rowid
+			aad := additionalData(table.name, rw.key)
+
+			body, err := r.decodeBody(aad, rw.data, codec.Codec(rw.codec), rw.keyID, rw.sum)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s key %s: %w", table.name, rw.key, err))
+
+				continue
+			}
+
+			sum, err := r.sum(body)
+			if err != nil {
+				return total, fmt.Errorf("checksum of %s key %s: %w", table.name, rw.key, err)
+			}
+
+			// контрольная сумма общего тела — его ключ, поэтому тело переносится под новый ключ.
+			if table.name == "blobs" && sum != rw.key {
+				n, err := r.rehashBlob(ctx, rw.key, rw.data, sum, body)
+				if err != nil {
+					return total, err
+				}
+
+				total += n
+
+				continue
+			}
+
+			data, c, keyID, err := r.encodeBody(aad, body)
+			if err != nil {
+				return total, fmt.Errorf("encoding %s key %s: %w", table.name, rw.key, err)
+			}
+
+			// строку могли перезаписать, пока она перешифровывалась; такую строку не трогаем.
+			res, err := r.db.ExecContext(ctx, fmt.Sprintf(
+				"UPDATE %s SET value = ?, codec = ?, key_id = ?, %s = ? WHERE rowid = ? AND key_id = ? AND value = ?",
+				table.name, table.sum),
+				data, string(c), keyID, sum, rw.rowid, rw.keyID, rw.data)
+			if err != nil {
+				return total, fmt.Errorf("updating %s key %s: %w", table.name, rw.key, err)
+			}
+
+			if n, err := res.RowsAffected(); err == nil {
+				total += int(n)
+			}
+		}
+	}
+}
+
+// rehashBlob переносит общее тело body с контрольной суммой old под контрольную сумму hash вместе со ссылками
+// строк storage, trash и quarantine. Если под hash тело уже есть, ссылки добавляются к нему. Тело, которое изменилось,
+// пока перешифровывалось, не трогается. Возвращает количество перенесённых тел.
+func (r *Repo) rehashBlob(ctx context.Context, old string, oldData []byte, hash string, body []byte) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin rehash of blob %s: %w", old, err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	var refs int
+
+	err = tx.QueryRowContext(ctx, "SELECT refs FROM blobs WHERE hash = ? AND value = ?", old, oldData).Scan(&refs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("read blob %s: %w", old, err)
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE blobs SET refs = refs + ? WHERE hash = ?", refs, hash)
+	if err != nil {
+		return 0, fmt.Errorf("referencing blob %s: %w", hash, err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		data, c, keyID, err := r.encodeBody(additionalData("blobs", hash), body)
+		if err != nil {
+			return 0, fmt.Errorf("encoding blob %s: %w", hash, err)
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO blobs (hash, value, codec, key_id, refs) VALUES (?, ?, ?, ?, ?)",
+			hash, data, string(c), keyID, refs)
+		if err != nil {
+			return 0, fmt.Errorf("inserting blob %s: %w", hash, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM blobs WHERE hash = ?", old); err != nil {
+		return 0, fmt.Errorf("deleting blob %s: %w", old, err)
+	}
+
+	for _, table := range []string{"storage", "trash"} {
+		_, err := tx.ExecContext(ctx, "UPDATE "+table+" SET blob = ?, checksum = ? WHERE blob = ?", hash, hash, old)
+		if err != nil {
+			return 0, fmt.Errorf("updating %s rows of blob %s: %w", table, old, err)
+		}
+	}
+
+	// карантин хранит копию тела с её контрольной суммой, поэтому в нём переносится только ссылка на общее тело.
+	if _, err := tx.ExecContext(ctx, "UPDATE quarantine SET blob = ? WHERE blob = ?", hash, old); err != nil {
+		return 0, fmt.Errorf("updating quarantine rows of blob %s: %w", old, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit rehash of blob %s: %w", old, err)
+	}
+
+	return 1, nil
+}
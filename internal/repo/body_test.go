@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/models"
+)
+
+// blobRefs возвращает количество ссылок на общие тела по их содержимому.
+func blobRefs(t *testing.T, repo *Repo) map[string]int {
+	t.Helper()
+
+	rows, err := repo.db.Query("SELECT hash, refs FROM blobs")
+	require.NoError(t, err)
+
+	defer rows.Close()
+
+	refs := make(map[string]int)
+
+	for rows.Next() {
+		var (
+			hash string
+			n    int
+		)
+
+		require.NoError(t, rows.Scan(&hash, &n))
+
+		refs[hash] = n
+	}
+
+	require.NoError(t, rows.Err())
+
+	return refs
+}
+
+func TestRepo_Deduplication(t *testing.T) {
+	defer removeStorage(t)
+
+	repo := testRepo(t)
+	defer repo.Close()
+
+	shared, other := []byte(`{"shared":true}`), []byte(`{"shared":false}`)
+
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 1, Body: shared}))
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 2, Body: shared}))
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 3, Body: other}))
+	require.NoError(t, repo.InsertTrash(context.Background(), models.TrashedItem{Item: models.Item{ID: 4, Body: shared}}))
+
+	require.Equal(t, map[string]int{checksum(shared): 3, checksum(other): 1}, blobRefs(t, repo))
+
+	// удаление объекта убирает ссылку, а тело без ссылок удаляется.
+	require.NoError(t, repo.Delete(1))
+	require.NoError(t, repo.Delete(3))
+	require.Equal(t, map[string]int{checksum(shared): 2}, blobRefs(t, repo))
+
+	item, err := repo.Read(2)
+	require.NoError(t, err)
+	require.Equal(t, shared, item.Body)
+
+	// тело остаётся, пока на него ссылается объект в корзине.
+	require.NoError(t, repo.DeleteAll(context.Background()))
+	require.Equal(t, map[string]int{checksum(shared): 1}, blobRefs(t, repo))
+
+	trash, err := repo.ReadTrash(context.Background())
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.Equal(t, shared, trash[0].Body)
+
+	require.NoError(t, repo.DeleteAllTrash(context.Background()))
+	require.Empty(t, blobRefs(t, repo))
+}
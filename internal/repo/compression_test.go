@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/models"
+	"st-test/internal/settings"
+)
+
+func TestRepo_Compression(t *testing.T) {
+	defer removeStorage(t)
+
+	body := bytes.Repeat([]byte(`{"key":"value"},`), 100)
+	body = append(append([]byte(`[`), body...), `{}]`...)
+
+	// строка, записанная без сжатия; тело отличается, чтобы не совпасть с общим телом объекта 2.
+	plainBody := bytes.ToUpper(body)
+
+	plain := testRepo(t)
+	require.NoError(t, plain.Insert(context.Background(), models.Item{ID: 1, Body: plainBody}))
+	plain.Close()
+
+	repo, err := NewRepo(settings.LocalStorageSettings{
+		Path:        storagePath,
+		Compression: settings.CompressionSettings{Codec: "zstd", Threshold: 64},
+	})
+	require.NoError(t, err)
+
+	defer repo.Close()
+
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 2, Body: body}))
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 3, Body: []byte(`{}`)}))
+
+	var (
+		size  int
+		codec string
+	)
+
+	err = repo.db.QueryRow("SELECT length(b.value), b.codec FROM storage t JOIN blobs b ON b.hash = t.blob WHERE t.key = 2").Scan(&size, &codec)
+	require.NoError(t, err)
+	require.Equal(t, "zstd", codec)
+	require.Less(t, size, len(body))
+
+	items, err := repo.ReadAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	require.Equal(t, plainBody, items[0].Body)
+	require.Equal(t, body, items[1].Body)
+	require.Equal(t, []byte(`{}`), items[2].Body)
+
+	item, err := repo.Read(2)
+	require.NoError(t, err)
+	require.Equal(t, body, item.Body)
+
+	_, err = NewRepo(settings.LocalStorageSettings{
+		Path:        storagePath,
+		Compression: settings.CompressionSettings{Codec: "lz4"},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,189 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/encryption"
+	"st-test/internal/models"
+	"st-test/internal/settings"
+)
+
+// writeKeyfile записывает файл ключей с основным ключом primary и ключами ids.
+func writeKeyfile(t *testing.T, primary string, ids ...string) string {
+	t.Helper()
+
+	keys := make(map[string]string, len(ids))
+	for i, id := range ids {
+		keys[id] = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{byte(i + 1)}, 32))
+	}
+
+	raw, err := json.Marshal(map[string]any{"primary": primary, "keys": keys})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+
+	return path
+}
+
+func encryptedRepo(t *testing.T, keyfile string) *Repo {
+	t.Helper()
+
+	repo, err := NewRepo(settings.LocalStorageSettings{
+		Path:       storagePath,
+		Encryption: settings.EncryptionSettings{Keyfile: keyfile},
+	})
+	require.NoError(t, err)
+
+	return repo
+}
+
+func TestRepo_Encryption(t *testing.T) {
+	defer removeStorage(t)
+
+	body := []byte(`{"secret":"value"}`)
+
+	repo := encryptedRepo(t, writeKeyfile(t, "k1", "k1"))
+
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 1, Body: body}))
+
+	var (
+		data       []byte
+		keyID, sum string
+	)
+
+	err := repo.db.QueryRow("SELECT b.value, b.key_id, t.checksum FROM storage t JOIN blobs b ON b.hash = t.blob WHERE t.key = 1").
+		Scan(&data, &keyID, &sum)
+	require.NoError(t, err)
+	require.Equal(t, "k1", keyID)
+	require.NotContains(t, string(data), "secret")
+
+	// контрольная сумма — MAC на ключе, а не sha256 открытого тела.
+	hash, err := repo.sum(body)
+	require.NoError(t, err)
+	require.Equal(t, hash, sum)
+	require.Equal(t, "hmac:k1:", sum[:len("hmac:k1:")])
+	require.NotContains(t, sum, checksum(body))
+
+	item, err := repo.Read(1)
+	require.NoError(t, err)
+	require.Equal(t, body, item.Body)
+
+	// шифротекст привязан к телу и не расшифровывается, будучи подставленным в другое тело.
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 2, Body: []byte(`{"other":"value"}`)}))
+
+	_, err = repo.db.Exec(`UPDATE blobs SET value = (SELECT value FROM blobs WHERE hash = ?)
+		WHERE hash = (SELECT blob FROM storage WHERE key = 2)`, hash)
+	require.NoError(t, err)
+
+	_, err = repo.Read(2)
+	require.ErrorIs(t, err, encryption.ErrDecrypt)
+	require.ErrorIs(t, err, models.ErrCorrupted)
+
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 3, Body: body}))
+	repo.Close()
+
+	// без файла ключей зашифрованные строки не читаются, но и не выдаются за отсутствующие или повреждённые.
+	plain := testRepo(t)
+	defer plain.Close()
+
+	_, err = plain.Read(3)
+	require.ErrorIs(t, err, encryption.ErrUnknownKey)
+	require.NotErrorIs(t, err, models.ErrCorrupted)
+
+	_, err = plain.ReadAll(context.Background())
+	require.ErrorIs(t, err, encryption.ErrUnknownKey)
+}
+
+func TestRepo_Reencrypt(t *testing.T) {
+	defer removeStorage(t)
+
+	// строки, записанные без шифрования, в том числе до появления общих тел, и старым ключом.
+	plain := testRepo(t)
+	require.NoError(t, plain.Insert(context.Background(), models.Item{ID: 1, Body: []byte(`{"n":1}`)}))
+	_, err := plain.db.Exec(`INSERT INTO storage (key, value) VALUES (4, CAST('{"n":4}' AS BLOB))`)
+	require.NoError(t, err)
+	require.NoError(t, plain.InsertTrash(context.Background(), models.TrashedItem{Item: models.Item{ID: 5, Body: []byte(`{"n":5}`)}}))
+	plain.Close()
+
+	old := encryptedRepo(t, writeKeyfile(t, "k1", "k1"))
+	require.NoError(t, old.Insert(context.Background(), models.Item{ID: 2, Body: []byte(`{"n":2}`)}))
+	require.NoError(t, old.Insert(context.Background(), models.Item{ID: 3, Body: []byte(`{"n":3}`)}))
+	// то же тело, что и у объекта 1, но под контрольной суммой на старом ключе.
+	require.NoError(t, old.Insert(context.Background(), models.Item{ID: 6, Body: []byte(`{"n":1}`)}))
+	// помещённый в карантин объект ссылается на общее тело объекта 2.
+	require.NoError(t, old.Insert(context.Background(), models.Item{ID: 7, Body: []byte(`{"n":2}`)}))
+	_, err = old.quarantine("storage", 7, "test")
+	require.NoError(t, err)
+	old.Close()
+
+	repo := encryptedRepo(t, writeKeyfile(t, "k2", "k1", "k2"))
+	defer repo.Close()
+
+	n, err := repo.Reencrypt(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, 6, n)
+
+	var count int
+
+	err = repo.db.QueryRow(`SELECT (SELECT count(*) FROM blobs WHERE key_id != 'k2')
+		+ (SELECT count(*) FROM storage WHERE blob = '' AND key_id != 'k2')`).Scan(&count)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	// контрольные суммы пересчитаны на основном ключе, а одинаковые тела снова хранятся один раз.
+	err = repo.db.QueryRow(`SELECT (SELECT count(*) FROM storage WHERE checksum NOT LIKE 'hmac:k2:%')
+		+ (SELECT count(*) FROM trash WHERE checksum NOT LIKE 'hmac:k2:%')`).Scan(&count)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	// ссылка карантина перенесена вместе с общим телом.
+	var blob string
+
+	require.NoError(t, repo.db.QueryRow("SELECT blob FROM quarantine WHERE key = 7").Scan(&blob))
+
+	quarantined, err := repo.sum([]byte(`{"n":2}`))
+	require.NoError(t, err)
+	require.Equal(t, quarantined, blob)
+
+	shared, err := repo.sum([]byte(`{"n":1}`))
+	require.NoError(t, err)
+	require.Equal(t, 2, blobRefs(t, repo)[shared])
+
+	items, err := repo.ReadAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 5)
+	require.Equal(t, []byte(`{"n":3}`), items[2].Body)
+	require.Equal(t, []byte(`{"n":4}`), items[3].Body)
+
+	trash, err := repo.ReadTrash(context.Background())
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.Equal(t, []byte(`{"n":5}`), trash[0].Body)
+
+	// повторный запуск ничего не перешифровывает.
+	n, err = repo.Reencrypt(context.Background(), 10)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestNewRepo_InvalidKeyfile(t *testing.T) {
+	defer removeStorage(t)
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"primary":"k1","keys":{"k1":"c2hvcnQ="}}`), 0o600))
+
+	_, err := NewRepo(settings.LocalStorageSettings{
+		Path:       storagePath,
+		Encryption: settings.EncryptionSettings{Keyfile: path},
+	})
+	require.ErrorIs(t, err, encryption.ErrInvalidKeyfile)
+}
@@ -0,0 +1,509 @@
+package repo
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"st-test/internal/codec"
+	"st-test/internal/models"
+	"st-test/internal/tracing"
+)
+
+// Тело крупного объекта хранится частями в таблице chunks под идентификатором object, которым служит id загрузки,
+// создавшей объект. Пока загрузка не завершена, её части принадлежат строке uploads, после завершения —
+// строке large_objects, а части прежнего тела объекта удаляются.
+const (
+	largeObjectsSchema = `CREATE TABLE IF NOT EXISTS large_objects (
+	key INTEGER PRIMARY KEY,
+	object TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	checksum TEXT NOT NULL,
+	owner TEXT NOT NULL DEFAULT '',
+	updated_at INTEGER NOT NULL
+)`
+	uploadsSchema = `CREATE TABLE IF NOT EXISTS uploads (
+	id TEXT PRIMARY KEY,
+	key INTEGER NOT NULL,
+	size INTEGER NOT NULL DEFAULT 0,
+	owner TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL
+)`
+	chunksSchema = `CREATE TABLE IF NOT EXISTS chunks (
+	object TEXT NOT NULL,
+	seq INTEGER NOT NULL,
+	start INTEGER NOT NULL,
+	value BLOB NOT NULL,
+	codec TEXT NOT NULL DEFAULT '',
+	key_id TEXT NOT NULL DEFAULT '',
+	checksum TEXT NOT NULL,
+	PRIMARY KEY (object, seq)
+)`
+	chunksIndex = `CREATE INDEX IF NOT EXISTS chunks_start ON chunks (object, start)`
+)
+
+// chunkAdditionalData привязывает шифротекст части к объекту и её номеру.
+func chunkAdditionalData(object string, seq int64) []byte {
+	return additionalData("chunks", object+":"+strconv.FormatInt(seq, 10))
+}
+
+// CreateUpload начинает загрузку крупного объекта key с типом содержимого contentType от имени клиента owner.
+func (r *Repo) CreateUpload(key int, owner, contentType string) (models.Upload, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return models.Upload{}, fmt.Errorf("generating upload id: %w", err)
+	}
+
+	now := time.Now()
+	up := models.Upload{
+		ID:          hex.EncodeToString(raw),
+		ObjectID:    key,
+		Owner:       owner,
+		ContentType: contentType,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	_, err := r.db.Exec(`INSERT INTO uploads (id, key, owner, content_type, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		up.ID, key, owner, contentType, now.UnixNano(), now.UnixNano())
+	if err != nil {
+		return models.Upload{}, fmt.Errorf("inserting upload of key %d: %w", key, err)
+	}
+
+	return up, nil
+}
+
+// Upload возвращает загрузку по id.
+func (r *Repo) Upload(id string) (models.Upload, error) {
+	var (
+		up                   models.Upload
+		createdAt, updatedAt int64
+	)
+
+	err := r.db.QueryRow("SELECT id, key, size, owner, content_type, created_at, updated_at FROM uploads WHERE id = ?", id).
+		Scan(&up.ID, &up.ObjectID, &up.Offset, &up.Owner, &up.ContentType, &createdAt, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Upload{}, models.ErrNotFound
+		}
+
+		return models.Upload{}, fmt.Errorf("read upload %s: %w", id, err)
+	}
+
+	up.CreatedAt = time.Unix(0, createdAt)
+	up.UpdatedAt = time.Unix(0, updatedAt)
+
+	return up, nil
+}
+
+// AppendUpload дописывает к загрузке id тело из body частями по chunkSize байт, начиная с offset.
+// offset должен совпадать с количеством уже принятых байт, иначе возвращается models.ErrOffsetMismatch.
+// Каждая часть сохраняется сразу, поэтому при обрыве body принятое остаётся в загрузке и её можно продолжить.
+// Если загрузка превысит limit байт, возвращается models.ErrTooLarge.
+func (r *Repo) AppendUpload(
+	ctx context.Context, id string, offset int64, body io.Reader, chunkSize int, limit int64,
+) (_ models.Upload, err error) {
+	ctx, span := r.startSpan(ctx, "append", "chunks")
+	defer func() { tracing.End(span, err) }()
+
+	up, err := r.Upload(id)
+	if err != nil {
+		return up, err
+	}
+
+	if offset != up.Offset {
+		return up, fmt.Errorf("%w: upload %s has %d bytes", models.ErrOffsetMismatch, id, up.Offset)
+	}
+
+	var seq int64
+
+	err = r.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(seq) + 1, 0) FROM chunks WHERE object = ?", id).Scan(&seq)
+	if err != nil {
+		return up, fmt.Errorf("read chunks of upload %s: %w", id, err)
+	}
+
+	buf := make([]byte, chunkSize)
+
+	for {
+		n, rerr := io.ReadFull(body, buf)
+		if n > 0 {
+			if up.Offset+int64(n) > limit {
+				return up, fmt.Errorf("%w: at most %d bytes are allowed", models.ErrTooLarge, limit)
+			}
+
+			if err := r.appendChunk(ctx, &up, seq, buf[:n]); err != nil {
+				return up, err
+			}
+
+			seq++
+		}
+
+		if errors.Is(rerr, io.EOF) || errors.Is(rerr, io.ErrUnexpectedEOF) {
+			return up, nil
+		}
+
+		if rerr != nil {
+			return up, fmt.Errorf("reading upload %s: %w", id, rerr)
+		}
+	}
+}
+
+// appendChunk сохраняет часть data загрузки up с номером seq и сдвигает её смещение.
+func (r *Repo) appendChunk(ctx context.Context, up *models.Upload, seq int64, data []byte) error {
+	value, c, keyID, err := r.encodeBody(chunkAdditionalData(up.ID, seq), data)
+	if err != nil {
+		return fmt.Errorf("encoding chunk %d of upload %s: %w", seq, up.ID, err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin append to upload %s: %w", up.ID, err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	now := time.Now()
+
+	// загрузку могли продолжить параллельно; тогда смещение уже другое и часть не записывается.
+	res, err := tx.Exec("UPDATE uploads SET size = size + ?, updated_at = ? WHERE id = ? AND size = ?",
+		len(data), now.UnixNano(), up.ID, up.Offset)
+	if err != nil {
+		return fmt.Errorf("updating upload %s: %w", up.ID, err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: upload %s was changed concurrently", models.ErrOffsetMismatch, up.ID)
+	}
+
+	sum, err := r.sum(data)
+	if err != nil {
+		return fmt.Errorf("checksum of chunk %d of upload %s: %w", seq, up.ID, err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO chunks (object, seq, start, value, codec, key_id, checksum)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		up.ID, seq, up.Offset, value, string(c), keyID, sum)
+	if err != nil {
+		return fmt.Errorf("inserting chunk %d of upload %s: %w", seq, up.ID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit append to upload %s: %w", up.ID, err)
+	}
+
+	up.Offset += int64(len(data))
+	up.UpdatedAt = now
+
+	return nil
+}
+
+// OpenUpload возвращает загрузку id и чтение уже принятого тела.
+func (r *Repo) OpenUpload(ctx context.Context, id string) (models.Upload, io.ReadSeeker, error) {
+	up, err := r.Upload(id)
+	if err != nil {
+		return up, nil, err
+	}
+
+	return up, &chunkReader{ctx: ctx, r: r, object: up.ID, size: up.Offset}, nil
+}
+
+// CommitUpload завершает загрузку id с контрольной суммой тела sum: тело становится телом крупного объекта,
+// а прежнее тело объекта удаляется. Возвращает объект и признак того, что объекта раньше не было.
+func (r *Repo) CommitUpload(id, sum string) (models.LargeObject, bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.LargeObject{}, false, fmt.Errorf("begin commit of upload %s: %w", id, err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	obj := models.LargeObject{Checksum: sum, UpdatedAt: time.Now()}
+
+	err = tx.QueryRow("SELECT key, size, owner, content_type FROM uploads WHERE id = ?", id).
+		Scan(&obj.ID, &obj.Size, &obj.Owner, &obj.ContentType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return obj, false, models.ErrNotFound
+		}
+
+		return obj, false, fmt.Errorf("read upload %s: %w", id, err)
+	}
+
+	var old string
+
+	err = tx.QueryRow("SELECT object FROM large_objects WHERE key = ?", obj.ID).Scan(&old)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return obj, false, fmt.Errorf("read large object %d: %w", obj.ID, err)
+	}
+
+	created := errors.Is(err, sql.ErrNoRows)
+
+	_, err = tx.Exec("DELETE FROM chunks WHERE object = ?", old)
+	if err != nil {
+		return obj, false, fmt.Errorf("deleting chunks of large object %d: %w", obj.ID, err)
+	}
+
+	_, err = tx.Exec(`INSERT OR REPLACE INTO large_objects (key, object, size, checksum, owner, content_type, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		obj.ID, id, obj.Size, obj.Checksum, obj.Owner, obj.ContentType, obj.UpdatedAt.UnixNano())
+	if err != nil {
+		return obj, false, fmt.Errorf("inserting large object %d: %w", obj.ID, err)
+	}
+
+	_, err = tx.Exec("DELETE FROM uploads WHERE id = ?", id)
+	if err != nil {
+		return obj, false, fmt.Errorf("deleting upload %s: %w", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return obj, false, fmt.Errorf("commit of upload %s: %w", id, err)
+	}
+
+	return obj, created, nil
+}
+
+// DeleteUpload отменяет загрузку id вместе с принятыми частями.
+func (r *Repo) DeleteUpload(id string) error {
+	return r.deleteChunked("uploads", "id", "id", id)
+}
+
+// PurgeUploads отменяет загрузки, которые не продолжались с момента before, и возвращает их количество.
+func (r *Repo) PurgeUploads(before time.Time) (int, error) {
+	rows, err := r.db.Query("SELECT id FROM uploads WHERE updated_at < ?", before.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("read stale uploads: %w", err)
+	}
+
+	var ids []string
+
+	for rows.Next() {
+		var id string
+
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+
+			return 0, fmt.Errorf("scan stale upload: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	rows.Close()
+
+	purged := 0
+
+	for _, id := range ids {
+		err := r.DeleteUpload(id)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			return purged, err
+		}
+
+		if err == nil {
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// LargeObject возвращает крупный объект по ключу.
+func (r *Repo) LargeObject(key int) (models.LargeObject, error) {
+	obj, _, err := r.largeObject(key)
+
+	return obj, err
+}
+
+// LargeObjects возвращает все крупные объекты без их тел.
+func (r *Repo) LargeObjects() ([]models.LargeObject, error) {
+	rows, err := r.db.Query("SELECT key, size, checksum, owner, content_type, updated_at FROM large_objects ORDER BY key")
+	if err != nil {
+		return nil, fmt.Errorf("read large objects: %w", err)
+	}
+
+	defer rows.Close()
+
+	var res []models.LargeObject
+
+	for rows.Next() {
+		var (
+			obj       models.LargeObject
+			updatedAt int64
+		)
+
+		if err := rows.Scan(&obj.ID, &obj.Size, &obj.Checksum, &obj.Owner, &obj.ContentType, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scan large object: %w", err)
+		}
+
+		obj.UpdatedAt = time.Unix(0, updatedAt)
+		res = append(res, obj)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read large objects: %w", err)
+	}
+
+	return res, nil
+}
+
+// OpenLargeObject возвращает крупный объект по ключу и чтение его тела. Тело читается частями по мере чтения.
+func (r *Repo) OpenLargeObject(ctx context.Context, key int) (models.LargeObject, io.ReadSeeker, error) {
+	obj, object, err := r.largeObject(key)
+	if err != nil {
+		return obj, nil, err
+	}
+
+	return obj, &chunkReader{ctx: ctx, r: r, object: object, size: obj.Size}, nil
+}
+
+// largeObject возвращает крупный объект по ключу и идентификатор частей его тела.
+func (r *Repo) largeObject(key int) (models.LargeObject, string, error) {
+	var (
+		obj       models.LargeObject
+		object    string
+		updatedAt int64
+	)
+
+	err := r.db.QueryRow(`SELECT key, object, size, checksum, owner, content_type, updated_at
+		FROM large_objects WHERE key = ?`, key).
+		Scan(&obj.ID, &object, &obj.Size, &obj.Checksum, &obj.Owner, &obj.ContentType, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.LargeObject{}, "", models.ErrNotFound
+		}
+
+		return models.LargeObject{}, "", fmt.Errorf("read large object %d: %w", key, err)
+	}
+
+	obj.UpdatedAt = time.Unix(0, updatedAt)
+
+	return obj, object, nil
+}
+
+// DeleteLargeObject удаляет крупный объект вместе с телом.
+func (r *Repo) DeleteLargeObject(key int) error {
+	return r.deleteChunked("large_objects", "object", "key", key)
+}
+
+// deleteChunked удаляет строку table, у которой колонка column равна value, вместе с частями её тела;
+// колонка object содержит идентификатор частей.
+func (r *Repo) deleteChunked(table, object, column string, value any) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin delete from %s: %w", table, err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	_, err = tx.Exec("DELETE FROM chunks WHERE object IN (SELECT "+object+" FROM "+table+" WHERE "+column+" = ?)", value)
+	if err != nil {
+		return fmt.Errorf("deleting chunks of %s %v: %w", table, value, err)
+	}
+
+	res, err := tx.Exec("DELETE FROM "+table+" WHERE "+column+" = ?", value)
+	if err != nil {
+		return fmt.Errorf("deleting %s %v: %w", table, value, err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return models.ErrNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit delete from %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// chunkReader читает тело, хранящееся частями, держа в памяти только текущую часть.
+type chunkReader struct {
+	ctx    context.Context
+	r      *Repo
+	object string
+	size   int64
+	pos    int64
+	// chunk текущая часть, начинающаяся с позиции start.
+	chunk []byte
+	start int64
+}
+
+// Read читает тело с текущей позиции. Повреждённая часть возвращает ошибку, оборачивающую models.ErrCorrupted.
+func (c *chunkReader) Read(p []byte) (int, error) {
+	if c.pos >= c.size {
+		return 0, io.EOF
+	}
+
+	if c.pos < c.start || c.pos >= c.start+int64(len(c.chunk)) {
+		if err := c.load(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, c.chunk[c.pos-c.start:])
+	c.pos += int64(n)
+
+	return n, nil
+}
+
+// Seek меняет позицию чтения.
+func (c *chunkReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += c.pos
+	case io.SeekEnd:
+		offset += c.size
+	default:
+		return c.pos, fmt.Errorf("seek %s: invalid whence %d", c.object, whence)
+	}
+
+	if offset < 0 {
+		return c.pos, fmt.Errorf("seek %s: negative position %d", c.object, offset)
+	}
+
+	c.pos = offset
+
+	return c.pos, nil
+}
+
+// load загружает часть, содержащую текущую позицию.
+func (c *chunkReader) load() error {
+	var (
+		seq int64
+		b   storedBody
+	)
+
+	err := c.r.db.QueryRowContext(c.ctx, `SELECT seq, start, value, codec, key_id, checksum FROM chunks
+		WHERE object = ? AND start <= ? ORDER BY start DESC LIMIT 1`, c.object, c.pos).
+		Scan(&seq, &c.start, &b.data, &b.codec, &b.keyID, &b.sum)
+	if err != nil {
+		c.chunk = nil
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("chunk of %s at %d: %w", c.object, c.pos, models.ErrNotFound)
+		}
+
+		return fmt.Errorf("read chunk of %s at %d: %w", c.object, c.pos, err)
+	}
+
+	c.chunk, err = c.r.decodeBody(chunkAdditionalData(c.object, seq), b.data, codec.Codec(b.codec), b.keyID, b.sum)
+	if err != nil {
+		return fmt.Errorf("decoding chunk %d of %s: %w", seq, c.object, err)
+	}
+
+	if c.pos >= c.start+int64(len(c.chunk)) {
+		c.chunk = nil
+
+		return fmt.Errorf("chunk of %s at %d: %w", c.object, c.pos, models.ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/models"
+)
+
+// largeObject записывает крупный объект key с телом body частями по chunkSize байт.
+func largeObject(t *testing.T, repo *Repo, key int, body string, chunkSize int) {
+	t.Helper()
+
+	up, err := repo.CreateUpload(key, "", "application/json")
+	require.NoError(t, err)
+
+	_, err = repo.AppendUpload(context.Background(), up.ID, 0, strings.NewReader(body), chunkSize, 1<<20)
+	require.NoError(t, err)
+
+	_, _, err = repo.CommitUpload(up.ID, checksum([]byte(body)))
+	require.NoError(t, err)
+}
+
+func TestRepo_LargeObject(t *testing.T) {
+	defer removeStorage(t)
+
+	body := strings.Repeat(`{"chunk":true},`, 20)
+
+	old := encryptedRepo(t, writeKeyfile(t, "k1", "k1"))
+	largeObject(t, old, 1, body, 16)
+	largeObject(t, old, 2, body, 16)
+	old.Close()
+
+	repo := encryptedRepo(t, writeKeyfile(t, "k2", "k1", "k2"))
+	defer repo.Close()
+
+	// части крупных объектов перешифровываются вместе с остальными телами.
+	n, err := repo.Reencrypt(context.Background(), 7)
+	require.NoError(t, err)
+	require.Equal(t, 38, n)
+
+	obj, rd, err := repo.OpenLargeObject(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(body)), obj.Size)
+	require.Equal(t, "application/json", obj.ContentType)
+
+	raw, err := io.ReadAll(rd)
+	require.NoError(t, err)
+	require.Equal(t, body, string(raw))
+
+	// замена тела удаляет части прежнего.
+	largeObject(t, repo, 1, `{}`, 16)
+
+	var chunks int
+
+	require.NoError(t, repo.db.QueryRow("SELECT count(*) FROM chunks").Scan(&chunks))
+	require.Equal(t, 20, chunks)
+
+	// повреждённая часть убирает в карантин весь объект.
+	_, err = repo.db.Exec(`UPDATE chunks SET value = x'00' WHERE seq = 3`)
+	require.NoError(t, err)
+
+	report, err := repo.Scrub(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, report.Corrupted, 1)
+	require.Equal(t, "large_objects", report.Corrupted[0].Source)
+	require.Equal(t, 2, report.Corrupted[0].ObjectID)
+
+	_, err = repo.LargeObject(2)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = repo.LargeObject(1)
+	require.NoError(t, err)
+
+	all, err := repo.LargeObjects()
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	require.Equal(t, 1, all[0].ID)
+	require.Equal(t, int64(2), all[0].Size)
+}
@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"st-test/internal/models"
+)
+
+// Метаданные и теги объекта хранятся открыто в колонках metadata и tags как json; пустая строка означает,
+// что их нет.
+
+// encodeMetadata возвращает метаданные и теги объекта для записи в колонки metadata и tags.
+func encodeMetadata(item models.Item) (string, string, error) {
+	var meta, tags []byte
+
+	if len(item.Metadata) > 0 {
+		var err error
+
+		meta, err = json.Marshal(item.Metadata)
+		if err != nil {
+			return "", "", fmt.Errorf("encoding metadata: %w", err)
+		}
+	}
+
+	if len(item.Tags) > 0 {
+		var err error
+
+		tags, err = json.Marshal(item.Tags)
+		if err != nil {
+			return "", "", fmt.Errorf("encoding tags: %w", err)
+		}
+	}
+
+	return string(meta), string(tags), nil
+}
+
+// decodeMetadata записывает в item метаданные и теги из колонок metadata и tags.
+func decodeMetadata(item *models.Item, meta, tags string) error {
+	if meta != "" {
+		if err := json.Unmarshal([]byte(meta), &item.Metadata); err != nil {
+			return fmt.Errorf("decoding metadata: %w", err)
+		}
+	}
+
+	if tags != "" {
+		if err := json.Unmarshal([]byte(tags), &item.Tags); err != nil {
+			return fmt.Errorf("decoding tags: %w", err)
+		}
+	}
+
+	return nil
+}
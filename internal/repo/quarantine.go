@@ -0,0 +1,361 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"st-test/internal/codec"
+	"st-test/internal/models"
+	"st-test/internal/tracing"
+)
+
+const quarantineSchema = `CREATE TABLE IF NOT EXISTS quarantine (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	source TEXT NOT NULL,
+	key INTEGER NOT NULL,
+	value BLOB NOT NULL,
+	version INTEGER NOT NULL DEFAULT 0,
+	codec TEXT NOT NULL DEFAULT '',
+	key_id TEXT NOT NULL DEFAULT '',
+	checksum TEXT NOT NULL DEFAULT '',
+	reason TEXT NOT NULL,
+	quarantined_at INTEGER NOT NULL
+)`
+
+// corruptedRow строка таблицы, которую не удалось прочитать из-за повреждения.
+type corruptedRow struct {
+	key int
+	err error
+}
+
+// quarantineRows переносит повреждённые строки таблицы table в карантин и возвращает ошибку,
+// перечисляющую их и оборачивающую models.ErrCorrupted. Если переносить нечего, возвращает nil.
+func (r *Repo) quarantineRows(table string, rows []corruptedRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(rows))
+
+	for _, row := range rows {
+		_, err := r.quarantine(table, row.key, row.err.Error())
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			return err
+		}
+
+		errs = append(errs, fmt.Errorf("%s key %d quarantined: %w", table, row.key, row.err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// quarantine переносит строку key таблицы table в карантин вместе с исходными данными тела.
+// Общее тело остаётся на месте, пока на него ссылаются другие строки.
+func (r *Repo) quarantine(table string, key int, reason string) (models.QuarantinedItem, error) {
+	item := models.QuarantinedItem{
+		Source:        table,
+		ObjectID:      key,
+		Reason:        reason,
+		QuarantinedAt: time.Now(),
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return item, fmt.Errorf("begin quarantine of %s key %d: %w", table, key, err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	err = tx.QueryRow(`INSERT INTO quarantine
+			(source, key, value, version, codec, key_id, checksum, blob, reason, quarantined_at)
+		SELECT ?, t.key, COALESCE(b.value, t.value), t.version, COALESCE(b.codec, t.codec),
+			COALESCE(b.key_id, t.key_id), t.checksum, t.blob, ?, ?
+		FROM `+table+bodyJoin+` WHERE t.key = ?
+		RETURNING id, version`,
+		table, reason, item.QuarantinedAt.UnixNano(), key).Scan(&item.ID, &item.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return item, models.ErrNotFound
+		}
+
+		return item, fmt.Errorf("quarantine %s key %d: %w", table, key, err)
+	}
+
+	if err := releaseBlobs(tx, table, "key = ?", key); err != nil {
+		return item, err
+	}
+
+	_, err = tx.Exec("DELETE FROM "+table+" WHERE key = ?", key)
+	if err != nil {
+		return item, fmt.Errorf("quarantine %s key %d: %w", table, key, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return item, fmt.Errorf("commit quarantine of %s key %d: %w", table, key, err)
+	}
+
+	return item, nil
+}
+
+// Quarantined возвращает limit последних объектов, убранных в карантин.
+func (r *Repo) Quarantined(limit int) ([]models.QuarantinedItem, error) {
+	rows, err := r.db.Query(`SELECT id, source, key, version, reason, quarantined_at
+		FROM quarantine ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("read quarantine: %w", err)
+	}
+
+	defer rows.Close()
+
+	items := make([]models.QuarantinedItem, 0)
+
+	for rows.Next() {
+		var (
+			i  models.QuarantinedItem
+			at int64
+		)
+
+		err := rows.Scan(&i.ID, &i.Source, &i.ObjectID, &i.Version, &i.Reason, &at)
+		if err != nil {
+			return nil, fmt.Errorf("scan quarantine row: %w", err)
+		}
+
+		i.QuarantinedAt = time.Unix(0, at)
+		items = append(items, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read quarantine: %w", err)
+	}
+
+	return items, nil
+}
+
+// Scrub проверяет все сохранённые тела порциями по batch строк и убирает объекты с повреждёнными телами в карантин.
+// Повреждённое общее тело убирает в карантин все ссылающиеся на него объекты.
+// Работает параллельно с чтением и записью и останавливается при отмене ctx.
+// Тела, которые не удалось проверить по другим причинам, учитываются в отчёте и перечисляются в ошибке.
+func (r *Repo) Scrub(ctx context.Context, batch int) (models.ScrubReport, error) {
+	report := models.ScrubReport{
+		StartedAt: time.Now(),
+		Corrupted: make([]models.QuarantinedItem, 0),
+	}
+
+	var errs []error
+
+	for _, table := range bodyTables {
+		err := r.scrubTable(ctx, table, batch, &report)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	report.FinishedAt = time.Now()
+
+	return report, errors.Join(errs...)
+}
+
+func (r *Repo) scrubTable(ctx context.Context, table bodyTable, batch int, report *models.ScrubReport) (err error) {
+	ctx, span := r.startSpan(ctx, "scrub", table.name)
+	defer func() { tracing.End(span, err) }()
+
+	type corruptedBody struct {
+		key string
+		err error
+	}
+
+	var (
+		last int64
+		errs []error
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		rows, err := r.db.QueryContext(ctx, fmt.Sprintf(
+			"SELECT rowid, %s, value, codec, key_id, %s FROM %s WHERE %s AND rowid > ? ORDER BY rowid LIMIT ?",
+			table.key, table.sum, table.name, table.own),
+			last, batch)
+		if err != nil {
+			return fmt.Errorf("read %s rows to scrub: %w", table.name, err)
+		}
+
+		var (
+			n         int
+			corrupted []corruptedBody
+		)
+
+		for rows.Next() {
+			var (
+				key string
+				b   storedBody
+			)
+
+			if err := rows.Scan(&last, &key, &b.data, &b.codec, &b.keyID, &b.sum); err != nil {
+				rows.Close()
+
+				return fmt.Errorf("scan %s row to scrub: %w", table.name, err)
+			}
+
+			n++
+
+			_, err := r.decodeBody(additionalData(table.name, key), b.data, codec.Codec(b.codec), b.keyID, b.sum)
+
+			switch {
+			case err == nil:
+				report.Checked++
+			case errors.Is(err, models.ErrCorrupted):
+				report.Checked++
+
+				corrupted = append(corrupted, corruptedBody{key: key, err: err})
+			default:
+				report.Unreadable++
+
+				errs = append(errs, fmt.Errorf("%s key %s: %w", table.name, key, err))
+			}
+		}
+
+		rows.Close()
+
+		// строки переносятся в карантин после закрытия выборки, чтобы не держать блокировку чтения при записи.
+		for _, c := range corrupted {
+			items, err := r.quarantineBody(table.name, c.key, c.err.Error())
+			report.Corrupted = append(report.Corrupted, items...)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if n < batch {
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// quarantineBody убирает в карантин объекты с повреждённым телом key таблицы table:
+// саму строку, все строки, ссылающиеся на повреждённое общее тело, или крупный объект с повреждённой частью.
+func (r *Repo) quarantineBody(table, key, reason string) ([]models.QuarantinedItem, error) {
+	type ref struct {
+		table string
+		key   int
+	}
+
+	var refs []ref
+
+	if table == "chunks" {
+		object, _, _ := strings.Cut(key, ":")
+
+		return r.quarantineChunked(object, reason)
+	}
+
+	if table == "blobs" {
+		for _, t := range []string{"storage", "trash"} {
+			rows, err := r.db.Query("SELECT key FROM "+t+" WHERE blob = ?", key)
+			if err != nil {
+				return nil, fmt.Errorf("read %s rows of blob %s: %w", t, key, err)
+			}
+
+			for rows.Next() {
+				rf := ref{table: t}
+
+				if err := rows.Scan(&rf.key); err != nil {
+					rows.Close()
+
+					return nil, fmt.Errorf("scan %s row of blob %s: %w", t, key, err)
+				}
+
+				refs = append(refs, rf)
+			}
+
+			rows.Close()
+		}
+	} else {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s key %s: %w", table, key, err)
+		}
+
+		refs = append(refs, ref{table: table, key: id})
+	}
+
+	items := make([]models.QuarantinedItem, 0, len(refs))
+
+	for _, rf := range refs {
+		item, err := r.quarantine(rf.table, rf.key, reason)
+		if err != nil {
+			// строку удалили, пока она проверялась.
+			if errors.Is(err, models.ErrNotFound) {
+				continue
+			}
+
+			return items, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// quarantineChunked убирает в карантин крупный объект или незавершённую загрузку, часть тела которых повреждена.
+// Тело крупного объекта в карантин не копируется и удаляется, в карантине остаётся только запись о нём.
+func (r *Repo) quarantineChunked(object, reason string) ([]models.QuarantinedItem, error) {
+	item := models.QuarantinedItem{
+		Source:        "large_objects",
+		Reason:        reason,
+		QuarantinedAt: time.Now(),
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin quarantine of chunks %s: %w", object, err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	err = tx.QueryRow("SELECT key FROM large_objects WHERE object = ?", object).Scan(&item.ObjectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		item.Source = "uploads"
+		err = tx.QueryRow("SELECT key FROM uploads WHERE id = ?", object).Scan(&item.ObjectID)
+	}
+
+	if err != nil {
+		// объект удалили, пока он проверялся.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read owner of chunks %s: %w", object, err)
+	}
+
+	err = tx.QueryRow(`INSERT INTO quarantine (source, key, value, blob, reason, quarantined_at)
+		VALUES (?, ?, x'', ?, ?, ?) RETURNING id`,
+		item.Source, item.ObjectID, object, reason, item.QuarantinedAt.UnixNano()).Scan(&item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("quarantine chunks %s: %w", object, err)
+	}
+
+	for _, q := range []string{
+		"DELETE FROM chunks WHERE object = ?",
+		"DELETE FROM large_objects WHERE object = ?",
+		"DELETE FROM uploads WHERE id = ?",
+	} {
+		if _, err := tx.Exec(q, object); err != nil {
+			return nil, fmt.Errorf("quarantine chunks %s: %w", object, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit quarantine of chunks %s: %w", object, err)
+	}
+
+	return []models.QuarantinedItem{item}, nil
+}
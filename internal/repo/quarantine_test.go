@@ -0,0 +1,122 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/models"
+)
+
+func TestRepo_Checksum(t *testing.T) {
+	defer removeStorage(t)
+
+	repo := testRepo(t)
+	defer repo.Close()
+
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 1, Body: []byte(`{"n":1}`)}))
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 2, Body: []byte(`{"n":2}`), Version: 7}))
+
+	var sum string
+
+	err := repo.db.QueryRow("SELECT checksum FROM storage WHERE key = 1").Scan(&sum)
+	require.NoError(t, err)
+	require.Equal(t, checksum([]byte(`{"n":1}`)), sum)
+
+	// тело изменилось на диске, а контрольная сумма осталась прежней.
+	_, err = repo.db.Exec(`UPDATE blobs SET value = '{"n":3}' WHERE hash = (SELECT blob FROM storage WHERE key = 2)`)
+	require.NoError(t, err)
+
+	items, err := repo.ReadAll(context.Background())
+	require.ErrorIs(t, err, models.ErrCorrupted)
+	require.Len(t, items, 1)
+	require.Equal(t, 1, items[0].ID)
+
+	// повреждённый объект убран в карантин и больше не читается.
+	_, err = repo.Read(2)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	quarantined, err := repo.Quarantined(10)
+	require.NoError(t, err)
+	require.Len(t, quarantined, 1)
+	require.Equal(t, "storage", quarantined[0].Source)
+	require.Equal(t, 2, quarantined[0].ObjectID)
+	require.Equal(t, uint64(7), quarantined[0].Version)
+	require.Contains(t, quarantined[0].Reason, "checksum mismatch")
+
+	items, err = repo.ReadAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+}
+
+func TestRepo_ChecksumLegacyRows(t *testing.T) {
+	defer removeStorage(t)
+
+	repo := testRepo(t)
+	defer repo.Close()
+
+	// строки, записанные до появления контрольных сумм, не проверяются.
+	_, err := repo.db.Exec(`INSERT INTO storage (key, value) VALUES (1, '{}')`)
+	require.NoError(t, err)
+
+	item, err := repo.Read(1)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{}`), item.Body)
+}
+
+func TestRepo_Scrub(t *testing.T) {
+	defer removeStorage(t)
+
+	repo := testRepo(t)
+	defer repo.Close()
+
+	for id := 1; id <= 5; id++ {
+		require.NoError(t, repo.Insert(context.Background(), models.Item{ID: id, Body: []byte(fmt.Sprintf(`{"n":%d}`, id))}))
+	}
+
+	// объект 6 разделяет тело с объектом 4, объект 7 записан до появления общих тел.
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 6, Body: []byte(`{"n":4}`)}))
+	_, err := repo.db.Exec(`INSERT INTO storage (key, value, checksum) VALUES (7, '{"n":7}', ?)`, checksum([]byte(`{}`)))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.InsertTrash(context.Background(), models.TrashedItem{Item: models.Item{ID: 10, Body: []byte(`{"n":10}`)}}))
+
+	_, err = repo.db.Exec(`UPDATE blobs SET value = '[]' WHERE hash IN (SELECT blob FROM storage WHERE key IN (2, 4))`)
+	require.NoError(t, err)
+	_, err = repo.db.Exec(`UPDATE blobs SET codec = 'zstd' WHERE hash = (SELECT blob FROM trash WHERE key = 10)`)
+	require.NoError(t, err)
+
+	report, err := repo.Scrub(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, 7, report.Checked)
+	require.Zero(t, report.Unreadable)
+	require.Len(t, report.Corrupted, 5)
+
+	var got []string
+	for _, c := range report.Corrupted {
+		got = append(got, fmt.Sprintf("%s:%d", c.Source, c.ObjectID))
+	}
+
+	require.ElementsMatch(t, []string{"storage:7", "storage:2", "storage:4", "storage:6", "trash:10"}, got)
+	require.False(t, report.FinishedAt.Before(report.StartedAt))
+
+	var blobs int
+
+	require.NoError(t, repo.db.QueryRow("SELECT count(*) FROM blobs").Scan(&blobs))
+	require.Equal(t, 3, blobs)
+
+	items, err := repo.ReadAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	report, err = repo.Scrub(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Checked)
+	require.Empty(t, report.Corrupted)
+
+	quarantined, err := repo.Quarantined(10)
+	require.NoError(t, err)
+	require.Len(t, quarantined, 5)
+}
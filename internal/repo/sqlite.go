@@ -2,25 +2,151 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"st-test/internal/codec"
+	"st-test/internal/encryption"
 	"st-test/internal/models"
 	"st-test/internal/settings"
+	"st-test/internal/tracing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 
 	// Use CGO-free sqlite driver.
 	_ "modernc.org/sqlite"
 )
 
+// schema запросы создания таблиц, выполняемые при открытии хранилища.
+var schema = []string{
+	"CREATE TABLE IF NOT EXISTS storage (key INTEGER PRIMARY KEY, value BLOB NOT NULL)",
+	webhooksSchema,
+	deliveriesSchema,
+	deliveriesIndex,
+	trashSchema,
+	quarantineSchema,
+	blobsSchema,
+	largeObjectsSchema,
+	uploadsSchema,
+	chunksSchema,
+	chunksIndex,
+	stateSchema,
+}
+
+// stateSchema таблица служебных значений хранилища, например номера последнего изменения объектов.
+const stateSchema = `CREATE TABLE IF NOT EXISTS state (name TEXT PRIMARY KEY, value INTEGER NOT NULL)`
+
+// lastSeqState имя значения с номером последнего изменения объектов.
+const lastSeqState = "last_seq"
+
+// columns колонки, добавленные в существующие таблицы после их создания.
+var columns = []struct {
+	table, name, definition string
+}{
+	{table: "storage", name: "version", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "storage", name: "codec", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "trash", name: "codec", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "storage", name: "key_id", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "trash", name: "key_id", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "storage", name: "checksum", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "trash", name: "checksum", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "storage", name: "owner", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "trash", name: "owner", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "storage", name: "blob", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "trash", name: "blob", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "quarantine", name: "blob", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "storage", name: "content_type", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "trash", name: "content_type", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "large_objects", name: "content_type", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "uploads", name: "content_type", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "storage", name: "metadata", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "trash", name: "metadata", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "storage", name: "tags", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "trash", name: "tags", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "storage", name: "created_at", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "trash", name: "created_at", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "storage", name: "updated_at", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "trash", name: "updated_at", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "storage", name: "expires", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "trash", name: "expires", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "storage", name: "expires_at", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "trash", name: "expires_at", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "storage", name: "sliding", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "trash", name: "sliding", definition: "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// migrate создаёт недостающие таблицы и добавляет недостающие колонки в таблицы, созданные прежними версиями сервиса.
+func migrate(db *sql.DB) error {
+	for _, stmt := range schema {
+		_, err := db.Exec(stmt)
+		if err != nil {
+			return fmt.Errorf("creating schema: %w", err)
+		}
+	}
+
+	for _, c := range columns {
+		var n int
+
+		err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", c.table, c.name).Scan(&n)
+		if err != nil {
+			return fmt.Errorf("checking column '%s.%s': %w", c.table, c.name, err)
+		}
+
+		if n > 0 {
+			continue
+		}
+
+		_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.name, c.definition))
+		if err != nil {
+			return fmt.Errorf("adding column '%s.%s': %w", c.table, c.name, err)
+		}
+	}
+
+	return nil
+}
+
 // Repo хранит объект для работы с БД и предоставляет методы для удобной работы.
+// Тела объектов сжимаются и, если задан файл ключей, шифруются при записи; кодек, id ключа и контрольная сумма
+// хранятся в каждой строке. Строки, не прошедшие проверку при чтении, убираются в карантин.
 type Repo struct {
-	db *sql.DB
+	db    *sql.DB
+	codec *codec.Compressor
+	keys  *encryption.Keyring
+	// tracer трассировщик запросов к базе; без WithTracer спаны не записываются.
+	tracer trace.Tracer
 }
 
-// NewRepo создаёт хранилище sqlite с единственной ключ-значение таблицей и возвращает объект Repo.
-func NewRepo(set settings.LocalStorageSettings) (*Repo, error) {
+// Option описывает необязательную настройку хранилища.
+type Option func(r *Repo)
+
+// WithMetrics регистрирует метрики сжатия в reg.
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(r *Repo) {
+		reg.MustRegister(r.codec.Collectors()...)
+	}
+}
+
+// NewRepo создаёт хранилище sqlite с ключ-значение таблицей объектов и служебными таблицами и возвращает объект Repo.
+func NewRepo(set settings.LocalStorageSettings, opts ...Option) (*Repo, error) {
+	compressor, err := codec.New(codec.Codec(set.Compression.Codec), set.Compression.Threshold)
+	if err != nil {
+		return nil, fmt.Errorf("creating compressor: %w", err)
+	}
+
+	var keys *encryption.Keyring
+
+	if set.Encryption.Keyfile != "" {
+		keys, err = encryption.Load(set.Encryption.Keyfile)
+		if err != nil {
+			return nil, fmt.Errorf("loading encryption keys: %w", err)
+		}
+	}
+
 	var db *sql.DB
 
 	var errOpen error
@@ -31,22 +157,120 @@ func NewRepo(set settings.LocalStorageSettings) (*Repo, error) {
 		return nil, fmt.Errorf("opening sqlite repo: %w", errOpen)
 	}
 
-	_, err := db.Exec("CREATE TABLE IF NOT EXISTS storage (key INTEGER PRIMARY KEY, value BLOB NOT NULL)")
+	err = migrate(db)
 	if err != nil {
 		cerr := db.Close()
 		if cerr != nil {
 			slog.Warn(fmt.Sprintf("closing sqlite repo: %v; ignore", cerr.Error()))
 		}
 
-		return nil, fmt.Errorf("creating 'storage' table: %w", err)
+		return nil, err
+	}
+
+	r := &Repo{db: db, codec: compressor, keys: keys, tracer: noopTracer()}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// Insert вставляет объект в таблицу. Тело объекта сохраняется один раз для всех объектов с таким же телом.
+func (r *Repo) Insert(ctx context.Context, item models.Item) (err error) {
+	ctx, span := r.startSpan(ctx, "insert", "storage")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin insert of key %d: %w", item.ID, err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := r.insert(ctx, tx, item); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit insert of key %d: %w", item.ID, err)
+	}
+
+	return nil
+}
+
+// ReplaceAll заменяет все объекты таблицы объектами items и запоминает номер последнего изменения объектов seq
+// в одной транзакции: если запись не удалась, в таблице остаются прежние объекты и прежний номер.
+func (r *Repo) ReplaceAll(ctx context.Context, items []models.Item, seq uint64) (err error) {
+	ctx, span := r.startSpan(ctx, "replace", "storage")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin replace: %w", err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := clearTable(tx, "storage"); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := r.insert(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO state (name, value) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET value = excluded.value`, lastSeqState, seq)
+	if err != nil {
+		return fmt.Errorf("saving last seq: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit replace: %w", err)
 	}
 
-	return &Repo{db: db}, nil
+	return nil
 }
 
-// Insert вставляет объект в таблицу.
-func (r *Repo) Insert(item models.Item) error {
-	_, err := r.db.Exec("INSERT INTO storage (key, value) VALUES (?, ?)", item.ID, item.Body)
+// LastSeq возвращает номер последнего изменения объектов, сохранённый ReplaceAll, или 0, если он не сохранялся.
+func (r *Repo) LastSeq(ctx context.Context) (_ uint64, err error) {
+	ctx, span := r.startSpan(ctx, "select", "state")
+	defer func() { tracing.End(span, err) }()
+
+	var seq uint64
+
+	err = r.db.QueryRowContext(ctx, "SELECT value FROM state WHERE name = ?", lastSeqState).Scan(&seq)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("read last seq: %w", err)
+	}
+
+	return seq, nil
+}
+
+// insert вставляет объект в таблицу в транзакции tx.
+func (r *Repo) insert(ctx context.Context, tx *sql.Tx, item models.Item) error {
+	meta, tags, err := encodeMetadata(item)
+	if err != nil {
+		return fmt.Errorf("inserting key %d: %w", item.ID, err)
+	}
+
+	hash, err := r.acquireBlob(tx, item.Body)
+	if err != nil {
+		return fmt.Errorf("encoding key %d: %w", item.ID, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO storage (key, value, version, checksum, owner, blob, content_type, metadata, tags,
+		created_at, updated_at, expires, expires_at, sliding)
+		VALUES (?, x'', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		item.ID, item.Version, hash, item.Owner, hash, item.ContentType, meta, tags, nanos(item.CreatedAt), nanos(item.UpdatedAt),
+		item.Expires, nanos(item.ExpiresAt), item.Sliding)
 	if err != nil {
 		return fmt.Errorf("inserting key %d: %w", item.ID, err)
 	}
@@ -56,9 +280,18 @@ func (r *Repo) Insert(item models.Item) error {
 
 // Read возвращает объект по ключу.
 func (r *Repo) Read(key int) (models.Item, error) {
-	var body []byte
+	var (
+		item                 = models.Item{ID: key}
+		b                    storedBody
+		meta, tags           string
+		createdAt, updatedAt int64
+		expiresAt            int64
+	)
 
-	err := r.db.QueryRow("SELECT value FROM storage WHERE key = ? LIMIT 1", key).Scan(&body)
+	err := r.db.QueryRow("SELECT t.version, t.owner, t.content_type, t.metadata, t.tags, t.created_at, t.updated_at, "+
+		"t.expires, t.expires_at, t.sliding, "+bodyColumns+" FROM storage"+bodyJoin+" WHERE t.key = ? LIMIT 1", key).
+		Scan(&item.Version, &item.Owner, &item.ContentType, &meta, &tags, &createdAt, &updatedAt,
+			&item.Expires, &expiresAt, &item.Sliding, &b.data, &b.codec, &b.keyID, &b.sum, &b.blob)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.Item{}, models.ErrNotFound
@@ -67,16 +300,34 @@ func (r *Repo) Read(key int) (models.Item, error) {
 		return models.Item{}, fmt.Errorf("read from repo: %w", err)
 	}
 
-	return models.Item{
-		ID:      key,
-		Body:    body,
-		Expires: 0,
-	}, nil
+	item.Body, err = r.decode("storage", key, b)
+	if err != nil {
+		if errors.Is(err, models.ErrCorrupted) {
+			return models.Item{}, r.quarantineRows("storage", []corruptedRow{{key: key, err: err}})
+		}
+
+		return models.Item{}, fmt.Errorf("decoding key %d: %w", key, err)
+	}
+
+	if err := decodeMetadata(&item, meta, tags); err != nil {
+		return models.Item{}, fmt.Errorf("decoding key %d: %w", key, err)
+	}
+
+	item.CreatedAt, item.UpdatedAt = fromNanos(createdAt), fromNanos(updatedAt)
+	item.ExpiresAt = fromNanos(expiresAt)
+
+	return item, nil
 }
 
-// ReadAll возвращает все объекты из таблицы.
-func (r *Repo) ReadAll() ([]models.Item, error) {
-	rows, err := r.db.Query("SELECT key, value FROM storage")
+// ReadAll возвращает все объекты из таблицы. Повреждённые объекты убираются в карантин и не возвращаются;
+// в этом случае вместе с остальными объектами возвращается ошибка, оборачивающая models.ErrCorrupted.
+func (r *Repo) ReadAll(ctx context.Context) (_ []models.Item, err error) {
+	ctx, span := r.startSpan(ctx, "select", "storage")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT t.key, t.version, t.owner, t.content_type, t.metadata, t.tags, t.created_at, t.updated_at, "+
+			"t.expires, t.expires_at, t.sliding, "+bodyColumns+" FROM storage"+bodyJoin)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, models.ErrNotFound
@@ -85,25 +336,60 @@ func (r *Repo) ReadAll() ([]models.Item, error) {
 		return nil, fmt.Errorf("read from repo: %w", err)
 	}
 
-	items := make([]models.Item, 0)
+	defer rows.Close()
+
+	var (
+		items     = make([]models.Item, 0)
+		corrupted []corruptedRow
+	)
 
 	for rows.Next() {
-		i := models.Item{}
+		var (
+			i                    models.Item
+			b                    storedBody
+			meta, tags           string
+			createdAt, updatedAt int64
+			expiresAt            int64
+		)
 
-		err = rows.Scan(&i.ID, &i.Body)
+		err = rows.Scan(&i.ID, &i.Version, &i.Owner, &i.ContentType, &meta, &tags, &createdAt, &updatedAt,
+			&i.Expires, &expiresAt, &i.Sliding, &b.data, &b.codec, &b.keyID, &b.sum, &b.blob)
 		if err != nil {
 			return nil, fmt.Errorf("scan row from repo: %w", err)
 		}
 
+		i.Body, err = r.decode("storage", i.ID, b)
+		if err != nil {
+			if errors.Is(err, models.ErrCorrupted) {
+				corrupted = append(corrupted, corruptedRow{key: i.ID, err: err})
+
+				continue
+			}
+
+			return nil, fmt.Errorf("decoding key %d: %w", i.ID, err)
+		}
+
+		if err := decodeMetadata(&i, meta, tags); err != nil {
+			return nil, fmt.Errorf("decoding key %d: %w", i.ID, err)
+		}
+
+		i.CreatedAt, i.UpdatedAt = fromNanos(createdAt), fromNanos(updatedAt)
+		i.ExpiresAt = fromNanos(expiresAt)
 		items = append(items, i)
 	}
 
-	return items, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read from repo: %w", err)
+	}
+
+	rows.Close()
+
+	return items, r.quarantineRows("storage", corrupted)
 }
 
-// Delete удаляет объект из таблицы по ключу.
+// Delete удаляет объект из таблицы по ключу. Тело, на которое больше не ссылается ни один объект, удаляется.
 func (r *Repo) Delete(key int) error {
-	_, err := r.db.Exec("DELETE FROM storage WHERE key = ?", key)
+	err := r.deleteRows("storage", "key = ?", key)
 	if err != nil {
 		return fmt.Errorf("deleting key %d: %w", key, err)
 	}
@@ -112,8 +398,11 @@ func (r *Repo) Delete(key int) error {
 }
 
 // DeleteAll удаляет все объекты из таблицы.
-func (r *Repo) DeleteAll() error {
-	_, err := r.db.Exec("DELETE FROM storage")
+func (r *Repo) DeleteAll(ctx context.Context) (err error) {
+	_, span := r.startSpan(ctx, "delete", "storage")
+	defer func() { tracing.End(span, err) }()
+
+	err = r.deleteRows("storage", "1")
 	if err != nil {
 		return fmt.Errorf("deleting: %w", err)
 	}
@@ -121,6 +410,39 @@ func (r *Repo) DeleteAll() error {
 	return nil
 }
 
+// Size возвращает размер файла базы данных в байтах.
+func (r *Repo) Size(ctx context.Context) (_ int64, err error) {
+	ctx, span := r.startSpan(ctx, "pragma", "")
+	defer func() { tracing.End(span, err) }()
+
+	var size int64
+
+	err = r.db.QueryRowContext(ctx, "SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()").Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("getting database size: %w", err)
+	}
+
+	return size, nil
+}
+
+// nanos возвращает время t в наносекундах Unix для записи в колонку; нулевое время записывается как 0.
+func nanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano()
+}
+
+// fromNanos возвращает время, записанное nanos.
+func fromNanos(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, n)
+}
+
 // Close закрывает sqlite-базу.
 func (r *Repo) Close() {
 	err := r.db.Close()
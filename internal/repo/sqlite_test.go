@@ -1,10 +1,16 @@
 package repo
 
 import (
+	"context"
+	"database/sql"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"st-test/internal/models"
 	"st-test/internal/settings"
@@ -45,7 +51,7 @@ func TestRepo_Insert(t *testing.T) {
 	repo := testRepo(t)
 	defer removeStorage(t)
 
-	err := repo.Insert(models.Item{
+	err := repo.Insert(context.Background(), models.Item{
 		ID:   1,
 		Body: []byte(`{"some":"body"}`),
 	})
@@ -60,21 +66,53 @@ func TestRepo_ReadAll(t *testing.T) {
 	repo := testRepo(t)
 	defer removeStorage(t)
 
-	err := repo.Insert(models.Item{
+	err := repo.Insert(context.Background(), models.Item{
 		ID:   1,
 		Body: []byte(`{"some":"body"}`),
 	})
 	require.NoError(t, err)
 
-	err = repo.Insert(models.Item{
-		ID:   2,
-		Body: []byte(`{"some2":"body2"}`),
+	err = repo.Insert(context.Background(), models.Item{
+		ID:          2,
+		Body:        []byte("some text"),
+		Owner:       "client-a",
+		ContentType: "text/plain; charset=utf-8",
+		Metadata:    map[string]string{"author": "bob"},
+		Tags:        []string{"draft"},
+		CreatedAt:   time.Unix(100, 0),
+		UpdatedAt:   time.Unix(200, 0),
+		Expires:     time.Hour,
+		ExpiresAt:   time.Unix(300, 0),
+		Sliding:     true,
 	})
 	require.NoError(t, err)
 
-	gotItems, err := repo.ReadAll()
+	gotItems, err := repo.ReadAll(context.Background())
 	require.NoError(t, err)
 	require.Len(t, gotItems, 2)
+	require.Empty(t, gotItems[0].ContentType)
+	require.Equal(t, "client-a", gotItems[1].Owner)
+	require.Equal(t, "text/plain; charset=utf-8", gotItems[1].ContentType)
+	require.Equal(t, []byte("some text"), gotItems[1].Body)
+	require.Nil(t, gotItems[0].Metadata)
+	require.Nil(t, gotItems[0].Tags)
+	require.Equal(t, map[string]string{"author": "bob"}, gotItems[1].Metadata)
+	require.Equal(t, []string{"draft"}, gotItems[1].Tags)
+	// у объектов без времени создания и изменения оно остаётся нулевым.
+	require.True(t, gotItems[0].CreatedAt.IsZero())
+	require.True(t, gotItems[0].UpdatedAt.IsZero())
+	require.Equal(t, time.Unix(100, 0), gotItems[1].CreatedAt)
+	require.Equal(t, time.Unix(200, 0), gotItems[1].UpdatedAt)
+	require.True(t, gotItems[0].ExpiresAt.IsZero())
+	require.Equal(t, time.Hour, gotItems[1].Expires)
+	require.Equal(t, time.Unix(300, 0), gotItems[1].ExpiresAt)
+	require.True(t, gotItems[1].Sliding)
+
+	item, err := repo.Read(2)
+	require.NoError(t, err)
+	require.Equal(t, gotItems[1].Metadata, item.Metadata)
+	require.Equal(t, gotItems[1].Tags, item.Tags)
+	require.Equal(t, gotItems[1].ExpiresAt, item.ExpiresAt)
 
 	_ = repo.Delete(1)
 	_ = repo.Delete(2)
@@ -86,7 +124,7 @@ func TestRepo_Delete(t *testing.T) {
 	repo := testRepo(t)
 	defer removeStorage(t)
 
-	err := repo.Insert(models.Item{
+	err := repo.Insert(context.Background(), models.Item{
 		ID:   1,
 		Body: []byte(`{"some":"body"}`),
 	})
@@ -106,13 +144,13 @@ func TestRepo_DeleteAll(t *testing.T) {
 	repo := testRepo(t)
 	defer removeStorage(t)
 
-	err := repo.Insert(models.Item{
+	err := repo.Insert(context.Background(), models.Item{
 		ID:   1,
 		Body: []byte(`{"some":"body"}`),
 	})
 	require.NoError(t, err)
 
-	err = repo.DeleteAll()
+	err = repo.DeleteAll(context.Background())
 	require.NoError(t, err)
 
 	_, err = repo.Read(1)
@@ -121,3 +159,114 @@ func TestRepo_DeleteAll(t *testing.T) {
 
 	repo.Close()
 }
+
+func TestRepo_ReplaceAll(t *testing.T) {
+	repo := testRepo(t)
+	defer removeStorage(t)
+	defer repo.Close()
+
+	ctx := context.Background()
+	shared := []byte(`{"shared":true}`)
+
+	require.NoError(t, repo.Insert(ctx, models.Item{ID: 1, Body: shared}))
+	require.NoError(t, repo.Insert(ctx, models.Item{ID: 2, Body: []byte(`{"n":2}`)}))
+
+	seq, err := repo.LastSeq(ctx)
+	require.NoError(t, err)
+	require.Zero(t, seq)
+
+	require.NoError(t, repo.ReplaceAll(ctx, []models.Item{{ID: 2, Body: shared, Version: 3}}, 5))
+
+	items, err := repo.ReadAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, 2, items[0].ID)
+	require.Equal(t, uint64(3), items[0].Version)
+	require.Equal(t, map[string]int{checksum(shared): 1}, blobRefs(t, repo))
+
+	// замена пустым списком удаляет все объекты, но номер последнего изменения сохраняется.
+	require.NoError(t, repo.ReplaceAll(ctx, []models.Item{}, 7))
+
+	items, err = repo.ReadAll(ctx)
+	require.NoError(t, err)
+	require.Empty(t, items)
+	require.Empty(t, blobRefs(t, repo))
+
+	seq, err = repo.LastSeq(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), seq)
+
+	require.NoError(t, repo.ReplaceTrash(ctx, []models.TrashedItem{{Item: models.Item{ID: 4, Body: shared}}}))
+
+	trash, err := repo.ReadTrash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.Equal(t, shared, trash[0].Body)
+}
+
+func TestRepo_Size(t *testing.T) {
+	repo := testRepo(t)
+	defer removeStorage(t)
+
+	size, err := repo.Size(context.Background())
+	require.NoError(t, err)
+
+	info, err := os.Stat(storagePath)
+	require.NoError(t, err)
+	require.Equal(t, info.Size(), size)
+
+	repo.Close()
+}
+
+func TestRepo_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	repo, err := NewRepo(settings.LocalStorageSettings{Path: storagePath}, WithTracer(tp))
+	require.NoError(t, err)
+
+	defer removeStorage(t)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "snapshot")
+
+	require.NoError(t, repo.Insert(ctx, models.Item{ID: 1, Body: []byte(`{"some":"body"}`)}))
+
+	_, err = repo.ReadAll(ctx)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, "repo.insert", spans[0].Name())
+	require.Equal(t, "repo.select", spans[1].Name())
+	require.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	require.Contains(t, spans[1].Attributes(), attribute.String("db.sql.table", "storage"))
+
+	repo.Close()
+}
+
+func TestNewRepo_Migrate(t *testing.T) {
+	defer removeStorage(t)
+
+	db, err := sql.Open("sqlite", storagePath)
+	require.NoError(t, err)
+
+	_, err = db.Exec("CREATE TABLE storage (key INTEGER PRIMARY KEY, value BLOB NOT NULL)")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO storage (key, value) VALUES (1, '{}')")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	repo := testRepo(t)
+	defer repo.Close()
+
+	item, err := repo.Read(1)
+	require.NoError(t, err)
+	require.Zero(t, item.Version)
+
+	require.NoError(t, repo.Insert(context.Background(), models.Item{ID: 2, Body: []byte(`{}`), Version: 7}))
+
+	items, err := repo.ReadAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, uint64(7), items[1].Version)
+}
@@ -0,0 +1,34 @@
+package repo
+
+import (
+	"context"
+
+	"st-test/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName имя трассировщика репозитория.
+const tracerName = "st-test/internal/repo"
+
+// WithTracer включает трассировку запросов к базе: спан запроса становится дочерним спану из переданного контекста.
+func WithTracer(tp trace.TracerProvider) Option {
+	return func(r *Repo) {
+		r.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// startSpan начинает спан запроса op к таблице table.
+func (r *Repo) startSpan(ctx context.Context, op, table string) (context.Context, trace.Span) {
+	return r.tracer.Start(ctx, "repo."+op, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", "sqlite"),
+		attribute.String("db.operation", op),
+		attribute.String("db.sql.table", table),
+	))
+}
+
+// noopTracer трассировщик, который не записывает спаны.
+func noopTracer() trace.Tracer {
+	return tracing.Noop().Tracer(tracerName)
+}
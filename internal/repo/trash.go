@@ -0,0 +1,177 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/tracing"
+)
+
+const trashSchema = `CREATE TABLE IF NOT EXISTS trash (
+	key INTEGER PRIMARY KEY,
+	value BLOB NOT NULL,
+	version INTEGER NOT NULL DEFAULT 0,
+	deleted_at INTEGER NOT NULL,
+	purge_at INTEGER NOT NULL
+)`
+
+// InsertTrash вставляет удалённый объект в корзину. Тело объекта, как и в Insert, хранится один раз.
+func (r *Repo) InsertTrash(ctx context.Context, item models.TrashedItem) (err error) {
+	ctx, span := r.startSpan(ctx, "insert", "trash")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin insert of trash key %d: %w", item.ID, err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := r.insertTrash(ctx, tx, item); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit insert of trash key %d: %w", item.ID, err)
+	}
+
+	return nil
+}
+
+// ReplaceTrash заменяет все объекты корзины объектами items в одной транзакции, как ReplaceAll.
+func (r *Repo) ReplaceTrash(ctx context.Context, items []models.TrashedItem) (err error) {
+	ctx, span := r.startSpan(ctx, "replace", "trash")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin replace of trash: %w", err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := clearTable(tx, "trash"); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := r.insertTrash(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit replace of trash: %w", err)
+	}
+
+	return nil
+}
+
+// insertTrash вставляет удалённый объект в корзину в транзакции tx.
+func (r *Repo) insertTrash(ctx context.Context, tx *sql.Tx, item models.TrashedItem) error {
+	meta, tags, err := encodeMetadata(item.Item)
+	if err != nil {
+		return fmt.Errorf("inserting trash key %d: %w", item.ID, err)
+	}
+
+	hash, err := r.acquireBlob(tx, item.Body)
+	if err != nil {
+		return fmt.Errorf("encoding trash key %d: %w", item.ID, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO trash
+		(key, value, version, deleted_at, purge_at, checksum, owner, blob, content_type, metadata, tags, created_at, updated_at,
+		expires, expires_at, sliding)
+		VALUES (?, x'', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		item.ID, item.Version, item.DeletedAt.UnixNano(), item.PurgeAt.UnixNano(), hash, item.Owner, hash, item.ContentType,
+		meta, tags, nanos(item.CreatedAt), nanos(item.UpdatedAt), item.Expires, nanos(item.ExpiresAt), item.Sliding)
+	if err != nil {
+		return fmt.Errorf("inserting trash key %d: %w", item.ID, err)
+	}
+
+	return nil
+}
+
+// ReadTrash возвращает все объекты из корзины. Повреждённые объекты, как и в ReadAll, убираются в карантин.
+func (r *Repo) ReadTrash(ctx context.Context) (_ []models.TrashedItem, err error) {
+	ctx, span := r.startSpan(ctx, "select", "trash")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT t.key, t.version, t.deleted_at, t.purge_at, t.owner, t.content_type, t.metadata, t.tags, "+
+			"t.created_at, t.updated_at, t.expires, t.expires_at, t.sliding, "+bodyColumns+
+			" FROM trash"+bodyJoin)
+	if err != nil {
+		return nil, fmt.Errorf("read trash from repo: %w", err)
+	}
+
+	defer rows.Close()
+
+	var (
+		items     = make([]models.TrashedItem, 0)
+		corrupted []corruptedRow
+	)
+
+	for rows.Next() {
+		var (
+			i                    models.TrashedItem
+			deletedAt, purgeAt   int64
+			b                    storedBody
+			meta, tags           string
+			createdAt, updatedAt int64
+			expiresAt            int64
+		)
+
+		err = rows.Scan(&i.ID, &i.Version, &deletedAt, &purgeAt, &i.Owner, &i.ContentType, &meta, &tags, &createdAt, &updatedAt,
+			&i.Expires, &expiresAt, &i.Sliding, &b.data, &b.codec, &b.keyID, &b.sum, &b.blob)
+		if err != nil {
+			return nil, fmt.Errorf("scan trash row from repo: %w", err)
+		}
+
+		i.Body, err = r.decode("trash", i.ID, b)
+		if err != nil {
+			if errors.Is(err, models.ErrCorrupted) {
+				corrupted = append(corrupted, corruptedRow{key: i.ID, err: err})
+
+				continue
+			}
+
+			return nil, fmt.Errorf("decoding trash key %d: %w", i.ID, err)
+		}
+
+		if err := decodeMetadata(&i.Item, meta, tags); err != nil {
+			return nil, fmt.Errorf("decoding trash key %d: %w", i.ID, err)
+		}
+
+		i.CreatedAt, i.UpdatedAt = fromNanos(createdAt), fromNanos(updatedAt)
+		i.ExpiresAt = fromNanos(expiresAt)
+		i.DeletedAt = time.Unix(0, deletedAt)
+		i.PurgeAt = time.Unix(0, purgeAt)
+		items = append(items, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read trash from repo: %w", err)
+	}
+
+	rows.Close()
+
+	return items, r.quarantineRows("trash", corrupted)
+}
+
+// DeleteAllTrash очищает корзину.
+func (r *Repo) DeleteAllTrash(ctx context.Context) (err error) {
+	_, span := r.startSpan(ctx, "delete", "trash")
+	defer func() { tracing.End(span, err) }()
+
+	err = r.deleteRows("trash", "1")
+	if err != nil {
+		return fmt.Errorf("deleting trash: %w", err)
+	}
+
+	return nil
+}
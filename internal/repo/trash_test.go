@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/models"
+)
+
+func TestRepo_Trash(t *testing.T) {
+	repo := testRepo(t)
+	defer removeStorage(t)
+	defer repo.Close()
+
+	now := time.Now()
+
+	err := repo.InsertTrash(context.Background(), models.TrashedItem{
+		Item:      models.Item{ID: 1, Body: []byte(`{}`), Version: 3, ContentType: "application/ld+json"},
+		DeletedAt: now,
+		PurgeAt:   now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	items, err := repo.ReadTrash(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, uint64(3), items[0].Version)
+	require.Equal(t, "application/ld+json", items[0].ContentType)
+	require.True(t, items[0].PurgeAt.Equal(now.Add(time.Hour)))
+
+	require.NoError(t, repo.DeleteAllTrash(context.Background()))
+
+	items, err = repo.ReadTrash(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, items)
+}
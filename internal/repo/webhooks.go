@@ -0,0 +1,261 @@
+package repo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"st-test/internal/models"
+)
+
+const (
+	webhooksSchema = `CREATE TABLE IF NOT EXISTS webhooks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		url TEXT NOT NULL,
+		events TEXT NOT NULL,
+		keys TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	)`
+	deliveriesSchema = `CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		webhook_id INTEGER NOT NULL,
+		event TEXT NOT NULL,
+		object_id INTEGER NOT NULL,
+		payload BLOB NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at INTEGER NOT NULL,
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	)`
+	deliveriesIndex = "CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at)"
+
+	deliveryColumns = "id, webhook_id, event, object_id, payload, status, attempts, next_attempt_at, last_error, created_at, updated_at"
+)
+
+// InsertWebhook сохраняет подписку и возвращает её id.
+func (r *Repo) InsertWebhook(w models.Webhook) (int64, error) {
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return 0, fmt.Errorf("marshal webhook events: %w", err)
+	}
+
+	keys, err := json.Marshal(w.Keys)
+	if err != nil {
+		return 0, fmt.Errorf("marshal webhook keys: %w", err)
+	}
+
+	res, err := r.db.Exec("INSERT INTO webhooks (url, events, keys, secret, created_at) VALUES (?, ?, ?, ?, ?)",
+		w.URL, string(events), string(keys), w.Secret, w.CreatedAt.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("inserting webhook: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("inserting webhook: %w", err)
+	}
+
+	return id, nil
+}
+
+// Webhooks возвращает все подписки.
+func (r *Repo) Webhooks() ([]models.Webhook, error) {
+	rows, err := r.db.Query("SELECT id, url, events, keys, secret, created_at FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("read webhooks from repo: %w", err)
+	}
+
+	defer rows.Close()
+
+	webhooks := make([]models.Webhook, 0)
+
+	for rows.Next() {
+		var (
+			w              models.Webhook
+			events, keys   string
+			createdAtNanos int64
+		)
+
+		err = rows.Scan(&w.ID, &w.URL, &events, &keys, &w.Secret, &createdAtNanos)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook from repo: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(events), &w.Events); err != nil {
+			return nil, fmt.Errorf("unmarshal webhook %d events: %w", w.ID, err)
+		}
+
+		if err := json.Unmarshal([]byte(keys), &w.Keys); err != nil {
+			return nil, fmt.Errorf("unmarshal webhook %d keys: %w", w.ID, err)
+		}
+
+		w.CreatedAt = time.Unix(0, createdAtNanos)
+		webhooks = append(webhooks, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read webhooks from repo: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с её недоставленными событиями.
+func (r *Repo) DeleteWebhook(id int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("deleting webhook %d: %w", id, err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec("DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("deleting webhook %d: %w", id, err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return models.ErrNotFound
+	}
+
+	_, err = tx.Exec("DELETE FROM webhook_deliveries WHERE webhook_id = ?", id)
+	if err != nil {
+		return fmt.Errorf("deleting webhook %d deliveries: %w", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("deleting webhook %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// InsertDeliveries ставит события в очередь доставки одной транзакцией.
+func (r *Repo) InsertDeliveries(deliveries []models.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("inserting deliveries: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`INSERT INTO webhook_deliveries
+		(webhook_id, event, object_id, payload, status, attempts, next_attempt_at, last_error, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("inserting deliveries: %w", err)
+	}
+
+	defer stmt.Close()
+
+	for _, d := range deliveries {
+		_, err := stmt.Exec(d.WebhookID, string(d.Event), d.ObjectID, d.Payload, string(d.Status), d.Attempts,
+			d.NextAttemptAt.UnixNano(), d.LastError, d.CreatedAt.UnixNano(), d.UpdatedAt.UnixNano())
+		if err != nil {
+			return fmt.Errorf("inserting delivery for webhook %d: %w", d.WebhookID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("inserting deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// DueDeliveries возвращает не более limit ожидающих событий, время очередной попытки доставки которых наступило к now.
+func (r *Repo) DueDeliveries(now time.Time, limit int) ([]models.Delivery, error) {
+	rows, err := r.db.Query("SELECT "+deliveryColumns+" FROM webhook_deliveries"+
+		" WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?",
+		string(models.DeliveryPending), now.UnixNano(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("read due deliveries from repo: %w", err)
+	}
+
+	return scanDeliveries(rows)
+}
+
+// Deliveries возвращает не более limit последних событий в очереди доставки. Пустой status означает все события.
+func (r *Repo) Deliveries(status models.DeliveryStatus, limit int) ([]models.Delivery, error) {
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries"
+	args := make([]any, 0, 2)
+
+	if status != "" {
+		query += " WHERE status = ?"
+
+		args = append(args, string(status))
+	}
+
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("read deliveries from repo: %w", err)
+	}
+
+	return scanDeliveries(rows)
+}
+
+// UpdateDelivery сохраняет результат попытки доставки.
+func (r *Repo) UpdateDelivery(d models.Delivery) error {
+	_, err := r.db.Exec(`UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, updated_at = ? WHERE id = ?`,
+		string(d.Status), d.Attempts, d.NextAttemptAt.UnixNano(), d.LastError, d.UpdatedAt.UnixNano(), d.ID)
+	if err != nil {
+		return fmt.Errorf("updating delivery %d: %w", d.ID, err)
+	}
+
+	return nil
+}
+
+// DeleteDelivery удаляет событие из очереди доставки.
+func (r *Repo) DeleteDelivery(id int64) error {
+	_, err := r.db.Exec("DELETE FROM webhook_deliveries WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("deleting delivery %d: %w", id, err)
+	}
+
+	return nil
+}
+
+func scanDeliveries(rows *sql.Rows) ([]models.Delivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]models.Delivery, 0)
+
+	for rows.Next() {
+		var (
+			d                             models.Delivery
+			event, status                 string
+			nextAttempt, created, updated int64
+		)
+
+		err := rows.Scan(&d.ID, &d.WebhookID, &event, &d.ObjectID, &d.Payload, &status, &d.Attempts,
+			&nextAttempt, &d.LastError, &created, &updated)
+		if err != nil {
+			return nil, fmt.Errorf("scan delivery from repo: %w", err)
+		}
+
+		d.Event = models.ChangeType(event)
+		d.Status = models.DeliveryStatus(status)
+		d.NextAttemptAt = time.Unix(0, nextAttempt)
+		d.CreatedAt = time.Unix(0, created)
+		d.UpdatedAt = time.Unix(0, updated)
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read deliveries from repo: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/models"
+)
+
+func TestRepo_Webhooks(t *testing.T) {
+	repo := testRepo(t)
+	defer removeStorage(t)
+	defer repo.Close()
+
+	id, err := repo.InsertWebhook(models.Webhook{
+		URL:       "http://example.com/hook",
+		Events:    []models.ChangeType{models.ChangeCreated},
+		Keys:      []string{"1-10"},
+		Secret:    "secret",
+		CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	webhooks, err := repo.Webhooks()
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	require.Equal(t, id, webhooks[0].ID)
+	require.Equal(t, []models.ChangeType{models.ChangeCreated}, webhooks[0].Events)
+	require.Equal(t, []string{"1-10"}, webhooks[0].Keys)
+
+	now := time.Now()
+
+	err = repo.InsertDeliveries([]models.Delivery{
+		{WebhookID: id, Event: models.ChangeCreated, ObjectID: 1, Payload: []byte(`{}`),
+			Status: models.DeliveryPending, NextAttemptAt: now, CreatedAt: now, UpdatedAt: now},
+		{WebhookID: id, Event: models.ChangeCreated, ObjectID: 2, Payload: []byte(`{}`),
+			Status: models.DeliveryPending, NextAttemptAt: now.Add(time.Hour), CreatedAt: now, UpdatedAt: now},
+	})
+	require.NoError(t, err)
+
+	due, err := repo.DueDeliveries(now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.Equal(t, 1, due[0].ObjectID)
+
+	d := due[0]
+	d.Status = models.DeliveryFailed
+	d.Attempts = 3
+	d.LastError = "boom"
+	require.NoError(t, repo.UpdateDelivery(d))
+
+	failed, err := repo.Deliveries(models.DeliveryFailed, 10)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	require.Equal(t, "boom", failed[0].LastError)
+	require.Equal(t, 3, failed[0].Attempts)
+
+	require.NoError(t, repo.DeleteDelivery(d.ID))
+
+	all, err := repo.Deliveries("", 10)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+
+	require.NoError(t, repo.DeleteWebhook(id))
+	require.ErrorIs(t, repo.DeleteWebhook(id), models.ErrNotFound)
+
+	all, err = repo.Deliveries("", 10)
+	require.NoError(t, err)
+	require.Empty(t, all)
+}
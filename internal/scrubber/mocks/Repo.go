@@ -0,0 +1,152 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "st-test/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+type Repo_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Repo) EXPECT() *Repo_Expecter {
+	return &Repo_Expecter{mock: &_m.Mock}
+}
+
+// Quarantined provides a mock function with given fields: limit
+func (_m *Repo) Quarantined(limit int) ([]models.QuarantinedItem, error) {
+	ret := _m.Called(limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Quarantined")
+	}
+
+	var r0 []models.QuarantinedItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) ([]models.QuarantinedItem, error)); ok {
+		return rf(limit)
+	}
+	if rf, ok := ret.Get(0).(func(int) []models.QuarantinedItem); ok {
+		r0 = rf(limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.QuarantinedItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_Quarantined_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Quarantined'
+type Repo_Quarantined_Call struct {
+	*mock.Call
+}
+
+// Quarantined is a helper method to define mock.On call
+//   - limit int
+func (_e *Repo_Expecter) Quarantined(limit interface{}) *Repo_Quarantined_Call {
+	return &Repo_Quarantined_Call{Call: _e.mock.On("Quarantined", limit)}
+}
+
+func (_c *Repo_Quarantined_Call) Run(run func(limit int)) *Repo_Quarantined_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int))
+	})
+	return _c
+}
+
+func (_c *Repo_Quarantined_Call) Return(_a0 []models.QuarantinedItem, _a1 error) *Repo_Quarantined_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_Quarantined_Call) RunAndReturn(run func(int) ([]models.QuarantinedItem, error)) *Repo_Quarantined_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Scrub provides a mock function with given fields: ctx, batch
+func (_m *Repo) Scrub(ctx context.Context, batch int) (models.ScrubReport, error) {
+	ret := _m.Called(ctx, batch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Scrub")
+	}
+
+	var r0 models.ScrubReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.ScrubReport, error)); ok {
+		return rf(ctx, batch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.ScrubReport); ok {
+		r0 = rf(ctx, batch)
+	} else {
+		r0 = ret.Get(0).(models.ScrubReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, batch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_Scrub_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Scrub'
+type Repo_Scrub_Call struct {
+	*mock.Call
+}
+
+// Scrub is a helper method to define mock.On call
+//   - ctx context.Context
+//   - batch int
+func (_e *Repo_Expecter) Scrub(ctx interface{}, batch interface{}) *Repo_Scrub_Call {
+	return &Repo_Scrub_Call{Call: _e.mock.On("Scrub", ctx, batch)}
+}
+
+func (_c *Repo_Scrub_Call) Run(run func(ctx context.Context, batch int)) *Repo_Scrub_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repo_Scrub_Call) Return(_a0 models.ScrubReport, _a1 error) *Repo_Scrub_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_Scrub_Call) RunAndReturn(run func(context.Context, int) (models.ScrubReport, error)) *Repo_Scrub_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
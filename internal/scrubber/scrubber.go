@@ -0,0 +1,155 @@
+// Package scrubber описывает фоновую проверку сохранённых объектов по контрольным суммам.
+package scrubber
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/settings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultInterval = 24 * time.Hour
+	defaultBatch    = 100
+)
+
+// ErrNoReport возвращается когда проверка ещё ни разу не выполнялась.
+var ErrNoReport = errors.New("scrub has not run yet")
+
+// Repo описывает методы хранилища для проверки объектов и просмотра карантина.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Repo --with-expecter=true
+type Repo interface {
+	Scrub(ctx context.Context, batch int) (models.ScrubReport, error)
+	Quarantined(limit int) ([]models.QuarantinedItem, error)
+}
+
+// Scrubber периодически проверяет все сохранённые объекты и хранит отчёт о последней проверке.
+// Одновременно выполняется не более одной проверки.
+type Scrubber struct {
+	log  *zap.Logger
+	repo Repo
+	set  settings.ScrubSettings
+
+	// run сериализует проверки.
+	run sync.Mutex
+
+	m    sync.RWMutex
+	last *models.ScrubReport
+
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewScrubber конструктор для Scrubber.
+func NewScrubber(log *zap.Logger, repo Repo, set settings.ScrubSettings) *Scrubber {
+	if set.Interval <= 0 {
+		set.Interval = defaultInterval
+	}
+
+	if set.Batch <= 0 {
+		set.Batch = defaultBatch
+	}
+
+	return &Scrubber{
+		log:  log.Named("scrubber"),
+		repo: repo,
+		set:  set,
+		done: make(chan struct{}),
+	}
+}
+
+// Run запускает периодическую проверку. Первая проверка выполняется через интервал после запуска.
+func (s *Scrubber) Run() {
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		s.loop()
+	}()
+}
+
+// Stop останавливает периодическую проверку и дожидается завершения текущей.
+func (s *Scrubber) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+
+	s.wg.Wait()
+}
+
+func (s *Scrubber) loop() {
+	ticker := time.NewTicker(s.set.Interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-s.done
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			_, _ = s.Scrub(ctx)
+		}
+	}
+}
+
+// Scrub проверяет все сохранённые объекты, убирая повреждённые в карантин, и запоминает отчёт.
+// Если проверка уже выполняется, дожидается её завершения и запускает новую.
+func (s *Scrubber) Scrub(ctx context.Context) (models.ScrubReport, error) {
+	s.run.Lock()
+	defer s.run.Unlock()
+
+	report, err := s.repo.Scrub(ctx, s.set.Batch)
+	if err != nil {
+		s.log.Error("scrub finished with errors", zap.Int("checked", report.Checked),
+			zap.Int("corrupted", len(report.Corrupted)), zap.Int("unreadable", report.Unreadable), zap.Error(err))
+	} else {
+		s.log.Info("scrub finished", zap.Int("checked", report.Checked),
+			zap.Int("corrupted", len(report.Corrupted)), zap.Duration("duration", report.FinishedAt.Sub(report.StartedAt)))
+	}
+
+	for _, item := range report.Corrupted {
+		s.log.Warn("corrupted object quarantined", zap.String("source", item.Source),
+			zap.Int("id", item.ObjectID), zap.String("reason", item.Reason))
+	}
+
+	// прерванная проверка не покрывает все объекты, поэтому её отчёт не запоминается.
+	if ctx.Err() == nil {
+		s.m.Lock()
+		s.last = &report
+		s.m.Unlock()
+	}
+
+	return report, err //nolint:wrapcheck
+}
+
+// Last возвращает отчёт о последней завершённой проверке или ErrNoReport.
+func (s *Scrubber) Last() (models.ScrubReport, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	if s.last == nil {
+		return models.ScrubReport{}, ErrNoReport
+	}
+
+	return *s.last, nil
+}
+
+// Quarantined возвращает не более limit последних объектов, убранных в карантин.
+func (s *Scrubber) Quarantined(limit int) ([]models.QuarantinedItem, error) {
+	return s.repo.Quarantined(limit) //nolint:wrapcheck
+}
@@ -0,0 +1,82 @@
+package scrubber
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/scrubber/mocks"
+	"st-test/internal/settings"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestScrubber_Scrub(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewRepo(t)
+	s := NewScrubber(zap.NewNop(), repo, settings.ScrubSettings{Batch: 10})
+
+	_, err := s.Last()
+	require.ErrorIs(t, err, ErrNoReport)
+
+	report := models.ScrubReport{
+		Checked:   3,
+		Corrupted: []models.QuarantinedItem{{ID: 1, Source: "storage", ObjectID: 2, Reason: "checksum mismatch"}},
+	}
+
+	repo.EXPECT().Scrub(mock.Anything, 10).Once().Return(report, nil)
+
+	got, err := s.Scrub(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, report, got)
+
+	last, err := s.Last()
+	require.NoError(t, err)
+	require.Equal(t, report, last)
+
+	// прерванная проверка не заменяет отчёт о последней завершённой.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	repo.EXPECT().Scrub(mock.Anything, 10).Once().Return(models.ScrubReport{Checked: 1}, context.Canceled)
+
+	_, err = s.Scrub(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	last, err = s.Last()
+	require.NoError(t, err)
+	require.Equal(t, report, last)
+}
+
+func TestScrubber_Run(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewRepo(t)
+	s := NewScrubber(zap.NewNop(), repo, settings.ScrubSettings{Interval: 10 * time.Millisecond})
+
+	called := make(chan struct{}, 1)
+
+	repo.EXPECT().Scrub(mock.Anything, defaultBatch).RunAndReturn(func(context.Context, int) (models.ScrubReport, error) {
+		select {
+		case called <- struct{}{}:
+		default:
+		}
+
+		return models.ScrubReport{}, errors.New("failed")
+	})
+
+	s.Run()
+
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("scrub was not called")
+	}
+
+	s.Stop()
+}
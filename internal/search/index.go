@@ -0,0 +1,267 @@
+// Package search реализует полнотекстовый поиск по строковым значениям JSON-объектов на базе инвертированного индекса в памяти.
+//
+// Запрос состоит из слов, разделённых пробелами. Слово в двойных кавычках ищется как фраза,
+// слово со звёздочкой на конце — как префикс. Объект подходит под запрос, если в нём найдены все части запроса.
+// Результаты ранжируются по BM25.
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// bm25K1 и bm25B параметры ранжирования BM25.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+var (
+	// ErrEmptyQuery возвращается когда запрос не содержит ни одного слова.
+	ErrEmptyQuery = errors.New("empty search query")
+	// ErrInvalidQuery возвращается когда запрос не удалось разобрать.
+	ErrInvalidQuery = errors.New("invalid search query")
+)
+
+// Hit найденный объект и его оценка релевантности.
+type Hit struct {
+	ID    int
+	Score float64
+}
+
+// Result страница результатов поиска.
+type Result struct {
+	// Hits найденные объекты, отсортированные по убыванию релевантности.
+	Hits []Hit
+	// Total общее количество найденных объектов.
+	Total int
+}
+
+// Index инвертированный индекс: для каждого слова хранит позиции его вхождений в каждом объекте.
+type Index struct {
+	m        sync.RWMutex
+	postings map[string]map[int][]int
+	docs     map[int]document
+	totalLen int
+}
+
+// document проиндексированные слова объекта.
+type document struct {
+	terms  []string
+	length int
+}
+
+// NewIndex конструктор для Index.
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int][]int),
+		docs:     make(map[int]document),
+	}
+}
+
+// Put индексирует строковые значения JSON-объекта. Предыдущее содержимое объекта с тем же id удаляется из индекса.
+func (idx *Index) Put(id int, body []byte) error {
+	var doc any
+
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("decode document %d: %w", id, err)
+	}
+
+	tokens := make([]string, 0)
+	tokens = collectTokens(doc, tokens)
+
+	idx.m.Lock()
+	defer idx.m.Unlock()
+
+	idx.remove(id)
+
+	positions := make(map[string][]int)
+	for pos, tok := range tokens {
+		if tok == "" {
+			continue
+		}
+
+		positions[tok] = append(positions[tok], pos)
+	}
+
+	terms := make([]string, 0, len(positions))
+	length := 0
+
+	for term, pos := range positions {
+		p, ok := idx.postings[term]
+		if !ok {
+			p = make(map[int][]int)
+			idx.postings[term] = p
+		}
+
+		p[id] = pos
+		terms = append(terms, term)
+		length += len(pos)
+	}
+
+	idx.docs[id] = document{terms: terms, length: length}
+	idx.totalLen += length
+
+	return nil
+}
+
+// Delete удаляет объект из индекса.
+func (idx *Index) Delete(id int) {
+	idx.m.Lock()
+	defer idx.m.Unlock()
+
+	idx.remove(id)
+}
+
+// Len возвращает количество проиндексированных объектов.
+func (idx *Index) Len() int {
+	idx.m.RLock()
+	defer idx.m.RUnlock()
+
+	return len(idx.docs)
+}
+
+func (idx *Index) remove(id int) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		p := idx.postings[term]
+		delete(p, id)
+
+		if len(p) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+
+	idx.totalLen -= doc.length
+	delete(idx.docs, id)
+}
+
+// Search ищет объекты по запросу и возвращает страницу результатов начиная с offset длиной не более limit.
+// Если limit <= 0, возвращаются все результаты начиная с offset.
+func (idx *Index) Search(query string, offset, limit int) (Result, error) {
+	clauses, err := parseQuery(query)
+	if err != nil {
+		return Result{}, err
+	}
+
+	idx.m.RLock()
+	defer idx.m.RUnlock()
+
+	var scores map[int]float64
+
+	for i, c := range clauses {
+		freqs := c.frequencies(idx)
+		idf := idx.idf(len(freqs))
+
+		// объект должен удовлетворять всем частям запроса, поэтому оставляем только найденные на всех шагах.
+		next := make(map[int]float64, len(freqs))
+
+		for id, tf := range freqs {
+			prev, ok := scores[id]
+			if i > 0 && !ok {
+				continue
+			}
+
+			next[id] = prev + idf*idx.saturate(tf, idx.docs[id].length)
+		}
+
+		scores = next
+		if len(scores) == 0 {
+			break
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+
+		return hits[i].ID < hits[j].ID
+	})
+
+	res := Result{Total: len(hits)}
+
+	offset = max(offset, 0)
+	if offset >= len(hits) {
+		res.Hits = make([]Hit, 0)
+
+		return res, nil
+	}
+
+	hits = hits[offset:]
+	if limit > 0 && limit < len(hits) {
+		hits = hits[:limit]
+	}
+
+	res.Hits = hits
+
+	return res, nil
+}
+
+// idf обратная частота документа для части запроса, найденной в df объектах.
+func (idx *Index) idf(df int) float64 {
+	n := float64(len(idx.docs))
+
+	return math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+// saturate нормирует частоту вхождений с учётом длины объекта.
+func (idx *Index) saturate(tf, length int) float64 {
+	avg := 1.0
+	if len(idx.docs) > 0 && idx.totalLen > 0 {
+		avg = float64(idx.totalLen) / float64(len(idx.docs))
+	}
+
+	f := float64(tf)
+
+	return f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*float64(length)/avg))
+}
+
+// collectTokens добавляет в tokens слова из всех строковых значений документа.
+// Между значениями вставляется пустой токен, чтобы фраза не находилась на стыке двух значений.
+func collectTokens(v any, tokens []string) []string {
+	switch t := v.(type) {
+	case string:
+		tokens = append(tokens, tokenize(t)...)
+		tokens = append(tokens, "")
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			tokens = collectTokens(t[k], tokens)
+		}
+	case []any:
+		for _, item := range t {
+			tokens = collectTokens(item, tokens)
+		}
+	}
+
+	return tokens
+}
+
+// tokenize разбивает текст на слова в нижнем регистре.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
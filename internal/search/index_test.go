@@ -0,0 +1,138 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIndex(t *testing.T) *Index {
+	t.Helper()
+
+	idx := NewIndex()
+
+	docs := map[int]string{
+		1: `{"title":"Quick brown fox","tags":["animal","forest"]}`,
+		2: `{"title":"Lazy dog","text":"the quick dog sleeps, the dog dreams"}`,
+		3: `{"title":"Brown bear","meta":{"note":"quick fix"}}`,
+		4: `{"count":10,"flag":true}`,
+	}
+
+	for id, body := range docs {
+		require.NoError(t, idx.Put(id, []byte(body)))
+	}
+
+	return idx
+}
+
+func ids(res Result) []int {
+	hits := make([]int, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		hits = append(hits, h.ID)
+	}
+
+	return hits
+}
+
+func TestIndex_Search(t *testing.T) {
+	t.Parallel()
+
+	idx := testIndex(t)
+	require.Equal(t, 4, idx.Len())
+
+	cases := []struct {
+		name    string
+		give    string
+		want    []int
+		wantErr error
+	}{
+		{name: "single term", give: "brown", want: []int{1, 3}},
+		{name: "case insensitive", give: "QUICK", want: []int{1, 2, 3}},
+		{name: "all terms required", give: "quick brown", want: []int{1, 3}},
+		{name: "phrase", give: `"brown fox"`, want: []int{1}},
+		{name: "phrase does not cross values", give: `"fox animal"`, want: []int{}},
+		{name: "prefix", give: "dre*", want: []int{2}},
+		{name: "nested values", give: "fix", want: []int{3}},
+		{name: "array values", give: "forest", want: []int{1}},
+		{name: "not found", give: "cat", want: []int{}},
+		{name: "empty", give: "  ", wantErr: ErrEmptyQuery},
+		{name: "unterminated phrase", give: `"brown fox`, wantErr: ErrInvalidQuery},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := idx.Search(tc.give, 0, 0)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.want, ids(res))
+			assert.Equal(t, len(tc.want), res.Total)
+		})
+	}
+}
+
+func TestIndex_Ranking(t *testing.T) {
+	t.Parallel()
+
+	idx := testIndex(t)
+
+	res, err := idx.Search("dog", 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []int{2}, ids(res))
+
+	require.NoError(t, idx.Put(5, []byte(`{"text":"a dog and a lot of other unrelated words here"}`)))
+
+	res, err = idx.Search("dog", 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 5}, ids(res))
+	require.Greater(t, res.Hits[0].Score, res.Hits[1].Score)
+}
+
+func TestIndex_Pagination(t *testing.T) {
+	t.Parallel()
+
+	idx := testIndex(t)
+
+	res, err := idx.Search("quick", 1, 1)
+	require.NoError(t, err)
+	require.Len(t, res.Hits, 1)
+	require.Equal(t, 3, res.Total)
+
+	res, err = idx.Search("quick", 10, 1)
+	require.NoError(t, err)
+	require.Empty(t, res.Hits)
+	require.Equal(t, 3, res.Total)
+}
+
+func TestIndex_PutDelete(t *testing.T) {
+	t.Parallel()
+
+	idx := testIndex(t)
+
+	require.NoError(t, idx.Put(1, []byte(`{"title":"Slow green turtle"}`)))
+
+	res, err := idx.Search("fox", 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, res.Hits)
+
+	res, err = idx.Search("turtle", 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, ids(res))
+
+	idx.Delete(1)
+	idx.Delete(100)
+
+	res, err = idx.Search("turtle", 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, res.Hits)
+	require.Equal(t, 3, idx.Len())
+
+	require.Error(t, idx.Put(6, []byte(`not json`)))
+}
@@ -0,0 +1,141 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+)
+
+type clauseKind int
+
+const (
+	clauseTerm clauseKind = iota
+	clausePrefix
+	clausePhrase
+)
+
+// clause часть поискового запроса: слово, префикс или фраза.
+type clause struct {
+	kind  clauseKind
+	terms []string
+}
+
+// parseQuery разбирает поисковый запрос на части.
+func parseQuery(query string) ([]clause, error) {
+	clauses := make([]clause, 0)
+
+	for rest := strings.TrimSpace(query); rest != ""; rest = strings.TrimSpace(rest) {
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated phrase", ErrInvalidQuery)
+			}
+
+			if terms := tokenize(rest[1 : end+1]); len(terms) > 0 {
+				clauses = append(clauses, clause{kind: clausePhrase, terms: terms})
+			}
+
+			rest = rest[end+2:]
+
+			continue
+		}
+
+		word := rest
+		if i := strings.IndexAny(rest, " \t\""); i >= 0 {
+			word = rest[:i]
+		}
+
+		rest = rest[len(word):]
+
+		prefix := strings.HasSuffix(word, "*")
+
+		terms := tokenize(word)
+		if len(terms) == 0 {
+			continue
+		}
+
+		// слово со знаками препинания внутри (например, "e-mail") ищется как фраза.
+		switch {
+		case prefix && len(terms) == 1:
+			clauses = append(clauses, clause{kind: clausePrefix, terms: terms})
+		case len(terms) > 1:
+			clauses = append(clauses, clause{kind: clausePhrase, terms: terms})
+		default:
+			clauses = append(clauses, clause{kind: clauseTerm, terms: terms})
+		}
+	}
+
+	if len(clauses) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	return clauses, nil
+}
+
+// frequencies возвращает количество вхождений части запроса в каждом объекте, где она найдена.
+func (c clause) frequencies(idx *Index) map[int]int {
+	freqs := make(map[int]int)
+
+	switch c.kind {
+	case clauseTerm:
+		for id, pos := range idx.postings[c.terms[0]] {
+			freqs[id] = len(pos)
+		}
+	case clausePrefix:
+		for term, p := range idx.postings {
+			if !strings.HasPrefix(term, c.terms[0]) {
+				continue
+			}
+
+			for id, pos := range p {
+				freqs[id] += len(pos)
+			}
+		}
+	case clausePhrase:
+		for id, first := range idx.postings[c.terms[0]] {
+			if n := c.phraseCount(idx, id, first); n > 0 {
+				freqs[id] = n
+			}
+		}
+	}
+
+	return freqs
+}
+
+// phraseCount считает вхождения фразы в объект, начиная с позиций первого слова.
+func (c clause) phraseCount(idx *Index, id int, first []int) int {
+	rest := make([]map[int]struct{}, 0, len(c.terms)-1)
+
+	for _, term := range c.terms[1:] {
+		pos, ok := idx.postings[term][id]
+		if !ok {
+			return 0
+		}
+
+		set := make(map[int]struct{}, len(pos))
+		for _, p := range pos {
+			set[p] = struct{}{}
+		}
+
+		rest = append(rest, set)
+	}
+
+	count := 0
+
+	for _, start := range first {
+		found := true
+
+		for i, set := range rest {
+			if _, ok := set[start+i+1]; !ok {
+				found = false
+
+				break
+			}
+		}
+
+		if found {
+			count++
+		}
+	}
+
+	return count
+}
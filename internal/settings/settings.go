@@ -3,6 +3,7 @@ package settings
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/file"
@@ -15,20 +16,91 @@ const (
 
 // Settings описывает структуру для хранения настроек сервера.
 type Settings struct {
-	API     APISettings          `koanf:"api"`
-	Storage LocalStorageSettings `koanf:"localstorage"`
-	Log     LogSettings          `koanf:"log"`
+	API      APISettings          `koanf:"api"`
+	Storage  LocalStorageSettings `koanf:"localstorage"`
+	Log      LogSettings          `koanf:"log"`
+	Search   SearchSettings       `koanf:"search"`
+	Webhooks WebhookSettings      `koanf:"webhooks"`
+	Trash    TrashSettings        `koanf:"trash"`
+	Expired  ExpiredSettings      `koanf:"expired"`
+	Scrub    ScrubSettings        `koanf:"scrub"`
+	Quotas   QuotaSettings        `koanf:"quotas"`
+	Large    LargeObjectSettings  `koanf:"large_objects"`
+	Tracing  TracingSettings      `koanf:"tracing"`
+	Replica  ReplicaSettings      `koanf:"replication"`
+	Cluster  ClusterSettings      `koanf:"cluster"`
 }
 
 // APISettings подструктура для хранения настроек API.
+// AllowedOrigins — источники (Origin), со страниц которых разрешено WebSocket-подключение, кроме источника
+// с хостом самого запроса.
 type APISettings struct {
-	Address string `koanf:"address"`
-	Port    int    `koanf:"port"`
+	Address        string         `koanf:"address"`
+	Port           int            `koanf:"port"`
+	Limits         LimitsSettings `koanf:"limits"`
+	AllowedOrigins []string       `koanf:"allowed_origins"`
 }
 
-// LocalStorageSettings подструктура для хранения пути к локальному хранилищу.
+// LimitsSettings подструктура для хранения ограничений на тело сохраняемого объекта.
+// MaxBodySize — размер тела в байтах, MaxJSONDepth — глубина вложенности, MaxJSONKeys — общее количество ключей.
+// ContentTypes — допустимые типы содержимого, пустой список допускает любой тип. JSONContentTypes — типы содержимого,
+// тело которых проверяется как json. Элемент списка типов — тип целиком (image/png), все подтипы (image/*)
+// или суффикс (+json). Нулевые значения заменяются значениями по умолчанию.
+type LimitsSettings struct {
+	MaxBodySize      int64    `koanf:"max_body_size"`
+	MaxJSONDepth     int      `koanf:"max_json_depth"`
+	MaxJSONKeys      int      `koanf:"max_json_keys"`
+	ContentTypes     []string `koanf:"content_types"`
+	JSONContentTypes []string `koanf:"json_content_types"`
+}
+
+// QuotaSettings подструктура для хранения квот клиентов на количество и суммарный размер объектов.
+// Квота верхнего уровня общая для клиентов, которых нет в Clients, и ограничивает их суммарный объём.
+// Нулевое значение означает отсутствие ограничения. Квот на бакеты нет: объекты хранилища не делятся на бакеты.
+type QuotaSettings struct {
+	MaxObjects int                    `koanf:"max_objects"`
+	MaxBytes   int64                  `koanf:"max_bytes"`
+	Clients    map[string]ClientQuota `koanf:"clients"`
+}
+
+// ClientQuota квота отдельного клиента.
+type ClientQuota struct {
+	MaxObjects int   `koanf:"max_objects"`
+	MaxBytes   int64 `koanf:"max_bytes"`
+}
+
+// LocalStorageSettings подструктура для хранения пути к локальному хранилищу и настроек сжатия.
 type LocalStorageSettings struct {
-	Path string `koanf:"path"`
+	Path        string              `koanf:"path"`
+	Compression CompressionSettings `koanf:"compression"`
+	Encryption  EncryptionSettings  `koanf:"encryption"`
+}
+
+// EncryptionSettings подструктура для хранения настроек шифрования тел объектов на диске.
+// Keyfile — путь к файлу ключей, пустое значение отключает шифрование. С шифрованием контрольные суммы тел
+// вычисляются как MAC на ключах из файла ключей.
+type EncryptionSettings struct {
+	Keyfile string `koanf:"keyfile"`
+}
+
+// CompressionSettings подструктура для хранения настроек сжатия тел объектов на диске.
+// Codec — gzip или zstd, пустое значение отключает сжатие. Threshold — минимальный размер сжимаемого тела в байтах.
+type CompressionSettings struct {
+	Codec     string `koanf:"codec"`
+	Threshold int    `koanf:"threshold"`
+}
+
+// LargeObjectSettings подструктура для хранения настроек крупных объектов, тела которых хранятся на диске частями.
+// Тела больше Threshold байт записываются потоково, но не больше MaxSize байт, и хранятся частями по ChunkSize байт.
+// Незавершённая загрузка отменяется, если не продолжалась UploadTTL; Timeout — время на запись или чтение
+// одного крупного объекта. Нулевые значения заменяются значениями по умолчанию.
+type LargeObjectSettings struct {
+	Enabled   bool          `koanf:"enabled"`
+	Threshold int64         `koanf:"threshold"`
+	MaxSize   int64         `koanf:"max_size"`
+	ChunkSize int           `koanf:"chunk_size"`
+	UploadTTL time.Duration `koanf:"upload_ttl"`
+	Timeout   time.Duration `koanf:"timeout"`
 }
 
 // LogSettings подструктура для хранения настроек логгера.
@@ -38,6 +110,100 @@ type LogSettings struct {
 	Format  string `koanf:"format"`
 }
 
+// SearchSettings подструктура для хранения настроек полнотекстового поиска.
+type SearchSettings struct {
+	Enabled bool `koanf:"enabled"`
+}
+
+// WebhookSettings подструктура для хранения настроек доставки событий подписчикам.
+// Нулевые значения заменяются значениями по умолчанию.
+type WebhookSettings struct {
+	Enabled      bool          `koanf:"enabled"`
+	MaxAttempts  int           `koanf:"max_attempts"`
+	Backoff      time.Duration `koanf:"backoff"`
+	MaxBackoff   time.Duration `koanf:"max_backoff"`
+	Timeout      time.Duration `koanf:"timeout"`
+	PollInterval time.Duration `koanf:"poll_interval"`
+}
+
+// TrashSettings подструктура для хранения настроек корзины удалённых объектов.
+type TrashSettings struct {
+	Enabled   bool          `koanf:"enabled"`
+	Retention time.Duration `koanf:"retention"`
+}
+
+// ExpiredSettings подструктура для хранения настроек области объектов с истёкшим сроком жизни. Объекты хранятся
+// в памяти Retention, но не больше MaxObjects; нулевые значения заменяются значениями по умолчанию.
+type ExpiredSettings struct {
+	Enabled    bool          `koanf:"enabled"`
+	Retention  time.Duration `koanf:"retention"`
+	MaxObjects int           `koanf:"max_objects"`
+}
+
+// TracingSettings подструктура для хранения настроек трассировки OpenTelemetry. Exporter — stdout, file или otlp:
+// file пишет спаны в File, otlp отправляет их по http на Endpoint (host:port), Insecure отключает tls.
+// SampleRatio — доля трассируемых запросов без входящего контекста трассировки; нулевое значение означает все запросы.
+type TracingSettings struct {
+	Enabled     bool    `koanf:"enabled"`
+	ServiceName string  `koanf:"service_name"`
+	Exporter    string  `koanf:"exporter"`
+	File        string  `koanf:"file"`
+	Endpoint    string  `koanf:"endpoint"`
+	Insecure    bool    `koanf:"insecure"`
+	SampleRatio float64 `koanf:"sample_ratio"`
+}
+
+// ReplicaSettings подструктура для хранения настроек репликации. С пустым Leader экземпляр работает ведущим,
+// иначе ведомым: загружает снимок ведущего по базовому url Leader, применяет его изменения и обслуживает только
+// чтение. Запись ведомый отклоняет, а с ProxyWrites перенаправляет ведущему. Ведомый готов принимать запросы,
+// пока отстаёт не больше чем на MaxLag изменений и получал вести от ведущего не позже MaxStaleness назад;
+// Retry — пауза перед переподключением к ведущему. Нулевые значения заменяются значениями по умолчанию.
+type ReplicaSettings struct {
+	Leader       string        `koanf:"leader"`
+	ProxyWrites  bool          `koanf:"proxy_writes"`
+	MaxLag       uint64        `koanf:"max_lag"`
+	MaxStaleness time.Duration `koanf:"max_staleness"`
+	Retry        time.Duration `koanf:"retry"`
+}
+
+// ClusterSettings подструктура для хранения настроек кластера: записи в хранилище реплицируются журналом Raft
+// между узлами Peers, запись и чтение обслуживает выбранный ведущий узел. NodeID — идентификатор узла, RaftAddress —
+// адрес (host:port) для обмена журналом с другими узлами, HTTPAddress — базовый url API узла, на который остальные
+// узлы перенаправляют запросы, пока он ведущий. Журнал и снимки хранятся в обязательном каталоге Dir.
+// Кластер создаётся при первом запуске узла из Peers, если узел в них есть; иначе узел ждёт, пока его
+// добавят в кластер. Новый кластер заполняется объектами из репозитория первого выбранного ведущего.
+// Журнал сжимается снимком хранилища раз в SnapshotInterval, если после прошлого снимка в него
+// добавлено не меньше SnapshotThreshold записей; ApplyTimeout — время на репликацию одной записи.
+// Нулевые значения заменяются значениями по умолчанию.
+type ClusterSettings struct {
+	Enabled           bool           `koanf:"enabled"`
+	NodeID            string         `koanf:"node_id"`
+	RaftAddress       string         `koanf:"raft_address"`
+	HTTPAddress       string         `koanf:"http_address"`
+	Dir               string         `koanf:"dir"`
+	Peers             []PeerSettings `koanf:"peers"`
+	HeartbeatTimeout  time.Duration  `koanf:"heartbeat_timeout"`
+	ElectionTimeout   time.Duration  `koanf:"election_timeout"`
+	SnapshotInterval  time.Duration  `koanf:"snapshot_interval"`
+	SnapshotThreshold uint64         `koanf:"snapshot_threshold"`
+	ApplyTimeout      time.Duration  `koanf:"apply_timeout"`
+}
+
+// PeerSettings узел кластера: идентификатор, адрес журнала Raft и базовый url API.
+type PeerSettings struct {
+	ID          string `koanf:"id"`
+	RaftAddress string `koanf:"raft_address"`
+	HTTPAddress string `koanf:"http_address"`
+}
+
+// ScrubSettings подструктура для хранения настроек фоновой проверки объектов по контрольным суммам.
+// Нулевые значения заменяются значениями по умолчанию.
+type ScrubSettings struct {
+	Enabled  bool          `koanf:"enabled"`
+	Interval time.Duration `koanf:"interval"`
+	Batch    int           `koanf:"batch"`
+}
+
 // NewSettings принимает путь до файла настроек и пытается создать объект Settings.
 func NewSettings(config string) (*Settings, error) {
 	if config == "" {
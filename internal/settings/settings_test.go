@@ -2,6 +2,7 @@ package settings
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -18,12 +19,80 @@ func TestNewSettings(t *testing.T) {
 	expected := Settings{}
 	expected.API.Address = "127.0.0.1"
 	expected.API.Port = 8080
+	expected.API.Limits.MaxBodySize = 1 << 20
+	expected.API.Limits.MaxJSONDepth = 32
+	expected.API.Limits.MaxJSONKeys = 10000
+	expected.API.Limits.ContentTypes = []string{"+json", "application/json", "application/x-protobuf", "image/*", "text/plain"}
+	expected.API.Limits.JSONContentTypes = []string{"application/json", "+json"}
 
 	expected.Storage.Path = "st-test.db"
+	expected.Storage.Compression.Codec = "zstd"
+	expected.Storage.Compression.Threshold = 512
 
 	expected.Log.Level = "debug"
 	expected.Log.Verbose = true
 	expected.Log.Format = "text"
 
+	expected.Search.Enabled = true
+
+	expected.Webhooks.Enabled = true
+	expected.Webhooks.MaxAttempts = 5
+	expected.Webhooks.Backoff = time.Second
+	expected.Webhooks.MaxBackoff = 5 * time.Minute
+	expected.Webhooks.Timeout = 10 * time.Second
+
+	expected.Trash.Enabled = true
+	expected.Trash.Retention = 72 * time.Hour
+
+	expected.Expired.Enabled = true
+	expected.Expired.Retention = time.Hour
+	expected.Expired.MaxObjects = 1000
+
+	expected.Scrub.Enabled = true
+	expected.Scrub.Interval = 24 * time.Hour
+	expected.Scrub.Batch = 100
+
+	expected.Quotas.MaxObjects = 100000
+	expected.Quotas.MaxBytes = 1 << 30
+	expected.Quotas.Clients = map[string]ClientQuota{"importer": {MaxObjects: 1000000}}
+	expected.Large = LargeObjectSettings{
+		Enabled:   true,
+		Threshold: 1 << 20,
+		MaxSize:   1 << 30,
+		ChunkSize: 256 << 10,
+		UploadTTL: 24 * time.Hour,
+		Timeout:   10 * time.Minute,
+	}
+
+	expected.Tracing = TracingSettings{
+		ServiceName: "st-test",
+		Exporter:    "otlp",
+		Endpoint:    "localhost:4318",
+		Insecure:    true,
+		SampleRatio: 1,
+	}
+	expected.Replica = ReplicaSettings{
+		ProxyWrites:  true,
+		MaxLag:       100,
+		MaxStaleness: 30 * time.Second,
+		Retry:        time.Second,
+	}
+	expected.Cluster = ClusterSettings{
+		NodeID:      "node-1",
+		RaftAddress: "127.0.0.1:7001",
+		HTTPAddress: "http://127.0.0.1:8080",
+		Dir:         "raft",
+		Peers: []PeerSettings{
+			{ID: "node-1", RaftAddress: "127.0.0.1:7001", HTTPAddress: "http://127.0.0.1:8080"},
+			{ID: "node-2", RaftAddress: "127.0.0.1:7002", HTTPAddress: "http://127.0.0.1:8081"},
+			{ID: "node-3", RaftAddress: "127.0.0.1:7003", HTTPAddress: "http://127.0.0.1:8082"},
+		},
+		HeartbeatTimeout:  time.Second,
+		ElectionTimeout:   time.Second,
+		SnapshotInterval:  2 * time.Minute,
+		SnapshotThreshold: 8192,
+		ApplyTimeout:      5 * time.Second,
+	}
+
 	require.Equal(t, expected, *sets)
 }
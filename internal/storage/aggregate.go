@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"st-test/internal/jsonpath"
+	"st-test/internal/models"
+)
+
+// AggregateOp операция агрегации.
+type AggregateOp string
+
+// Поддерживаемые операции агрегации.
+const (
+	AggregateCount AggregateOp = "count"
+	AggregateSum   AggregateOp = "sum"
+	AggregateAvg   AggregateOp = "avg"
+	AggregateMin   AggregateOp = "min"
+	AggregateMax   AggregateOp = "max"
+)
+
+var (
+	// ErrUnsupportedAggregate возвращается когда запрошена неизвестная операция агрегации.
+	ErrUnsupportedAggregate = errors.New("unsupported aggregate operation")
+	// ErrAggregatePathRequired возвращается когда для операции не указан путь к значению.
+	ErrAggregatePathRequired = errors.New("aggregate path is required")
+	// ErrAggregateTooLarge возвращается когда для агрегации нужно просмотреть больше объектов, чем разрешено.
+	ErrAggregateTooLarge = errors.New("too many objects to aggregate")
+)
+
+// AggregateOptions параметры агрегации.
+type AggregateOptions struct {
+	// Op операция агрегации.
+	Op AggregateOp
+	// Path путь к агрегируемому значению. Для count необязателен: если указан, считаются только объекты,
+	// в которых по пути есть значение.
+	Path *jsonpath.Path
+	// GroupBy путь к значению, по которому объекты группируются. Если не указан, результат состоит из одной группы.
+	GroupBy *jsonpath.Path
+	// Filter фильтр объектов. Если не указан, агрегируются все объекты.
+	Filter *jsonpath.Expr
+	// MaxScanned максимальное количество просматриваемых объектов. Фильтр не уменьшает просмотр: просматриваются
+	// все объекты хранилища. 0 — без ограничения.
+	MaxScanned int
+}
+
+// AggregateGroup результат агрегации для одной группы.
+type AggregateGroup struct {
+	// Group значение пути GroupBy. nil, если группировка не задана или значение в объекте отсутствует.
+	Group any
+	// Count количество объектов в группе, участвовавших в агрегации.
+	Count int
+	// Value результат операции. nil, если в группе нет числовых значений.
+	Value *float64
+}
+
+// Validate проверяет корректность параметров агрегации.
+func (o AggregateOptions) Validate() error {
+	switch o.Op {
+	case AggregateCount:
+		return nil
+	case AggregateSum, AggregateAvg, AggregateMin, AggregateMax:
+		if o.Path == nil {
+			return fmt.Errorf("%w: %s", ErrAggregatePathRequired, o.Op)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAggregate, o.Op)
+	}
+}
+
+// Aggregate вычисляет агрегат по всем объектам хранилища, подходящим под фильтр.
+// Объекты просматриваются пачками так же, как в Scan, поэтому вычисление не блокирует обычные запросы.
+// Объекты, тело которых не является корректным json, пропускаются.
+// count без группировки всегда возвращает одну группу: если подходящих объектов нет, её количество равно нулю.
+// Если объектов в хранилище больше MaxScanned, агрегация не выполняется и возвращается ErrAggregateTooLarge.
+func (s *Store) Aggregate(ctx context.Context, opts AggregateOptions) (_ []AggregateGroup, err error) {
+	ctx, span, _ := s.startSpan(ctx, "Aggregate")
+	defer func() { endSpan(span, err) }()
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.MaxScanned > 0 {
+		s.m.Lock()
+		n := len(s.s)
+		s.m.Unlock()
+
+		if n > opts.MaxScanned {
+			return nil, fmt.Errorf("%w: %d objects, at most %d are allowed", ErrAggregateTooLarge, n, opts.MaxScanned)
+		}
+	}
+
+	acc := make(map[string]*aggregator)
+
+	_, err = s.Scan(ctx, ScanOptions{}, func(item models.Item) bool {
+		doc, err := jsonpath.Decode(item.Body)
+		if err != nil {
+			return false
+		}
+
+		if opts.Filter != nil && !opts.Filter.Match(doc) {
+			return false
+		}
+
+		var group any
+
+		if opts.GroupBy != nil {
+			if values := opts.GroupBy.Select(doc); len(values) > 0 {
+				group = values[0]
+			}
+		}
+
+		key, err := json.Marshal(group)
+		if err != nil {
+			return false
+		}
+
+		a, ok := acc[string(key)]
+		if !ok {
+			a = &aggregator{group: group}
+			acc[string(key)] = a
+		}
+
+		a.add(opts, doc)
+
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aggregate: %w", err)
+	}
+
+	keys := make([]string, 0, len(acc))
+	for k := range acc {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	res := make([]AggregateGroup, 0, len(keys))
+
+	for _, k := range keys {
+		a := acc[k]
+		if a.count == 0 {
+			continue
+		}
+
+		res = append(res, a.result(opts.Op))
+	}
+
+	if len(res) == 0 && opts.Op == AggregateCount && opts.GroupBy == nil {
+		res = append(res, (&aggregator{}).result(opts.Op))
+	}
+
+	return res, nil
+}
+
+// aggregator накапливает значения одной группы.
+type aggregator struct {
+	group    any
+	count    int
+	numbers  int
+	sum      float64
+	min, max float64
+}
+
+func (a *aggregator) add(opts AggregateOptions, doc any) {
+	if opts.Path == nil {
+		a.count++
+
+		return
+	}
+
+	values := opts.Path.Select(doc)
+	if len(values) == 0 {
+		return
+	}
+
+	a.count++
+
+	for _, v := range values {
+		f, ok := v.(float64)
+		if !ok {
+			continue
+		}
+
+		if a.numbers == 0 || f < a.min {
+			a.min = f
+		}
+
+		if a.numbers == 0 || f > a.max {
+			a.max = f
+		}
+
+		a.sum += f
+		a.numbers++
+	}
+}
+
+func (a *aggregator) result(op AggregateOp) AggregateGroup {
+	g := AggregateGroup{Group: a.group, Count: a.count}
+
+	var v float64
+
+	switch op {
+	case AggregateCount:
+		v = float64(a.count)
+	case AggregateSum:
+		v = a.sum
+	case AggregateAvg:
+		if a.numbers == 0 {
+			return g
+		}
+
+		v = a.sum / float64(a.numbers)
+	case AggregateMin:
+		if a.numbers == 0 {
+			return g
+		}
+
+		v = a.min
+	case AggregateMax:
+		if a.numbers == 0 {
+			return g
+		}
+
+		v = a.max
+	}
+
+	g.Value = &v
+
+	return g
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/jsonpath"
+	"st-test/internal/models"
+)
+
+func TestStore_Aggregate(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+
+	s.s[1] = models.Item{ID: 1, Body: []byte(`{"status":"new","amount":10}`)}
+	s.s[2] = models.Item{ID: 2, Body: []byte(`{"status":"new","amount":30}`)}
+	s.s[3] = models.Item{ID: 3, Body: []byte(`{"status":"done","amount":5}`)}
+	s.s[4] = models.Item{ID: 4, Body: []byte(`{"status":"done"}`)}
+	s.s[5] = models.Item{ID: 5, Body: []byte(`{"amount":"text"}`)}
+	s.s[6] = models.Item{ID: 6, Body: []byte(`not json`)}
+
+	mustPath := func(src string) *jsonpath.Path {
+		p, err := jsonpath.ParsePath(src)
+		require.NoError(t, err)
+
+		return p
+	}
+
+	value := func(v float64) *float64 { return &v }
+
+	cases := []struct {
+		name    string
+		give    AggregateOptions
+		want    []AggregateGroup
+		wantErr error
+	}{
+		{
+			name: "count all",
+			give: AggregateOptions{Op: AggregateCount},
+			want: []AggregateGroup{{Count: 5, Value: value(5)}},
+		},
+		{
+			name: "count nothing matched",
+			give: func() AggregateOptions {
+				f, err := jsonpath.Parse("$.status == 'archived'")
+				require.NoError(t, err)
+
+				return AggregateOptions{Op: AggregateCount, Filter: f}
+			}(),
+			want: []AggregateGroup{{Count: 0, Value: value(0)}},
+		},
+		{
+			name: "count missing path",
+			give: AggregateOptions{Op: AggregateCount, Path: mustPath("$.missing")},
+			want: []AggregateGroup{{Count: 0, Value: value(0)}},
+		},
+		{
+			name: "count by status",
+			give: AggregateOptions{Op: AggregateCount, GroupBy: mustPath("$.status")},
+			want: []AggregateGroup{
+				{Group: "done", Count: 2, Value: value(2)},
+				{Group: "new", Count: 2, Value: value(2)},
+				{Group: nil, Count: 1, Value: value(1)},
+			},
+		},
+		{
+			name: "sum by status",
+			give: AggregateOptions{Op: AggregateSum, Path: mustPath("$.amount"), GroupBy: mustPath("$.status")},
+			want: []AggregateGroup{
+				{Group: "done", Count: 1, Value: value(5)},
+				{Group: "new", Count: 2, Value: value(40)},
+				{Group: nil, Count: 1, Value: value(0)},
+			},
+		},
+		{
+			name: "avg with filter",
+			give: func() AggregateOptions {
+				f, err := jsonpath.Parse("$.status == 'new'")
+				require.NoError(t, err)
+
+				return AggregateOptions{Op: AggregateAvg, Path: mustPath("$.amount"), Filter: f}
+			}(),
+			want: []AggregateGroup{{Count: 2, Value: value(20)}},
+		},
+		{
+			name: "min",
+			give: AggregateOptions{Op: AggregateMin, Path: mustPath("$.amount")},
+			want: []AggregateGroup{{Count: 4, Value: value(5)}},
+		},
+		{
+			name: "max",
+			give: AggregateOptions{Op: AggregateMax, Path: mustPath("$.amount")},
+			want: []AggregateGroup{{Count: 4, Value: value(30)}},
+		},
+		{
+			name:    "unsupported op",
+			give:    AggregateOptions{Op: "median"},
+			wantErr: ErrUnsupportedAggregate,
+		},
+		{
+			name:    "path required",
+			give:    AggregateOptions{Op: AggregateSum},
+			wantErr: ErrAggregatePathRequired,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := s.Aggregate(context.Background(), tc.give)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestStore_AggregateEmpty(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+
+	got, err := s.Aggregate(context.Background(), AggregateOptions{Op: AggregateCount})
+	require.NoError(t, err)
+
+	zero := float64(0)
+	require.Equal(t, []AggregateGroup{{Count: 0, Value: &zero}}, got)
+
+	// с группировкой групп нет, а для остальных операций нет значений.
+	path, err := jsonpath.ParsePath("$.status")
+	require.NoError(t, err)
+
+	got, err = s.Aggregate(context.Background(), AggregateOptions{Op: AggregateCount, GroupBy: path})
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	got, err = s.Aggregate(context.Background(), AggregateOptions{Op: AggregateSum, Path: path})
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
+
+func TestStore_AggregateTooLarge(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+
+	for id := 1; id <= 3; id++ {
+		s.s[id] = models.Item{ID: id, Body: []byte(`{"amount":1}`)}
+	}
+
+	got, err := s.Aggregate(context.Background(), AggregateOptions{Op: AggregateCount, MaxScanned: 3})
+	require.NoError(t, err)
+	require.Equal(t, 3, got[0].Count)
+
+	// фильтр не уменьшает просмотр, поэтому ограничение проверяется по всем объектам хранилища.
+	filter, err := jsonpath.Parse("$.amount > 5")
+	require.NoError(t, err)
+
+	_, err = s.Aggregate(context.Background(), AggregateOptions{Op: AggregateCount, Filter: filter, MaxScanned: 2})
+	require.ErrorIs(t, err, ErrAggregateTooLarge)
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"st-test/internal/models"
+)
+
+const (
+	// defaultChangeLogSize количество последних изменений, которые хранилище хранит для возобновления подписок.
+	defaultChangeLogSize = 1024
+)
+
+// ErrChangesExpired возвращается когда запрошенные изменения уже вытеснены из журнала изменений
+// или относятся к другому запуску хранилища.
+var ErrChangesExpired = errors.New("requested changes are no longer retained")
+
+// changeLog ограниченный журнал последних изменений хранилища.
+// Запись в журнал никогда не блокируется на читателях: читатели сами забирают изменения по номеру
+// и ожидают новые через канал уведомления.
+type changeLog struct {
+	m      sync.Mutex
+	buf    []models.Change
+	start  int
+	size   int
+	seq    uint64
+	notify chan struct{}
+}
+
+func newChangeLog(capacity int) *changeLog {
+	if capacity <= 0 {
+		capacity = defaultChangeLogSize
+	}
+
+	return &changeLog{
+		buf:    make([]models.Change, capacity),
+		notify: make(chan struct{}),
+	}
+}
+
+// append добавляет изменение в журнал, присваивает ему номер и будит ожидающих читателей.
+// Версией созданного или изменённого объекта становится номер изменения.
+func (l *changeLog) append(c models.Change) models.Change {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	l.seq++
+	c.Seq = l.seq
+
+	if c.Type == models.ChangeCreated || c.Type == models.ChangeUpdated {
+		c.Item.Version = c.Seq
+	}
+
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+
+	if l.size < len(l.buf) {
+		l.buf[(l.start+l.size)%len(l.buf)] = c
+		l.size++
+	} else {
+		l.buf[l.start] = c
+		l.start = (l.start + 1) % len(l.buf)
+	}
+
+	close(l.notify)
+	l.notify = make(chan struct{})
+
+	return c
+}
+
+// since возвращает не более limit изменений с номером больше after и канал, который закроется при следующем изменении.
+func (l *changeLog) since(after uint64, limit int) ([]models.Change, <-chan struct{}, error) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if after > l.seq {
+		return nil, nil, ErrChangesExpired
+	}
+
+	oldest := l.seq - uint64(l.size) + 1
+	if after+1 < oldest {
+		return nil, nil, ErrChangesExpired
+	}
+
+	n := int(l.seq - after)
+	if limit > 0 {
+		n = min(n, limit)
+	}
+
+	res := make([]models.Change, 0, n)
+	skip := int(after + 1 - oldest)
+
+	for i := 0; i < n; i++ {
+		res = append(res, l.buf[(l.start+skip+i)%len(l.buf)])
+	}
+
+	return res, l.notify, nil
+}
+
+// resume продолжает нумерацию изменений после seq, если журнал ещё пуст.
+// Изменения до seq считаются вытесненными из журнала.
+func (l *changeLog) resume(seq uint64) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if l.size == 0 && seq > l.seq {
+		l.seq = seq
+	}
+}
+
+// reset очищает журнал и продолжает нумерацию изменений после seq.
+// Читатели изменений до seq получат ErrChangesExpired.
+func (l *changeLog) reset(seq uint64) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	l.start, l.size, l.seq = 0, 0, seq
+	clear(l.buf)
+
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
+
+func (l *changeLog) last() uint64 {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	return l.seq
+}
+
+// WithChangeLogSize задаёт количество последних изменений, хранимых для возобновления подписок.
+func WithChangeLogSize(size int) Option {
+	return func(s *Store) {
+		s.changes = newChangeLog(size)
+	}
+}
+
+// Changes возвращает не более limit изменений с номером больше after в порядке возрастания номера,
+// а так же канал, который будет закрыт при появлении следующего изменения.
+// Если изменения после after уже вытеснены из журнала, возвращается ErrChangesExpired.
+func (s *Store) Changes(after uint64, limit int) ([]models.Change, <-chan struct{}, error) {
+	return s.changes.since(after, limit)
+}
+
+// LastChange возвращает номер последнего изменения хранилища.
+func (s *Store) LastChange() uint64 {
+	return s.changes.last()
+}
+
+// publish записывает изменение объекта в журнал, передаёт его слушателям и будит ожидающих изменения этого объекта.
+// Вызывается под блокировкой хранилища.
+func (s *Store) publish(t models.ChangeType, item models.Item) models.Change {
+	return s.publishChange(models.Change{Type: t, ID: item.ID, Body: item.Body, Item: item})
+}
+
+// publishChange записывает изменение c в журнал, передаёт его слушателям и будит ожидающих изменения объекта.
+// Вызывается под блокировкой хранилища.
+func (s *Store) publishChange(c models.Change) models.Change {
+	c = s.changes.append(c)
+	s.notifyListeners(c)
+
+	if w, ok := s.watchers[c.ID]; ok {
+		close(w.ch)
+		delete(s.watchers, c.ID)
+	}
+
+	return c
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/models"
+)
+
+func TestChangeLog(t *testing.T) {
+	t.Parallel()
+
+	l := newChangeLog(3)
+
+	got, wait, err := l.since(0, 0)
+	require.NoError(t, err)
+	require.Empty(t, got)
+	require.NotNil(t, wait)
+
+	for id := 1; id <= 5; id++ {
+		c := l.append(models.Change{Type: models.ChangeCreated, ID: id})
+		require.Equal(t, uint64(id), c.Seq)
+		require.Equal(t, c.Seq, c.Item.Version)
+		require.False(t, c.Time.IsZero())
+	}
+
+	select {
+	case <-wait:
+	default:
+		t.Fatal("wait channel must be closed after append")
+	}
+
+	got, _, err = l.since(2, 0)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	require.Equal(t, []int{3, 4, 5}, []int{got[0].ID, got[1].ID, got[2].ID})
+
+	got, _, err = l.since(3, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, uint64(4), got[0].Seq)
+
+	got, _, err = l.since(5, 0)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	_, _, err = l.since(1, 0)
+	require.ErrorIs(t, err, ErrChangesExpired)
+
+	_, _, err = l.since(6, 0)
+	require.ErrorIs(t, err, ErrChangesExpired)
+
+	require.Equal(t, uint64(5), l.last())
+}
+
+func TestStore_Changes(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"v":1}`)})
+	require.NoError(t, err)
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"v":2}`)})
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteObject(ctx, 1))
+
+	changes, _, err := s.Changes(0, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	require.Equal(t, models.ChangeCreated, changes[0].Type)
+	require.Equal(t, models.ChangeUpdated, changes[1].Type)
+	require.Equal(t, []byte(`{"v":2}`), changes[1].Body)
+	require.Equal(t, models.ChangeDeleted, changes[2].Type)
+	require.Equal(t, uint64(3), s.LastChange())
+}
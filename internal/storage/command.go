@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"st-test/internal/models"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// CommandType тип записи в хранилище.
+type CommandType string
+
+// Типы записей в хранилище.
+const (
+	CommandSave           CommandType = "save"
+	CommandDelete         CommandType = "delete"
+	CommandUpdateMetadata CommandType = "update_metadata"
+	CommandSetTTL         CommandType = "set_ttl"
+	CommandTouch          CommandType = "touch"
+	CommandRestore        CommandType = "restore"
+	CommandExpire         CommandType = "expire"
+	CommandPurge          CommandType = "purge"
+)
+
+// ErrUnknownCommand возвращается при применении записи неизвестного типа.
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Command запись в хранилище, которую можно применить на любом экземпляре с тем же результатом.
+// Time — момент записи, от которого отсчитываются сроки жизни и время изменения объектов; Item — объект,
+// уже прошедший хуки BeforeSave; IDs — объекты с истёкшим сроком жизни для CommandExpire.
+type Command struct {
+	Type  CommandType          `json:"type"`
+	ID    int                  `json:"id,omitempty"`
+	IDs   []int                `json:"ids,omitempty"`
+	Item  models.Item          `json:"item"`
+	Patch models.MetadataPatch `json:"patch"`
+	TTL   models.TTL           `json:"ttl"`
+	Time  time.Time            `json:"time"`
+}
+
+// Result результат записи: ID для CommandSave, Item для записей, возвращающих объект, и ошибка записи.
+type Result struct {
+	ID   int
+	Item models.Item
+	Err  error
+}
+
+// Proposer реплицирует записи в хранилище. Propose возвращает результат применения записи
+// или ошибку, если запись не удалось реплицировать.
+type Proposer interface {
+	Propose(ctx context.Context, c Command) Result
+}
+
+// WithProposer включает репликацию записей: вместо применения на месте запись передаётся p, а p применяет её
+// на всех экземплярах через Apply. Объекты с истёкшим сроком жизни так же удаляются только записями
+// CommandExpire, а корзина и область истёкших объектов очищаются записями CommandPurge, а не фоновой проверкой;
+// объекты и корзина из репозитория при запуске не загружаются, так как восстанавливаются из журнала p.
+// Заполнить из репозитория пустой журнал можно снимком RepoSnapshot.
+func WithProposer(p Proposer) Option {
+	return func(s *Store) {
+		s.proposer = p
+	}
+}
+
+// propose передаёт запись на репликацию, если она включена. Второе значение сообщает, была ли запись передана.
+func (s *Store) propose(ctx context.Context, c Command) (Result, bool) {
+	if s.proposer == nil {
+		return Result{}, false
+	}
+
+	return s.proposer.Propose(ctx, c), true
+}
+
+// Apply применяет реплицированную запись. Хуки BeforeSave к записи не применяются: их уже прошёл Item.
+func (s *Store) Apply(ctx context.Context, c Command) Result {
+	_, span, log := s.startSpan(ctx, "Apply", attribute.String("command", string(c.Type)), objectID(c.ID))
+
+	var res Result
+
+	switch c.Type {
+	case CommandSave:
+		res.ID, res.Err = s.saveObject(log, c.Item, c.Time)
+	case CommandDelete:
+		res.Err = s.deleteObject(log, c.ID, c.Time)
+	case CommandUpdateMetadata:
+		res.Item, res.Err = s.updateMetadata(log, c.ID, c.Patch, c.Time)
+	case CommandSetTTL:
+		res.Item, res.Err = s.setTTL(log, c.ID, c.TTL, c.Time)
+	case CommandTouch:
+		res.Item, res.Err = s.touch(c.ID, c.Time)
+	case CommandRestore:
+		res.Item, res.Err = s.restoreObject(log, c.ID, c.Time)
+	case CommandExpire:
+		s.expireObjects(c.IDs, c.Time)
+	case CommandPurge:
+		s.purgeTrash(c.Time)
+		s.purgeExpired(c.Time)
+	default:
+		res.Err = fmt.Errorf("%w: %q", ErrUnknownCommand, c.Type)
+	}
+
+	endSpan(span, res.Err)
+
+	return res
+}
+
+// Expiring возвращает id объектов, срок жизни которых истёк к моменту now, но которые ещё не удалены.
+func (s *Store) Expiring(now time.Time) []int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	var ids []int
+
+	for id, item := range s.s {
+		if item.Expired(now) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// RepoSnapshot возвращает объекты и корзину, записанные в репозиторий, не загружая их в хранилище.
+// Если репозиторий не удалось прочитать, он не перезаписывается при остановке хранилища.
+func (s *Store) RepoSnapshot(ctx context.Context) (_ models.Snapshot, err error) {
+	ctx, span, _ := s.startSpan(ctx, "RepoSnapshot")
+	defer func() { endSpan(span, err) }()
+
+	snap := models.Snapshot{Items: []models.Item{}}
+
+	defer func() {
+		if err != nil {
+			s.m.Lock()
+			s.loadFailed = true
+			s.m.Unlock()
+
+			s.metrics.repoErrors.WithLabelValues(repoRead).Inc()
+		}
+	}()
+
+	items, err := s.repo.ReadAll(ctx)
+	if errors.Is(err, models.ErrCorrupted) {
+		s.log.Warn("corrupted items were quarantined", zap.Error(err))
+
+		err = nil
+	}
+
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return models.Snapshot{}, fmt.Errorf("read items: %w", err)
+	}
+
+	snap.Seq, err = s.repo.LastSeq(ctx)
+	if err != nil {
+		return models.Snapshot{}, fmt.Errorf("read last seq: %w", err)
+	}
+
+	for _, item := range items {
+		snap.Items = append(snap.Items, item)
+		snap.Seq = max(snap.Seq, item.Version)
+	}
+
+	if s.trash == nil {
+		return snap, nil
+	}
+
+	trash, err := s.repo.ReadTrash(ctx)
+	if errors.Is(err, models.ErrCorrupted) {
+		s.log.Warn("corrupted trash items were quarantined", zap.Error(err))
+
+		err = nil
+	}
+
+	if err != nil {
+		return models.Snapshot{}, fmt.Errorf("read trash: %w", err)
+	}
+
+	snap.Trash = append([]models.TrashedItem{}, trash...)
+
+	return snap, nil
+}
+
+// Purgeable сообщает, есть ли в корзине или области истёкших объектов объекты, срок хранения которых
+// прошёл к моменту now.
+func (s *Store) Purgeable(now time.Time) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, item := range s.trash {
+		if !now.Before(item.PurgeAt) {
+			return true
+		}
+	}
+
+	for _, item := range s.expired {
+		if !now.Before(item.PurgeAt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expireObjects удаляет объекты ids, срок жизни которых истёк к моменту now.
+func (s *Store) expireObjects(ids []int, now time.Time) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	expired := 0
+
+	for _, id := range ids {
+		item, ok := s.s[id]
+		if !ok || !item.Expired(now) {
+			continue
+		}
+
+		s.expireItem(item, now)
+
+		expired++
+	}
+
+	if expired > 0 {
+		s.log.Info("expired items removed", zap.Int("count", expired))
+	}
+}
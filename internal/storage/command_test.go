@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// journal реплицирует записи в хранилище по порядку на все экземпляры, как журнал консенсуса.
+type journal struct {
+	t      *testing.T
+	stores []*Store
+}
+
+func (j *journal) Propose(ctx context.Context, c Command) Result {
+	// запись проходит через журнал в JSON, поэтому экземпляры получают её копию.
+	data, err := json.Marshal(c)
+	require.NoError(j.t, err)
+
+	var res Result
+
+	for i, s := range j.stores {
+		var got Command
+		require.NoError(j.t, json.Unmarshal(data, &got))
+
+		if r := s.Apply(ctx, got); i == 0 {
+			res = r
+		}
+	}
+
+	return res
+}
+
+func testCluster(t *testing.T, size int) []*Store {
+	t.Helper()
+
+	j := &journal{t: t}
+
+	for range size {
+		// объекты восстанавливаются из журнала, поэтому из репозитория при запуске не читаются.
+		j.stores = append(j.stores, NewStore(zap.NewNop(), mocks.NewRepo(t), WithProposer(j), WithTrash(time.Hour)))
+	}
+
+	return j.stores
+}
+
+func TestStore_Apply(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	stores := testCluster(t, 3)
+	s := stores[1]
+
+	var hooked int
+
+	s.BeforeSave(func(_ context.Context, item models.Item) (models.Item, error) {
+		hooked++
+		item.Owner = "alice"
+
+		return item, nil
+	})
+
+	id, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"a":1}`), Expires: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{"b":2}`)})
+	require.NoError(t, err)
+
+	value := "v"
+	_, err = s.UpdateMetadata(ctx, 2, models.MetadataPatch{Metadata: map[string]*string{"k": &value}})
+	require.NoError(t, err)
+
+	_, err = s.SetTTL(ctx, 2, models.TTL{Duration: time.Minute})
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteObject(ctx, 1))
+	_, err = s.RestoreObject(ctx, 1)
+	require.NoError(t, err)
+
+	_, err = s.Touch(ctx, 1)
+	require.NoError(t, err)
+
+	// хук выполняется один раз на экземпляре, принявшем запись.
+	assert.Equal(t, 2, hooked)
+
+	require.ErrorIs(t, s.DeleteObject(ctx, 3), models.ErrNotFound)
+
+	want, err := stores[0].Snapshot(ctx)
+	require.NoError(t, err)
+	require.Len(t, want.Items, 2)
+
+	for _, other := range stores[1:] {
+		got, err := other.Snapshot(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want.Seq, got.Seq)
+		assert.ElementsMatch(t, want.Items, got.Items)
+	}
+
+	got, err := stores[2].GetObject(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.Owner)
+	assert.Equal(t, map[string]string{"k": "v"}, got.Metadata)
+}
+
+func TestStore_ApplyExpire(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	stores := testCluster(t, 2)
+	now := time.Now()
+
+	_, err := stores[0].SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Expires: time.Minute})
+	require.NoError(t, err)
+	_, err = stores[0].SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	ids := stores[0].Expiring(now.Add(time.Hour))
+	require.Equal(t, []int{1}, ids)
+
+	for _, s := range stores {
+		res := s.Apply(ctx, Command{Type: CommandExpire, IDs: []int{1, 2}, Time: now.Add(time.Hour)})
+		require.NoError(t, res.Err)
+
+		_, err := s.StatObject(ctx, 1)
+		require.ErrorIs(t, err, models.ErrNotFound)
+		assert.Len(t, s.s, 1)
+	}
+
+	res := stores[0].Apply(ctx, Command{Type: "unknown"})
+	require.ErrorIs(t, res.Err, ErrUnknownCommand)
+}
+
+func TestStore_ApplyPurge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	stores := testCluster(t, 2)
+	now := time.Now()
+
+	_, err := stores[0].SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+	require.NoError(t, stores[0].DeleteObject(ctx, 1))
+
+	// корзина очищается только записью журнала, а не фоновой проверкой каждого экземпляра.
+	assert.False(t, stores[0].Purgeable(now))
+	assert.True(t, stores[0].Purgeable(now.Add(2*time.Hour)))
+
+	// снимок переносит корзину, поэтому восстановленный из него экземпляр восстанавливает те же объекты.
+	snap, err := stores[0].Snapshot(ctx)
+	require.NoError(t, err)
+	require.Len(t, snap.Trash, 1)
+
+	restored := NewStore(zap.NewNop(), mocks.NewRepo(t), WithProposer(failingProposer{}), WithTrash(time.Hour))
+	require.NoError(t, restored.ApplySnapshot(ctx, snap))
+	assert.Len(t, restored.trash, 1)
+
+	for _, s := range stores {
+		require.NoError(t, s.Apply(ctx, Command{Type: CommandPurge, Time: now.Add(2 * time.Hour)}).Err)
+		assert.Empty(t, s.trash)
+	}
+
+	_, err = stores[1].RestoreObject(ctx, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestStore_GetObjectSliding(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	stores := testCluster(t, 2)
+
+	_, err := stores[0].SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Expires: time.Minute, Sliding: true})
+	require.NoError(t, err)
+
+	before := stores[1].s[1].ExpiresAt
+
+	time.Sleep(10 * time.Millisecond)
+
+	// продление скользящего срока жизни при чтении реплицируется на все экземпляры.
+	got, err := stores[0].GetObject(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, got.ExpiresAt.After(before))
+
+	for _, s := range stores {
+		assert.Equal(t, got.ExpiresAt, s.s[1].ExpiresAt)
+	}
+}
+
+func TestStore_ProposeFailed(t *testing.T) {
+	t.Parallel()
+
+	errNoLeader := errors.New("no leader")
+	s := NewStore(zap.NewNop(), mocks.NewRepo(t), WithProposer(failingProposer{err: errNoLeader}))
+
+	_, err := s.SaveObject(context.Background(), models.Item{ID: 1, Body: []byte(`{}`)})
+	require.ErrorIs(t, err, errNoLeader)
+	assert.Empty(t, s.s)
+}
+
+// failingProposer не может реплицировать ни одну запись.
+type failingProposer struct {
+	err error
+}
+
+func (p failingProposer) Propose(context.Context, Command) Result {
+	return Result{Err: p.err}
+}
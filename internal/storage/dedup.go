@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"crypto/sha256"
+)
+
+// blob общее тело объектов с одинаковым содержимым.
+type blob struct {
+	body []byte
+	// refs количество объектов в хранилище и корзине, ссылающихся на тело.
+	refs int
+}
+
+// acquireBody возвращает общее тело, равное body, и добавляет на него ссылку.
+// Если такого тела ещё нет, общим становится body. Вызывается под блокировкой хранилища.
+func (s *Store) acquireBody(body []byte) []byte {
+	hash := sha256.Sum256(body)
+
+	b, ok := s.blobs[hash]
+	if !ok {
+		b = &blob{body: body}
+		s.blobs[hash] = b
+		s.metrics.blobBytes.Add(float64(len(body)))
+	}
+
+	b.refs++
+	s.metrics.blobs.Set(float64(len(s.blobs)))
+
+	return b.body
+}
+
+// releaseBody убирает ссылку на общее тело и забывает тело, на которое больше никто не ссылается.
+// Вызывается под блокировкой хранилища.
+func (s *Store) releaseBody(body []byte) {
+	hash := sha256.Sum256(body)
+
+	b, ok := s.blobs[hash]
+	if !ok {
+		return
+	}
+
+	b.refs--
+	if b.refs > 0 {
+		return
+	}
+
+	delete(s.blobs, hash)
+	s.metrics.blobs.Set(float64(len(s.blobs)))
+	s.metrics.blobBytes.Sub(float64(len(body)))
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"st-test/internal/models"
+	"st-test/internal/storage/mocks"
+)
+
+func TestStore_Deduplication(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+	repo.EXPECT().ReadTrash(mock.Anything).Once().Return(nil, nil)
+
+	s := NewStore(zap.NewNop(), repo, WithTrash(time.Hour))
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"a":1}`)})
+	require.NoError(t, err)
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{"a":1}`)})
+	require.NoError(t, err)
+	_, err = s.SaveObject(ctx, models.Item{ID: 3, Body: []byte(`{"b":2}`), Expires: time.Hour})
+	require.NoError(t, err)
+
+	one, err := s.GetObject(ctx, 1)
+	require.NoError(t, err)
+	two, err := s.GetObject(ctx, 2)
+	require.NoError(t, err)
+
+	// объекты с одинаковым телом хранят его в памяти один раз.
+	require.Same(t, &one.Body[0], &two.Body[0])
+	require.Len(t, s.blobs, 2)
+	require.Equal(t, float64(len(`{"a":1}`)+len(`{"b":2}`)), testutil.ToFloat64(s.metrics.blobBytes))
+
+	require.Equal(t, 1, s.expire(time.Now().Add(2*time.Hour)))
+	require.Len(t, s.blobs, 1)
+
+	// тело объекта в корзине остаётся, пока его не удалят из корзины.
+	require.NoError(t, s.DeleteObject(ctx, 1))
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{"c":3}`)})
+	require.NoError(t, err)
+	require.Len(t, s.blobs, 2)
+
+	_, err = s.RestoreObject(ctx, 1)
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteObject(ctx, 1))
+	require.Equal(t, 1, s.purgeTrash(time.Now().Add(2*time.Hour)))
+
+	require.Len(t, s.blobs, 1)
+	require.Equal(t, float64(1), testutil.ToFloat64(s.metrics.blobs))
+	require.Equal(t, float64(len(`{"c":3}`)), testutil.ToFloat64(s.metrics.blobBytes))
+}
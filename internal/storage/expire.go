@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"time"
+
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// expireInterval период проверки объектов с истёкшим сроком жизни.
+	expireInterval = time.Second
+)
+
+// expireLoop периодически удаляет объекты с истёкшим сроком жизни до остановки хранилища.
+// Реплика не удаляет их сама, а ждёт изменений ведущего; при репликации записей их удаляют записи CommandExpire,
+// а корзину и область истёкших объектов очищают записи CommandPurge.
+func (s *Store) expireLoop() {
+	ticker := time.NewTicker(expireInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			if s.proposer != nil {
+				continue
+			}
+
+			if !s.replica {
+				s.expire(now)
+			}
+
+			s.purgeTrash(now)
+			s.purgeExpired(now)
+		}
+	}
+}
+
+// expire удаляет объекты, срок жизни которых истёк к моменту now, и возвращает их количество.
+func (s *Store) expire(now time.Time) int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	expired := 0
+
+	for _, item := range s.s {
+		if !item.Expired(now) {
+			continue
+		}
+
+		s.expireItem(item, now)
+
+		expired++
+	}
+
+	if expired > 0 {
+		s.log.Info("expired items removed", zap.Int("count", expired))
+	}
+
+	return expired
+}
+
+// expireItem удаляет объект с истёкшим сроком жизни: публикует изменение expired, учитывает его в метриках
+// и кладёт объект в область истёкших объектов, если она включена. Вызывается под блокировкой хранилища.
+func (s *Store) expireItem(item models.Item, now time.Time) {
+	delete(s.s, item.ID)
+	s.unindexItem(item.ID)
+	s.untrack(item)
+	s.moveToExpired(item, now)
+	s.publish(models.ChangeExpired, item)
+	s.metrics.expired.Inc()
+
+	s.log.Info("item expired", zap.Int("id", item.ID), zap.String("owner", item.Owner),
+		zap.Time("expires_at", item.ExpiresAt))
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/models"
+)
+
+func TestStore_expire(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Expires: time.Minute})
+	require.NoError(t, err)
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	require.Equal(t, 0, s.expire(time.Now()))
+
+	// объект с истёкшим сроком жизни не отдаётся ещё до того, как его удалит фоновая проверка.
+	s.m.Lock()
+	item := s.s[1]
+	item.ExpiresAt = time.Now().Add(-time.Second)
+	s.s[1] = item
+	s.m.Unlock()
+
+	_, err = s.GetObject(ctx, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	require.Equal(t, 1, s.expire(time.Now()))
+	require.Len(t, s.s, 1)
+
+	changes, _, err := s.Changes(2, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, models.ChangeExpired, changes[0].Type)
+	require.Equal(t, 1, changes[0].ID)
+
+	// повторное сохранение истёкшего объекта считается созданием.
+	gotID, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+	require.Equal(t, 1, gotID)
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultExpiredRetention время хранения объектов с истёкшим сроком жизни по умолчанию.
+	defaultExpiredRetention = time.Hour
+	// defaultMaxExpired количество хранимых объектов с истёкшим сроком жизни по умолчанию.
+	defaultMaxExpired = 1000
+)
+
+// ErrExpiredDisabled возвращается когда область объектов с истёкшим сроком жизни не включена.
+var ErrExpiredDisabled = errors.New("expired area is disabled")
+
+// WithExpiredArea включает область объектов с истёкшим сроком жизни: такие объекты хранятся в ней retention
+// для отладки, но не больше maxObjects; при переполнении вытесняются истёкшие раньше остальных.
+// Область хранится только в памяти и не переживает перезапуск.
+func WithExpiredArea(retention time.Duration, maxObjects int) Option {
+	return func(s *Store) {
+		if retention <= 0 {
+			retention = defaultExpiredRetention
+		}
+
+		if maxObjects <= 0 {
+			maxObjects = defaultMaxExpired
+		}
+
+		s.expired = make(map[int]models.ExpiredItem)
+		s.expiredRetention = retention
+		s.maxExpired = maxObjects
+	}
+}
+
+// ExpiredObjects возвращает не более limit объектов с истёкшим сроком жизни, начиная с последних истёкших.
+func (s *Store) ExpiredObjects(ctx context.Context, limit int) (_ []models.ExpiredItem, err error) {
+	_, span, _ := s.startSpan(ctx, "ExpiredObjects")
+	defer func() { endSpan(span, err) }()
+
+	if s.expired == nil {
+		return nil, ErrExpiredDisabled
+	}
+
+	s.m.Lock()
+
+	items := make([]models.ExpiredItem, 0, len(s.expired))
+	for _, item := range s.expired {
+		items = append(items, item)
+	}
+
+	s.m.Unlock()
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].ExpiredAt.Equal(items[j].ExpiredAt) {
+			return items[i].ExpiredAt.After(items[j].ExpiredAt)
+		}
+
+		return items[i].ID < items[j].ID
+	})
+
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items, nil
+}
+
+// ExpiredObject возвращает объект с истёкшим сроком жизни по id. Если его нет, возвращается models.ErrNotFound.
+func (s *Store) ExpiredObject(ctx context.Context, id int) (_ models.ExpiredItem, err error) {
+	_, span, _ := s.startSpan(ctx, "ExpiredObject", objectID(id))
+	defer func() { endSpan(span, err) }()
+
+	if s.expired == nil {
+		return models.ExpiredItem{}, ErrExpiredDisabled
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	item, ok := s.expired[id]
+	if !ok {
+		return models.ExpiredItem{}, models.ErrNotFound
+	}
+
+	return item, nil
+}
+
+// moveToExpired кладёт объект с истёкшим сроком жизни в область истёкших объектов, если она включена,
+// вместе со ссылкой на его тело. Вызывается под блокировкой хранилища.
+func (s *Store) moveToExpired(item models.Item, now time.Time) {
+	if s.expired == nil {
+		s.releaseBody(item.Body)
+
+		return
+	}
+
+	if old, ok := s.expired[item.ID]; ok {
+		s.releaseBody(old.Body)
+	}
+
+	s.expired[item.ID] = models.ExpiredItem{
+		Item:      item,
+		ExpiredAt: now,
+		PurgeAt:   now.Add(s.expiredRetention),
+	}
+
+	if len(s.expired) > s.maxExpired {
+		s.evictExpired()
+	}
+
+	s.metrics.expiredObjects.Set(float64(len(s.expired)))
+}
+
+// evictExpired вытесняет объект, истёкший раньше остальных. Вызывается под блокировкой хранилища.
+func (s *Store) evictExpired() {
+	var oldest models.ExpiredItem
+
+	for _, item := range s.expired {
+		if oldest.ExpiredAt.IsZero() || item.ExpiredAt.Before(oldest.ExpiredAt) ||
+			item.ExpiredAt.Equal(oldest.ExpiredAt) && item.ID < oldest.ID {
+			oldest = item
+		}
+	}
+
+	delete(s.expired, oldest.ID)
+	s.releaseBody(oldest.Body)
+	s.metrics.expiredEvictions.Inc()
+
+	s.log.Info("expired item evicted", zap.Int("id", oldest.ID), zap.Time("expired_at", oldest.ExpiredAt))
+}
+
+// purgeExpired удаляет объекты, срок хранения которых в области истёкших объектов прошёл к моменту now,
+// и возвращает их количество.
+func (s *Store) purgeExpired(now time.Time) int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	purged := 0
+
+	for id, item := range s.expired {
+		if now.Before(item.PurgeAt) {
+			continue
+		}
+
+		delete(s.expired, id)
+		s.releaseBody(item.Body)
+
+		purged++
+	}
+
+	if purged > 0 {
+		s.metrics.expiredObjects.Set(float64(len(s.expired)))
+		s.log.Info("expired items purged", zap.Int("count", purged))
+	}
+
+	return purged
+}
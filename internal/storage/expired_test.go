@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testExpiredStore(t *testing.T, maxObjects int) *Store {
+	t.Helper()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	return NewStore(zap.NewNop(), repo, WithExpiredArea(time.Hour, maxObjects))
+}
+
+// expireNow переводит срок жизни объекта в прошлое.
+func expireNow(s *Store, id int) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	item := s.s[id]
+	item.ExpiresAt = time.Now().Add(-time.Second)
+	s.s[id] = item
+}
+
+func TestStore_ExpiredDisabled(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+
+	_, err := s.ExpiredObjects(ctx, 10)
+	require.ErrorIs(t, err, ErrExpiredDisabled)
+
+	_, err = s.ExpiredObject(ctx, 1)
+	require.ErrorIs(t, err, ErrExpiredDisabled)
+}
+
+func TestStore_ExpiredObjects(t *testing.T) {
+	t.Parallel()
+
+	s := testExpiredStore(t, 2)
+	ctx := context.Background()
+
+	for id := 1; id <= 3; id++ {
+		_, err := s.SaveObject(ctx, models.Item{ID: id, Body: []byte(`{"v":1}`), Expires: time.Minute})
+		require.NoError(t, err)
+
+		expireNow(s, id)
+		require.Equal(t, 1, s.expire(time.Now().Add(time.Duration(id)*time.Second)))
+	}
+
+	// область переполнена: первый истёкший объект вытеснен.
+	items, err := s.ExpiredObjects(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, 3, items[0].ID)
+	assert.Equal(t, 2, items[1].ID)
+	assert.WithinDuration(t, items[0].ExpiredAt.Add(time.Hour), items[0].PurgeAt, 0)
+
+	_, err = s.ExpiredObject(ctx, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	item, err := s.ExpiredObject(ctx, 2)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"v":1}`, string(item.Body))
+
+	items, err = s.ExpiredObjects(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	assert.Zero(t, s.purgeExpired(time.Now()))
+	assert.Equal(t, 2, s.purgeExpired(time.Now().Add(2*time.Hour)))
+
+	items, err = s.ExpiredObjects(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestStore_SaveObjectOverExpired(t *testing.T) {
+	t.Parallel()
+
+	s := testExpiredStore(t, 10)
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"v":1}`), Expires: time.Minute})
+	require.NoError(t, err)
+
+	expireNow(s, 1)
+
+	// перезапись объекта, который ещё не удалила фоновая проверка, публикует его истечение.
+	_, err = s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"v":2}`)})
+	require.NoError(t, err)
+
+	changes, _, err := s.Changes(1, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, models.ChangeExpired, changes[0].Type)
+	assert.Equal(t, models.ChangeCreated, changes[1].Type)
+
+	item, err := s.ExpiredObject(ctx, 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"v":1}`, string(item.Body))
+}
@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// hooksBatchSize количество изменений, забираемых из журнала для хуков за один раз.
+const hooksBatchSize = 100
+
+// Имена хуков в логах и метриках.
+const (
+	hookBeforeSave = "before_save"
+	hookOnSave     = "on_save"
+	hookOnDelete   = "on_delete"
+	hookOnExpire   = "on_expire"
+)
+
+// ErrRejected возвращается из SaveObject когда запись отклонена хуком BeforeSave.
+var ErrRejected = errors.New("write rejected")
+
+// BeforeSaveHook вызывается синхронно перед сохранением объекта. Хук может вернуть изменённый объект
+// или ошибку, которая отменяет запись. Хук не должен вызывать методы изменения хранилища.
+type BeforeSaveHook func(ctx context.Context, item models.Item) (models.Item, error)
+
+// ChangeHook вызывается асинхронно после изменения объекта. Version объекта равна номеру изменения.
+// Паника в хуке перехватывается и не влияет на хранилище и другие хуки.
+type ChangeHook func(item models.Item)
+
+// ChangeListener вызывается синхронно при каждом изменении хранилища, в порядке изменений и под блокировкой
+// хранилища. В отличие от хуков изменений, слушатель получает все изменения, даже если запись опережает
+// чтение журнала. Слушатель не должен блокироваться и вызывать методы хранилища.
+type ChangeListener func(c models.Change)
+
+// hooks зарегистрированные хуки хранилища.
+type hooks struct {
+	m          sync.RWMutex
+	beforeSave []BeforeSaveHook
+	onSave     []ChangeHook
+	onDelete   []ChangeHook
+	onExpire   []ChangeHook
+	listeners  map[int]ChangeListener
+	nextID     int
+}
+
+// BeforeSave регистрирует хук, вызываемый перед каждым сохранением объекта. Хуки вызываются в порядке регистрации,
+// каждый получает объект, возвращённый предыдущим.
+func (s *Store) BeforeSave(hook BeforeSaveHook) {
+	s.hooks.m.Lock()
+	defer s.hooks.m.Unlock()
+
+	s.hooks.beforeSave = append(s.hooks.beforeSave, hook)
+}
+
+// OnSave регистрирует хук, вызываемый после создания или обновления объекта.
+func (s *Store) OnSave(hook ChangeHook) {
+	s.hooks.m.Lock()
+	defer s.hooks.m.Unlock()
+
+	s.hooks.onSave = append(s.hooks.onSave, hook)
+}
+
+// OnDelete регистрирует хук, вызываемый после удаления объекта. Хук получает последнее сохранённое тело объекта.
+func (s *Store) OnDelete(hook ChangeHook) {
+	s.hooks.m.Lock()
+	defer s.hooks.m.Unlock()
+
+	s.hooks.onDelete = append(s.hooks.onDelete, hook)
+}
+
+// OnExpire регистрирует хук, вызываемый после удаления объекта с истёкшим сроком жизни.
+func (s *Store) OnExpire(hook ChangeHook) {
+	s.hooks.m.Lock()
+	defer s.hooks.m.Unlock()
+
+	s.hooks.onExpire = append(s.hooks.onExpire, hook)
+}
+
+// Listen регистрирует слушателя изменений и возвращает функцию, которая его удаляет.
+func (s *Store) Listen(l ChangeListener) (cancel func()) {
+	s.hooks.m.Lock()
+	defer s.hooks.m.Unlock()
+
+	if s.hooks.listeners == nil {
+		s.hooks.listeners = make(map[int]ChangeListener)
+	}
+
+	id := s.hooks.nextID
+	s.hooks.nextID++
+	s.hooks.listeners[id] = l
+
+	return func() {
+		s.hooks.m.Lock()
+		defer s.hooks.m.Unlock()
+
+		delete(s.hooks.listeners, id)
+	}
+}
+
+// notifyListeners передаёт изменение слушателям. Вызывается под блокировкой хранилища.
+func (s *Store) notifyListeners(c models.Change) {
+	s.hooks.m.RLock()
+	defer s.hooks.m.RUnlock()
+
+	for _, l := range s.hooks.listeners {
+		l(c)
+	}
+}
+
+// runBeforeSave применяет хуки BeforeSave к объекту. Паника в хуке превращается в ошибку.
+func (s *Store) runBeforeSave(ctx context.Context, item models.Item) (models.Item, error) {
+	s.hooks.m.RLock()
+	before := s.hooks.beforeSave
+	s.hooks.m.RUnlock()
+
+	for _, hook := range before {
+		var err error
+
+		item, err = s.callBeforeSave(ctx, hook, item)
+		if err != nil {
+			return models.Item{}, err
+		}
+	}
+
+	return item, nil
+}
+
+func (s *Store) callBeforeSave(ctx context.Context, hook BeforeSaveHook, item models.Item) (res models.Item, err error) {
+	start := time.Now()
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Error("before save hook panicked", zap.Any("panic", r), zap.Int("id", item.ID))
+			s.metrics.hookDone(hookBeforeSave, resultPanic, start)
+
+			err = fmt.Errorf("%s hook panicked: %v", hookBeforeSave, r)
+		}
+	}()
+
+	id := item.ID
+
+	res, err = hook(ctx, item)
+	if err != nil {
+		s.metrics.hookDone(hookBeforeSave, resultRejected, start)
+
+		return models.Item{}, fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+
+	// хук может изменить тело и время жизни, но не id объекта.
+	res.ID = id
+
+	s.metrics.hookDone(hookBeforeSave, resultOK, start)
+
+	return res, nil
+}
+
+// hooksLoop вызывает хуки после изменений с номером больше last до остановки хранилища. Изменения читаются из журнала,
+// поэтому медленные хуки не задерживают запись; если хуки отстали больше чем на размер журнала,
+// пропущенные изменения учитываются в метриках. После сброса журнала к меньшему номеру (например, снимком ведущего)
+// хуки продолжают с нового номера, ничего не пропуская.
+func (s *Store) hooksLoop(last uint64) {
+	for {
+		changes, wait, err := s.changes.since(last, hooksBatchSize)
+		if err != nil {
+			next := s.changes.last()
+
+			if next > last {
+				s.log.Warn("change hooks lagged behind, changes skipped", zap.Uint64("after", last), zap.Uint64("until", next))
+				s.metrics.hookSkipped.Add(float64(next - last))
+			}
+
+			last = next
+
+			continue
+		}
+
+		for _, c := range changes {
+			last = c.Seq
+
+			s.runChangeHooks(c)
+		}
+
+		if len(changes) > 0 {
+			continue
+		}
+
+		select {
+		case <-s.done:
+			return
+		case <-wait:
+		}
+	}
+}
+
+// runChangeHooks вызывает хуки, зарегистрированные для типа изменения.
+func (s *Store) runChangeHooks(c models.Change) {
+	var (
+		name  string
+		hooks []ChangeHook
+	)
+
+	s.hooks.m.RLock()
+
+	switch c.Type {
+	case models.ChangeCreated, models.ChangeUpdated:
+		name, hooks = hookOnSave, s.hooks.onSave
+	case models.ChangeDeleted:
+		name, hooks = hookOnDelete, s.hooks.onDelete
+	case models.ChangeExpired:
+		name, hooks = hookOnExpire, s.hooks.onExpire
+	}
+
+	s.hooks.m.RUnlock()
+
+	item := models.Item{ID: c.ID, Body: c.Body, Version: c.Seq}
+
+	for _, hook := range hooks {
+		s.callChangeHook(name, hook, item)
+	}
+}
+
+func (s *Store) callChangeHook(name string, hook ChangeHook, item models.Item) {
+	start := time.Now()
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Error("change hook panicked", zap.String("hook", name), zap.Any("panic", r), zap.Int("id", item.ID))
+			s.metrics.hookDone(name, resultPanic, start)
+		}
+	}()
+
+	hook(item)
+
+	s.metrics.hookDone(name, resultOK, start)
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"st-test/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_BeforeSave(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+
+	s.BeforeSave(func(_ context.Context, item models.Item) (models.Item, error) {
+		if string(item.Body) == `{"forbidden":true}` {
+			return item, errors.New("forbidden body")
+		}
+
+		item.Body = []byte(`{"transformed":true}`)
+		item.ID = 100 // id изменить нельзя
+
+		return item, nil
+	})
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"forbidden":true}`)})
+	require.ErrorIs(t, err, ErrRejected)
+	assert.Contains(t, err.Error(), "forbidden body")
+
+	_, err = s.GetObject(ctx, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	item, err := s.GetObject(ctx, 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"transformed":true}`, string(item.Body))
+
+	s.BeforeSave(func(context.Context, models.Item) (models.Item, error) {
+		panic("boom")
+	})
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`)})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRejected)
+
+	assert.InDelta(t, 1, testutil.ToFloat64(s.metrics.hookCalls.WithLabelValues(hookBeforeSave, resultRejected)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(s.metrics.hookCalls.WithLabelValues(hookBeforeSave, resultPanic)), 0)
+}
+
+func TestStore_ChangeHooks(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+
+	var (
+		m      sync.Mutex
+		called []string
+	)
+
+	record := func(name string) ChangeHook {
+		return func(item models.Item) {
+			m.Lock()
+			defer m.Unlock()
+
+			called = append(called, name+":"+string(item.Body))
+		}
+	}
+
+	s.OnSave(func(models.Item) { panic("boom") })
+	s.OnSave(record("save"))
+	s.OnDelete(record("delete"))
+	s.OnExpire(record("expire"))
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`1`)})
+	require.NoError(t, err)
+	_, err = s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`2`)})
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteObject(ctx, 1))
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`3`), Expires: time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, 1, s.expire(time.Now().Add(time.Second)))
+
+	want := []string{"save:1", "save:2", "delete:2", "save:3", "expire:3"}
+
+	require.Eventually(t, func() bool {
+		m.Lock()
+		defer m.Unlock()
+
+		return len(called) == len(want)
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, want, called)
+	assert.InDelta(t, 3, testutil.ToFloat64(s.metrics.hookCalls.WithLabelValues(hookOnSave, resultPanic)), 0)
+}
+
+func TestStore_ChangeHooksReset(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+
+	var saved atomic.Int32
+
+	s.OnSave(func(models.Item) { saved.Add(1) })
+
+	for id := 1; id <= 3; id++ {
+		_, err := s.SaveObject(ctx, models.Item{ID: id, Body: []byte(`{}`)})
+		require.NoError(t, err)
+	}
+
+	require.Eventually(t, func() bool { return saved.Load() == 3 }, time.Second, 5*time.Millisecond)
+
+	// журнал сбрасывается к меньшему номеру: хуки продолжают с него, и пропущенных изменений нет.
+	// запись повторяется, пока хуки не увидят сброс.
+	s.changes.reset(1)
+
+	require.Eventually(t, func() bool {
+		_, err := s.SaveObject(ctx, models.Item{ID: 4, Body: []byte(`{}`)})
+		require.NoError(t, err)
+
+		return saved.Load() > 3
+	}, time.Second, 5*time.Millisecond)
+	assert.Zero(t, testutil.ToFloat64(s.metrics.hookSkipped))
+}
@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// largeObject крупный объект, учтённый в квоте владельца. Тела крупных объектов хранятся вне хранилища.
+type largeObject struct {
+	owner string
+	size  int64
+}
+
+// reservation место в квоте, занятое под крупный объект на время его записи.
+type reservation struct {
+	owner string
+	size  int64
+}
+
+// LoadLarge учитывает в квотах сохранённые крупные объекты objs. Вызывается при запуске до записи крупных объектов.
+func (s *Store) LoadLarge(_ context.Context, objs []models.LargeObject) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, obj := range objs {
+		if old, ok := s.large[obj.ID]; ok {
+			s.addUsage(old.owner, -1, -old.size)
+		}
+
+		s.large[obj.ID] = largeObject{owner: obj.Owner, size: obj.Size}
+		s.addUsage(obj.Owner, 1, obj.Size)
+	}
+}
+
+// ReserveLarge занимает в квоте клиента owner место под крупный объект id размером size, записываемый загрузкой
+// uploadID; прежний резерв этой загрузки освобождается. Объект с тем же id, который будет заменён, в квоте
+// не учитывается. Возвращает, сколько байт клиент может записать сверх резерва, или -1, если объём
+// не ограничен. Если квоты не хватает, возвращается ErrQuotaExceeded.
+func (s *Store) ReserveLarge(ctx context.Context, uploadID string, id int, owner string, size int64) (_ int64, err error) {
+	_, span, log := s.startSpan(ctx, "ReserveLarge", objectID(id))
+	defer func() { endSpan(span, err) }()
+
+	if s.replica {
+		return 0, ErrReadOnly
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.releaseLarge(uploadID)
+
+	var (
+		oldOwner string
+		oldSize  int64
+		replaced bool
+	)
+
+	if item, ok := s.s[id]; ok && !item.Expired(time.Now()) {
+		oldOwner, oldSize, replaced = item.Owner, int64(len(item.Body)), true
+	} else if large, ok := s.large[id]; ok {
+		oldOwner, oldSize, replaced = large.owner, large.size, true
+	}
+
+	if err := s.quotas.fits(owner, size, oldOwner, oldSize, replaced); err != nil {
+		s.metrics.quotaRejections.WithLabelValues(owner).Inc()
+		log.Info("large object was not reserved", zap.Int("id", id), zap.Error(err))
+
+		return 0, err
+	}
+
+	s.reserved[uploadID] = reservation{owner: owner, size: size}
+	s.addUsage(owner, 1, size)
+
+	remaining := s.quotas.remaining(owner)
+	if remaining >= 0 && replaced && s.quotas.sameAccount(oldOwner, owner) {
+		remaining += oldSize
+	}
+
+	return remaining, nil
+}
+
+// ReleaseLarge освобождает место в квоте, занятое загрузкой uploadID. Загрузка без резерва пропускается.
+func (s *Store) ReleaseLarge(_ context.Context, uploadID string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.releaseLarge(uploadID)
+}
+
+// releaseLarge освобождает резерв загрузки uploadID. Вызывается под блокировкой хранилища.
+func (s *Store) releaseLarge(uploadID string) {
+	r, ok := s.reserved[uploadID]
+	if !ok {
+		return
+	}
+
+	delete(s.reserved, uploadID)
+	s.addUsage(r.owner, -1, -r.size)
+}
+
+// PutLarge учитывает записанный крупный объект obj вместо резерва загрузки uploadID и публикует изменение
+// created или updated без тела. Обычный объект с тем же id заменяется крупным: он убирается из хранилища
+// без изменения deleted и без перемещения в корзину. Возвращает признак того, что объекта с таким id раньше не было.
+func (s *Store) PutLarge(ctx context.Context, uploadID string, obj models.LargeObject) bool {
+	_, span, log := s.startSpan(ctx, "PutLarge", objectID(obj.ID))
+	defer span.End()
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.releaseLarge(uploadID)
+
+	now := time.Now()
+	item := models.Item{ID: obj.ID, Owner: obj.Owner, ContentType: obj.ContentType, CreatedAt: now, UpdatedAt: obj.UpdatedAt}
+
+	// объект, срок жизни которого истёк, но который ещё не удалила фоновая проверка, истекает перед заменой.
+	old, ok := s.s[obj.ID]
+	if ok && old.Expired(now) {
+		s.expireItem(old, now)
+
+		ok = false
+	}
+
+	if ok {
+		s.dropItem(old)
+
+		if !old.CreatedAt.IsZero() {
+			item.CreatedAt = old.CreatedAt
+		}
+	}
+
+	if large, exists := s.large[obj.ID]; exists {
+		s.addUsage(large.owner, -1, -large.size)
+
+		ok = true
+	}
+
+	s.large[obj.ID] = largeObject{owner: obj.Owner, size: obj.Size}
+	s.addUsage(obj.Owner, 1, obj.Size)
+
+	change := models.ChangeCreated
+	if ok {
+		change = models.ChangeUpdated
+	}
+
+	s.publishChange(models.Change{Type: change, ID: obj.ID, Item: item, Large: true})
+	s.counters.writes++
+
+	log.Info("large object saved", zap.Int("id", obj.ID), zap.Int64("size", obj.Size))
+
+	return !ok
+}
+
+// DeleteLarge убирает крупный объект id из квоты владельца и публикует изменение deleted.
+// Если крупный объект не учтён (например, уже заменён обычным), ничего не происходит.
+func (s *Store) DeleteLarge(ctx context.Context, id int) {
+	_, span, _ := s.startSpan(ctx, "DeleteLarge", objectID(id))
+	defer span.End()
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	large, ok := s.large[id]
+	if !ok {
+		return
+	}
+
+	delete(s.large, id)
+	s.addUsage(large.owner, -1, -large.size)
+	s.publishChange(models.Change{Type: models.ChangeDeleted, ID: id, Item: models.Item{ID: id, Owner: large.owner}, Large: true})
+}
+
+// forgetLarge убирает из квоты крупный объект id, который заменяется обычным объектом. Тело крупного объекта
+// удаляет его хранилище. Вызывается под блокировкой хранилища.
+func (s *Store) forgetLarge(id int) {
+	if large, ok := s.large[id]; ok {
+		delete(s.large, id)
+		s.addUsage(large.owner, -1, -large.size)
+	}
+}
+
+// dropItem убирает объект item из хранилища без публикации изменения и без перемещения в корзину.
+// Вызывается под блокировкой хранилища.
+func (s *Store) dropItem(item models.Item) {
+	delete(s.s, item.ID)
+	s.unindexItem(item.ID)
+	s.untrack(item)
+	s.releaseBody(item.Body)
+}
+
+// addUsage изменяет объём клиента client на objects объектов и bytes байт и обновляет его метрики.
+// Вызывается под блокировкой хранилища.
+func (s *Store) addUsage(client string, objects int, bytes int64) {
+	s.quotas.addUsage(client, objects, bytes)
+
+	usage, ok := s.quotas.usage[client]
+	if !ok {
+		usage = models.Usage{Client: client}
+	}
+
+	s.metrics.usage(usage)
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/models"
+)
+
+func TestStore_LargeQuota(t *testing.T) {
+	t.Parallel()
+
+	s := testQuotaStore(t, WithQuotas(models.Quota{}, map[string]models.Quota{"importer": {MaxObjects: 2, MaxBytes: 100}}))
+	ctx := context.Background()
+
+	s.LoadLarge(ctx, []models.LargeObject{{ID: 1, Owner: "importer", Size: 40}})
+
+	// место под тело занимается до записи: остаётся 100 - 40 байт.
+	remaining, err := s.ReserveLarge(ctx, "up-2", 2, "importer", 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(60), remaining)
+
+	// загрузка уже заняла второй объект квоты.
+	_, err = s.SaveObject(ctx, models.Item{ID: 3, Body: []byte(`{}`), Owner: "importer"})
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+
+	_, err = s.ReserveLarge(ctx, "up-2", 2, "importer", 70)
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+
+	// отменённая загрузка освобождает место.
+	s.ReleaseLarge(ctx, "up-2")
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 3, Body: []byte(`{}`), Owner: "importer"})
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteObject(ctx, 3))
+
+	// заменяемый крупный объект в квоте не учитывается.
+	remaining, err = s.ReserveLarge(ctx, "up-1", 1, "importer", 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), remaining)
+
+	_, err = s.ReserveLarge(ctx, "up-1", 1, "importer", 90)
+	require.NoError(t, err)
+	require.False(t, s.PutLarge(ctx, "up-1", models.LargeObject{ID: 1, Owner: "importer", Size: 90}))
+
+	require.Equal(t, []models.Usage{
+		{Client: "importer", Objects: 1, Bytes: 90, Quota: models.Quota{MaxObjects: 2, MaxBytes: 100}},
+	}, s.Usage(ctx))
+
+	s.DeleteLarge(ctx, 1)
+
+	require.Equal(t, []models.Usage{
+		{Client: "importer", Quota: models.Quota{MaxObjects: 2, MaxBytes: 100}},
+	}, s.Usage(ctx))
+}
+
+func TestStore_PutLarge(t *testing.T) {
+	t.Parallel()
+
+	s, _ := testTrashStore(t)
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"a":1}`), Owner: "a"})
+	require.NoError(t, err)
+
+	remaining, err := s.ReserveLarge(ctx, "up", 1, "a", 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), remaining)
+
+	// крупный объект заменяет обычный без изменения deleted и без перемещения обычного в корзину.
+	require.False(t, s.PutLarge(ctx, "up", models.LargeObject{ID: 1, Owner: "a", Size: 10}))
+
+	_, err = s.GetObject(ctx, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	trashed, err := s.Trash(ctx, 0)
+	require.NoError(t, err)
+	require.Empty(t, trashed)
+
+	changes, _, err := s.Changes(0, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, models.ChangeUpdated, changes[1].Type)
+	require.True(t, changes[1].Large)
+	require.Empty(t, changes[1].Item.Body)
+
+	// обычный объект заменяет крупный как обновление, а крупный перестаёт учитываться в квоте.
+	id, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Owner: "a"})
+	require.NoError(t, err)
+	require.Zero(t, id)
+
+	require.Equal(t, []models.Usage{{Client: "a", Objects: 1, Bytes: 2, Shared: true}}, s.Usage(ctx))
+
+	// крупный объект, уже заменённый обычным, не удаляется повторно.
+	s.DeleteLarge(ctx, 1)
+
+	changes, _, err = s.Changes(0, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.Equal(t, models.ChangeUpdated, changes[2].Type)
+	require.False(t, changes[2].Large)
+}
+
+func TestStore_LargeReplica(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	replica := testReplica(t)
+
+	_, err := replica.ReserveLarge(ctx, "up", 1, "a", 0)
+	require.ErrorIs(t, err, ErrReadOnly)
+
+	require.NoError(t, replica.ApplyChange(ctx, models.Change{Seq: 1, Type: models.ChangeCreated, ID: 1,
+		Item: models.Item{ID: 1, Body: []byte(`{}`)}}))
+	require.NoError(t, replica.ApplyChange(ctx, models.Change{Seq: 2, Type: models.ChangeUpdated, ID: 1,
+		Item: models.Item{ID: 1}, Large: true}))
+
+	// тело крупного объекта не реплицируется, поэтому обычный объект с тем же id убирается.
+	_, err = replica.GetObject(ctx, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	changes, _, err := replica.Changes(0, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.True(t, changes[1].Large)
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// UpdateMetadata меняет метаданные и теги объекта id, не перезаписывая тело. Изменение получает новую версию
+// и публикуется как обновление объекта; хуки BeforeSave не вызываются. Если объекта нет, возвращается
+// models.ErrNotFound, если изменённые метаданные не прошли проверку — ошибка, оборачивающая models.ErrInvalidMetadata.
+func (s *Store) UpdateMetadata(ctx context.Context, id int, patch models.MetadataPatch) (_ models.Item, err error) {
+	ctx, span, log := s.startSpan(ctx, "UpdateMetadata", objectID(id))
+	defer func() { endSpan(span, err) }()
+
+	if s.replica {
+		return models.Item{}, ErrReadOnly
+	}
+
+	if res, ok := s.propose(ctx, Command{Type: CommandUpdateMetadata, ID: id, Patch: patch, Time: time.Now()}); ok {
+		return res.Item, res.Err
+	}
+
+	return s.updateMetadata(log, id, patch, time.Now())
+}
+
+// updateMetadata меняет метаданные и теги объекта id в момент now.
+func (s *Store) updateMetadata(log *zap.Logger, id int, patch models.MetadataPatch, now time.Time) (models.Item, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	item, ok := s.s[id]
+	if !ok || item.Expired(now) {
+		return models.Item{}, models.ErrNotFound
+	}
+
+	item, err := patch.Apply(item)
+	if err != nil {
+		return models.Item{}, err //nolint:wrapcheck
+	}
+
+	item.UpdatedAt = now
+	item.Version = s.publish(models.ChangeUpdated, item).Seq
+	s.s[id] = item
+
+	log.Info("item metadata updated", zap.Int("id", id))
+
+	return item, nil
+}
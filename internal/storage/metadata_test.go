@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/models"
+)
+
+func TestStore_UpdateMetadata(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+	body := []byte(`{"some":"body"}`)
+
+	_, err := s.SaveObject(ctx, models.Item{
+		ID:       1,
+		Body:     body,
+		Metadata: map[string]string{"author": "alice", "lang": "en"},
+		Tags:     []string{"draft"},
+	})
+	require.NoError(t, err)
+
+	saved, err := s.GetObject(ctx, 1)
+	require.NoError(t, err)
+
+	lang, tags := "ru", []string{"reviewed", "public", "reviewed"}
+
+	item, err := s.UpdateMetadata(ctx, 1, models.MetadataPatch{
+		Metadata: map[string]*string{"Lang": &lang, "author": nil},
+		Tags:     &tags,
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"lang": "ru"}, item.Metadata)
+	require.Equal(t, []string{"public", "reviewed"}, item.Tags)
+	require.Equal(t, body, item.Body)
+	require.Greater(t, item.Version, saved.Version)
+
+	// изменение публикуется как обновление объекта.
+	changes, _, err := s.Changes(saved.Version, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, models.ChangeUpdated, changes[0].Type)
+
+	// без Tags теги остаются прежними.
+	item, err = s.UpdateMetadata(ctx, 1, models.MetadataPatch{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"public", "reviewed"}, item.Tags)
+
+	invalid := []string{"a,b"}
+
+	_, err = s.UpdateMetadata(ctx, 1, models.MetadataPatch{Tags: &invalid})
+	require.ErrorIs(t, err, models.ErrInvalidMetadata)
+
+	_, err = s.UpdateMetadata(ctx, 2, models.MetadataPatch{})
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	got, err := s.GetObject(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, got.HasMetadata(map[string]string{"lang": "ru"}, []string{"reviewed"}))
+	require.False(t, got.HasMetadata(nil, []string{"draft"}))
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"time"
+
+	"st-test/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Результаты вызова хуков в метриках.
+const (
+	resultOK       = "ok"
+	resultRejected = "rejected"
+	resultPanic    = "panic"
+)
+
+// Результаты чтения объектов в метриках.
+const (
+	resultHit  = "hit"
+	resultMiss = "miss"
+)
+
+// Операции с репозиторием в метриках ошибок.
+const (
+	repoRead  = "read"
+	repoWrite = "write"
+	repoSize  = "size"
+)
+
+// metrics метрики хранилища.
+type metrics struct {
+	hookCalls    *prometheus.CounterVec
+	hookDuration *prometheus.HistogramVec
+	hookSkipped  prometheus.Counter
+
+	objects  prometheus.Gauge
+	bytes    prometheus.Gauge
+	reads    *prometheus.CounterVec
+	snapshot prometheus.Histogram
+	// repoErrors ошибки обращений к репозиторию по операции.
+	repoErrors *prometheus.CounterVec
+
+	clientObjects   *prometheus.GaugeVec
+	clientBytes     *prometheus.GaugeVec
+	quotaRejections *prometheus.CounterVec
+
+	blobs     prometheus.Gauge
+	blobBytes prometheus.Gauge
+
+	expired          prometheus.Counter
+	expiredObjects   prometheus.Gauge
+	expiredEvictions prometheus.Counter
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		hookCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "store",
+			Name:      "hook_calls_total",
+			Help:      "Number of store hook calls by hook and result.",
+		}, []string{"hook", "result"}),
+		hookDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "store",
+			Name:      "hook_duration_seconds",
+			Help:      "Duration of store hook calls.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"hook"}),
+		hookSkipped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "store",
+			Name:      "hook_skipped_changes_total",
+			Help:      "Number of changes not passed to after-change hooks because the hooks lagged behind.",
+		}),
+		objects: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "store",
+			Name:      "objects",
+			Help:      "Number of objects in the store.",
+		}),
+		bytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "store",
+			Name:      "bytes",
+			Help:      "Total size of object bodies in the store before deduplication.",
+		}),
+		reads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "store",
+			Name:      "reads_total",
+			Help:      "Number of object reads by result: hit or miss.",
+		}, []string{"result"}),
+		snapshot: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "store",
+			Name:      "snapshot_duration_seconds",
+			Help:      "Duration of writing all objects to the repository.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+		}),
+		repoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "store",
+			Name:      "repo_errors_total",
+			Help:      "Number of failed repository operations by operation.",
+		}, []string{"op"}),
+		clientObjects: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "store",
+			Name:      "client_objects",
+			Help:      "Number of objects stored by client.",
+		}, []string{"client"}),
+		clientBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "store",
+			Name:      "client_bytes",
+			Help:      "Size of object bodies stored by client.",
+		}, []string{"client"}),
+		quotaRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "store",
+			Name:      "quota_rejections_total",
+			Help:      "Number of writes rejected because they would exceed the client quota.",
+		}, []string{"client"}),
+		blobs: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "store",
+			Name:      "blobs",
+			Help:      "Number of distinct object bodies kept in memory.",
+		}),
+		blobBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "store",
+			Name:      "blob_bytes",
+			Help:      "Size of distinct object bodies kept in memory.",
+		}),
+		expired: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "store",
+			Name:      "expired_objects_total",
+			Help:      "Number of objects removed because their lifetime expired.",
+		}),
+		expiredObjects: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "store",
+			Name:      "expired_area_objects",
+			Help:      "Number of expired objects kept for debugging.",
+		}),
+		expiredEvictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "store",
+			Name:      "expired_area_evictions_total",
+			Help:      "Number of expired objects evicted from the expired area because it was full.",
+		}),
+	}
+}
+
+func (m *metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.hookCalls, m.hookDuration, m.hookSkipped,
+		m.objects, m.bytes, m.reads, m.snapshot, m.repoErrors,
+		m.clientObjects, m.clientBytes, m.quotaRejections,
+		m.blobs, m.blobBytes,
+		m.expired, m.expiredObjects, m.expiredEvictions,
+	}
+}
+
+// usage обновляет объём клиента; клиенты без объектов убираются из метрик.
+func (m *metrics) usage(u models.Usage) {
+	if u.Objects == 0 {
+		m.clientObjects.DeleteLabelValues(u.Client)
+		m.clientBytes.DeleteLabelValues(u.Client)
+
+		return
+	}
+
+	m.clientObjects.WithLabelValues(u.Client).Set(float64(u.Objects))
+	m.clientBytes.WithLabelValues(u.Client).Set(float64(u.Bytes))
+}
+
+// stored учитывает объект в общем количестве и объёме объектов, sign = 1 при добавлении и -1 при удалении.
+func (m *metrics) stored(item models.Item, sign int) {
+	m.objects.Add(float64(sign))
+	m.bytes.Add(float64(sign * len(item.Body)))
+}
+
+func (m *metrics) hookDone(hook, result string, start time.Time) {
+	m.hookCalls.WithLabelValues(hook, result).Inc()
+	m.hookDuration.WithLabelValues(hook).Observe(time.Since(start).Seconds())
+}
+
+// WithMetrics регистрирует метрики хранилища в reg.
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(s *Store) {
+		reg.MustRegister(s.metrics.collectors()...)
+	}
+}
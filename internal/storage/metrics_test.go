@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"st-test/internal/models"
+	"st-test/internal/storage/mocks"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStore_Metrics(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	reg := prometheus.NewRegistry()
+	s := NewStore(zap.NewNop(), repo, WithMetrics(reg))
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"v":1}`)})
+	require.NoError(t, err)
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`)})
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteObject(ctx, 2))
+
+	_, err = s.GetObject(ctx, 1)
+	require.NoError(t, err)
+	_, err = s.GetObject(ctx, 2)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	assert.InDelta(t, 1, testutil.ToFloat64(s.metrics.objects), 0)
+	assert.InDelta(t, 7, testutil.ToFloat64(s.metrics.bytes), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(s.metrics.reads.WithLabelValues(resultHit)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(s.metrics.reads.WithLabelValues(resultMiss)), 0)
+
+	repo.EXPECT().ReplaceAll(mock.Anything, mock.AnythingOfType("[]models.Item"), mock.Anything).Once().Return(errors.New("some error"))
+
+	s.Stop()
+
+	assert.InDelta(t, 1, testutil.ToFloat64(s.metrics.repoErrors.WithLabelValues(repoWrite)), 0)
+
+	count, err := testutil.GatherAndCount(reg, "store_snapshot_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
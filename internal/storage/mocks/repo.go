@@ -3,6 +3,7 @@
 package mocks
 
 import (
+	context "context"
 	models "st-test/internal/models"
 
 	mock "github.com/stretchr/testify/mock"
@@ -21,17 +22,189 @@ func (_m *Repo) EXPECT() *Repo_Expecter {
 	return &Repo_Expecter{mock: &_m.Mock}
 }
 
-// DeleteAll provides a mock function with given fields:
-func (_m *Repo) DeleteAll() error {
-	ret := _m.Called()
+// LastSeq provides a mock function with given fields: ctx
+func (_m *Repo) LastSeq(ctx context.Context) (uint64, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteAll")
+		panic("no return value specified for LastSeq")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (uint64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) uint64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_LastSeq_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LastSeq'
+type Repo_LastSeq_Call struct {
+	*mock.Call
+}
+
+// LastSeq is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Repo_Expecter) LastSeq(ctx interface{}) *Repo_LastSeq_Call {
+	return &Repo_LastSeq_Call{Call: _e.mock.On("LastSeq", ctx)}
+}
+
+func (_c *Repo_LastSeq_Call) Run(run func(ctx context.Context)) *Repo_LastSeq_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Repo_LastSeq_Call) Return(_a0 uint64, _a1 error) *Repo_LastSeq_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_LastSeq_Call) RunAndReturn(run func(context.Context) (uint64, error)) *Repo_LastSeq_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReadAll provides a mock function with given fields: ctx
+func (_m *Repo) ReadAll(ctx context.Context) ([]models.Item, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadAll")
+	}
+
+	var r0 []models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.Item, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Item); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_ReadAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReadAll'
+type Repo_ReadAll_Call struct {
+	*mock.Call
+}
+
+// ReadAll is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Repo_Expecter) ReadAll(ctx interface{}) *Repo_ReadAll_Call {
+	return &Repo_ReadAll_Call{Call: _e.mock.On("ReadAll", ctx)}
+}
+
+func (_c *Repo_ReadAll_Call) Run(run func(ctx context.Context)) *Repo_ReadAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Repo_ReadAll_Call) Return(_a0 []models.Item, _a1 error) *Repo_ReadAll_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_ReadAll_Call) RunAndReturn(run func(context.Context) ([]models.Item, error)) *Repo_ReadAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReadTrash provides a mock function with given fields: ctx
+func (_m *Repo) ReadTrash(ctx context.Context) ([]models.TrashedItem, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadTrash")
+	}
+
+	var r0 []models.TrashedItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.TrashedItem, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []models.TrashedItem); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TrashedItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_ReadTrash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReadTrash'
+type Repo_ReadTrash_Call struct {
+	*mock.Call
+}
+
+// ReadTrash is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Repo_Expecter) ReadTrash(ctx interface{}) *Repo_ReadTrash_Call {
+	return &Repo_ReadTrash_Call{Call: _e.mock.On("ReadTrash", ctx)}
+}
+
+func (_c *Repo_ReadTrash_Call) Run(run func(ctx context.Context)) *Repo_ReadTrash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Repo_ReadTrash_Call) Return(_a0 []models.TrashedItem, _a1 error) *Repo_ReadTrash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_ReadTrash_Call) RunAndReturn(run func(context.Context) ([]models.TrashedItem, error)) *Repo_ReadTrash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReplaceAll provides a mock function with given fields: ctx, items, seq
+func (_m *Repo) ReplaceAll(ctx context.Context, items []models.Item, seq uint64) error {
+	ret := _m.Called(ctx, items, seq)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceAll")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, []models.Item, uint64) error); ok {
+		r0 = rf(ctx, items, seq)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -39,44 +212,47 @@ func (_m *Repo) DeleteAll() error {
 	return r0
 }
 
-// Repo_DeleteAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAll'
-type Repo_DeleteAll_Call struct {
+// Repo_ReplaceAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReplaceAll'
+type Repo_ReplaceAll_Call struct {
 	*mock.Call
 }
 
-// DeleteAll is a helper method to define mock.On call
-func (_e *Repo_Expecter) DeleteAll() *Repo_DeleteAll_Call {
-	return &Repo_DeleteAll_Call{Call: _e.mock.On("DeleteAll")}
+// ReplaceAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - items []models.Item
+//   - seq uint64
+func (_e *Repo_Expecter) ReplaceAll(ctx interface{}, items interface{}, seq interface{}) *Repo_ReplaceAll_Call {
+	return &Repo_ReplaceAll_Call{Call: _e.mock.On("ReplaceAll", ctx, items, seq)}
 }
 
-func (_c *Repo_DeleteAll_Call) Run(run func()) *Repo_DeleteAll_Call {
+func (_c *Repo_ReplaceAll_Call) Run(run func(ctx context.Context, items []models.Item, seq uint64)) *Repo_ReplaceAll_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context), args[1].([]models.Item), args[2].(uint64))
 	})
 	return _c
 }
 
-func (_c *Repo_DeleteAll_Call) Return(_a0 error) *Repo_DeleteAll_Call {
+func (_c *Repo_ReplaceAll_Call) Return(_a0 error) *Repo_ReplaceAll_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repo_DeleteAll_Call) RunAndReturn(run func() error) *Repo_DeleteAll_Call {
+func (_c *Repo_ReplaceAll_Call) RunAndReturn(run func(context.Context, []models.Item, uint64) error) *Repo_ReplaceAll_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Insert provides a mock function with given fields: item
-func (_m *Repo) Insert(item models.Item) error {
-	ret := _m.Called(item)
+// ReplaceTrash provides a mock function with given fields: ctx, items
+func (_m *Repo) ReplaceTrash(ctx context.Context, items []models.TrashedItem) error {
+	ret := _m.Called(ctx, items)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Insert")
+		panic("no return value specified for ReplaceTrash")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(models.Item) error); ok {
-		r0 = rf(item)
+	if rf, ok := ret.Get(0).(func(context.Context, []models.TrashedItem) error); ok {
+		r0 = rf(ctx, items)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -84,57 +260,56 @@ func (_m *Repo) Insert(item models.Item) error {
 	return r0
 }
 
-// Repo_Insert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Insert'
-type Repo_Insert_Call struct {
+// Repo_ReplaceTrash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReplaceTrash'
+type Repo_ReplaceTrash_Call struct {
 	*mock.Call
 }
 
-// Insert is a helper method to define mock.On call
-//   - item models.Item
-func (_e *Repo_Expecter) Insert(item interface{}) *Repo_Insert_Call {
-	return &Repo_Insert_Call{Call: _e.mock.On("Insert", item)}
+// ReplaceTrash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - items []models.TrashedItem
+func (_e *Repo_Expecter) ReplaceTrash(ctx interface{}, items interface{}) *Repo_ReplaceTrash_Call {
+	return &Repo_ReplaceTrash_Call{Call: _e.mock.On("ReplaceTrash", ctx, items)}
 }
 
-func (_c *Repo_Insert_Call) Run(run func(item models.Item)) *Repo_Insert_Call {
+func (_c *Repo_ReplaceTrash_Call) Run(run func(ctx context.Context, items []models.TrashedItem)) *Repo_ReplaceTrash_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(models.Item))
+		run(args[0].(context.Context), args[1].([]models.TrashedItem))
 	})
 	return _c
 }
 
-func (_c *Repo_Insert_Call) Return(_a0 error) *Repo_Insert_Call {
+func (_c *Repo_ReplaceTrash_Call) Return(_a0 error) *Repo_ReplaceTrash_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repo_Insert_Call) RunAndReturn(run func(models.Item) error) *Repo_Insert_Call {
+func (_c *Repo_ReplaceTrash_Call) RunAndReturn(run func(context.Context, []models.TrashedItem) error) *Repo_ReplaceTrash_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ReadAll provides a mock function with given fields:
-func (_m *Repo) ReadAll() ([]models.Item, error) {
-	ret := _m.Called()
+// Size provides a mock function with given fields: ctx
+func (_m *Repo) Size(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ReadAll")
+		panic("no return value specified for Size")
 	}
 
-	var r0 []models.Item
+	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]models.Item, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func() []models.Item); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Item)
-		}
+		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -142,29 +317,30 @@ func (_m *Repo) ReadAll() ([]models.Item, error) {
 	return r0, r1
 }
 
-// Repo_ReadAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReadAll'
-type Repo_ReadAll_Call struct {
+// Repo_Size_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Size'
+type Repo_Size_Call struct {
 	*mock.Call
 }
 
-// ReadAll is a helper method to define mock.On call
-func (_e *Repo_Expecter) ReadAll() *Repo_ReadAll_Call {
-	return &Repo_ReadAll_Call{Call: _e.mock.On("ReadAll")}
+// Size is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Repo_Expecter) Size(ctx interface{}) *Repo_Size_Call {
+	return &Repo_Size_Call{Call: _e.mock.On("Size", ctx)}
 }
 
-func (_c *Repo_ReadAll_Call) Run(run func()) *Repo_ReadAll_Call {
+func (_c *Repo_Size_Call) Run(run func(ctx context.Context)) *Repo_Size_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *Repo_ReadAll_Call) Return(_a0 []models.Item, _a1 error) *Repo_ReadAll_Call {
+func (_c *Repo_Size_Call) Return(_a0 int64, _a1 error) *Repo_Size_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repo_ReadAll_Call) RunAndReturn(run func() ([]models.Item, error)) *Repo_ReadAll_Call {
+func (_c *Repo_Size_Call) RunAndReturn(run func(context.Context) (int64, error)) *Repo_Size_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"st-test/internal/models"
+)
+
+// ErrQuotaExceeded возвращается когда запись превысила бы квоту клиента.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// quotas квоты клиентов и занимаемый ими объём.
+type quotas struct {
+	def     models.Quota
+	clients map[string]models.Quota
+	usage   map[string]models.Usage
+	// shared общий объём клиентов без отдельной квоты, который ограничивает квота def.
+	shared models.Usage
+}
+
+// WithQuotas ограничивает количество и суммарный размер объектов каждого клиента из clients.
+// def — общая квота всех остальных клиентов, в том числе клиента без идентификатора: иначе клиент мог бы
+// обойти ограничение, записывая объекты под новыми идентификаторами.
+func WithQuotas(def models.Quota, clients map[string]models.Quota) Option {
+	return func(s *Store) {
+		s.quotas.def = def
+		s.quotas.clients = clients
+	}
+}
+
+// quota возвращает квоту клиента и признак того, что она общая для клиентов без отдельной квоты.
+func (q *quotas) quota(client string) (models.Quota, bool) {
+	if quota, ok := q.clients[client]; ok {
+		return quota, false
+	}
+
+	return q.def, true
+}
+
+// account возвращает квоту клиента и объём, который она ограничивает: объём самого клиента или общий объём
+// клиентов без отдельной квоты.
+func (q *quotas) account(client string) (models.Quota, models.Usage) {
+	quota, shared := q.quota(client)
+	if shared {
+		return quota, q.shared
+	}
+
+	return quota, q.usage[client]
+}
+
+// sameAccount сообщает, учитываются ли объекты клиентов a и b в одном объёме.
+func (q *quotas) sameAccount(a, b string) bool {
+	_, sharedA := q.quota(a)
+	_, sharedB := q.quota(b)
+
+	return a == b || sharedA && sharedB
+}
+
+// check проверяет, что замена объекта old (если ok) на item не превысит квоту владельца item.
+func (q *quotas) check(item, old models.Item, ok bool) error {
+	return q.fits(item.Owner, int64(len(item.Body)), old.Owner, int64(len(old.Body)), ok)
+}
+
+// fits проверяет, что объект клиента owner размером size, заменяющий объект клиента oldOwner размером oldSize
+// (если replaced), не превысит квоту owner.
+func (q *quotas) fits(owner string, size int64, oldOwner string, oldSize int64, replaced bool) error {
+	quota, usage := q.account(owner)
+
+	usage.Objects++
+	usage.Bytes += size
+
+	if replaced && q.sameAccount(oldOwner, owner) {
+		usage.Objects--
+		usage.Bytes -= oldSize
+	}
+
+	if quota.MaxObjects > 0 && usage.Objects > quota.MaxObjects {
+		return fmt.Errorf("%w: client %q may store at most %d objects", ErrQuotaExceeded, owner, quota.MaxObjects)
+	}
+
+	if quota.MaxBytes > 0 && usage.Bytes > quota.MaxBytes {
+		return fmt.Errorf("%w: client %q may store at most %d bytes", ErrQuotaExceeded, owner, quota.MaxBytes)
+	}
+
+	return nil
+}
+
+// remaining возвращает, сколько байт клиент client может записать сверх занятого объёма, или -1, если объём
+// не ограничен.
+func (q *quotas) remaining(client string) int64 {
+	quota, usage := q.account(client)
+	if quota.MaxBytes <= 0 {
+		return -1
+	}
+
+	return max(0, quota.MaxBytes-usage.Bytes)
+}
+
+// add учитывает объект в объёме его владельца, sign = 1 при добавлении и -1 при удалении.
+func (q *quotas) add(item models.Item, sign int) {
+	q.addUsage(item.Owner, sign, int64(sign*len(item.Body)))
+}
+
+// addUsage добавляет к объёму клиента client objects объектов и bytes байт; отрицательные значения уменьшают объём.
+func (q *quotas) addUsage(client string, objects int, bytes int64) {
+	if _, shared := q.quota(client); shared {
+		q.shared.Objects += objects
+		q.shared.Bytes += bytes
+	}
+
+	usage := q.usage[client]
+	usage.Client = client
+	usage.Objects += objects
+	usage.Bytes += bytes
+
+	if usage.Objects == 0 {
+		delete(q.usage, client)
+
+		return
+	}
+
+	q.usage[client] = usage
+}
+
+// track учитывает добавление объекта в хранилище и его метрики. Вызывается под блокировкой хранилища.
+func (s *Store) track(item models.Item) {
+	s.quotas.add(item, 1)
+	s.metrics.usage(s.quotas.usage[item.Owner])
+	s.metrics.stored(item, 1)
+}
+
+// untrack учитывает удаление объекта из хранилища и его метрики. Вызывается под блокировкой хранилища.
+func (s *Store) untrack(item models.Item) {
+	s.quotas.add(item, -1)
+
+	usage, ok := s.quotas.usage[item.Owner]
+	if !ok {
+		usage = models.Usage{Client: item.Owner}
+	}
+
+	s.metrics.usage(usage)
+	s.metrics.stored(item, -1)
+}
+
+// Usage возвращает объём, занимаемый объектами каждого клиента, отсортированный по клиенту.
+// Клиенты с отдельной квотой возвращаются, даже если у них нет объектов. У клиентов без отдельной квоты
+// выставлен Shared: их квота ограничивает суммарный объём всех таких клиентов.
+func (s *Store) Usage(_ context.Context) []models.Usage {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	res := make([]models.Usage, 0, len(s.quotas.usage)+len(s.quotas.clients))
+
+	for client, usage := range s.quotas.usage {
+		usage.Quota, usage.Shared = s.quotas.quota(client)
+		res = append(res, usage)
+	}
+
+	for client, quota := range s.quotas.clients {
+		if _, ok := s.quotas.usage[client]; !ok {
+			res = append(res, models.Usage{Client: client, Quota: quota})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Client < res[j].Client })
+
+	return res
+}
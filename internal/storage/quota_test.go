@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"st-test/internal/models"
+	"st-test/internal/storage/mocks"
+)
+
+func testQuotaStore(t *testing.T, opts ...Option) *Store {
+	t.Helper()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	return NewStore(zap.NewNop(), repo, opts...)
+}
+
+func TestStore_Quotas(t *testing.T) {
+	t.Parallel()
+
+	s := testQuotaStore(t, WithQuotas(
+		models.Quota{MaxObjects: 2},
+		map[string]models.Quota{"small": {MaxBytes: 10}},
+	))
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Owner: "a"})
+	require.NoError(t, err)
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`), Owner: "a"})
+	require.NoError(t, err)
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 3, Body: []byte(`{}`), Owner: "a"})
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+
+	// обновление своего объекта не увеличивает количество объектов.
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{"a":1}`), Owner: "a"})
+	require.NoError(t, err)
+
+	// объект переходит к клиенту, который записал его последним.
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{"a":1}`), Owner: "small"})
+	require.NoError(t, err)
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 3, Body: []byte(`{}`), Owner: "a"})
+	require.NoError(t, err)
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 4, Body: []byte(`{"b":2}`), Owner: "small"})
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+
+	require.NoError(t, s.DeleteObject(ctx, 2))
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 4, Body: []byte(`{"b":2}`), Owner: "small"})
+	require.NoError(t, err)
+
+	require.Equal(t, []models.Usage{
+		{Client: "a", Objects: 2, Bytes: 4, Quota: models.Quota{MaxObjects: 2}, Shared: true},
+		{Client: "small", Objects: 1, Bytes: 7, Quota: models.Quota{MaxBytes: 10}},
+	}, s.Usage(ctx))
+
+	require.Equal(t, float64(2), testutil.ToFloat64(s.metrics.clientObjects.WithLabelValues("a")))
+	require.Equal(t, float64(7), testutil.ToFloat64(s.metrics.clientBytes.WithLabelValues("small")))
+	require.Equal(t, float64(1), testutil.ToFloat64(s.metrics.quotaRejections.WithLabelValues("small")))
+}
+
+func TestStore_QuotasShared(t *testing.T) {
+	t.Parallel()
+
+	s := testQuotaStore(t, WithQuotas(models.Quota{MaxObjects: 2}, map[string]models.Quota{"own": {MaxObjects: 1}}))
+	ctx := context.Background()
+
+	// клиенты без отдельной квоты делят общую квоту: новый идентификатор не даёт новой квоты.
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Owner: "a"})
+	require.NoError(t, err)
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 3, Body: []byte(`{}`), Owner: "b"})
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+
+	// перезапись объекта другим клиентом без отдельной квоты не увеличивает общий объём.
+	_, err = s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Owner: "b"})
+	require.NoError(t, err)
+
+	// клиент с отдельной квотой не расходует общую.
+	_, err = s.SaveObject(ctx, models.Item{ID: 3, Body: []byte(`{}`), Owner: "own"})
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteObject(ctx, 2))
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 4, Body: []byte(`{}`), Owner: "c"})
+	require.NoError(t, err)
+
+	require.Equal(t, []models.Usage{
+		{Client: "b", Objects: 1, Bytes: 2, Quota: models.Quota{MaxObjects: 2}, Shared: true},
+		{Client: "c", Objects: 1, Bytes: 2, Quota: models.Quota{MaxObjects: 2}, Shared: true},
+		{Client: "own", Objects: 1, Bytes: 2, Quota: models.Quota{MaxObjects: 1}},
+	}, s.Usage(ctx))
+}
+
+func TestStore_QuotasExpire(t *testing.T) {
+	t.Parallel()
+
+	s := testQuotaStore(t, WithQuotas(models.Quota{MaxObjects: 1}, nil))
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Expires: time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`)})
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+
+	s.expire(time.Now().Add(time.Second))
+
+	require.Empty(t, s.Usage(ctx))
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`)})
+	require.NoError(t, err)
+}
+
+func TestStore_QuotasRestore(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+	repo.EXPECT().ReadTrash(mock.Anything).Once().Return(nil, nil)
+
+	s := NewStore(zap.NewNop(), repo, WithTrash(time.Hour), WithQuotas(models.Quota{MaxObjects: 1}, nil))
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteObject(ctx, 1))
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	_, err = s.RestoreObject(ctx, 1)
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// ErrReplicaGap возвращается когда изменение ведущего не следует за последним применённым:
+// часть изменений пропущена, и реплике нужно заново загрузить снимок ведущего.
+var ErrReplicaGap = errors.New("change does not follow the last applied one")
+
+// ErrReadOnly возвращается при попытке записи в реплику: объекты реплики меняются только изменениями ведущего.
+var ErrReadOnly = errors.New("store is a read-only replica")
+
+// WithReplica переводит хранилище в режим реплики ведущего экземпляра. Реплика получает объекты только через
+// ApplySnapshot и ApplyChange и нумерует свои изменения так же, как ведущий, поэтому объекты с истёкшим сроком
+// жизни удаляются по изменениям ведущего, а не фоновой проверкой. Истёкшие объекты при этом уже не читаются.
+// Запись в реплику возвращает ErrReadOnly.
+func WithReplica() Option {
+	return func(s *Store) {
+		s.replica = true
+	}
+}
+
+// Snapshot возвращает все объекты хранилища вместе с номером последнего изменения, учтённого в них,
+// и объекты корзины, если она включена. Изменения после Seq можно получить через Changes.
+func (s *Store) Snapshot(ctx context.Context) (_ models.Snapshot, err error) {
+	_, span, _ := s.startSpan(ctx, "ReadSnapshot")
+	defer func() { endSpan(span, err) }()
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	snap := models.Snapshot{
+		Seq:   s.changes.last(),
+		Items: make([]models.Item, 0, len(s.s)),
+	}
+
+	for _, item := range s.s {
+		snap.Items = append(snap.Items, item)
+	}
+
+	if s.trash != nil {
+		snap.Trash = make([]models.TrashedItem, 0, len(s.trash))
+		for _, item := range s.trash {
+			snap.Trash = append(snap.Trash, item)
+		}
+	}
+
+	return snap, nil
+}
+
+// ApplySnapshot заменяет все объекты хранилища объектами снимка ведущего. Журнал изменений очищается
+// и продолжается с номера снимка; объекты сохраняют версии ведущего, хуки и квоты к ним не применяются.
+// Корзина заменяется корзиной снимка, если она включена и снимок снят с корзиной.
+func (s *Store) ApplySnapshot(ctx context.Context, snap models.Snapshot) (err error) {
+	_, span, log := s.startSpan(ctx, "ApplySnapshot")
+	defer func() { endSpan(span, err) }()
+
+	s.applySnapshot(log, snap)
+
+	return nil
+}
+
+// applySnapshot заменяет объекты и корзину хранилища объектами снимка snap.
+func (s *Store) applySnapshot(log *zap.Logger, snap models.Snapshot) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for id, item := range s.s {
+		delete(s.s, id)
+		s.unindexItem(id)
+		s.untrack(item)
+		s.releaseBody(item.Body)
+	}
+
+	for _, item := range snap.Items {
+		item.Body = s.acquireBody(item.Body)
+		s.s[item.ID] = item
+		s.indexItem(item)
+		s.track(item)
+	}
+
+	if s.trash != nil && snap.Trash != nil {
+		for id, item := range s.trash {
+			delete(s.trash, id)
+			s.releaseBody(item.Body)
+		}
+
+		for _, item := range snap.Trash {
+			item.Body = s.acquireBody(item.Body)
+			s.trash[item.ID] = item
+		}
+	}
+
+	s.changes.reset(snap.Seq)
+
+	// ожидающие изменения объектов перечитывают их уже из нового снимка.
+	for id, w := range s.watchers {
+		close(w.ch)
+		delete(s.watchers, id)
+	}
+
+	log.Info("snapshot applied", zap.Uint64("seq", snap.Seq), zap.Int("count", len(snap.Items)))
+}
+
+// ApplyChange применяет изменение ведущего. Изменения, которые уже применены, пропускаются;
+// если изменение не следует за последним применённым, возвращается ErrReplicaGap.
+// Объект сохраняет версию ведущего, хуки и квоты к нему не применяются; продление срока жизни (touched)
+// меняет только срок жизни объекта.
+func (s *Store) ApplyChange(ctx context.Context, c models.Change) (err error) {
+	_, span, _ := s.startSpan(ctx, "ApplyChange", objectID(c.ID))
+	defer func() { endSpan(span, err) }()
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	last := s.changes.last()
+	if c.Seq <= last {
+		return nil
+	}
+
+	if c.Seq != last+1 {
+		return fmt.Errorf("%w: got %d after %d", ErrReplicaGap, c.Seq, last)
+	}
+
+	old, ok := s.s[c.ID]
+
+	// тела крупных объектов не реплицируются: реплика только убирает обычный объект, заменённый крупным,
+	// и занимает номер изменения.
+	if c.Large {
+		if ok && (c.Type == models.ChangeCreated || c.Type == models.ChangeUpdated) {
+			s.dropItem(old)
+		}
+
+		s.publishChange(models.Change{Type: c.Type, ID: c.ID, Item: c.Item, Large: true})
+
+		return nil
+	}
+
+	switch c.Type {
+	case models.ChangeCreated, models.ChangeUpdated:
+		item := c.Item
+		item.Body = s.acquireBody(item.Body)
+
+		if ok {
+			s.untrack(old)
+			s.releaseBody(old.Body)
+		}
+
+		item.Version = s.publish(c.Type, item).Seq
+		s.s[item.ID] = item
+		s.indexItem(item)
+		s.track(item)
+	case models.ChangeDeleted:
+		if ok {
+			delete(s.s, c.ID)
+			s.unindexItem(c.ID)
+			s.untrack(old)
+			s.moveToTrash(old, c.Time)
+		}
+
+		s.publish(c.Type, c.Item)
+	case models.ChangeExpired:
+		if !ok {
+			s.publish(c.Type, c.Item)
+
+			break
+		}
+
+		s.expireItem(old, c.Time)
+	case models.ChangeTouched:
+		if !ok {
+			s.publish(c.Type, c.Item)
+
+			break
+		}
+
+		old.Expires, old.ExpiresAt, old.Sliding = c.Item.Expires, c.Item.ExpiresAt, c.Item.Sliding
+		s.s[c.ID] = old
+		s.publish(c.Type, old)
+	default:
+		// неизвестное изменение всё равно занимает свой номер, чтобы не разойтись с нумерацией ведущего.
+		s.publish(c.Type, c.Item)
+	}
+
+	return nil
+}
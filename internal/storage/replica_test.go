@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testReplica(t *testing.T) *Store {
+	t.Helper()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	return NewStore(zap.NewNop(), repo, WithReplica())
+}
+
+func TestStore_Replication(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	leader := testStore(t)
+	replica := testReplica(t)
+
+	_, err := leader.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"a":1}`)})
+	require.NoError(t, err)
+	_, err = leader.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{"b":2}`), Expires: time.Hour})
+	require.NoError(t, err)
+
+	snap, err := leader.Snapshot(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), snap.Seq)
+	require.Len(t, snap.Items, 2)
+
+	require.NoError(t, replica.ApplySnapshot(ctx, snap))
+	require.Equal(t, snap.Seq, replica.LastChange())
+
+	_, err = replica.SaveObject(ctx, models.Item{ID: 3, Body: []byte(`{}`)})
+	require.ErrorIs(t, err, ErrReadOnly)
+	require.ErrorIs(t, replica.DeleteObject(ctx, 1), ErrReadOnly)
+
+	got, err := replica.GetObject(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), got.Version)
+	assert.Equal(t, `{"b":2}`, string(got.Body))
+
+	_, err = leader.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"a":2}`)})
+	require.NoError(t, err)
+	require.NoError(t, leader.DeleteObject(ctx, 2))
+
+	changes, _, err := leader.Changes(snap.Seq, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+
+	for _, c := range changes {
+		require.NoError(t, replica.ApplyChange(ctx, c))
+	}
+
+	// повторное изменение пропускается, пропуск изменений обнаруживается.
+	require.NoError(t, replica.ApplyChange(ctx, changes[0]))
+	require.ErrorIs(t, replica.ApplyChange(ctx, models.Change{Seq: 10, Type: models.ChangeDeleted, ID: 1}), ErrReplicaGap)
+
+	require.Equal(t, leader.LastChange(), replica.LastChange())
+
+	want, err := leader.GetObject(ctx, 1)
+	require.NoError(t, err)
+	got, err = replica.GetObject(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, want.Version, got.Version)
+	assert.Equal(t, want.Body, got.Body)
+
+	_, err = replica.GetObject(ctx, 2)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	// новый снимок заменяет все объекты реплики.
+	require.NoError(t, replica.ApplySnapshot(ctx, models.Snapshot{Seq: 20, Items: []models.Item{{ID: 3, Body: []byte(`{}`), Version: 20}}}))
+	require.Equal(t, uint64(20), replica.LastChange())
+
+	_, err = replica.GetObject(ctx, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	assert.Len(t, replica.s, 1)
+}
+
+func TestStore_ReplicaExpiry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	replica := testReplica(t)
+	now := time.Now()
+
+	require.NoError(t, replica.ApplySnapshot(ctx, models.Snapshot{Seq: 1, Items: []models.Item{
+		{ID: 1, Body: []byte(`{}`), Version: 1, ExpiresAt: now.Add(-time.Second)},
+	}}))
+
+	// истёкший объект не читается, но остаётся до изменения ведущего.
+	_, err := replica.GetObject(ctx, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+	require.Len(t, replica.s, 1)
+
+	require.NoError(t, replica.ApplyChange(ctx, models.Change{Seq: 2, Type: models.ChangeExpired, ID: 1, Time: now}))
+	require.Empty(t, replica.s)
+	require.Equal(t, uint64(2), replica.LastChange())
+}
+
+func TestStore_ReplicaSlidingTTL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	leader := testStore(t)
+	replica := testReplica(t)
+
+	_, err := leader.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Expires: time.Hour, Sliding: true})
+	require.NoError(t, err)
+
+	snap, err := leader.Snapshot(ctx)
+	require.NoError(t, err)
+	require.NoError(t, replica.ApplySnapshot(ctx, snap))
+
+	// чтение реплики срок жизни не продлевает и изменений не публикует.
+	before := replica.s[1].ExpiresAt
+
+	_, err = replica.GetObject(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, before, replica.s[1].ExpiresAt)
+	assert.Equal(t, snap.Seq, replica.LastChange())
+
+	// продление на ведущем публикуется изменением touched без новой версии, и реплика его применяет.
+	time.Sleep(time.Millisecond)
+
+	_, err = leader.GetObject(ctx, 1)
+	require.NoError(t, err)
+
+	changes, _, err := leader.Changes(snap.Seq, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, models.ChangeTouched, changes[0].Type)
+	assert.Equal(t, snap.Items[0].Version, changes[0].Item.Version)
+
+	require.NoError(t, replica.ApplyChange(ctx, changes[0]))
+	assert.True(t, replica.s[1].ExpiresAt.After(before))
+	assert.Equal(t, leader.s[1].ExpiresAt, replica.s[1].ExpiresAt)
+	assert.Equal(t, leader.s[1].Version, replica.s[1].Version)
+}
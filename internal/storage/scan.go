@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	// defaultScanBatch количество объектов, копируемых из хранилища за один захват мьютекса.
+	defaultScanBatch = 256
+)
+
+// ScanOptions параметры потокового сканирования хранилища.
+type ScanOptions struct {
+	// Cursor курсор продолжения, полученный из предыдущего ScanResult. Пустой курсор — сканирование с начала.
+	Cursor string
+	// Limit максимальное количество подходящих объектов в результате. 0 — без ограничения.
+	Limit int
+	// Budget бюджет времени на сканирование. По его истечении возвращается частичный результат. 0 — без ограничения.
+	Budget time.Duration
+	// Batch количество объектов, обрабатываемых за один захват мьютекса.
+	Batch int
+}
+
+// ScanResult результат потокового сканирования хранилища.
+type ScanResult struct {
+	// Items подходящие объекты в порядке возрастания id.
+	Items []models.Item
+	// Cursor курсор для продолжения сканирования. Пустой, если хранилище просмотрено полностью.
+	Cursor string
+	// Partial признак того, что сканирование прервано по бюджету времени.
+	Partial bool
+	// Scanned количество просмотренных объектов.
+	Scanned int
+}
+
+// Scan последовательно просматривает объекты в порядке возрастания id и возвращает те, для которых match вернул true.
+// Мьютекс хранилища захватывается только на время копирования очередной пачки объектов, а проверка объектов
+// выполняется без блокировки, поэтому долгий скан не блокирует обычные запросы.
+func (s *Store) Scan(ctx context.Context, opts ScanOptions, match func(item models.Item) bool) (_ ScanResult, err error) {
+	ctx, span, _ := s.startSpan(ctx, "Scan")
+	defer func() { endSpan(span, err) }()
+
+	after, hasAfter, err := decodeCursor(opts.Cursor)
+	if err != nil {
+		return ScanResult{}, err
+	}
+
+	batch := opts.Batch
+	if batch <= 0 {
+		batch = defaultScanBatch
+	}
+
+	var deadline time.Time
+	if opts.Budget > 0 {
+		deadline = time.Now().Add(opts.Budget)
+	}
+
+	s.m.Lock()
+	keys := maps.Keys(s.s)
+	s.m.Unlock()
+
+	slices.Sort(keys)
+
+	if hasAfter {
+		idx, _ := slices.BinarySearch(keys, after+1)
+		keys = keys[idx:]
+	}
+
+	res := ScanResult{Items: make([]models.Item, 0)}
+
+	for len(keys) > 0 {
+		if err := ctx.Err(); err != nil {
+			return ScanResult{}, fmt.Errorf("scan: %w", err)
+		}
+
+		n := min(batch, len(keys))
+		items := s.batch(keys[:n])
+
+		for i, item := range items {
+			res.Scanned++
+
+			if !match(item) {
+				continue
+			}
+
+			res.Items = append(res.Items, item)
+
+			if opts.Limit > 0 && len(res.Items) >= opts.Limit {
+				if i < len(items)-1 || n < len(keys) {
+					res.Cursor = encodeCursor(item.ID)
+				}
+
+				return res, nil
+			}
+		}
+
+		last := keys[n-1]
+		keys = keys[n:]
+
+		if !deadline.IsZero() && len(keys) > 0 && time.Now().After(deadline) {
+			res.Cursor = encodeCursor(last)
+			res.Partial = true
+
+			s.log.Info("scan budget exceeded", zap.Int("scanned", res.Scanned), zap.Int("found", len(res.Items)))
+
+			return res, nil
+		}
+	}
+
+	return res, nil
+}
+
+// batch копирует объекты с переданными id под мьютексом. Объекты, удалённые после снятия списка id
+// или с истёкшим сроком жизни, пропускаются.
+func (s *Store) batch(keys []int) []models.Item {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	items := make([]models.Item, 0, len(keys))
+	now := time.Now()
+
+	for _, id := range keys {
+		if item, ok := s.s[id]; ok && !item.Expired(now) {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, bool, error) {
+	if cursor == "" {
+		return 0, false, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	id, err := strconv.Atoi(string(raw))
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	return id, true, nil
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"st-test/internal/models"
+	"st-test/internal/storage/mocks"
+)
+
+func testStore(t *testing.T) *Store {
+	t.Helper()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	return NewStore(log, repo)
+}
+
+func TestStore_Scan(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+
+	for id := 1; id <= 10; id++ {
+		s.s[id] = models.Item{ID: id, Body: []byte(`{}`)}
+	}
+
+	even := func(item models.Item) bool { return item.ID%2 == 0 }
+
+	t.Run("all matches", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := s.Scan(context.Background(), ScanOptions{}, even)
+		require.NoError(t, err)
+		require.Len(t, res.Items, 5)
+		require.Equal(t, 2, res.Items[0].ID)
+		require.Empty(t, res.Cursor)
+		require.False(t, res.Partial)
+		require.Equal(t, 10, res.Scanned)
+	})
+
+	t.Run("pagination by limit", func(t *testing.T) {
+		t.Parallel()
+
+		ids := make([]int, 0)
+		cursor := ""
+
+		for {
+			res, err := s.Scan(context.Background(), ScanOptions{Cursor: cursor, Limit: 2, Batch: 3}, even)
+			require.NoError(t, err)
+
+			for _, item := range res.Items {
+				ids = append(ids, item.ID)
+			}
+
+			if res.Cursor == "" {
+				break
+			}
+
+			cursor = res.Cursor
+		}
+
+		require.Equal(t, []int{2, 4, 6, 8, 10}, ids)
+	})
+
+	t.Run("budget exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		slow := func(item models.Item) bool {
+			time.Sleep(5 * time.Millisecond)
+
+			return true
+		}
+
+		res, err := s.Scan(context.Background(), ScanOptions{Budget: time.Millisecond, Batch: 1}, slow)
+		require.NoError(t, err)
+		require.True(t, res.Partial)
+		require.NotEmpty(t, res.Cursor)
+		require.Len(t, res.Items, 1)
+
+		res, err = s.Scan(context.Background(), ScanOptions{Cursor: res.Cursor}, slow)
+		require.NoError(t, err)
+		require.False(t, res.Partial)
+		require.Len(t, res.Items, 9)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := s.Scan(context.Background(), ScanOptions{Cursor: "???"}, even)
+		require.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := s.Scan(ctx, ScanOptions{}, even)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
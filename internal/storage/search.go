@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// ErrSearchDisabled возвращается когда полнотекстовый индекс не включён.
+var ErrSearchDisabled = errors.New("full-text search is disabled")
+
+// SearchHit найденный объект и его оценка релевантности.
+type SearchHit struct {
+	Item  models.Item
+	Score float64
+}
+
+// SearchResult страница результатов полнотекстового поиска.
+type SearchResult struct {
+	Hits  []SearchHit
+	Total int
+}
+
+// Search выполняет полнотекстовый поиск по строковым значениям объектов и возвращает страницу результатов.
+func (s *Store) Search(ctx context.Context, query string, offset, limit int) (_ SearchResult, err error) {
+	_, span, _ := s.startSpan(ctx, "Search")
+	defer func() { endSpan(span, err) }()
+
+	if s.index == nil {
+		return SearchResult{}, ErrSearchDisabled
+	}
+
+	res, err := s.index.Search(query, offset, limit)
+	if err != nil {
+		return SearchResult{}, fmt.Errorf("search: %w", err)
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	hits := make([]SearchHit, 0, len(res.Hits))
+	now := time.Now()
+
+	for _, hit := range res.Hits {
+		item, ok := s.s[hit.ID]
+		if !ok || item.Expired(now) {
+			continue
+		}
+
+		hits = append(hits, SearchHit{Item: item, Score: hit.Score})
+	}
+
+	return SearchResult{Hits: hits, Total: res.Total}, nil
+}
+
+// indexItem добавляет объект в полнотекстовый индекс, если он включён. Тела не json (изображения, текст)
+// не индексируются, а прежнее содержимое объекта убирается из индекса.
+func (s *Store) indexItem(item models.Item) {
+	if s.index == nil {
+		return
+	}
+
+	if err := s.index.Put(item.ID, item.Body); err != nil {
+		s.index.Delete(item.ID)
+		s.log.Debug("item is not indexed", zap.Int("id", item.ID), zap.String("content type", item.ContentType), zap.Error(err))
+	}
+}
+
+// unindexItem удаляет объект из полнотекстового индекса, если он включён.
+func (s *Store) unindexItem(id int) {
+	if s.index == nil {
+		return
+	}
+
+	s.index.Delete(id)
+}
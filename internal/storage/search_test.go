@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"st-test/internal/models"
+	"st-test/internal/search"
+	"st-test/internal/storage/mocks"
+)
+
+func TestStore_Search(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return([]models.Item{
+		{ID: 1, Body: []byte(`{"name":"loaded from repo"}`)},
+	}, nil)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	s := NewStore(log, repo, WithSearchIndex(search.NewIndex()))
+
+	_, err = s.SaveObject(context.Background(), models.Item{ID: 2, Body: []byte(`{"name":"saved object"}`)})
+	require.NoError(t, err)
+
+	res, err := s.Search(context.Background(), "loaded", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, res.Total)
+	require.Equal(t, 1, res.Hits[0].Item.ID)
+
+	res, err = s.Search(context.Background(), "obj*", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, res.Total)
+	require.Equal(t, []byte(`{"name":"saved object"}`), res.Hits[0].Item.Body)
+
+	// тело не json не индексируется и убирает из индекса прежнее тело объекта.
+	_, err = s.SaveObject(context.Background(), models.Item{ID: 1, Body: []byte(`loaded as text`), ContentType: "text/plain"})
+	require.NoError(t, err)
+
+	res, err = s.Search(context.Background(), "loaded", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, res.Total)
+
+	require.NoError(t, s.DeleteObject(context.Background(), 2))
+
+	res, err = s.Search(context.Background(), "obj*", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, res.Total)
+
+	_, err = s.Search(context.Background(), "", 0, 10)
+	require.ErrorIs(t, err, search.ErrEmptyQuery)
+
+	disabled := testStore(t)
+
+	_, err = disabled.Search(context.Background(), "object", 0, 10)
+	require.ErrorIs(t, err, ErrSearchDisabled)
+}
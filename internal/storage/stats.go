@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"st-test/internal/models"
+)
+
+// sizeBuckets верхние границы корзин гистограммы размеров тел объектов.
+func sizeBuckets() []int64 {
+	return []int64{1 << 10, 16 << 10, 256 << 10, 1 << 20, 16 << 20}
+}
+
+// counters счётчики обращений к хранилищу с запуска. Изменяются под блокировкой хранилища.
+type counters struct {
+	reads  uint64
+	writes uint64
+	misses uint64
+}
+
+// Stats возвращает сводные сведения о хранилище и размер файла репозитория.
+func (s *Store) Stats(ctx context.Context) (_ models.StoreStats, err error) {
+	ctx, span, _ := s.startSpan(ctx, "Stats")
+	defer func() { endSpan(span, err) }()
+
+	repoBytes, err := s.repo.Size(ctx)
+	if err != nil {
+		s.metrics.repoErrors.WithLabelValues(repoSize).Inc()
+
+		return models.StoreStats{}, fmt.Errorf("getting repo size: %w", err)
+	}
+
+	bounds := sizeBuckets()
+
+	stats := models.StoreStats{
+		Sizes:     make([]models.SizeBucket, len(bounds)+1),
+		RepoBytes: repoBytes,
+	}
+
+	for i, le := range bounds {
+		stats.Sizes[i].Le = le
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	now := time.Now()
+
+	for _, item := range s.s {
+		if item.Expired(now) {
+			continue
+		}
+
+		size := int64(len(item.Body))
+
+		stats.Objects++
+		stats.Bytes += size
+		stats.Sizes[sizeBucket(bounds, size)].Count++
+
+		if item.ExpiresAt.IsZero() {
+			continue
+		}
+
+		stats.WithTTL++
+
+		if stats.NextExpiry.IsZero() || item.ExpiresAt.Before(stats.NextExpiry) {
+			stats.NextExpiry = item.ExpiresAt
+		}
+	}
+
+	stats.Reads, stats.Writes, stats.Misses = s.counters.reads, s.counters.writes, s.counters.misses
+	stats.LastSnapshot = s.lastSnapshot
+
+	return stats, nil
+}
+
+// sizeBucket возвращает номер корзины гистограммы для тела размера size.
+func sizeBucket(bounds []int64, size int64) int {
+	for i, le := range bounds {
+		if size <= le {
+			return i
+		}
+	}
+
+	return len(bounds)
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStore_Stats(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	s := NewStore(zap.NewNop(), repo)
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: make([]byte, 2<<10), Expires: time.Hour})
+	require.NoError(t, err)
+	_, err = s.SaveObject(ctx, models.Item{ID: 3, Body: make([]byte, 32<<20), Expires: time.Minute})
+	require.NoError(t, err)
+
+	_, err = s.GetObject(ctx, 1)
+	require.NoError(t, err)
+	_, err = s.GetObject(ctx, 4)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	repo.EXPECT().Size(mock.Anything).Once().Return(int64(4096), nil)
+
+	stats, err := s.Stats(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, stats.Objects)
+	assert.Equal(t, int64(2+2<<10+32<<20), stats.Bytes)
+	assert.Equal(t, []models.SizeBucket{
+		{Le: 1 << 10, Count: 1}, {Le: 16 << 10, Count: 1}, {Le: 256 << 10}, {Le: 1 << 20}, {Le: 16 << 20}, {Count: 1},
+	}, stats.Sizes)
+	assert.Equal(t, 2, stats.WithTTL)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), stats.NextExpiry, time.Second)
+	assert.Equal(t, uint64(1), stats.Reads)
+	assert.Equal(t, uint64(3), stats.Writes)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.True(t, stats.LastSnapshot.IsZero())
+	assert.Equal(t, int64(4096), stats.RepoBytes)
+
+	repo.EXPECT().Size(mock.Anything).Once().Return(int64(0), errors.New("some error"))
+
+	_, err = s.Stats(ctx)
+	require.Error(t, err)
+}
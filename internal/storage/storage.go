@@ -4,91 +4,402 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"sync"
+	"time"
 
 	"st-test/internal/models"
+	"st-test/internal/search"
+	"st-test/internal/tracing"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"golang.org/x/exp/maps"
 )
 
 var errNotAvailable = errors.New("store is not available")
 
+// ErrInvalidCursor возвращается когда передан некорректный курсор продолжения сканирования.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 // repo описывает методы хранилища для сохранения и получения объектов на диск.
 //
 //go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=repo --with-expecter=true --exported
 type repo interface {
-	Insert(item models.Item) error
-	ReadAll() ([]models.Item, error)
-	DeleteAll() error
+	ReadAll(ctx context.Context) ([]models.Item, error)
+	ReplaceAll(ctx context.Context, items []models.Item, seq uint64) error
+	LastSeq(ctx context.Context) (uint64, error)
+	ReadTrash(ctx context.Context) ([]models.TrashedItem, error)
+	ReplaceTrash(ctx context.Context, items []models.TrashedItem) error
+	Size(ctx context.Context) (int64, error)
 }
 
 // Store является локальным хранилищем объектов в оперативной памяти.
 type Store struct {
-	log  *zap.Logger
-	s    map[int]models.Item
-	m    sync.Mutex
-	repo repo
+	log      *zap.Logger
+	s        map[int]models.Item
+	m        sync.Mutex
+	repo     repo
+	index    *search.Index
+	changes  *changeLog
+	watchers map[int]*watcher
+	hooks    hooks
+	metrics  *metrics
+	quotas   quotas
+	trash    map[int]models.TrashedItem
+	// large крупные объекты, учтённые в квотах, reserved место в квотах, занятое записываемыми крупными объектами.
+	large    map[int]largeObject
+	reserved map[string]reservation
+	// expired объекты с истёкшим сроком жизни, оставленные для отладки; nil, если область не включена.
+	expired map[int]models.ExpiredItem
+	// blobs общие тела объектов хранилища и корзины по их контрольной сумме sha256.
+	blobs map[[sha256.Size]byte]*blob
+	// trashRetention время хранения объектов в корзине.
+	trashRetention time.Duration
+	// expiredRetention время хранения и maxExpired наибольшее количество объектов с истёкшим сроком жизни.
+	expiredRetention time.Duration
+	maxExpired       int
+	// counters счётчики обращений к хранилищу; lastSnapshot время последней записи объектов в репозиторий.
+	counters     counters
+	lastSnapshot time.Time
+	// tracer трассировщик операций хранилища; без WithTracer спаны не записываются.
+	tracer trace.Tracer
+	// replica выставляется в режиме реплики: объекты меняются только изменениями ведущего.
+	replica bool
+	// proposer реплицирует записи в хранилище; nil, если записи применяются на месте.
+	proposer Proposer
+	// loadFailed выставляется, если объекты не удалось прочитать из репозитория (например, не расшифровались);
+	// в этом случае при остановке репозиторий не перезаписывается, чтобы не потерять данные на диске.
+	loadFailed bool
+	done       chan struct{}
+	stopOnce   sync.Once
+}
+
+// Option описывает необязательную настройку хранилища.
+type Option func(s *Store)
+
+// WithSearchIndex включает полнотекстовый индекс, который обновляется при каждом изменении хранилища.
+func WithSearchIndex(index *search.Index) Option {
+	return func(s *Store) {
+		s.index = index
+	}
 }
 
 // NewStore конструктор для хранилища. Так же после успешного создания пытаемся прочитать объекты из файла.
-func NewStore(log *zap.Logger, repo repo) *Store {
+func NewStore(log *zap.Logger, repo repo, opts ...Option) *Store {
 	s := &Store{
-		log:  log.Named("store"),
-		s:    make(map[int]models.Item),
-		repo: repo,
+		log:      log.Named("store"),
+		s:        make(map[int]models.Item),
+		repo:     repo,
+		changes:  newChangeLog(defaultChangeLogSize),
+		watchers: make(map[int]*watcher),
+		metrics:  newMetrics(),
+		quotas:   quotas{usage: make(map[string]models.Usage)},
+		blobs:    make(map[[sha256.Size]byte]*blob),
+		large:    make(map[int]largeObject),
+		reserved: make(map[string]reservation),
+		tracer:   tracing.Noop().Tracer(tracerName),
+		done:     make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// загрузка и запись объектов не относятся ни к одному запросу, поэтому их спаны корневые.
+	ctx, span, _ := s.startSpan(context.Background(), "Load")
+	if s.proposer == nil {
+		s.loadItems(ctx)
+		s.loadTrash(ctx)
 	}
+	span.End()
 
-	s.loadItems()
+	go s.expireLoop()
+	go s.hooksLoop(s.changes.last())
 
 	return s
 }
 
 // Stop "останавливает" работу хранилища и записывает все текущие объекты в файл.
 func (s *Store) Stop() {
-	s.saveItems()
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+
+	ctx, span, _ := s.startSpan(context.Background(), "Snapshot")
+	defer span.End()
+
+	s.saveItems(ctx)
+	s.saveTrash(ctx)
+}
+
+// SaveObject сохраняет объект в хранилище. Если объект с таким id уже есть, он заменяется и возвращается id = 0.
+// Перед сохранением объект проходит через хуки BeforeSave; если хук отклонил запись, возвращается ErrRejected.
+// Если запись превысит квоту владельца объекта, возвращается ErrQuotaExceeded. Срок жизни объекта задаётся
+// его Expires или ExpiresAt; с KeepTTL перезаписываемый объект сохраняет прежний срок жизни.
+func (s *Store) SaveObject(ctx context.Context, item models.Item) (_ int, err error) {
+	ctx, span, log := s.startSpan(ctx, "SaveObject", objectID(item.ID))
+	defer func() { endSpan(span, err) }()
+
+	if s.replica {
+		return 0, ErrReadOnly
+	}
+
+	saved, err := s.runBeforeSave(ctx, item)
+	if err != nil {
+		log.Info("the item was not saved", zap.Int("id", item.ID), zap.Error(err))
+
+		return 0, err
+	}
+
+	if res, ok := s.propose(ctx, Command{Type: CommandSave, ID: saved.ID, Item: saved, Time: time.Now()}); ok {
+		return res.ID, res.Err
+	}
+
+	return s.saveObject(log, saved, time.Now())
 }
 
-// SaveObject сохраняет объект в хранилище.
-func (s *Store) SaveObject(_ context.Context, item models.Item) (int, error) {
+// saveObject сохраняет объект, уже прошедший хуки BeforeSave, в момент now.
+func (s *Store) saveObject(log *zap.Logger, item models.Item, now time.Time) (int, error) {
 	s.m.Lock()
 	defer s.m.Unlock()
-	s.log.Info("New item request", zap.Int("id", item.ID), zap.Int64("expires", int64(item.Expires)))
+	log.Info("New item request", zap.Int("id", item.ID), zap.Int64("expires", int64(item.Expires)))
+
+	// объект, срок жизни которого истёк, но который ещё не удалила фоновая проверка, истекает перед перезаписью.
+	old, ok := s.s[item.ID]
+	if ok && old.Expired(now) {
+		s.expireItem(old, now)
+
+		ok = false
+	}
+
+	item = withTTL(item, old, ok, now)
+
+	// крупный объект с тем же id заменяется обычным.
+	large, replacesLarge := s.large[item.ID]
+
+	err := s.quotas.check(item, old, ok)
+	if replacesLarge {
+		err = s.quotas.fits(item.Owner, int64(len(item.Body)), large.owner, large.size, true)
+	}
+
+	if err != nil {
+		s.metrics.quotaRejections.WithLabelValues(item.Owner).Inc()
+		log.Info("the item was not saved", zap.Int("id", item.ID), zap.Error(err))
+
+		return 0, err
+	}
+
+	// ссылка на новое тело добавляется до освобождения старого, чтобы не пересоздавать неизменившееся тело.
+	item.Body = s.acquireBody(item.Body)
 
-	_, ok := s.s[item.ID]
 	if ok {
-		s.log.Info("the item has already been saved")
+		s.untrack(old)
+		s.releaseBody(old.Body)
+	}
 
-		return 0, nil
+	change := models.ChangeCreated
+	if ok || replacesLarge {
+		change = models.ChangeUpdated
+	}
+
+	s.forgetLarge(item.ID)
+
+	// время создания сохраняется при перезаписи объекта.
+	item.CreatedAt, item.UpdatedAt = now, now
+	if change == models.ChangeUpdated && !old.CreatedAt.IsZero() {
+		item.CreatedAt = old.CreatedAt
 	}
 
+	item.Version = s.publish(change, item).Seq
 	s.s[item.ID] = item
+	s.indexItem(item)
+	s.track(item)
+	s.counters.writes++
+
+	if change == models.ChangeUpdated {
+		log.Info("the item has already been saved, updated")
+
+		return 0, nil
+	}
 
-	s.log.Info("the object was saved successfully")
+	log.Info("the object was saved successfully")
 
 	return item.ID, nil
 }
 
-// GetObject возвращает объект из хранилища по id.
-func (s *Store) GetObject(_ context.Context, id int) (models.Item, error) {
+// DeleteObject удаляет объект из хранилища по id. Если включена корзина, объект перемещается в неё.
+func (s *Store) DeleteObject(ctx context.Context, id int) (err error) {
+	ctx, span, log := s.startSpan(ctx, "DeleteObject", objectID(id))
+	defer func() { endSpan(span, err) }()
+
+	if s.replica {
+		return ErrReadOnly
+	}
+
+	if res, ok := s.propose(ctx, Command{Type: CommandDelete, ID: id, Time: time.Now()}); ok {
+		return res.Err
+	}
+
+	return s.deleteObject(log, id, time.Now())
+}
+
+// deleteObject удаляет объект id в момент now.
+func (s *Store) deleteObject(log *zap.Logger, id int, now time.Time) error {
 	s.m.Lock()
 	defer s.m.Unlock()
 
-	s.log.Info("Request on get item", zap.Int("id", id))
+	log.Info("Request on delete item", zap.Int("id", id))
 
 	item, ok := s.s[id]
-	if !ok {
-		s.log.Info("Item not found", zap.Int("id", id))
+	if !ok || item.Expired(now) {
+		log.Info("Item not found", zap.Int("id", id))
+
+		return models.ErrNotFound
+	}
+
+	delete(s.s, id)
+	s.unindexItem(id)
+	s.untrack(item)
+	s.moveToTrash(item, now)
+	s.publish(models.ChangeDeleted, item)
+
+	log.Info("Item was deleted", zap.Int("id", id))
+
+	return nil
+}
+
+// GetObject возвращает объект из хранилища по id. Скользящий срок жизни объекта отсчитывается заново;
+// при репликации записей продление реплицируется записью CommandTouch.
+func (s *Store) GetObject(ctx context.Context, id int) (_ models.Item, err error) {
+	ctx, span, log := s.startSpan(ctx, "GetObject", objectID(id))
+	defer func() { endSpan(span, err) }()
+
+	log.Info("Request on get item", zap.Int("id", id))
+
+	now := time.Now()
+
+	item, err := s.getObject(id, now)
+	if err != nil {
+		log.Info("Item not found", zap.Int("id", id))
+
+		return models.Item{}, err
+	}
+
+	if item.Sliding {
+		if res, ok := s.propose(ctx, Command{Type: CommandTouch, ID: id, Time: now}); ok {
+			// объект всё равно возвращается: продление не удалось, но сам объект прочитан.
+			if res.Err != nil {
+				log.Warn("cannot extend sliding ttl", zap.Int("id", id), zap.Error(res.Err))
+			} else {
+				item.ExpiresAt = res.Item.ExpiresAt
+			}
+		}
+	}
+
+	log.Info("Item was found", zap.Int("id", id))
+
+	return item, nil
+}
+
+// getObject возвращает объект id, читаемый в момент now, и продлевает его скользящий срок жизни,
+// если записи применяются на месте. Продление публикуется изменением touched; реплика срок жизни
+// не продлевает, а получает продление от ведущего.
+func (s *Store) getObject(id int, now time.Time) (models.Item, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	item, ok := s.s[id]
+	if !ok || item.Expired(now) {
+		s.counters.misses++
+		s.metrics.reads.WithLabelValues(resultMiss).Inc()
 
 		return models.Item{}, models.ErrNotFound
 	}
 
-	s.log.Info("Item was found", zap.Int("id", id))
+	s.counters.reads++
+	s.metrics.reads.WithLabelValues(resultHit).Inc()
+
+	// чтение продлевает скользящий срок жизни.
+	if item.Sliding && s.proposer == nil && !s.replica {
+		item.ExpiresAt = now.Add(item.Expires)
+		s.s[id] = item
+		s.publish(models.ChangeTouched, item)
+	}
 
 	return item, nil
 }
 
+// StatObject возвращает сведения об объекте id без его тела. Если объекта нет, возвращается models.ErrNotFound.
+func (s *Store) StatObject(ctx context.Context, id int) (_ models.Stat, err error) {
+	_, span, _ := s.startSpan(ctx, "StatObject", objectID(id))
+	defer func() { endSpan(span, err) }()
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	item, ok := s.s[id]
+	if !ok || item.Expired(time.Now()) {
+		return models.Stat{}, models.ErrNotFound
+	}
+
+	return item.Stat(), nil
+}
+
+// WaitObject возвращает объект, как только его версия станет больше version.
+// Если объекта нет, ожидает его создания. При отмене ctx возвращает ошибку контекста.
+func (s *Store) WaitObject(ctx context.Context, id int, version uint64) (_ models.Item, err error) {
+	ctx, span, _ := s.startSpan(ctx, "WaitObject", objectID(id))
+	defer func() { endSpan(span, err) }()
+
+	for {
+		s.m.Lock()
+
+		item, ok := s.s[id]
+		if ok && !item.Expired(time.Now()) && item.Version > version {
+			s.m.Unlock()
+
+			return item, nil
+		}
+
+		w, ok := s.watchers[id]
+		if !ok {
+			w = &watcher{ch: make(chan struct{})}
+			s.watchers[id] = w
+		}
+
+		w.n++
+
+		s.m.Unlock()
+
+		select {
+		case <-ctx.Done():
+			s.unwatch(id, w)
+
+			return models.Item{}, ctx.Err() //nolint:wrapcheck
+		case <-w.ch:
+		}
+	}
+}
+
+// watcher канал, который закрывается при изменении объекта, и количество ожидающих его.
+type watcher struct {
+	ch chan struct{}
+	n  int
+}
+
+// unwatch снимает ожидание w изменения объекта id. Когда ожидающих не остаётся, w удаляется,
+// чтобы ожидания объектов, которые так и не изменились, не копились в памяти.
+func (s *Store) unwatch(id int, w *watcher) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	w.n--
+	if w.n == 0 && s.watchers[id] == w {
+		delete(s.watchers, id)
+	}
+}
+
 // Check возвращает состояние хранилища. Необходим для обработчика здоровья.
 func (s *Store) Check() error {
 	if s.s == nil {
@@ -98,16 +409,32 @@ func (s *Store) Check() error {
 	return nil
 }
 
-func (s *Store) loadItems() {
-	items, err := s.repo.ReadAll()
-	if err != nil {
-		if errors.Is(err, models.ErrNotFound) {
-			s.log.Info("no items in local repo")
+func (s *Store) loadItems(ctx context.Context) {
+	items, err := s.repo.ReadAll(ctx)
+	if errors.Is(err, models.ErrCorrupted) {
+		// повреждённые объекты уже убраны репозиторием в карантин, остальные загружаются как обычно.
+		s.log.Warn("corrupted items were quarantined", zap.Error(err))
 
-			return
-		}
+		err = nil
+	}
+
+	if errors.Is(err, models.ErrNotFound) {
+		s.log.Info("no items in local repo")
+
+		err = nil
+	}
 
+	var seq uint64
+
+	if err == nil {
+		seq, err = s.repo.LastSeq(ctx)
+	}
+
+	if err != nil {
 		s.log.Error("cannot load items from local repo", zap.Error(err))
+		s.metrics.repoErrors.WithLabelValues(repoRead).Inc()
+
+		s.loadFailed = true
 
 		return
 	}
@@ -116,38 +443,51 @@ func (s *Store) loadItems() {
 	defer s.m.Unlock()
 
 	for _, item := range items {
+		item.Body = s.acquireBody(item.Body)
 		s.s[item.ID] = item
+		s.indexItem(item)
+		s.track(item)
+
+		seq = max(seq, item.Version)
 	}
 
+	// версии объектов должны расти и после перезапуска, поэтому нумерация изменений продолжается с сохранённого
+	// номера последнего изменения: версии удалённых до перезапуска объектов не выдаются повторно.
+	s.changes.resume(seq)
+
 	s.log.Info("successful load items from local repo", zap.Int("items size", len(items)))
 }
 
-func (s *Store) saveItems() {
-	if len(s.s) == 0 {
-		s.log.Info("no items to save into local repo")
+// saveItems записывает в репозиторий все объекты хранилища, кроме истёкших, заменяя прежние. Объекты
+// заменяются одной транзакцией, поэтому при сбое записи в репозитории остаются объекты прошлой записи.
+func (s *Store) saveItems(ctx context.Context) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.loadFailed {
+		s.log.Warn("items were not loaded from local repo, skip saving to keep it intact")
 
 		return
 	}
 
-	err := s.repo.DeleteAll()
-	if err != nil {
-		s.log.Error("cannot remove old items from local repo", zap.Error(err))
+	start := time.Now()
+	items := make([]models.Item, 0, len(s.s))
 
-		return
+	for _, item := range s.s {
+		if !item.Expired(start) {
+			items = append(items, item)
+		}
 	}
 
-	s.m.Lock()
-	defer s.m.Unlock()
+	if err := s.repo.ReplaceAll(ctx, items, s.changes.last()); err != nil {
+		s.log.Error("cannot save items in local repo", zap.Error(err))
+		s.metrics.repoErrors.WithLabelValues(repoWrite).Inc()
 
-	items := maps.Values(s.s)
-	for _, item := range items {
-		err := s.repo.Insert(item)
-		if err != nil {
-			s.log.Error("cannot save item in local repo", zap.Error(err))
+		return
+	}
 
-			continue
-		}
+	s.log.Info("items saved to local repo", zap.Int("count", len(items)))
 
-		s.log.Info("item successful insert to local repo", zap.Int("id", item.ID))
-	}
+	s.lastSnapshot = start
+	s.metrics.snapshot.Observe(time.Since(start).Seconds())
 }
@@ -2,7 +2,10 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"st-test/internal/storage/mocks"
@@ -20,7 +23,8 @@ func TestNewStore(t *testing.T) {
 
 	repo := mocks.NewRepo(t)
 	require.NotNil(t, repo)
-	repo.EXPECT().ReadAll().Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
 
 	s := NewStore(log, repo)
 	require.NotNil(t, s)
@@ -36,7 +40,8 @@ func TestStore_SaveObject(t *testing.T) {
 
 	repo := mocks.NewRepo(t)
 	require.NotNil(t, repo)
-	repo.EXPECT().ReadAll().Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
 
 	s := NewStore(log, repo)
 	require.NotNil(t, s)
@@ -52,12 +57,42 @@ func TestStore_SaveObject(t *testing.T) {
 
 	updateGotID, err := s.SaveObject(context.Background(), models.Item{
 		ID:      1,
-		Body:    []byte(`{"some":"body"}`),
+		Body:    []byte(`{"some":"new body"}`),
 		Expires: 0,
 	})
 	require.NoError(t, err)
 	require.Equal(t, 0, updateGotID)
 	require.Len(t, s.s, 1)
+	require.Equal(t, []byte(`{"some":"new body"}`), s.s[1].Body)
+}
+
+func TestStore_DeleteObject(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	repo := mocks.NewRepo(t)
+	require.NotNil(t, repo)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	s := NewStore(log, repo)
+	require.NotNil(t, s)
+
+	_, err = s.SaveObject(context.Background(), models.Item{
+		ID:   1,
+		Body: []byte(`{"some":"body"}`),
+	})
+	require.NoError(t, err)
+
+	err = s.DeleteObject(context.Background(), 1)
+	require.NoError(t, err)
+	require.Empty(t, s.s)
+
+	err = s.DeleteObject(context.Background(), 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
 }
 
 func TestStore_GetObject(t *testing.T) {
@@ -69,7 +104,8 @@ func TestStore_GetObject(t *testing.T) {
 
 	repo := mocks.NewRepo(t)
 	require.NotNil(t, repo)
-	repo.EXPECT().ReadAll().Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
 
 	s := NewStore(log, repo)
 	require.NotNil(t, s)
@@ -94,6 +130,50 @@ func TestStore_GetObject(t *testing.T) {
 	require.Nil(t, notFoundItem.Body)
 }
 
+func TestStore_StatObject(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{
+		ID:          1,
+		Body:        []byte("some text"),
+		Expires:     time.Hour,
+		Owner:       "client-a",
+		ContentType: "text/plain",
+	})
+	require.NoError(t, err)
+
+	created, err := s.StatObject(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(len("some text")), created.Size)
+	require.Equal(t, "client-a", created.Owner)
+	require.Equal(t, "text/plain", created.ContentType)
+	require.False(t, created.CreatedAt.IsZero())
+	require.Equal(t, created.CreatedAt, created.UpdatedAt)
+
+	ttl, ok := created.TTL(time.Now())
+	require.True(t, ok)
+	require.InDelta(t, time.Hour, ttl, float64(time.Minute))
+
+	// перезапись сохраняет время создания и меняет время изменения.
+	_, err = s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	updated, err := s.StatObject(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, created.CreatedAt, updated.CreatedAt)
+	require.False(t, updated.UpdatedAt.Before(created.UpdatedAt))
+	require.Greater(t, updated.Version, created.Version)
+
+	_, ok = updated.TTL(time.Now())
+	require.False(t, ok)
+
+	_, err = s.StatObject(ctx, 2)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
 func TestStore_Stop(t *testing.T) {
 	t.Parallel()
 
@@ -109,18 +189,32 @@ func TestStore_Stop(t *testing.T) {
 		{
 			name: "without objects",
 			prepareRepo: func(rep *mocks.Repo) {
-				rep.EXPECT().ReadAll().Once().Return(nil, models.ErrNotFound)
+				rep.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+				rep.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+				rep.EXPECT().ReplaceAll(mock.Anything, []models.Item{}, uint64(0)).Once().Return(nil)
 			},
 		},
 		{
 			name: "with objects",
 			prepareRepo: func(rep *mocks.Repo) {
-				rep.EXPECT().ReadAll().Once().Return(nil, models.ErrNotFound)
-				rep.EXPECT().DeleteAll().Once().Return(nil)
-				rep.EXPECT().Insert(mock.AnythingOfType("models.Item")).Once().Return(nil)
+				rep.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+				rep.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+				rep.EXPECT().ReplaceAll(mock.Anything, []models.Item{{ID: 1}}, uint64(0)).Once().Return(nil)
 			},
 			prepareStore: func(s *Store) {
-				s.s[1] = models.Item{}
+				s.s[1] = models.Item{ID: 1}
+			},
+		},
+		{
+			// последний объект удалён, поэтому из репозитория удаляются все объекты, а номер его удаления сохраняется.
+			name: "all objects deleted",
+			prepareRepo: func(rep *mocks.Repo) {
+				rep.EXPECT().ReadAll(mock.Anything).Once().Return([]models.Item{{ID: 1, Body: []byte(`{}`), Version: 4}}, nil)
+				rep.EXPECT().LastSeq(mock.Anything).Once().Return(uint64(3), nil)
+				rep.EXPECT().ReplaceAll(mock.Anything, []models.Item{}, uint64(5)).Once().Return(nil)
+			},
+			prepareStore: func(s *Store) {
+				require.NoError(t, s.DeleteObject(context.Background(), 1))
 			},
 		},
 	}
@@ -146,3 +240,42 @@ func TestStore_Stop(t *testing.T) {
 		})
 	}
 }
+
+func TestStore_StopAfterLoadFailure(t *testing.T) {
+	t.Parallel()
+
+	log, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	// mock упадёт, если при остановке будет вызван ReplaceAll.
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, errors.New("decryption failed"))
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	s := NewStore(log, repo)
+
+	_, err = s.SaveObject(context.Background(), models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	s.Stop()
+}
+
+func TestStore_LoadWithCorruptedItems(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(
+		[]models.Item{{ID: 1, Body: []byte(`{}`), Version: 3}},
+		fmt.Errorf("storage key 2 quarantined: %w", models.ErrCorrupted),
+	)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	s := NewStore(zap.NewNop(), repo)
+
+	item, err := s.GetObject(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), item.Version)
+
+	_, err = s.GetObject(context.Background(), 2)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"st-test/internal/models"
+	"st-test/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// tracerName имя трассировщика хранилища.
+const tracerName = "st-test/internal/storage"
+
+// WithTracer включает трассировку операций хранилища: спан операции становится дочерним спану из переданного контекста.
+func WithTracer(tp trace.TracerProvider) Option {
+	return func(s *Store) {
+		s.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// startSpan начинает спан операции хранилища op и возвращает логгер с идентификаторами трассировки.
+func (s *Store) startSpan(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, trace.Span, *zap.Logger) {
+	ctx, span := s.tracer.Start(ctx, "store."+op, trace.WithAttributes(attrs...))
+
+	return ctx, span, s.log.With(tracing.LogFields(ctx)...)
+}
+
+// endSpan завершает спан операции хранилища. Отсутствие объекта — обычный результат, а не сбой, поэтому
+// models.ErrNotFound не помечает спан как неуспешный.
+func endSpan(span trace.Span, err error) {
+	if errors.Is(err, models.ErrNotFound) {
+		err = nil
+	}
+
+	tracing.End(span, err)
+}
+
+// objectID атрибут спана с id объекта.
+func objectID(id int) attribute.KeyValue {
+	return attribute.Int("object.id", id)
+}
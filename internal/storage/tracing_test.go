@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"st-test/internal/models"
+	"st-test/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+)
+
+func TestStore_Tracing(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	s := NewStore(zap.NewNop(), repo, WithTracer(tp))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	_, err = s.GetObject(ctx, 2)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	parent.End()
+
+	// спан загрузки, спаны операций и спан запроса.
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	assert.Equal(t, "store.Load", spans[0].Name())
+
+	for _, span := range spans[1:3] {
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	}
+
+	assert.Equal(t, "store.SaveObject", spans[1].Name())
+	assert.Equal(t, "store.GetObject", spans[2].Name())
+}
@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// defaultTrashRetention время хранения удалённых объектов в корзине по умолчанию.
+const defaultTrashRetention = 7 * 24 * time.Hour
+
+var (
+	// ErrTrashDisabled возвращается когда корзина не включена.
+	ErrTrashDisabled = errors.New("trash is disabled")
+	// ErrAlreadyExists возвращается при восстановлении объекта, id которого уже занят.
+	ErrAlreadyExists = errors.New("object already exists")
+)
+
+// WithTrash включает корзину: удалённые объекты хранятся в ней retention и могут быть восстановлены.
+// Корзина загружается из файла при создании хранилища и записывается в файл при остановке.
+func WithTrash(retention time.Duration) Option {
+	return func(s *Store) {
+		if retention <= 0 {
+			retention = defaultTrashRetention
+		}
+
+		s.trash = make(map[int]models.TrashedItem)
+		s.trashRetention = retention
+	}
+}
+
+// Trash возвращает не более limit объектов из корзины, начиная с последних удалённых.
+func (s *Store) Trash(ctx context.Context, limit int) (_ []models.TrashedItem, err error) {
+	_, span, _ := s.startSpan(ctx, "Trash")
+	defer func() { endSpan(span, err) }()
+
+	if s.trash == nil {
+		return nil, ErrTrashDisabled
+	}
+
+	s.m.Lock()
+
+	items := make([]models.TrashedItem, 0, len(s.trash))
+	for _, item := range s.trash {
+		items = append(items, item)
+	}
+
+	s.m.Unlock()
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].DeletedAt.Equal(items[j].DeletedAt) {
+			return items[i].DeletedAt.After(items[j].DeletedAt)
+		}
+
+		return items[i].ID < items[j].ID
+	})
+
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items, nil
+}
+
+// RestoreObject возвращает объект из корзины в хранилище как новую версию. Срок жизни объекта отсчитывается заново.
+// Если объект с тем же id уже создан заново, возвращается ErrAlreadyExists, если восстановление превысит квоту
+// владельца — ErrQuotaExceeded.
+func (s *Store) RestoreObject(ctx context.Context, id int) (_ models.Item, err error) {
+	ctx, span, log := s.startSpan(ctx, "RestoreObject", objectID(id))
+	defer func() { endSpan(span, err) }()
+
+	if s.replica {
+		return models.Item{}, ErrReadOnly
+	}
+
+	if s.trash == nil {
+		return models.Item{}, ErrTrashDisabled
+	}
+
+	if res, ok := s.propose(ctx, Command{Type: CommandRestore, ID: id, Time: time.Now()}); ok {
+		return res.Item, res.Err
+	}
+
+	return s.restoreObject(log, id, time.Now())
+}
+
+// restoreObject возвращает объект id из корзины в хранилище в момент now.
+func (s *Store) restoreObject(log *zap.Logger, id int, now time.Time) (models.Item, error) {
+	if s.trash == nil {
+		return models.Item{}, ErrTrashDisabled
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	trashed, ok := s.trash[id]
+	if !ok {
+		return models.Item{}, models.ErrNotFound
+	}
+
+	// объект, срок жизни которого истёк, но который ещё не удалила фоновая проверка, истекает перед восстановлением,
+	// поэтому восстановленный объект ничего не заменяет.
+	if old, ok := s.s[id]; ok {
+		if !old.Expired(now) {
+			return models.Item{}, ErrAlreadyExists
+		}
+
+		s.expireItem(old, now)
+	}
+
+	item := trashed.Item
+	item.ExpiresAt = time.Time{}
+
+	if err := s.quotas.check(item, models.Item{}, false); err != nil {
+		s.metrics.quotaRejections.WithLabelValues(item.Owner).Inc()
+
+		return models.Item{}, err
+	}
+
+	if item.Expires > 0 {
+		item.ExpiresAt = now.Add(item.Expires)
+	}
+
+	item.Version = s.publish(models.ChangeCreated, item).Seq
+	s.s[id] = item
+	s.indexItem(item)
+	s.track(item)
+	delete(s.trash, id)
+
+	log.Info("Item was restored from trash", zap.Int("id", id))
+
+	return item, nil
+}
+
+// moveToTrash кладёт удалённый объект в корзину, если она включена, вместе со ссылкой на его тело.
+// Вызывается под блокировкой хранилища.
+func (s *Store) moveToTrash(item models.Item, now time.Time) {
+	if s.trash == nil {
+		s.releaseBody(item.Body)
+
+		return
+	}
+
+	if old, ok := s.trash[item.ID]; ok {
+		s.releaseBody(old.Body)
+	}
+
+	s.trash[item.ID] = models.TrashedItem{
+		Item:      item,
+		DeletedAt: now,
+		PurgeAt:   now.Add(s.trashRetention),
+	}
+}
+
+// purgeTrash окончательно удаляет объекты, срок хранения которых в корзине истёк к моменту now, и возвращает их количество.
+func (s *Store) purgeTrash(now time.Time) int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	purged := 0
+
+	for id, item := range s.trash {
+		if now.Before(item.PurgeAt) {
+			continue
+		}
+
+		delete(s.trash, id)
+		s.releaseBody(item.Body)
+
+		purged++
+	}
+
+	if purged > 0 {
+		s.log.Info("trash purged", zap.Int("count", purged))
+	}
+
+	return purged
+}
+
+func (s *Store) loadTrash(ctx context.Context) {
+	if s.trash == nil {
+		return
+	}
+
+	items, err := s.repo.ReadTrash(ctx)
+	if errors.Is(err, models.ErrCorrupted) {
+		s.log.Warn("corrupted trash items were quarantined", zap.Error(err))
+
+		err = nil
+	}
+
+	if err != nil {
+		s.log.Error("cannot load trash from local repo", zap.Error(err))
+		s.metrics.repoErrors.WithLabelValues(repoRead).Inc()
+
+		s.loadFailed = true
+
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, item := range items {
+		item.Body = s.acquireBody(item.Body)
+		s.trash[item.ID] = item
+	}
+
+	s.log.Info("successful load trash from local repo", zap.Int("trash size", len(items)))
+}
+
+func (s *Store) saveTrash(ctx context.Context) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.trash == nil || s.loadFailed {
+		return
+	}
+
+	items := make([]models.TrashedItem, 0, len(s.trash))
+	for _, item := range s.trash {
+		items = append(items, item)
+	}
+
+	if err := s.repo.ReplaceTrash(ctx, items); err != nil {
+		s.log.Error("cannot save trash in local repo", zap.Error(err))
+		s.metrics.repoErrors.WithLabelValues(repoWrite).Inc()
+	}
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testTrashStore(t *testing.T, trashed ...models.TrashedItem) (*Store, *mocks.Repo) {
+	t.Helper()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+	repo.EXPECT().ReadTrash(mock.Anything).Once().Return(trashed, nil)
+
+	return NewStore(zap.NewNop(), repo, WithTrash(time.Hour)), repo
+}
+
+func TestStore_Trash(t *testing.T) {
+	t.Parallel()
+
+	s, _ := testTrashStore(t)
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"v":1}`)})
+	require.NoError(t, err)
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{"v":2}`)})
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteObject(ctx, 1))
+	require.NoError(t, s.DeleteObject(ctx, 2))
+
+	items, err := s.Trash(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.False(t, items[0].DeletedAt.Before(items[1].DeletedAt))
+	assert.WithinDuration(t, items[0].DeletedAt.Add(time.Hour), items[0].PurgeAt, 0)
+
+	items, err = s.Trash(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	restored, err := s.RestoreObject(ctx, 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"v":1}`, string(restored.Body))
+
+	item, err := s.GetObject(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, restored.Version, item.Version)
+
+	_, err = s.RestoreObject(ctx, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	// id удалённого объекта занят новым объектом.
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{"v":3}`)})
+	require.NoError(t, err)
+
+	_, err = s.RestoreObject(ctx, 2)
+	require.ErrorIs(t, err, ErrAlreadyExists)
+
+	assert.Zero(t, s.purgeTrash(time.Now()))
+	assert.Equal(t, 1, s.purgeTrash(time.Now().Add(2*time.Hour)))
+
+	items, err = s.Trash(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestStore_TrashPersisted(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	trashed := models.TrashedItem{
+		Item:      models.Item{ID: 5, Body: []byte(`{}`)},
+		DeletedAt: now,
+		PurgeAt:   now.Add(time.Hour),
+	}
+
+	s, repo := testTrashStore(t, trashed)
+
+	items, err := s.Trash(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	repo.EXPECT().ReplaceAll(mock.Anything, []models.Item{}, mock.Anything).Once().Return(nil)
+	repo.EXPECT().ReplaceTrash(mock.Anything, []models.TrashedItem{trashed}).Once().Return(nil)
+
+	s.Stop()
+}
+
+func TestStore_TrashDisabled(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+
+	_, err := s.Trash(context.Background(), 10)
+	require.ErrorIs(t, err, ErrTrashDisabled)
+
+	_, err = s.RestoreObject(context.Background(), 1)
+	require.ErrorIs(t, err, ErrTrashDisabled)
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"st-test/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// withTTL возвращает записываемый объект item со сроком жизни, отсчитанным от момента now. С KeepTTL объект
+// получает срок жизни прежнего объекта old, если тот существует (exists); иначе применяется срок жизни item.
+func withTTL(item, old models.Item, exists bool, now time.Time) models.Item {
+	keep := item.KeepTTL
+	item.KeepTTL = false
+
+	if keep && exists {
+		item.Expires, item.ExpiresAt, item.Sliding = old.Expires, old.ExpiresAt, old.Sliding
+
+		return item
+	}
+
+	return item.WithTTL(item.TTL(), now)
+}
+
+// SetTTL задаёт объекту id срок жизни ttl; нулевой ttl делает объект бессрочным. Изменение получает новую версию
+// и публикуется как обновление объекта. Если объекта нет, возвращается models.ErrNotFound, если срок жизни задан
+// неверно — ошибка, оборачивающая models.ErrInvalidTTL.
+func (s *Store) SetTTL(ctx context.Context, id int, ttl models.TTL) (_ models.Item, err error) {
+	ctx, span, log := s.startSpan(ctx, "SetTTL", objectID(id))
+	defer func() { endSpan(span, err) }()
+
+	if s.replica {
+		return models.Item{}, ErrReadOnly
+	}
+
+	now := time.Now()
+
+	if err := ttl.Validate(now); err != nil {
+		return models.Item{}, err //nolint:wrapcheck
+	}
+
+	if res, ok := s.propose(ctx, Command{Type: CommandSetTTL, ID: id, TTL: ttl, Time: now}); ok {
+		return res.Item, res.Err
+	}
+
+	return s.setTTL(log, id, ttl, now)
+}
+
+// setTTL задаёт объекту id срок жизни ttl, отсчитанный от момента now.
+func (s *Store) setTTL(log *zap.Logger, id int, ttl models.TTL, now time.Time) (models.Item, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	item, ok := s.s[id]
+	if !ok || item.Expired(now) {
+		return models.Item{}, models.ErrNotFound
+	}
+
+	item = item.WithTTL(ttl, now)
+	item.Version = s.publish(models.ChangeUpdated, item).Seq
+	s.s[id] = item
+
+	log.Info("item ttl updated", zap.Int("id", id), zap.Time("expires_at", item.ExpiresAt))
+
+	return item, nil
+}
+
+// Touch заново отсчитывает относительный срок жизни объекта id от текущего момента, не меняя версию объекта,
+// и публикует изменение touched. У объекта без срока жизни или с абсолютным сроком жизни ничего не меняется.
+// Если объекта нет, возвращается models.ErrNotFound.
+func (s *Store) Touch(ctx context.Context, id int) (_ models.Item, err error) {
+	ctx, span, _ := s.startSpan(ctx, "Touch", objectID(id))
+	defer func() { endSpan(span, err) }()
+
+	if s.replica {
+		return models.Item{}, ErrReadOnly
+	}
+
+	if res, ok := s.propose(ctx, Command{Type: CommandTouch, ID: id, Time: time.Now()}); ok {
+		return res.Item, res.Err
+	}
+
+	return s.touch(id, time.Now())
+}
+
+// touch заново отсчитывает относительный срок жизни объекта id от момента now.
+func (s *Store) touch(id int, now time.Time) (models.Item, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	item, ok := s.s[id]
+	if !ok || item.Expired(now) {
+		return models.Item{}, models.ErrNotFound
+	}
+
+	if item.Expires > 0 {
+		item.ExpiresAt = now.Add(item.Expires)
+		s.s[id] = item
+		s.publish(models.ChangeTouched, item)
+	}
+
+	return item, nil
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"st-test/internal/models"
+)
+
+// setExpiresAt переносит момент истечения срока жизни объекта id.
+func setExpiresAt(s *Store, id int, at time.Time) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	item := s.s[id]
+	item.ExpiresAt = at
+	s.s[id] = item
+}
+
+func TestStore_SaveObjectTTL(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), ExpiresAt: at})
+	require.NoError(t, err)
+
+	item, err := s.GetObject(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, at, item.ExpiresAt)
+
+	// перезапись с KeepTTL сохраняет прежний срок жизни, без него срок жизни сбрасывается.
+	_, err = s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"v":2}`), Expires: time.Minute, KeepTTL: true})
+	require.NoError(t, err)
+
+	item, err = s.GetObject(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, at, item.ExpiresAt)
+	require.False(t, item.KeepTTL)
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"v":3}`)})
+	require.NoError(t, err)
+
+	item, err = s.GetObject(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, item.ExpiresAt.IsZero())
+
+	// у нового объекта с KeepTTL применяется его собственный срок жизни.
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`), Expires: time.Minute, KeepTTL: true})
+	require.NoError(t, err)
+
+	item, err = s.GetObject(ctx, 2)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Minute), item.ExpiresAt, time.Second)
+}
+
+func TestStore_SlidingTTL(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Expires: time.Minute, Sliding: true})
+	require.NoError(t, err)
+
+	// чтение продлевает срок жизни, получение сведений об объекте — нет.
+	setExpiresAt(s, 1, time.Now().Add(time.Second))
+
+	stat, err := s.StatObject(ctx, 1)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Second), stat.ExpiresAt, time.Second)
+
+	_, err = s.GetObject(ctx, 1)
+	require.NoError(t, err)
+
+	stat, err = s.StatObject(ctx, 1)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Minute), stat.ExpiresAt, time.Second)
+	require.True(t, stat.Sliding)
+}
+
+func TestStore_SetTTL(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	saved, err := s.GetObject(ctx, 1)
+	require.NoError(t, err)
+
+	item, err := s.SetTTL(ctx, 1, models.TTL{Duration: time.Hour, Sliding: true})
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, item.Expires)
+	require.True(t, item.Sliding)
+	require.WithinDuration(t, time.Now().Add(time.Hour), item.ExpiresAt, time.Second)
+	require.Greater(t, item.Version, saved.Version)
+
+	changes, _, err := s.Changes(saved.Version, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, models.ChangeUpdated, changes[0].Type)
+
+	// нулевой срок жизни делает объект бессрочным.
+	item, err = s.SetTTL(ctx, 1, models.TTL{})
+	require.NoError(t, err)
+	require.True(t, item.ExpiresAt.IsZero())
+	require.False(t, item.Sliding)
+
+	_, err = s.SetTTL(ctx, 1, models.TTL{At: time.Now().Add(-time.Minute)})
+	require.ErrorIs(t, err, models.ErrInvalidTTL)
+
+	_, err = s.SetTTL(ctx, 2, models.TTL{Duration: time.Hour})
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestStore_Touch(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+	at := time.Now().Add(time.Hour)
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{}`), Expires: time.Minute})
+	require.NoError(t, err)
+
+	_, err = s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`), ExpiresAt: at})
+	require.NoError(t, err)
+
+	setExpiresAt(s, 1, time.Now().Add(time.Second))
+
+	item, err := s.Touch(ctx, 1)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Minute), item.ExpiresAt, time.Second)
+
+	// абсолютный срок жизни не меняется.
+	item, err = s.Touch(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, at, item.ExpiresAt)
+
+	_, err = s.Touch(ctx, 3)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStore_WaitObject(t *testing.T) {
+	t.Parallel()
+
+	s := testStore(t)
+	ctx := context.Background()
+
+	_, err := s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"v":1}`)})
+	require.NoError(t, err)
+
+	item, err := s.GetObject(ctx, 1)
+	require.NoError(t, err)
+	require.NotZero(t, item.Version)
+
+	t.Run("already newer", func(t *testing.T) {
+		got, err := s.WaitObject(ctx, 1, item.Version-1)
+		require.NoError(t, err)
+		assert.Equal(t, item.Version, got.Version)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		wctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		_, err := s.WaitObject(wctx, 1, item.Version)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("watchers released", func(t *testing.T) {
+		wctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		// ожидания объекта, который так и не появился, не остаются в памяти после отмены.
+		var wg sync.WaitGroup
+
+		for range 3 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, err := s.WaitObject(wctx, 100, 0)
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			}()
+		}
+
+		wg.Wait()
+
+		s.m.Lock()
+		defer s.m.Unlock()
+
+		assert.NotContains(t, s.watchers, 100)
+	})
+
+	t.Run("woken by update", func(t *testing.T) {
+		// изменение другого объекта не должно будить ожидающего.
+		_, err := s.SaveObject(ctx, models.Item{ID: 2, Body: []byte(`{}`)})
+		require.NoError(t, err)
+
+		res := make(chan models.Item, 1)
+
+		go func() {
+			got, err := s.WaitObject(ctx, 1, item.Version)
+			assert.NoError(t, err)
+
+			res <- got
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+
+		_, err = s.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"v":2}`)})
+		require.NoError(t, err)
+
+		select {
+		case got := <-res:
+			assert.Greater(t, got.Version, item.Version)
+			assert.JSONEq(t, `{"v":2}`, string(got.Body))
+		case <-time.After(time.Second):
+			t.Fatal("waiter was not woken")
+		}
+	})
+}
+
+func TestStore_VersionAfterRestart(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return([]models.Item{{ID: 1, Body: []byte(`{}`), Version: 41}}, nil)
+	repo.EXPECT().LastSeq(mock.Anything).Maybe().Return(uint64(0), nil)
+
+	s := NewStore(zap.NewNop(), repo)
+
+	_, err := s.SaveObject(context.Background(), models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	item, err := s.GetObject(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), item.Version)
+
+	// изменения до перезапуска не хранятся.
+	_, _, err = s.Changes(40, 10)
+	assert.ErrorIs(t, err, ErrChangesExpired)
+}
+
+func TestStore_VersionAfterDeleteAndRestart(t *testing.T) {
+	t.Parallel()
+
+	// объект с версией 50 был удалён до перезапуска: его версия не должна выдаваться повторно.
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return([]models.Item{{ID: 1, Body: []byte(`{}`), Version: 41}}, nil)
+	repo.EXPECT().LastSeq(mock.Anything).Once().Return(uint64(51), nil)
+
+	s := NewStore(zap.NewNop(), repo)
+
+	_, err := s.SaveObject(context.Background(), models.Item{ID: 2, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	item, err := s.GetObject(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(52), item.Version)
+}
+
+func TestStore_LastSeqError(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewRepo(t)
+	repo.EXPECT().ReadAll(mock.Anything).Once().Return(nil, models.ErrNotFound)
+	repo.EXPECT().LastSeq(mock.Anything).Once().Return(uint64(0), errors.New("some error"))
+
+	s := NewStore(zap.NewNop(), repo)
+
+	// без номера последнего изменения версии могут повториться, поэтому репозиторий не перезаписывается.
+	s.Stop()
+}
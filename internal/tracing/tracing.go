@@ -0,0 +1,163 @@
+// Package tracing настраивает трассировку OpenTelemetry: экспорт спанов, распространение контекста
+// трассировки в заголовке W3C traceparent и идентификаторы трассировки в полях логов.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"st-test/internal/settings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+// Экспортёры спанов.
+const (
+	// ExporterStdout пишет спаны в стандартный вывод.
+	ExporterStdout = "stdout"
+	// ExporterFile пишет спаны в файл.
+	ExporterFile = "file"
+	// ExporterOTLP отправляет спаны коллектору по OTLP/http.
+	ExporterOTLP = "otlp"
+)
+
+// defaultServiceName имя сервиса в спанах по умолчанию.
+const defaultServiceName = "st-test"
+
+var (
+	// ErrUnknownExporter возвращается когда экспортёр спанов не поддерживается.
+	ErrUnknownExporter = errors.New("unknown tracing exporter")
+	// ErrNoFile возвращается когда для экспортёра file не задан файл.
+	ErrNoFile = errors.New("tracing file is not set")
+)
+
+// Provider поставщик трассировщиков, отправляющий спаны выбранному экспортёру.
+type Provider struct {
+	*sdktrace.TracerProvider
+	file *os.File
+}
+
+// New создаёт поставщик трассировщиков по настройкам. Спаны отправляются пачками; перед завершением работы
+// необходимо вызвать Shutdown, чтобы отправить оставшиеся.
+func New(ctx context.Context, set settings.TracingSettings) (*Provider, error) {
+	p := &Provider{}
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+
+	switch set.Exporter {
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterFile:
+		if set.File == "" {
+			return nil, ErrNoFile
+		}
+
+		p.file, err = os.OpenFile(set.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("opening tracing file: %w", err)
+		}
+
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(p.file))
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(set.Endpoint)}
+		if set.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownExporter, set.Exporter)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("creating %s exporter: %w", set.Exporter, err)
+	}
+
+	name := set.ServiceName
+	if name == "" {
+		name = defaultServiceName
+	}
+
+	sampler := sdktrace.AlwaysSample()
+	if set.SampleRatio > 0 && set.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(set.SampleRatio)
+	}
+
+	p.TracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", name))),
+	)
+
+	return p, nil
+}
+
+// Shutdown отправляет оставшиеся спаны и останавливает экспортёр.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.TracerProvider.Shutdown(ctx)
+	if err != nil {
+		err = fmt.Errorf("shutting down tracer provider: %w", err)
+	}
+
+	if p.file != nil {
+		if cerr := p.file.Close(); cerr != nil {
+			err = errors.Join(err, fmt.Errorf("closing tracing file: %w", cerr))
+		}
+	}
+
+	return err
+}
+
+// Noop возвращает поставщик трассировщиков, который не записывает спаны. Используется, когда трассировка не включена.
+func Noop() trace.TracerProvider {
+	return noop.NewTracerProvider()
+}
+
+// Propagator возвращает распространитель контекста трассировки в заголовке W3C traceparent.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.TraceContext{}
+}
+
+// Transport оборачивает транспорт base так, что для каждого исходящего запроса начинается клиентский спан,
+// дочерний спану из контекста запроса, а контекст трассировки передаётся в заголовке traceparent.
+// Если base nil, используется http.DefaultTransport.
+func Transport(tp trace.TracerProvider, base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base, otelhttp.WithTracerProvider(tp), otelhttp.WithPropagators(Propagator()))
+}
+
+// LogFields возвращает поля лога с идентификаторами трассировки и спана из ctx.
+// Если в ctx нет записываемого спана, возвращается пустой список.
+func LogFields(ctx context.Context) []zap.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return []zap.Field{zap.Stringer("trace_id", sc.TraceID()), zap.Stringer("span_id", sc.SpanID())}
+}
+
+// End завершает спан; ошибка err, если она есть, записывается в спан и помечает его как неуспешный.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
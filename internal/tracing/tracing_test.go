@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"st-test/internal/settings"
+)
+
+func TestNew_File(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "spans.json")
+
+	p, err := New(context.Background(), settings.TracingSettings{Exporter: ExporterFile, File: file})
+	require.NoError(t, err)
+
+	_, span := p.Tracer("test").Start(context.Background(), "some span")
+	span.End()
+
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	raw, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"Name":"some span"`)
+	assert.Contains(t, string(raw), `"Value":"st-test"`)
+}
+
+func TestNew_OTLP(t *testing.T) {
+	t.Parallel()
+
+	// локальная замена коллектора принимает спаны по OTLP/http.
+	received := make(chan string, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r.URL.Path + " " + r.Header.Get("Content-Type") + " " + string(body)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	p, err := New(context.Background(), settings.TracingSettings{
+		Exporter:    ExporterOTLP,
+		Endpoint:    strings.TrimPrefix(collector.URL, "http://"),
+		Insecure:    true,
+		ServiceName: "test-service",
+	})
+	require.NoError(t, err)
+
+	_, span := p.Tracer("test").Start(context.Background(), "some span")
+	span.End()
+
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	got := <-received
+	assert.True(t, strings.HasPrefix(got, "/v1/traces application/x-protobuf"))
+	assert.Contains(t, got, "some span")
+	assert.Contains(t, got, "test-service")
+}
+
+func TestNew_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(context.Background(), settings.TracingSettings{Exporter: "jaeger"})
+	require.ErrorIs(t, err, ErrUnknownExporter)
+
+	_, err = New(context.Background(), settings.TracingSettings{Exporter: ExporterFile})
+	require.ErrorIs(t, err, ErrNoFile)
+}
+
+func TestLogFields(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, LogFields(context.Background()))
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "some span")
+	End(span, errors.New("some error"))
+
+	fields := LogFields(ctx)
+	require.Len(t, fields, 2)
+	assert.Equal(t, "trace_id", fields[0].Key)
+	assert.Equal(t, span.SpanContext().TraceID(), fields[0].Interface)
+
+	require.Len(t, recorder.Ended(), 1)
+	assert.Equal(t, codes.Error, recorder.Ended()[0].Status().Code)
+}
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	var traceparent string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	t.Cleanup(srv.Close)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, http.NoBody)
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: Transport(tp, nil)}).Do(req)
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, resp.Body.Close())
+
+	parent.End()
+
+	// клиентский спан запроса дочерний спану из контекста, и серверу передаётся именно он.
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	client := spans[0]
+	assert.Equal(t, trace.SpanKindClient, client.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), client.Parent().SpanID())
+	assert.Equal(t, "00-"+client.SpanContext().TraceID().String()+"-"+client.SpanContext().SpanID().String()+"-01", traceparent)
+}
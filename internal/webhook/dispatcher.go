@@ -0,0 +1,560 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"st-test/internal/keyfilter"
+	"st-test/internal/models"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
+	"st-test/internal/tracing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxAttempts  = 8
+	defaultBackoff      = time.Second
+	defaultMaxBackoff   = 10 * time.Minute
+	defaultTimeout      = 10 * time.Second
+	defaultPollInterval = time.Second
+
+	// batchSize количество изменений и событий, обрабатываемых за один раз.
+	batchSize = 100
+	// maxErrorLength максимальная длина сохраняемого текста ошибки доставки.
+	maxErrorLength = 512
+)
+
+// Repo описывает методы хранилища подписок и очереди доставки.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.42.1 --name=Repo --with-expecter=true
+type Repo interface {
+	InsertWebhook(w models.Webhook) (int64, error)
+	Webhooks() ([]models.Webhook, error)
+	DeleteWebhook(id int64) error
+	InsertDeliveries(deliveries []models.Delivery) error
+	DueDeliveries(now time.Time, limit int) ([]models.Delivery, error)
+	Deliveries(status models.DeliveryStatus, limit int) ([]models.Delivery, error)
+	UpdateDelivery(d models.Delivery) error
+	DeleteDelivery(id int64) error
+}
+
+// Changes описывает метод хранилища объектов для подписки на его изменения.
+type Changes interface {
+	Listen(l storage.ChangeListener) (cancel func())
+}
+
+// subscription подписка с разобранными фильтрами.
+type subscription struct {
+	models.Webhook
+	events map[models.ChangeType]struct{}
+	keys   keyfilter.Set
+}
+
+// match проверяет, что изменение c подходит под фильтры подписки. Продления срока жизни (touched) происходят
+// при каждом чтении скользящего объекта, поэтому доставляются только подписке, явно указавшей это событие.
+func (s subscription) match(c models.Change) bool {
+	if len(s.events) > 0 {
+		if _, ok := s.events[c.Type]; !ok {
+			return false
+		}
+	} else if c.Type == models.ChangeTouched {
+		return false
+	}
+
+	return len(s.keys) == 0 || s.keys.Match(c.ID)
+}
+
+// Dispatcher ставит изменения объектов в очередь доставки и доставляет их подписчикам.
+// События ставятся в очередь при каждом изменении хранилища и сохраняются в репозиторий, пока Dispatcher работает,
+// и при его остановке, поэтому ни одно изменение не пропускается.
+type Dispatcher struct {
+	log    *zap.Logger
+	repo   Repo
+	client *http.Client
+	set    settings.WebhookSettings
+	// cancel отменяет подписку на изменения хранилища.
+	cancel func()
+
+	m    sync.RWMutex
+	subs map[int64]subscription
+
+	// pending события, ещё не сохранённые в очередь доставки.
+	pm      sync.Mutex
+	pending []models.Delivery
+
+	flush    chan struct{}
+	wake     chan struct{}
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// Option дополнительная настройка Dispatcher.
+type Option func(d *Dispatcher)
+
+// WithTracer включает трассировку доставок: каждый запрос доставки записывается клиентским спаном,
+// а контекст трассировки передаётся подписчику в заголовке traceparent.
+func WithTracer(tp trace.TracerProvider) Option {
+	return func(d *Dispatcher) {
+		d.client.Transport = tracing.Transport(tp, d.client.Transport)
+	}
+}
+
+// NewDispatcher конструктор для Dispatcher. Загружает подписки из хранилища и подписывается на изменения changes:
+// события изменений с этого момента ставятся в очередь, даже если Run ещё не вызван.
+func NewDispatcher(log *zap.Logger, repo Repo, changes Changes, set settings.WebhookSettings, opts ...Option) (*Dispatcher, error) {
+	if set.MaxAttempts <= 0 {
+		set.MaxAttempts = defaultMaxAttempts
+	}
+
+	if set.Backoff <= 0 {
+		set.Backoff = defaultBackoff
+	}
+
+	if set.MaxBackoff <= 0 {
+		set.MaxBackoff = defaultMaxBackoff
+	}
+
+	if set.Timeout <= 0 {
+		set.Timeout = defaultTimeout
+	}
+
+	if set.PollInterval <= 0 {
+		set.PollInterval = defaultPollInterval
+	}
+
+	d := &Dispatcher{
+		log:    log.Named("webhooks"),
+		repo:   repo,
+		client: &http.Client{Timeout: set.Timeout},
+		set:    set,
+		subs:   make(map[int64]subscription),
+		flush:  make(chan struct{}, 1),
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	webhooks, err := repo.Webhooks()
+	if err != nil {
+		return nil, fmt.Errorf("load webhooks: %w", err)
+	}
+
+	for _, w := range webhooks {
+		sub, err := newSubscription(w)
+		if err != nil {
+			return nil, fmt.Errorf("load webhook %d: %w", w.ID, err)
+		}
+
+		d.subs[w.ID] = sub
+	}
+
+	d.cancel = changes.Listen(d.listen)
+
+	return d, nil
+}
+
+// Run запускает сохранение событий в очередь и их доставку.
+func (d *Dispatcher) Run() {
+	d.wg.Add(2)
+
+	go func() {
+		defer d.wg.Done()
+
+		d.flushLoop()
+	}()
+
+	go func() {
+		defer d.wg.Done()
+
+		d.deliverLoop()
+	}()
+}
+
+// Stop останавливает доставку, дожидается завершения текущих попыток и сохраняет в очередь события,
+// которые ещё не сохранены; они будут доставлены после перезапуска.
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.done)
+	})
+
+	d.wg.Wait()
+	d.cancel()
+
+	if err := d.flushPending(); err != nil {
+		d.log.Error("failed save pending deliveries", zap.Int("count", d.pendingCount()), zap.Error(err))
+	}
+}
+
+// Subscribe проверяет и сохраняет подписку. Если секрет не задан, он генерируется.
+// Возвращает сохранённую подписку вместе с секретом.
+func (d *Dispatcher) Subscribe(w models.Webhook) (models.Webhook, error) {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.Webhook{}, fmt.Errorf("%w: url must be an absolute http or https url", ErrInvalidWebhook)
+	}
+
+	if w.Secret == "" {
+		w.Secret, err = newSecret()
+		if err != nil {
+			return models.Webhook{}, fmt.Errorf("generate secret: %w", err)
+		}
+	}
+
+	if w.Events == nil {
+		w.Events = make([]models.ChangeType, 0)
+	}
+
+	if w.Keys == nil {
+		w.Keys = make([]string, 0)
+	}
+
+	w.CreatedAt = time.Now()
+
+	sub, err := newSubscription(w)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	sub.ID, err = d.repo.InsertWebhook(w)
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("save webhook: %w", err)
+	}
+
+	d.m.Lock()
+	d.subs[sub.ID] = sub
+	d.m.Unlock()
+
+	d.log.Info("webhook subscribed", zap.Int64("id", sub.ID), zap.String("url", w.URL))
+
+	return sub.Webhook, nil
+}
+
+// Unsubscribe удаляет подписку и её недоставленные события.
+func (d *Dispatcher) Unsubscribe(id int64) error {
+	if err := d.repo.DeleteWebhook(id); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	d.m.Lock()
+	delete(d.subs, id)
+	d.m.Unlock()
+
+	d.log.Info("webhook unsubscribed", zap.Int64("id", id))
+
+	return nil
+}
+
+// Webhooks возвращает подписки, отсортированные по id.
+func (d *Dispatcher) Webhooks() []models.Webhook {
+	d.m.RLock()
+	defer d.m.RUnlock()
+
+	webhooks := make([]models.Webhook, 0, len(d.subs))
+	for _, s := range d.subs {
+		webhooks = append(webhooks, s.Webhook)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+
+	return webhooks
+}
+
+// Deliveries возвращает не более limit последних недоставленных событий. Пустой status означает все события.
+func (d *Dispatcher) Deliveries(status models.DeliveryStatus, limit int) ([]models.Delivery, error) {
+	return d.repo.Deliveries(status, limit) //nolint:wrapcheck
+}
+
+// listen ставит событие изменения c для подходящих подписок в очередь на сохранение.
+// Вызывается хранилищем синхронно при каждом изменении.
+func (d *Dispatcher) listen(c models.Change) {
+	deliveries, err := d.deliveries(c, time.Now())
+	if err != nil {
+		d.log.Error("failed enqueue deliveries", zap.Uint64("change", c.Seq), zap.Error(err))
+
+		return
+	}
+
+	if len(deliveries) == 0 {
+		return
+	}
+
+	d.pm.Lock()
+	d.pending = append(d.pending, deliveries...)
+	d.pm.Unlock()
+
+	select {
+	case d.flush <- struct{}{}:
+	default:
+	}
+}
+
+// deliveries возвращает события изменения c для подходящих подписок.
+func (d *Dispatcher) deliveries(c models.Change, now time.Time) ([]models.Delivery, error) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+
+	var (
+		deliveries []models.Delivery
+		payload    []byte
+	)
+
+	for _, s := range d.subs {
+		if !s.match(c) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+
+			payload, err = newPayload(c)
+			if err != nil {
+				return nil, fmt.Errorf("marshal change %d: %w", c.Seq, err)
+			}
+		}
+
+		deliveries = append(deliveries, models.Delivery{
+			WebhookID:     s.ID,
+			Event:         c.Type,
+			ObjectID:      c.ID,
+			Payload:       payload,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+
+	return deliveries, nil
+}
+
+// flushLoop сохраняет поставленные в очередь события, а если сохранить их не удалось, повторяет попытку
+// через PollInterval.
+func (d *Dispatcher) flushLoop() {
+	ticker := time.NewTicker(d.set.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-d.flush:
+		case <-ticker.C:
+		}
+
+		if err := d.flushPending(); err != nil {
+			d.log.Error("failed save deliveries", zap.Int("count", d.pendingCount()), zap.Error(err))
+		}
+	}
+}
+
+// flushPending сохраняет поставленные в очередь события в репозиторий. Если сохранить их не удалось,
+// события остаются в очереди.
+func (d *Dispatcher) flushPending() error {
+	d.pm.Lock()
+	pending := d.pending
+	d.pending = nil
+	d.pm.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := d.repo.InsertDeliveries(pending); err != nil {
+		d.pm.Lock()
+		d.pending = append(pending, d.pending...)
+		d.pm.Unlock()
+
+		return fmt.Errorf("save deliveries: %w", err)
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) pendingCount() int {
+	d.pm.Lock()
+	defer d.pm.Unlock()
+
+	return len(d.pending)
+}
+
+// deliverLoop периодически доставляет события, время попытки которых наступило.
+func (d *Dispatcher) deliverLoop() {
+	ticker := time.NewTicker(d.set.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+
+		for d.deliverDue() == batchSize {
+			select {
+			case <-d.done:
+				return
+			default:
+			}
+		}
+	}
+}
+
+// deliverDue делает попытку доставки событий, время которых наступило, и возвращает их количество.
+func (d *Dispatcher) deliverDue() int {
+	due, err := d.repo.DueDeliveries(time.Now(), batchSize)
+	if err != nil {
+		d.log.Error("failed read due deliveries", zap.Error(err))
+
+		return 0
+	}
+
+	for _, delivery := range due {
+		d.m.RLock()
+		sub, ok := d.subs[delivery.WebhookID]
+		d.m.RUnlock()
+
+		if !ok {
+			// подписку удалили, пока событие ждало доставки.
+			if err := d.repo.DeleteDelivery(delivery.ID); err != nil {
+				d.log.Error("failed delete delivery", zap.Int64("delivery", delivery.ID), zap.Error(err))
+			}
+
+			continue
+		}
+
+		d.attempt(sub, delivery)
+	}
+
+	return len(due)
+}
+
+// attempt доставляет событие подписчику и сохраняет результат попытки.
+func (d *Dispatcher) attempt(sub subscription, delivery models.Delivery) {
+	err := d.send(sub, delivery)
+	if err == nil {
+		if err := d.repo.DeleteDelivery(delivery.ID); err != nil {
+			d.log.Error("failed delete delivery", zap.Int64("delivery", delivery.ID), zap.Error(err))
+		}
+
+		return
+	}
+
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+	delivery.UpdatedAt = now
+
+	if len(delivery.LastError) > maxErrorLength {
+		delivery.LastError = delivery.LastError[:maxErrorLength]
+	}
+
+	if delivery.Attempts >= d.set.MaxAttempts {
+		delivery.Status = models.DeliveryFailed
+
+		d.log.Warn("webhook delivery failed",
+			zap.Int64("delivery", delivery.ID), zap.Int64("webhook", sub.ID), zap.Error(err))
+	} else {
+		delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+
+		d.log.Info("webhook delivery will be retried",
+			zap.Int64("delivery", delivery.ID), zap.Int("attempts", delivery.Attempts), zap.Error(err))
+	}
+
+	if err := d.repo.UpdateDelivery(delivery); err != nil {
+		d.log.Error("failed update delivery", zap.Int64("delivery", delivery.ID), zap.Error(err))
+	}
+}
+
+// backoff задержка перед попыткой после attempts неудачных: Backoff, 2*Backoff, 4*Backoff... но не больше MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.set.Backoff
+
+	for i := 1; i < attempts && delay < d.set.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, d.set.MaxBackoff)
+}
+
+// send отправляет подписанный запрос доставки. Успешной считается доставка с ответом 2xx.
+func (d *Dispatcher) send(sub subscription, delivery models.Delivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.set.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookID, strconv.FormatInt(sub.ID, 10))
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderEvent, string(delivery.Event))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func newSubscription(w models.Webhook) (subscription, error) {
+	sub := subscription{
+		Webhook: w,
+		events:  make(map[models.ChangeType]struct{}, len(w.Events)),
+		keys:    make(keyfilter.Set, len(w.Keys)),
+	}
+
+	for _, e := range w.Events {
+		switch e {
+		case models.ChangeCreated, models.ChangeUpdated, models.ChangeDeleted, models.ChangeExpired, models.ChangeTouched:
+			sub.events[e] = struct{}{}
+		default:
+			return subscription{}, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, e)
+		}
+	}
+
+	keys, err := keyfilter.ParseAll(w.Keys)
+	if err != nil {
+		return subscription{}, fmt.Errorf("%w: %w", ErrInvalidWebhook, err)
+	}
+
+	sub.keys.Add(keys...)
+
+	return sub, nil
+}
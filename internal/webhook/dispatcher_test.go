@@ -0,0 +1,319 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"st-test/internal/models"
+	"st-test/internal/repo"
+	"st-test/internal/settings"
+	"st-test/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// receiver тестовый получатель событий.
+type receiver struct {
+	m        sync.Mutex
+	payloads []Payload
+	headers  []http.Header
+	bodies   [][]byte
+	fail     atomic.Int32
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rc.fail.Load() != 0 {
+		rc.fail.Add(-1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	var p Payload
+	_ = json.Unmarshal(body, &p)
+
+	rc.m.Lock()
+	rc.payloads = append(rc.payloads, p)
+	rc.headers = append(rc.headers, r.Header.Clone())
+	rc.bodies = append(rc.bodies, body)
+	rc.m.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rc *receiver) received() []Payload {
+	rc.m.Lock()
+	defer rc.m.Unlock()
+
+	return append([]Payload(nil), rc.payloads...)
+}
+
+func testDispatcher(t *testing.T, set settings.WebhookSettings, opts ...Option) (*Dispatcher, *storage.Store, *repo.Repo) {
+	t.Helper()
+
+	log := zap.NewNop()
+
+	r, err := repo.NewRepo(settings.LocalStorageSettings{Path: filepath.Join(t.TempDir(), "test.db")})
+	require.NoError(t, err)
+
+	store := storage.NewStore(log, r)
+
+	d, err := NewDispatcher(log, r, store, set, opts...)
+	require.NoError(t, err)
+
+	d.Run()
+
+	t.Cleanup(func() {
+		d.Stop()
+		store.Stop()
+		r.Close()
+	})
+
+	return d, store, r
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	t.Parallel()
+
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	d, store, _ := testDispatcher(t, settings.WebhookSettings{PollInterval: 10 * time.Millisecond})
+
+	w, err := d.Subscribe(models.Webhook{
+		URL:    srv.URL,
+		Events: []models.ChangeType{models.ChangeCreated, models.ChangeDeleted},
+		Keys:   []string{"1-5"},
+		Secret: "secret",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = store.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"a":1}`)})
+	require.NoError(t, err)
+	// не подходит под фильтр событий.
+	_, err = store.SaveObject(ctx, models.Item{ID: 1, Body: []byte(`{"a":2}`)})
+	require.NoError(t, err)
+	// не подходит под фильтр ключей.
+	_, err = store.SaveObject(ctx, models.Item{ID: 6, Body: []byte(`{"a":3}`)})
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteObject(ctx, 1))
+
+	require.Eventually(t, func() bool { return len(rc.received()) == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	got := rc.received()
+	assert.Equal(t, "created", got[0].Type)
+	assert.Equal(t, 1, got[0].ID)
+	assert.JSONEq(t, `{"a":1}`, string(got[0].Body))
+	assert.Equal(t, "deleted", got[1].Type)
+
+	rc.m.Lock()
+	h, body := rc.headers[0], rc.bodies[0]
+	rc.m.Unlock()
+
+	ts, err := strconv.ParseInt(h.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, Verify("secret", ts, body, h.Get(HeaderSignature)))
+	assert.False(t, Verify("other", ts, body, h.Get(HeaderSignature)))
+	assert.Equal(t, strconv.FormatInt(w.ID, 10), h.Get(HeaderWebhookID))
+	assert.Equal(t, "created", h.Get(HeaderEvent))
+
+	// доставленные события удаляются из очереди.
+	require.Eventually(t, func() bool {
+		all, err := d.Deliveries("", 10)
+
+		return err == nil && len(all) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDispatcher_Tracing(t *testing.T) {
+	t.Parallel()
+
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	d, store, _ := testDispatcher(t, settings.WebhookSettings{PollInterval: 10 * time.Millisecond}, WithTracer(tp))
+
+	_, err := d.Subscribe(models.Webhook{URL: srv.URL})
+	require.NoError(t, err)
+
+	_, err = store.SaveObject(context.Background(), models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(recorder.Ended()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// запрос доставки записывается клиентским спаном, и подписчик получает его контекст трассировки.
+	span := recorder.Ended()[0]
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+
+	rc.m.Lock()
+	h := rc.headers[0]
+	rc.m.Unlock()
+
+	sc := span.SpanContext()
+	assert.Equal(t, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01", h.Get("traceparent"))
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	t.Parallel()
+
+	rc := &receiver{}
+	rc.fail.Store(2)
+
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	d, store, _ := testDispatcher(t, settings.WebhookSettings{
+		MaxAttempts:  5,
+		Backoff:      10 * time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	_, err := d.Subscribe(models.Webhook{URL: srv.URL})
+	require.NoError(t, err)
+
+	_, err = store.SaveObject(context.Background(), models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(rc.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(0), rc.fail.Load())
+}
+
+func TestDispatcher_Failed(t *testing.T) {
+	t.Parallel()
+
+	rc := &receiver{}
+	rc.fail.Store(1000)
+
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	d, store, r := testDispatcher(t, settings.WebhookSettings{
+		MaxAttempts:  3,
+		Backoff:      5 * time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+	})
+
+	w, err := d.Subscribe(models.Webhook{URL: srv.URL})
+	require.NoError(t, err)
+	require.NotEmpty(t, w.Secret)
+
+	_, err = store.SaveObject(context.Background(), models.Item{ID: 1, Body: []byte(`{}`)})
+	require.NoError(t, err)
+
+	var failed []models.Delivery
+
+	require.Eventually(t, func() bool {
+		failed, err = d.Deliveries(models.DeliveryFailed, 10)
+
+		return err == nil && len(failed) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 3, failed[0].Attempts)
+	assert.Equal(t, models.ChangeCreated, failed[0].Event)
+	assert.Contains(t, failed[0].LastError, "503")
+
+	// подписки и очередь сохраняются в хранилище и загружаются при создании.
+	restored, err := NewDispatcher(zap.NewNop(), r, store, settings.WebhookSettings{})
+	require.NoError(t, err)
+	require.Len(t, restored.Webhooks(), 1)
+
+	require.NoError(t, d.Unsubscribe(w.ID))
+	assert.Empty(t, d.Webhooks())
+
+	all, err := d.Deliveries("", 10)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func TestDispatcher_NoSkippedChanges(t *testing.T) {
+	t.Parallel()
+
+	log := zap.NewNop()
+	ctx := context.Background()
+
+	r, err := repo.NewRepo(settings.LocalStorageSettings{Path: filepath.Join(t.TempDir(), "test.db")})
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+
+	// журнал изменений меньше количества изменений, но события ставятся в очередь при каждом изменении.
+	store := storage.NewStore(log, r, storage.WithChangeLogSize(4))
+	t.Cleanup(store.Stop)
+
+	d, err := NewDispatcher(log, r, store, settings.WebhookSettings{})
+	require.NoError(t, err)
+
+	_, err = d.Subscribe(models.Webhook{URL: "http://127.0.0.1:1"})
+	require.NoError(t, err)
+
+	// изменения до Run и не сохранённые до остановки события сохраняются при остановке.
+	for i := 1; i <= 20; i++ {
+		_, err = store.SaveObject(ctx, models.Item{ID: i, Body: []byte(`{}`)})
+		require.NoError(t, err)
+	}
+
+	d.Stop()
+
+	all, err := d.Deliveries(models.DeliveryPending, 100)
+	require.NoError(t, err)
+	assert.Len(t, all, 20)
+
+	// после остановки изменения больше не ставятся в очередь.
+	_, err = store.SaveObject(ctx, models.Item{ID: 21, Body: []byte(`{}`)})
+	require.NoError(t, err)
+	assert.Zero(t, d.pendingCount())
+}
+
+func TestDispatcher_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	d, _, _ := testDispatcher(t, settings.WebhookSettings{})
+
+	cases := []models.Webhook{
+		{URL: "not a url"},
+		{URL: "ftp://example.com"},
+		{URL: "http://example.com", Events: []models.ChangeType{"moved"}},
+		{URL: "http://example.com", Keys: []string{"a-b"}},
+	}
+
+	for _, give := range cases {
+		_, err := d.Subscribe(give)
+		assert.ErrorIs(t, err, ErrInvalidWebhook, give)
+	}
+
+	assert.ErrorIs(t, d.Unsubscribe(42), models.ErrNotFound)
+}
+
+func TestDispatcher_backoff(t *testing.T) {
+	t.Parallel()
+
+	d := &Dispatcher{set: settings.WebhookSettings{Backoff: time.Second, MaxBackoff: 5 * time.Second}}
+
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 4*time.Second, d.backoff(3))
+	assert.Equal(t, 5*time.Second, d.backoff(4))
+	assert.Equal(t, 5*time.Second, d.backoff(40))
+}
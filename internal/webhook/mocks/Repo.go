@@ -0,0 +1,453 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "st-test/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+type Repo_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Repo) EXPECT() *Repo_Expecter {
+	return &Repo_Expecter{mock: &_m.Mock}
+}
+
+// DeleteDelivery provides a mock function with given fields: id
+func (_m *Repo) DeleteDelivery(id int64) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repo_DeleteDelivery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteDelivery'
+type Repo_DeleteDelivery_Call struct {
+	*mock.Call
+}
+
+// DeleteDelivery is a helper method to define mock.On call
+//   - id int64
+func (_e *Repo_Expecter) DeleteDelivery(id interface{}) *Repo_DeleteDelivery_Call {
+	return &Repo_DeleteDelivery_Call{Call: _e.mock.On("DeleteDelivery", id)}
+}
+
+func (_c *Repo_DeleteDelivery_Call) Run(run func(id int64)) *Repo_DeleteDelivery_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int64))
+	})
+	return _c
+}
+
+func (_c *Repo_DeleteDelivery_Call) Return(_a0 error) *Repo_DeleteDelivery_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repo_DeleteDelivery_Call) RunAndReturn(run func(int64) error) *Repo_DeleteDelivery_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteWebhook provides a mock function with given fields: id
+func (_m *Repo) DeleteWebhook(id int64) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repo_DeleteWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteWebhook'
+type Repo_DeleteWebhook_Call struct {
+	*mock.Call
+}
+
+// DeleteWebhook is a helper method to define mock.On call
+//   - id int64
+func (_e *Repo_Expecter) DeleteWebhook(id interface{}) *Repo_DeleteWebhook_Call {
+	return &Repo_DeleteWebhook_Call{Call: _e.mock.On("DeleteWebhook", id)}
+}
+
+func (_c *Repo_DeleteWebhook_Call) Run(run func(id int64)) *Repo_DeleteWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int64))
+	})
+	return _c
+}
+
+func (_c *Repo_DeleteWebhook_Call) Return(_a0 error) *Repo_DeleteWebhook_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repo_DeleteWebhook_Call) RunAndReturn(run func(int64) error) *Repo_DeleteWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Deliveries provides a mock function with given fields: status, limit
+func (_m *Repo) Deliveries(status models.DeliveryStatus, limit int) ([]models.Delivery, error) {
+	ret := _m.Called(status, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Deliveries")
+	}
+
+	var r0 []models.Delivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.DeliveryStatus, int) ([]models.Delivery, error)); ok {
+		return rf(status, limit)
+	}
+	if rf, ok := ret.Get(0).(func(models.DeliveryStatus, int) []models.Delivery); ok {
+		r0 = rf(status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Delivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.DeliveryStatus, int) error); ok {
+		r1 = rf(status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_Deliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Deliveries'
+type Repo_Deliveries_Call struct {
+	*mock.Call
+}
+
+// Deliveries is a helper method to define mock.On call
+//   - status models.DeliveryStatus
+//   - limit int
+func (_e *Repo_Expecter) Deliveries(status interface{}, limit interface{}) *Repo_Deliveries_Call {
+	return &Repo_Deliveries_Call{Call: _e.mock.On("Deliveries", status, limit)}
+}
+
+func (_c *Repo_Deliveries_Call) Run(run func(status models.DeliveryStatus, limit int)) *Repo_Deliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.DeliveryStatus), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repo_Deliveries_Call) Return(_a0 []models.Delivery, _a1 error) *Repo_Deliveries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_Deliveries_Call) RunAndReturn(run func(models.DeliveryStatus, int) ([]models.Delivery, error)) *Repo_Deliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DueDeliveries provides a mock function with given fields: now, limit
+func (_m *Repo) DueDeliveries(now time.Time, limit int) ([]models.Delivery, error) {
+	ret := _m.Called(now, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DueDeliveries")
+	}
+
+	var r0 []models.Delivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, int) ([]models.Delivery, error)); ok {
+		return rf(now, limit)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, int) []models.Delivery); ok {
+		r0 = rf(now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Delivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_DueDeliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DueDeliveries'
+type Repo_DueDeliveries_Call struct {
+	*mock.Call
+}
+
+// DueDeliveries is a helper method to define mock.On call
+//   - now time.Time
+//   - limit int
+func (_e *Repo_Expecter) DueDeliveries(now interface{}, limit interface{}) *Repo_DueDeliveries_Call {
+	return &Repo_DueDeliveries_Call{Call: _e.mock.On("DueDeliveries", now, limit)}
+}
+
+func (_c *Repo_DueDeliveries_Call) Run(run func(now time.Time, limit int)) *Repo_DueDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repo_DueDeliveries_Call) Return(_a0 []models.Delivery, _a1 error) *Repo_DueDeliveries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_DueDeliveries_Call) RunAndReturn(run func(time.Time, int) ([]models.Delivery, error)) *Repo_DueDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertDeliveries provides a mock function with given fields: deliveries
+func (_m *Repo) InsertDeliveries(deliveries []models.Delivery) error {
+	ret := _m.Called(deliveries)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertDeliveries")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]models.Delivery) error); ok {
+		r0 = rf(deliveries)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repo_InsertDeliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertDeliveries'
+type Repo_InsertDeliveries_Call struct {
+	*mock.Call
+}
+
+// InsertDeliveries is a helper method to define mock.On call
+//   - deliveries []models.Delivery
+func (_e *Repo_Expecter) InsertDeliveries(deliveries interface{}) *Repo_InsertDeliveries_Call {
+	return &Repo_InsertDeliveries_Call{Call: _e.mock.On("InsertDeliveries", deliveries)}
+}
+
+func (_c *Repo_InsertDeliveries_Call) Run(run func(deliveries []models.Delivery)) *Repo_InsertDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]models.Delivery))
+	})
+	return _c
+}
+
+func (_c *Repo_InsertDeliveries_Call) Return(_a0 error) *Repo_InsertDeliveries_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repo_InsertDeliveries_Call) RunAndReturn(run func([]models.Delivery) error) *Repo_InsertDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertWebhook provides a mock function with given fields: w
+func (_m *Repo) InsertWebhook(w models.Webhook) (int64, error) {
+	ret := _m.Called(w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertWebhook")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Webhook) (int64, error)); ok {
+		return rf(w)
+	}
+	if rf, ok := ret.Get(0).(func(models.Webhook) int64); ok {
+		r0 = rf(w)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Webhook) error); ok {
+		r1 = rf(w)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_InsertWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertWebhook'
+type Repo_InsertWebhook_Call struct {
+	*mock.Call
+}
+
+// InsertWebhook is a helper method to define mock.On call
+//   - w models.Webhook
+func (_e *Repo_Expecter) InsertWebhook(w interface{}) *Repo_InsertWebhook_Call {
+	return &Repo_InsertWebhook_Call{Call: _e.mock.On("InsertWebhook", w)}
+}
+
+func (_c *Repo_InsertWebhook_Call) Run(run func(w models.Webhook)) *Repo_InsertWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.Webhook))
+	})
+	return _c
+}
+
+func (_c *Repo_InsertWebhook_Call) Return(_a0 int64, _a1 error) *Repo_InsertWebhook_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_InsertWebhook_Call) RunAndReturn(run func(models.Webhook) (int64, error)) *Repo_InsertWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateDelivery provides a mock function with given fields: d
+func (_m *Repo) UpdateDelivery(d models.Delivery) error {
+	ret := _m.Called(d)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.Delivery) error); ok {
+		r0 = rf(d)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repo_UpdateDelivery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateDelivery'
+type Repo_UpdateDelivery_Call struct {
+	*mock.Call
+}
+
+// UpdateDelivery is a helper method to define mock.On call
+//   - d models.Delivery
+func (_e *Repo_Expecter) UpdateDelivery(d interface{}) *Repo_UpdateDelivery_Call {
+	return &Repo_UpdateDelivery_Call{Call: _e.mock.On("UpdateDelivery", d)}
+}
+
+func (_c *Repo_UpdateDelivery_Call) Run(run func(d models.Delivery)) *Repo_UpdateDelivery_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.Delivery))
+	})
+	return _c
+}
+
+func (_c *Repo_UpdateDelivery_Call) Return(_a0 error) *Repo_UpdateDelivery_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repo_UpdateDelivery_Call) RunAndReturn(run func(models.Delivery) error) *Repo_UpdateDelivery_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Webhooks provides a mock function with given fields:
+func (_m *Repo) Webhooks() ([]models.Webhook, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Webhooks")
+	}
+
+	var r0 []models.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]models.Webhook, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []models.Webhook); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_Webhooks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Webhooks'
+type Repo_Webhooks_Call struct {
+	*mock.Call
+}
+
+// Webhooks is a helper method to define mock.On call
+func (_e *Repo_Expecter) Webhooks() *Repo_Webhooks_Call {
+	return &Repo_Webhooks_Call{Call: _e.mock.On("Webhooks")}
+}
+
+func (_c *Repo_Webhooks_Call) Run(run func()) *Repo_Webhooks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Repo_Webhooks_Call) Return(_a0 []models.Webhook, _a1 error) *Repo_Webhooks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_Webhooks_Call) RunAndReturn(run func() ([]models.Webhook, error)) *Repo_Webhooks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
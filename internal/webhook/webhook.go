@@ -0,0 +1,86 @@
+// Package webhook доставляет изменения объектов хранилища внешним подписчикам HTTP POST запросами.
+//
+// События ставятся в очередь в sqlite-хранилище, поэтому недоставленные события переживают перезапуск сервиса.
+// Неудачные доставки повторяются с экспоненциальной задержкой; после исчерпания попыток событие помечается
+// как failed и остаётся в очереди для просмотра через административный API.
+//
+// Каждый запрос подписывается HMAC-SHA256 секретом подписки: заголовок X-Webhook-Signature содержит
+// "sha256=" и hex-подпись строки "<X-Webhook-Timestamp>.<тело запроса>".
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"st-test/internal/models"
+)
+
+// Заголовки запроса доставки.
+const (
+	HeaderWebhookID = "X-Webhook-Id"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// ErrInvalidWebhook возвращается когда параметры подписки некорректны.
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// Payload тело запроса доставки. Large выставляется для крупных объектов, тело которых не передаётся.
+type Payload struct {
+	Seq   uint64          `json:"seq"`
+	Type  string          `json:"type"`
+	ID    int             `json:"id"`
+	Time  time.Time       `json:"time"`
+	Body  json.RawMessage `json:"body,omitempty"`
+	Large bool            `json:"large,omitempty"`
+}
+
+func newPayload(c models.Change) ([]byte, error) {
+	p := Payload{
+		Seq:   c.Seq,
+		Type:  string(c.Type),
+		ID:    c.ID,
+		Time:  c.Time,
+		Large: c.Large,
+	}
+
+	if json.Valid(c.Body) {
+		p.Body = c.Body
+	}
+
+	return json.Marshal(p) //nolint:wrapcheck
+}
+
+// Sign возвращает значение заголовка X-Webhook-Signature для тела запроса, отправленного в момент timestamp.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись запроса. Предназначен для получателей событий.
+func Verify(secret string, timestamp int64, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, payload)), []byte(signature))
+}
+
+// newSecret генерирует случайный секрет подписки.
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	return hex.EncodeToString(b), nil
+}